@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+
+	"yule-log/internal/inventory"
+)
+
+// ---- Window Alerts
+// With --alerts, a window of the tmux server ringing its bell or flagged
+// by monitor-activity shows up in the top-right corner, its name and a
+// badge counting the others, so the screensaver need not be dismissed to
+// see that something wants attention. A new alert flashes for a moment.
+// The windows are polled like the session overlay's.
+
+// alertFlashDuration is how long a new alert flashes.
+const alertFlashDuration = 3 * time.Second
+
+// alertIndicator is the state of the window alerts.
+type alertIndicator struct {
+	alerts []inventory.Alert
+	seen   map[string]bool // Keys of the alerts shown, see inventory.Alert
+	flash  int             // Frames left flashing
+}
+
+// initAlerts starts polling the windows with --alerts.
+func (s *screensaver) initAlerts() {
+	if !s.cfg.alerts {
+		return
+	}
+	s.alerts = &alertIndicator{seen: map[string]bool{}}
+	s.pollSessions()
+}
+
+// updateAlerts picks up the flagged windows of the last listing and
+// flashes when one was not flagged before.
+func (s *screensaver) updateAlerts() {
+	a := s.alerts
+	if a == nil {
+		return
+	}
+	if a.flash > 0 {
+		a.flash--
+	}
+	a.alerts = inventory.Alerts(s.sessions.sessions, s.sessions.window)
+	seen := make(map[string]bool, len(a.alerts))
+	for _, alert := range a.alerts {
+		key := alert.Key()
+		if !a.seen[key] {
+			a.flash = framesFor(alertFlashDuration)
+		}
+		seen[key] = true
+	}
+	a.seen = seen
+}
+
+// renderAlerts draws the first alert in the top-right corner, in reverse
+// video every other alertBlink while flashing.
+func (s *screensaver) renderAlerts() {
+	a := s.alerts
+	if a == nil || len(a.alerts) == 0 || s.reveal != nil {
+		return
+	}
+	first := a.alerts[0]
+	text := fmt.Sprintf(" %c %s:%s ", first.Mark, first.Session, first.Window.Name)
+	if more := len(a.alerts) - 1; more > 0 {
+		text += fmt.Sprintf("+%d ", more)
+	}
+
+	style := tcell.StyleDefault.Foreground(s.theme.text).Background(tcell.ColorBlack).Bold(true)
+	if a.flash > 0 && (a.flash/max(framesFor(alertBlink), 1))%2 == 0 {
+		style = style.Reverse(true)
+	}
+	col := s.width - uniseg.StringWidth(text)
+	if col < 0 {
+		return
+	}
+	g := uniseg.NewGraphemes(text)
+	for g.Next() {
+		runes := g.Runes()
+		s.screen.SetContent(col, 0, runes[0], runes[1:], style)
+		col += g.Width()
+	}
+}
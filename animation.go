@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"yule-log/internal/anim"
+	"yule-log/internal/fire"
+)
+
+// ---- Animations
+
+const (
+	animationFire  = "fire"
+	animationCycle = "cycle"
+
+	defaultCycleInterval = 5 * time.Minute
+)
+
+// animationNames returns every selectable animation, fire first.
+func animationNames() []string {
+	return append([]string{animationFire}, anim.Names()...)
+}
+
+// validateAnimation checks an --animation flag value. Empty selects the
+// mode's default, see initAnimation.
+func validateAnimation(name string) error {
+	if name == "" || name == animationCycle {
+		return nil
+	}
+	for _, n := range animationNames() {
+		if n == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown animation %q (want %s or %s)", name, strings.Join(animationNames(), ", "), animationCycle)
+}
+
+// fireAnimation adapts the screensaver's own fire simulation to the
+// anim.Animation interface. The heat buffer is owned by the screensaver,
+// which also feeds it with key presses and lock state.
+type fireAnimation struct {
+	s *screensaver
+}
+
+func (f fireAnimation) Resize(int, int) {}
+
+func (f fireAnimation) Step() {
+	switch {
+	case f.s.stepReveal():
+	case f.s.stepIgnition():
+	default:
+		f.s.generateHeat()
+	}
+	f.s.sim.Step()
+}
+
+func (f fireAnimation) Draw(anim.Canvas) {
+	f.s.renderFire()
+	f.s.renderIgnition()
+	f.s.renderSparks()
+	f.s.renderReveal()
+}
+
+// fireModel returns the heat propagation model of the fire: the
+// [animation] model config entry, else the theme's, else average. Broken
+// config layers are skipped when loaded, so both names are valid.
+func (s *screensaver) fireModel() fire.Model {
+	name := s.theme.model
+	if m := s.conf.Animation.Model; m != nil {
+		name = *m
+	}
+	model, err := fire.NewModel(name)
+	if err != nil {
+		return fire.Average{}
+	}
+	return model
+}
+
+// modeAnimation returns the [animation] config entry for the mode.
+func (s *screensaver) modeAnimation() *string {
+	switch s.cfg.mode {
+	case ModeLock:
+		return s.conf.Animation.Lock
+	case ModePlayground:
+		return s.conf.Animation.Playground
+	default:
+		return s.conf.Animation.Normal
+	}
+}
+
+// initAnimation sets up the configured animation (or the first one of the
+// cycle): --animation, else the mode's [animation] config entry, else fire.
+// Unknown names from config files fall back to fire.
+func (s *screensaver) initAnimation() {
+	s.cycle = nil
+	name := s.cfg.animation
+	if name == "" {
+		if conf := s.modeAnimation(); conf != nil && validateAnimation(*conf) == nil {
+			name = *conf
+		}
+	}
+	if name == "" {
+		name = animationFire
+	}
+	if name == animationCycle {
+		s.cycle = animationNames()
+		name = s.cycle[0]
+	}
+	s.setAnimation(name)
+}
+
+func (s *screensaver) setAnimation(name string) {
+	s.animationName = name
+	s.animationFrames = 0
+	if name == animationFire {
+		s.anim = fireAnimation{s: s}
+		return
+	}
+
+	a, err := anim.New(name, anim.Options{ASCII: s.cfg.ascii, Rand: s.rng})
+	if err != nil {
+		s.anim = fireAnimation{s: s}
+		return
+	}
+	s.anim = a
+	s.anim.Resize(s.width, s.height-s.tickerRows())
+	s.feedTicker()
+}
+
+// updateAnimation advances the cycle, switching animation every
+// cycleInterval.
+func (s *screensaver) updateAnimation() {
+	s.animationFrames++
+	if len(s.cycle) < 2 {
+		return
+	}
+
+	interval := s.cfg.cycleInterval
+	if interval <= 0 {
+		interval = defaultCycleInterval
+	}
+	if s.animationFrames < framesFor(interval) {
+		return
+	}
+
+	next := 0
+	for i, name := range s.cycle {
+		if name == s.animationName {
+			next = (i + 1) % len(s.cycle)
+			break
+		}
+	}
+	s.setAnimation(s.cycle[next])
+}
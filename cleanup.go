@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"yule-log/internal/fsutil"
+	"yule-log/internal/xdg"
+)
+
+// ---- Crash Cleanup
+// A lock restricts the tmux socket and takes over the terminal. Deferred
+// calls undo both when the lock returns, even on a panic of the render
+// loop, but a panic on any other goroutine ends the process without
+// running them. Cleanups registered with atCrash run in that case too:
+// main and the goroutines started while the screen is up defer guard.
+
+var crashCleanups struct {
+	sync.Mutex
+	fns []*func()
+}
+
+// atCrash registers fn to run if a guarded goroutine panics. The returned
+// function unregisters it, to be deferred next to the regular cleanup.
+func atCrash(fn func()) (remove func()) {
+	crashCleanups.Lock()
+	defer crashCleanups.Unlock()
+	entry := &fn
+	crashCleanups.fns = append(crashCleanups.fns, entry)
+	return func() {
+		crashCleanups.Lock()
+		defer crashCleanups.Unlock()
+		for i, f := range crashCleanups.fns {
+			if f == entry {
+				crashCleanups.fns = append(crashCleanups.fns[:i], crashCleanups.fns[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// guard runs the crash cleanups, latest first, when the calling goroutine
+// panics, then panics again to report it. The panic and its stack are
+// kept for yule-log report. Defer it first thing.
+func guard() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	recordCrash(r, debug.Stack())
+	crashCleanups.Lock()
+	fns := crashCleanups.fns
+	crashCleanups.fns = nil
+	crashCleanups.Unlock()
+	for i := len(fns) - 1; i >= 0; i-- {
+		func() {
+			defer func() { _ = recover() }() // One failing cleanup must not skip the others
+			(*fns[i])()
+		}()
+	}
+	panic(r)
+}
+
+// recordCrash replaces the crash file with the report of this panic.
+// Failing to write it is not worth hiding the panic for.
+func recordCrash(r any, stack []byte) {
+	path, err := xdg.CrashFile()
+	if err != nil {
+		return
+	}
+	report := fmt.Sprintf("yule-log %s crashed at %s\n\npanic: %v\n\n%s",
+		version(), time.Now().Format(time.RFC3339), r, stack)
+	_ = fsutil.WriteFile(path, []byte(report), 0600)
+}
+
+// ---- Signals
+
+// signalStop closes a channel on the first of some signals, so that the
+// render loop returns and deferred cleanups run, instead of the process
+// dying on the spot.
+type signalStop struct {
+	stop chan struct{}
+	sigs chan os.Signal
+	done chan struct{}
+
+	mu       sync.Mutex
+	received os.Signal
+}
+
+// stopOnSignals starts catching sigs. Stop releases them.
+func stopOnSignals(sigs ...os.Signal) *signalStop {
+	s := &signalStop{
+		stop: make(chan struct{}),
+		sigs: make(chan os.Signal, 1),
+		done: make(chan struct{}),
+	}
+	signal.Notify(s.sigs, sigs...)
+	go func() {
+		select {
+		case sig := <-s.sigs:
+			s.mu.Lock()
+			s.received = sig
+			s.mu.Unlock()
+			close(s.stop)
+		case <-s.done:
+		}
+	}()
+	return s
+}
+
+// Received returns the signal caught, nil if none.
+func (s *signalStop) Received() os.Signal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.received
+}
+
+// Stop stops catching the signals.
+func (s *signalStop) Stop() {
+	signal.Stop(s.sigs)
+	close(s.done)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/render"
+)
+
+// ---- Clock
+// --clock draws the time in big digits in the middle of the fire, for
+// those who leave the screensaver up as an idle screen. The digits are
+// laid over the animation: cells between them keep the flames, and lit
+// cells keep the color of the fire behind them as background.
+
+// clockLayouts are the time formats tried in turn, the first that fits the
+// screen wins.
+var clockLayouts = []string{"15:04:05", "15:04"}
+
+// renderClock draws the current time centered above the ticker.
+func (s *screensaver) renderClock() {
+	if !s.cfg.clock || s.reveal != nil {
+		return
+	}
+	area := s.height - s.tickerRows()
+	if area < render.BigTextHeight {
+		return
+	}
+	now := time.Now()
+	var rows []string
+	for _, layout := range clockLayouts {
+		if r := render.BigText(now.Format(layout)); len(r[0]) <= s.width {
+			rows = r
+			break
+		}
+	}
+	if rows == nil {
+		return
+	}
+
+	pixel := '█'
+	if s.cfg.ascii {
+		pixel = '#'
+	}
+	left := (s.width - len(rows[0])) / 2
+	top := (area - render.BigTextHeight) / 2
+	for y, row := range rows {
+		for x, px := range row {
+			if px == ' ' {
+				continue
+			}
+			_, _, style, _ := s.screen.GetContent(left+x, top+y)
+			_, bg, _ := style.Decompose()
+			s.screen.SetContent(left+x, top+y, pixel, nil, tcell.StyleDefault.Foreground(s.theme.text).Background(bg).Bold(true))
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/command"
+	"yule-log/internal/lock"
+	"yule-log/internal/themes"
+	"yule-log/internal/xdg"
+)
+
+// ---- Command Palette
+// ':' opens a one-line command palette above the ticker in normal and
+// playground modes: the keyboard counterpart of the flags, changing the
+// running screensaver without restarting it. Tab completes command names
+// and their arguments, Enter runs the line and Esc closes the palette.
+// The lock screen has no palette: every key there is the password.
+
+// maxFPS bounds the fps command.
+const maxFPS = 60
+
+// errLockRequested ends the screensaver when the lock command asks to
+// turn it into the lock screen, see execLock.
+var errLockRequested = errors.New("lock requested")
+
+// commandPalette is the line being typed in the palette.
+type commandPalette struct {
+	input   []rune
+	matches []string // Candidates of the last completion, shown as a hint
+}
+
+// paletteCommands returns the commands of the palette.
+func (s *screensaver) paletteCommands() command.Set {
+	return command.Set{
+		{Name: "theme", Usage: "<name>", Args: func() []string {
+			dir, _ := xdg.ThemesDir()
+			return themes.Names(dir)
+		}},
+		{Name: "animation", Usage: "<name>", Args: func() []string {
+			return append(animationNames(), animationCycle)
+		}},
+		{Name: "fps", Usage: "<1-60>", Args: func() []string {
+			return []string{"10", "20", "30", "60"}
+		}},
+		{Name: "sessions"},
+		{Name: "lock"},
+		{Name: "quit"},
+		{Name: "help"},
+	}
+}
+
+// openPalette shows an empty command line.
+func (s *screensaver) openPalette() {
+	s.commands = &commandPalette{}
+}
+
+// handleKeyPalette edits the command line, running it on Enter.
+func (s *screensaver) handleKeyPalette(ev *tcell.EventKey) action {
+	p := s.commands
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		s.commands = nil
+	case tcell.KeyEnter:
+		s.commands = nil
+		return s.runCommand(string(p.input))
+	case tcell.KeyTab:
+		line, matches := s.paletteCommands().Complete(string(p.input))
+		p.input = []rune(line)
+		p.matches = nil
+		if len(matches) > 1 {
+			p.matches = matches
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(p.input) == 0 {
+			s.commands = nil
+			break
+		}
+		p.input = p.input[:len(p.input)-1]
+		p.matches = nil
+	case tcell.KeyCtrlU:
+		p.input = p.input[:0]
+		p.matches = nil
+	case tcell.KeyRune:
+		p.input = append(p.input, ev.Rune())
+		p.matches = nil
+	}
+	return actionNone
+}
+
+// runCommand runs a palette command line. Errors show as a notice.
+func (s *screensaver) runCommand(line string) action {
+	c, args, err := s.paletteCommands().Parse(line)
+	if errors.Is(err, command.ErrEmpty) {
+		return actionNone
+	}
+	if err == nil && c.Usage != "" && len(args) != 1 {
+		err = fmt.Errorf("usage: %s %s", c.Name, c.Usage)
+	}
+	if err != nil {
+		s.setNotice(err.Error())
+		return actionNone
+	}
+
+	switch c.Name {
+	case "theme":
+		err = s.setTheme(args[0])
+	case "animation":
+		err = validateAnimation(args[0])
+		if err == nil {
+			s.cfg.animation = args[0]
+			s.initAnimation()
+			s.setNotice("animation " + args[0])
+		}
+	case "fps":
+		err = s.setFPS(args[0])
+	case "sessions":
+		s.toggleSessions()
+	case "lock":
+		if !lock.PasswordExists() {
+			err = fmt.Errorf("no password configured, run 'yule-log lock set-password' first")
+			break
+		}
+		s.lockRequested = true
+		return actionExit
+	case "quit":
+		return actionExit
+	case "help":
+		s.setNotice(s.paletteCommands().Help())
+	}
+	if err != nil {
+		s.setNotice(err.Error())
+	}
+	return actionNone
+}
+
+// setTheme switches to another theme, adapted to the terminal like
+// --theme. The fire restarts with the theme's propagation model.
+func (s *screensaver) setTheme(name string) error {
+	cfg := s.cfg
+	cfg.theme = name
+	t, err := cfg.resolveTheme()
+	if err != nil {
+		return err
+	}
+	s.cfg.theme = name
+	s.theme = t
+	s.model = s.fireModel()
+	s.initPalette()
+	s.resize()
+	s.setNotice("theme " + name)
+	return nil
+}
+
+// setFPS changes the frame rate. Durations counted in frames (notices,
+// events) scale with it.
+func (s *screensaver) setFPS(arg string) error {
+	fps, err := strconv.Atoi(arg)
+	if err != nil || fps < 1 || fps > maxFPS {
+		return fmt.Errorf("fps must be within 1..%d, got %q", maxFPS, arg)
+	}
+	s.fps = fps
+	s.setNotice(fmt.Sprintf("%d fps", fps))
+	return nil
+}
+
+// baseFrameDelay is the time between frames at the chosen rate: frameDelay
+// unless changed with the fps command.
+func (s *screensaver) baseFrameDelay() time.Duration {
+	if s.fps > 0 {
+		return time.Second / time.Duration(s.fps)
+	}
+	return frameDelay
+}
+
+// renderPalette draws the command line over the notice row, followed by
+// the candidates of an ambiguous completion.
+func (s *screensaver) renderPalette() {
+	if s.commands == nil {
+		return
+	}
+	line := ":" + string(s.commands.input) + "_"
+	if len(s.commands.matches) > 0 {
+		line += "   " + strings.Join(s.commands.matches, " ")
+	}
+	s.renderOverlayLine(line)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"yule-log/internal/forge"
+	"yule-log/internal/themes"
+	"yule-log/internal/xdg"
+)
+
+// ---- Contribution Heat
+// With the contribs theme and a forge token, the heat sources follow the
+// contribution calendar of the user: the screen spans the last year, one
+// week per slice of columns, and the sources of quiet weeks burn low while
+// those of busy weeks blaze. The calendar is cached and fetched again in
+// the background a few times a day.
+
+const (
+	// contribsRefresh is how often the calendar is fetched again.
+	contribsRefresh = 6 * time.Hour
+	// contribsTimeout bounds one fetch of the calendar.
+	contribsTimeout = 30 * time.Second
+	// contribsFloor is the share of the heat power of the quietest weeks,
+	// so they still smolder.
+	contribsFloor = 0.4
+)
+
+// initContribs loads the cached contribution calendar and fetches it again
+// in the background, for the contribs theme only.
+func (s *screensaver) initContribs() {
+	if s.cfg.themeName() != themes.Contribs {
+		return
+	}
+	var backend forge.Backend
+	if s.conf.Forge.Backend != nil {
+		backend = forge.Backend(*s.conf.Forge.Backend)
+	}
+	var url, user string
+	if s.conf.Forge.URL != nil {
+		url = *s.conf.Forge.URL
+	}
+	if s.conf.Forge.User != nil {
+		user = *s.conf.Forge.User
+	}
+	path, err := xdg.ContribsCacheFile()
+	if err != nil {
+		return
+	}
+	key := forge.ContributionsKey(backend, url, user)
+
+	b := &backgroundSource{every: contribsRefresh}
+	b.fetch = func() func() {
+		token := forge.Token(backend)
+		if token == "" {
+			return nil
+		}
+		f, err := forge.New(backend, forge.Options{URL: url, Token: token})
+		if err != nil {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), contribsTimeout)
+		defer cancel()
+		days, err := f.Contributions(ctx, user)
+		if err != nil || len(days) == 0 {
+			return nil
+		}
+		_ = forge.SaveContributions(path, key, forge.ContributionsCached{Fetched: time.Now(), Days: days})
+		weeks := forge.Weeks(days)
+		return func() { s.contribWeeks = weeks }
+	}
+
+	cached, _ := forge.LoadContributions(path, key)
+	b.fetched = cached.Fetched
+	s.contribWeeks = forge.Weeks(cached.Days)
+	s.addBackgroundSource(b)
+}
+
+// contribHeat returns the heat of a source in column x: the heat power
+// scaled by the activity of the week under that column, or the heat power
+// itself without a calendar.
+func (s *screensaver) contribHeat(x int) int {
+	weeks := s.contribWeeks
+	if len(weeks) == 0 || s.width <= 0 {
+		return s.heatPower
+	}
+	level := weeks[x*len(weeks)/s.width]
+	return int(float64(s.heatPower) * (contribsFloor + (1-contribsFloor)*level))
+}
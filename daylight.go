@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"yule-log/internal/daylight"
+)
+
+// ---- Time of Day
+
+const (
+	// daylightCheckInterval is how often the temperature shift is updated.
+	daylightCheckInterval = 10 * time.Second
+	// daylightMinChange is the smallest shift change worth rebuilding the
+	// palette for. The shift moves by about 0.01 per minute at most.
+	daylightMinChange = 0.002
+)
+
+// initDaylight sets the starting temperature shift.
+func (s *screensaver) initDaylight() {
+	if s.cfg.daylight {
+		s.temperature = s.daylightShift(time.Now())
+	}
+}
+
+// updateDaylight follows the time of day, rebuilding the palette when the
+// temperature shift has drifted.
+func (s *screensaver) updateDaylight() {
+	if !s.cfg.daylight || s.frame%framesFor(daylightCheckInterval) != 0 {
+		return
+	}
+	shift := s.daylightShift(time.Now())
+	if math.Abs(shift-s.temperature) < daylightMinChange {
+		return
+	}
+	s.temperature = shift
+	s.initPalette()
+}
+
+// daylightShift returns the temperature shift at t, from sun times at the
+// configured location, or the default ones.
+func (s *screensaver) daylightShift(t time.Time) float64 {
+	sun := daylight.DefaultSun
+	if lat, lon := s.conf.Daylight.Latitude, s.conf.Daylight.Longitude; lat != nil && lon != nil {
+		if at, ok := daylight.SunAt(t, daylight.Location{Latitude: *lat, Longitude: *lon}); ok {
+			sun = at
+		}
+	}
+	return daylight.Shift(t, sun)
+}
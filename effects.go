@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/shader"
+)
+
+// ---- Effects
+// Post-processing of the fire (experimental): the heat values and colors
+// of a frame go through a chain of per-cell shaders before they are drawn,
+// see internal/shader. It is off unless the config file sets
+//
+//	[effects]
+//	enabled = true
+//	chain = ["curvature", "scanlines"]  # optional, else the theme's effects
+//
+// The shaders work on RGB colors: terminals drawing the theme's indexed
+// colors skip them, like the color levels.
+
+// initEffects builds the effect chain of the config file or the theme.
+// Both were validated when loaded.
+func (s *screensaver) initEffects() {
+	s.effects = shader.Pipeline{}
+	if enabled := s.conf.Effects.Enabled; enabled == nil || !*enabled {
+		return
+	}
+	chain := s.theme.effects
+	if s.conf.Effects.Chain != nil {
+		chain = s.conf.Effects.Chain
+	}
+	if p, err := shader.Parse(chain); err == nil {
+		s.effects = p
+	}
+}
+
+// shading reports whether the fire goes through the effect chain.
+func (s *screensaver) shading() bool {
+	return len(s.effects.Stages) > 0 && s.indexed == nil
+}
+
+// resetGrid sizes the effect grid to the fire area, the top rows of the
+// screen.
+func (s *screensaver) resetGrid(rows int) {
+	size := s.width * max(rows, 0)
+	if cap(s.grid.Cells) < size {
+		s.grid.Cells = make([]shader.Cell, size)
+	}
+	s.grid.Cells = s.grid.Cells[:size]
+	s.grid.Width, s.grid.Height, s.grid.Frame = s.width, max(rows, 0), s.frame
+}
+
+// renderEffects runs the effect chain over the grid filled by renderFire
+// and draws it.
+func (s *screensaver) renderEffects() {
+	s.effects.Apply(&s.grid)
+	for y := 0; y < s.grid.Height; y++ {
+		for x := 0; x < s.grid.Width; x++ {
+			c := s.grid.At(x, y)
+			style := tcell.StyleDefault.Foreground(tcell.NewRGBColor(int32(c.Color.R), int32(c.Color.G), int32(c.Color.B)))
+			s.screen.SetContent(x, y, s.theme.chars[clamp(c.Value, 0, 9)], nil, style)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import "time"
+
+// ---- Ember State
+// A screensaver left untouched long enough drops to a few frames per
+// second with a small fire and a still ticker, to save CPU on long runs.
+// Any input flares it back to full animation.
+
+const (
+	defaultEmberAfter = 15 * time.Minute
+	emberFrameDelay   = 500 * time.Millisecond // 2 FPS
+	emberHeatPower    = 20
+)
+
+// wake records user input, leaving the ember state with a flare.
+func (s *screensaver) wake() {
+	s.lastInput = time.Now()
+	if s.ember {
+		s.ember = false
+		s.flareFrames = flareDuration
+	}
+}
+
+// updateEmber enters the ember state after cfg.emberAfter without input.
+func (s *screensaver) updateEmber() {
+	if s.cfg.emberAfter <= 0 || s.ember || s.reveal != nil {
+		return
+	}
+	s.ember = time.Since(s.lastInput) >= s.cfg.emberAfter
+}
+
+// frameInterval returns how long to wait before the next frame.
+func (s *screensaver) frameInterval() time.Duration {
+	switch {
+	case s.ember:
+		return emberFrameDelay
+	case s.unfocused:
+		// Nobody is looking: keep the fire alive at a fraction of the
+		// frame rate to save CPU and bandwidth.
+		return s.baseFrameDelay() * unfocusedSlowdown
+	default:
+		return s.baseFrameDelay()
+	}
+}
+
+// ---- Burn Down
+// With --burn-down, the base heat follows a long arc instead of staying
+// steady: a roaring fire that settles into embers over the evening. Key
+// presses still flare it up on top.
+
+// updateBurnDown sets the base heat for the time the screensaver has run.
+func (s *screensaver) updateBurnDown() {
+	if s.cfg.burnDown > 0 {
+		s.visualState.SetBaseHeat(s.burnDown.Heat(time.Since(s.startedAt)))
+	}
+}
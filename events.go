@@ -0,0 +1,103 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/fire"
+)
+
+// ---- Random Events
+
+const (
+	defaultEventMinInterval = 30 * time.Second
+	defaultEventMaxInterval = 120 * time.Second
+
+	flareDuration = 50 // ~1.5 sec at 30ms/frame
+	flareHeat     = 40 // Extra heat at the start of a flare
+	gustDuration  = 80 // ~2.5 sec at 30ms/frame
+	sparksPerPop  = 18
+)
+
+// framesFor converts a duration to a frame count at frameDelay.
+func framesFor(d time.Duration) int {
+	return max(int(d/frameDelay), 1)
+}
+
+func (s *screensaver) initEvents() {
+	s.scheduler = fire.NewEventScheduler(s.cfg.events,
+		framesFor(s.cfg.eventMinInterval), framesFor(s.cfg.eventMaxInterval), s.rng)
+}
+
+// updateEvents advances the event scheduler and running events by one frame.
+func (s *screensaver) updateEvents() {
+	if kind, ok := s.scheduler.Tick(); ok && !s.ember {
+		s.startEvent(kind)
+	}
+
+	if shift := s.wind.Step(s.frame); shift != 0 {
+		s.shiftHeat(shift)
+	}
+	s.particles.Step(s.wind.Direction * min(s.wind.Frames, 1))
+
+	if s.flareFrames > 0 {
+		s.flareFrames--
+	}
+}
+
+func (s *screensaver) startEvent(kind fire.EventKind) {
+	switch kind {
+	case fire.EventSparks:
+		if s.width <= 0 {
+			return
+		}
+		x := float64(s.rng.Intn(s.width))
+		y := float64(s.height - 1 - s.tickerRows())
+		s.particles.Burst(s.rng, x, y, sparksPerPop)
+	case fire.EventFlare:
+		s.flareFrames = flareDuration
+	case fire.EventWind:
+		s.wind.Gust(s.rng, gustDuration)
+	}
+}
+
+// flareBonus returns the extra heat of a running flare, fading out.
+func (s *screensaver) flareBonus() int {
+	return flareHeat * s.flareFrames / flareDuration
+}
+
+// shiftHeat moves every row of the heat field one cell left or right.
+func (s *screensaver) shiftHeat(dir int) {
+	if s.width < 2 {
+		return
+	}
+	for row := 0; row < s.height; row++ {
+		line := s.sim.Heat[row*s.width : (row+1)*s.width]
+		if dir > 0 {
+			copy(line[1:], line[:len(line)-1])
+			line[0] = 0
+		} else {
+			copy(line, line[1:])
+			line[len(line)-1] = 0
+		}
+	}
+}
+
+// renderSparks draws live sparks over the fire.
+func (s *screensaver) renderSparks() {
+	limit := s.height - s.tickerRows()
+	for _, sp := range s.particles.Sparks() {
+		x, y := int(sp.X), int(sp.Y)
+		if x < 0 || x >= s.width || y < 0 || y >= limit {
+			continue
+		}
+		char := '*'
+		color := tcell.NewRGBColor(255, 220, 120)
+		if sp.Life < 10 {
+			char = '.'
+			color = tcell.NewRGBColor(200, 80, 0)
+		}
+		s.screen.SetContent(x, y, char, nil, tcell.StyleDefault.Foreground(color))
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/fire"
+)
+
+// ---- Heat Controls
+// In playground mode the arrow keys tune the fire live: up/down for the
+// heat power, left/right for the number of heat sources. With --mouse the
+// wheel does the same outside the lock screen, shift+wheel for the
+// sources. Both go through adjustHeat, which shows the new levels as a
+// gauge above the ticker. The adjustments are offsets, so they hold
+// through typing bursts, --burn-down and resizes.
+
+const (
+	heatPowerStep  = 5
+	heatSourceStep = 2
+	minHeatPower   = 10
+	maxHeatPower   = fire.MaxBurstHeat
+	heatGaugeWidth = 10
+)
+
+// adjustHeat changes the heat power and the number of heat sources by the
+// given number of steps.
+func (s *screensaver) adjustHeat(power, sources int) {
+	base := s.visualState.BaseHeat
+	s.heatAdjust = min(max(base+s.heatAdjust+power*heatPowerStep, minHeatPower), maxHeatPower) - base
+
+	s.heatSourceAdjust = s.adjustedHeatSources(s.heatSourceAdjust+sources*heatSourceStep) - s.width/heatSourceDivisor
+	s.heatSources = s.adjustedHeatSources(s.heatSourceAdjust)
+
+	s.setNotice(s.heatGauge())
+}
+
+// adjustedHeatSources returns the number of heat sources for the current
+// width with the adjustment adjust, between one and one per column.
+func (s *screensaver) adjustedHeatSources(adjust int) int {
+	return max(min(s.width/heatSourceDivisor+adjust, s.width), 1)
+}
+
+// heatGauge draws the heat power and source count.
+func (s *screensaver) heatGauge() string {
+	power := s.visualState.BaseHeat + s.heatAdjust
+	filled := heatGaugeWidth * power / maxHeatPower
+	full, empty := "█", "░"
+	if s.cfg.ascii {
+		full, empty = "#", "-"
+	}
+	bar := strings.Repeat(full, filled) + strings.Repeat(empty, heatGaugeWidth-filled)
+	return fmt.Sprintf("heat %s %d  sources %d", bar, power, s.heatSources)
+}
+
+// handleKeyHeat adjusts the heat with the arrow keys. Returns true if the
+// key was one of them.
+func (s *screensaver) handleKeyHeat(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyUp:
+		s.adjustHeat(1, 0)
+	case tcell.KeyDown:
+		s.adjustHeat(-1, 0)
+	case tcell.KeyRight:
+		s.adjustHeat(0, 1)
+	case tcell.KeyLeft:
+		s.adjustHeat(0, -1)
+	default:
+		return false
+	}
+	return true
+}
+
+// handleWheel adjusts the heat with the mouse wheel, the sources with
+// shift held. Returns true if the event was a wheel scroll.
+func (s *screensaver) handleWheel(ev *tcell.EventMouse) bool {
+	steps := 0
+	switch {
+	case ev.Buttons()&tcell.WheelUp != 0:
+		steps = 1
+	case ev.Buttons()&tcell.WheelDown != 0:
+		steps = -1
+	default:
+		return false
+	}
+	if s.cfg.mode == ModeLock {
+		return true // The lock screen is not to be tuned
+	}
+	if ev.Modifiers()&tcell.ModShift != 0 {
+		s.adjustHeat(0, steps)
+	} else {
+		s.adjustHeat(steps, 0)
+	}
+	return true
+}
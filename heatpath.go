@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/config"
+	"yule-log/internal/fire"
+	"yule-log/internal/xdg"
+)
+
+// ---- Heat Path
+// In playground mode, p starts drawing the heat sources: a cursor runs
+// along the bottom row and up the sides, drawing with the arrow keys or a
+// mouse drag. The drawn path replaces the random sources of the bottom
+// row, and Enter saves it to the [heat] profile so every mode burns along
+// it.
+
+// pathEditor is the state of the heat path being drawn.
+type pathEditor struct {
+	x, y     int
+	pen      bool      // Moving the cursor draws
+	dragging bool      // Mouse button held
+	saved    fire.Path // Path to restore on cancel
+}
+
+// initHeatPath loads the heat path of the configured profile. A broken
+// heat file keeps the random sources.
+func (s *screensaver) initHeatPath() {
+	path, err := xdg.HeatFile()
+	if err != nil {
+		return
+	}
+	s.heatPath, _ = config.LoadHeatPath(path, s.conf.HeatProfile())
+}
+
+// feedHeatPath feeds the sources of the fire along the heat path, as many
+// per cell as along the bottom row without one. It returns false without
+// a path.
+func (s *screensaver) feedHeatPath() bool {
+	cells := s.heatCells
+	if len(cells) == 0 {
+		return false
+	}
+	n := max(s.heatSources*len(cells)/s.width, 1)
+	for i := 0; i < n; i++ {
+		idx := cells[rand.Intn(len(cells))]
+		s.sim.Heat[idx] = s.contribHeat(idx % s.width)
+	}
+	return true
+}
+
+// setHeatPath replaces the heat path and the cells it covers.
+func (s *screensaver) setHeatPath(p fire.Path) {
+	s.heatPath = p
+	s.heatCells = p.Cells(s.width, s.height)
+}
+
+// startPathEditor starts drawing the heat path, the cursor in the middle
+// of the bottom row and the mouse enabled.
+func (s *screensaver) startPathEditor() {
+	s.pathEditor = &pathEditor{x: s.width / 2, y: s.height - 1, saved: s.heatPath}
+	if !s.cfg.mouse {
+		s.screen.EnableMouse(tcell.MouseDragEvents)
+	}
+	s.setNotice("heat path: arrows move, space draws, backspace undoes, x clears, enter saves, esc cancels")
+}
+
+// stopPathEditor stops drawing the heat path.
+func (s *screensaver) stopPathEditor() {
+	s.pathEditor = nil
+	if !s.cfg.mouse {
+		s.screen.DisableMouse()
+	}
+}
+
+// savePath saves the heat path to the profile.
+func (s *screensaver) savePath() {
+	profile := s.conf.HeatProfile()
+	path, err := xdg.HeatFile()
+	if err == nil {
+		err = config.SaveHeatPath(path, profile, s.heatPath)
+	}
+	if err != nil {
+		s.setNotice("heat path not saved: " + err.Error())
+		return
+	}
+	s.setNotice(fmt.Sprintf("heat path saved to profile %q", profile))
+}
+
+// handleKeyPathEditor handles the keys while drawing the heat path.
+func (s *screensaver) handleKeyPathEditor(ev *tcell.EventKey) {
+	e := s.pathEditor
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		s.setHeatPath(e.saved)
+		s.stopPathEditor()
+		s.setNotice("heat path unchanged")
+	case tcell.KeyEnter:
+		s.stopPathEditor()
+		s.savePath()
+	case tcell.KeyUp:
+		s.movePathCursor(e.x, e.y-1)
+	case tcell.KeyDown:
+		s.movePathCursor(e.x, e.y+1)
+	case tcell.KeyLeft:
+		s.movePathCursor(e.x-1, e.y)
+	case tcell.KeyRight:
+		s.movePathCursor(e.x+1, e.y)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		s.undoPathPoint()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case ' ':
+			e.pen = !e.pen
+			if e.pen {
+				s.addPathPoint(true)
+			}
+		case 'x':
+			e.pen = false
+			s.setHeatPath(nil)
+		}
+	}
+}
+
+// handleMousePathEditor draws the heat path with mouse drags.
+func (s *screensaver) handleMousePathEditor(ev *tcell.EventMouse) {
+	e := s.pathEditor
+	if ev.Buttons()&tcell.Button1 == 0 {
+		e.dragging = false
+		return
+	}
+	x, y := ev.Position()
+	e.x, e.y = fire.SnapToEdge(x, y, s.width, s.height)
+	s.addPathPoint(!e.dragging)
+	e.dragging = true
+}
+
+// movePathCursor moves the cursor to the edge cell closest to x, y,
+// drawing with the pen down.
+func (s *screensaver) movePathCursor(x, y int) {
+	e := s.pathEditor
+	e.x, e.y = fire.SnapToEdge(x, y, s.width, s.height)
+	if e.pen {
+		s.addPathPoint(false)
+	}
+}
+
+// addPathPoint adds the cursor to the heat path, as a new stroke or at the
+// end of the last one.
+func (s *screensaver) addPathPoint(newStroke bool) {
+	e := s.pathEditor
+	pt := fire.PointAt(e.x, e.y, s.width, s.height)
+	p := append(fire.Path(nil), s.heatPath...)
+	last := len(p) - 1
+	switch {
+	case newStroke || last < 0:
+		p = append(p, fire.Stroke{pt})
+	case p[last][len(p[last])-1] != pt:
+		p[last] = append(append(fire.Stroke(nil), p[last]...), pt)
+	}
+	s.setHeatPath(p)
+}
+
+// undoPathPoint removes the last point of the heat path.
+func (s *screensaver) undoPathPoint() {
+	p := append(fire.Path(nil), s.heatPath...)
+	if last := len(p) - 1; last >= 0 {
+		if p[last] = p[last][:len(p[last])-1]; len(p[last]) == 0 {
+			p = p[:last]
+			s.pathEditor.pen = false
+		}
+	}
+	s.setHeatPath(p)
+}
+
+// renderPathEditor draws the heat path and the cursor over the fire while
+// it is edited.
+func (s *screensaver) renderPathEditor() {
+	e := s.pathEditor
+	if e == nil {
+		return
+	}
+	style := tcell.StyleDefault.Foreground(s.theme.text).Background(tcell.ColorBlack)
+	for _, i := range s.heatCells {
+		s.screen.SetContent(i%s.width, i/s.width, '·', nil, style.Dim(true))
+	}
+	cursor := '+'
+	if e.pen {
+		cursor = '*'
+	}
+	s.screen.SetContent(e.x, e.y, cursor, nil, style.Bold(true))
+}
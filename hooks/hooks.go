@@ -0,0 +1,218 @@
+// Package hooks is the extension API of yule-log. Compiled-in extensions
+// implement Hooks, usually by embedding Base, and call Register from an
+// init function:
+//
+//	type bell struct{ hooks.Base }
+//
+//	func (bell) OnUnlock(u hooks.Unlock) {
+//		if !u.OK {
+//			fmt.Fprint(os.Stderr, "\a")
+//		}
+//	}
+//
+//	func init() { hooks.Register(bell{}) }
+//
+// Hooks run synchronously on the screensaver render loop or the idle
+// watcher loop: they must return quickly and start a goroutine for
+// anything slow. Events are structs so they can grow fields; the Hooks
+// interface itself doesn't change, new events get new interfaces.
+//
+// Password content never reaches hooks: in lock mode, key events name
+// Enter and Backspace, and every other key KeyHidden.
+package hooks
+
+import (
+	"sync"
+	"time"
+)
+
+// Hooks receives yule-log events.
+type Hooks interface {
+	// OnFrame is called once per rendered screensaver frame.
+	OnFrame(Frame)
+	// OnKey is called for every key pressed in the screensaver.
+	OnKey(Key)
+	// OnTrigger is called by the idle watcher when the client went idle.
+	OnTrigger(Trigger)
+	// OnLock is called when the lock screen starts.
+	OnLock(Lock)
+	// OnUnlock is called after every unlock attempt, and when a lock ends
+	// without one.
+	OnUnlock(Unlock)
+	// OnTickerItem is called when a ticker commit scrolls into view.
+	OnTickerItem(TickerItem)
+}
+
+// Mode is the screensaver mode.
+type Mode string
+
+const (
+	ModeNormal     Mode = "normal"
+	ModePlayground Mode = "playground"
+	ModeLock       Mode = "lock"
+)
+
+// Frame describes a rendered frame.
+type Frame struct {
+	Number        int
+	Width, Height int
+	HeatPower     int // Heat fed at the bottom of the fire
+}
+
+// Key describes a key press.
+type Key struct {
+	Mode Mode
+	Name string // Key name, e.g. "Enter", "Up" or "Rune"; see KeyHidden
+	Rune rune   // Typed character, always 0 in lock mode
+}
+
+// KeyHidden names the keys of lock mode other than Enter and Backspace:
+// characters, arrows, function and navigation keys may all be part of
+// the password.
+const KeyHidden = "Key"
+
+// LockKey returns the event of the key named name pressed in lock mode.
+func LockKey(name string) Key {
+	switch name {
+	case "Enter", "Backspace", "Backspace2":
+	default:
+		name = KeyHidden
+	}
+	return Key{Mode: ModeLock, Name: name}
+}
+
+// Trigger describes an idle trigger.
+type Trigger struct {
+	Idle time.Duration // How long the client has been idle
+	Lock bool          // The trigger starts the lock screen
+}
+
+// Lock describes a lock screen start.
+type Lock struct {
+	Auto bool // Engaged by the idle watcher
+}
+
+// Unlock describes an unlock attempt or the end of a lock.
+type Unlock struct {
+	OK       bool // The password was accepted
+	Failures int  // Consecutive failed attempts, including this one
+	Expired  bool // The lock gave up after its maximum duration (--max-lock)
+}
+
+// TickerItem describes a ticker commit.
+type TickerItem struct {
+	Hash    string
+	Subject string
+	Author  string
+	Time    time.Time
+}
+
+// Start describes a screensaver start.
+type Start struct {
+	Mode Mode
+}
+
+// Panic describes the panic keys pressed on the lock screen.
+type Panic struct {
+	Keys string // The key sequence, e.g. "F12 F12 F12"
+}
+
+// Panicker is implemented by hooks that want to know when the panic keys
+// are pressed on the lock screen. Multi calls it on the hooks
+// implementing it.
+type Panicker interface {
+	OnPanic(Panic)
+}
+
+// Starter is implemented by hooks that want to know when a screensaver
+// starts, in any mode. Multi calls it on the hooks implementing it.
+type Starter interface {
+	OnStart(Start)
+}
+
+// Base implements every hook as a no-op; embed it to implement only the
+// hooks you need.
+type Base struct{}
+
+func (Base) OnFrame(Frame)           {}
+func (Base) OnKey(Key)               {}
+func (Base) OnTrigger(Trigger)       {}
+func (Base) OnLock(Lock)             {}
+func (Base) OnUnlock(Unlock)         {}
+func (Base) OnTickerItem(TickerItem) {}
+
+// Multi calls each of its hooks in order.
+type Multi []Hooks
+
+func (m Multi) OnFrame(f Frame) {
+	for _, h := range m {
+		h.OnFrame(f)
+	}
+}
+
+func (m Multi) OnKey(k Key) {
+	for _, h := range m {
+		h.OnKey(k)
+	}
+}
+
+func (m Multi) OnTrigger(t Trigger) {
+	for _, h := range m {
+		h.OnTrigger(t)
+	}
+}
+
+func (m Multi) OnLock(l Lock) {
+	for _, h := range m {
+		h.OnLock(l)
+	}
+}
+
+func (m Multi) OnUnlock(u Unlock) {
+	for _, h := range m {
+		h.OnUnlock(u)
+	}
+}
+
+func (m Multi) OnStart(s Start) {
+	for _, h := range m {
+		if starter, ok := h.(Starter); ok {
+			starter.OnStart(s)
+		}
+	}
+}
+
+func (m Multi) OnPanic(p Panic) {
+	for _, h := range m {
+		if panicker, ok := h.(Panicker); ok {
+			panicker.OnPanic(p)
+		}
+	}
+}
+
+func (m Multi) OnTickerItem(item TickerItem) {
+	for _, h := range m {
+		h.OnTickerItem(item)
+	}
+}
+
+// ---- Registry
+
+var (
+	mu         sync.Mutex
+	registered Multi
+)
+
+// Register adds h to the hooks called by yule-log. Call it from init.
+func Register(h Hooks) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered = append(registered, h)
+}
+
+// Registered returns the registered hooks, in registration order.
+func Registered() Multi {
+	mu.Lock()
+	defer mu.Unlock()
+	return append(Multi(nil), registered...)
+}
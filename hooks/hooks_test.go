@@ -0,0 +1,81 @@
+package hooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	Base
+	name string
+	log  *[]string
+}
+
+func (r recorder) OnLock(Lock)     { *r.log = append(*r.log, r.name+" lock") }
+func (r recorder) OnUnlock(Unlock) { *r.log = append(*r.log, r.name+" unlock") }
+
+func TestMulti(t *testing.T) {
+	var log []string
+	m := Multi{recorder{name: "a", log: &log}, recorder{name: "b", log: &log}}
+
+	m.OnLock(Lock{})
+	m.OnFrame(Frame{}) // Base no-op
+	m.OnUnlock(Unlock{OK: true})
+
+	assert.Equal(t, []string{"a lock", "b lock", "a unlock", "b unlock"}, log)
+}
+
+type starter struct {
+	recorder
+}
+
+func (s starter) OnStart(st Start) { *s.log = append(*s.log, s.name+" start "+string(st.Mode)) }
+
+func TestMultiStart(t *testing.T) {
+	var log []string
+	m := Multi{recorder{name: "a", log: &log}, starter{recorder{name: "b", log: &log}}}
+
+	m.OnStart(Start{Mode: ModeLock})
+	m.OnLock(Lock{})
+
+	assert.Equal(t, []string{"b start lock", "a lock", "b lock"}, log)
+}
+
+type panicker struct {
+	recorder
+}
+
+func (p panicker) OnPanic(pa Panic) { *p.log = append(*p.log, p.name+" panic "+pa.Keys) }
+
+func TestMultiPanic(t *testing.T) {
+	var log []string
+	m := Multi{recorder{name: "a", log: &log}, panicker{recorder{name: "b", log: &log}}}
+
+	m.OnPanic(Panic{Keys: "F12 F12 F12"})
+
+	assert.Equal(t, []string{"b panic F12 F12 F12"}, log)
+}
+
+func TestRegister(t *testing.T) {
+	defer func() { registered = nil }()
+
+	var log []string
+	Register(recorder{name: "a", log: &log})
+	got := Registered()
+	assert.Len(t, got, 1)
+
+	// The returned slice is a copy.
+	got[0] = Base{}
+	Registered().OnLock(Lock{})
+	assert.Equal(t, []string{"a lock"}, log)
+}
+
+func TestLockKey(t *testing.T) {
+	for _, name := range []string{"Rune", "Up", "Left", "F1", "F12", "Home", "End", "PgUp", "PgDn", "Tab"} {
+		assert.Equal(t, Key{Mode: ModeLock, Name: KeyHidden}, LockKey(name), name)
+	}
+	for _, name := range []string{"Enter", "Backspace", "Backspace2"} {
+		assert.Equal(t, Key{Mode: ModeLock, Name: name}, LockKey(name))
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"yule-log/internal/config"
+	"yule-log/internal/lock"
+	"yule-log/internal/stats"
+	"yule-log/internal/themes"
+	"yule-log/internal/xdg"
+)
+
+// ---- Info
+// yule-log info answers "what is yule-log doing right now": whether the
+// idle watcher runs, whether the session is locked, which configuration
+// and theme are in use, the files involved and the last logged events.
+
+// infoLogLines is how many lines of the log info shows.
+const infoLogLines = 5
+
+// infoTimeout bounds the tmux queries of info.
+const infoTimeout = time.Second
+
+func execInfo() error {
+	writeInfo(os.Stdout)
+	return nil
+}
+
+// writeInfo writes the info report to w.
+func writeInfo(w io.Writer) {
+	ctx, cancel := context.WithTimeout(context.Background(), infoTimeout)
+	defer cancel()
+
+	fmt.Fprintf(w, "Version: %s\n", version())
+	fmt.Fprintf(w, "Idle watcher: %s\n", watcherInfo(ctx))
+	fmt.Fprintf(w, "Lock: %s\n", lockInfo())
+
+	conf, confErr := config.Load(".")
+	fmt.Fprintf(w, "Theme: %s\n", themeInfo(ctx))
+	fmt.Fprintf(w, "Heat profile: %s\n", conf.HeatProfile())
+
+	fmt.Fprintln(w, "Paths:")
+	printPath(w, "config", xdg.ConfigFile)
+	if root := config.RepoRoot("."); root != "" {
+		printPath(w, "repo config", func() (string, error) { return filepath.Join(root, config.RepoFileName), nil })
+	}
+	printPath(w, "themes", xdg.ThemesDir)
+	printPath(w, "password", xdg.PasswordFile)
+	printPath(w, "lock state", xdg.LockStateFile)
+	printPath(w, "runtime", xdg.RuntimeDir)
+	printPath(w, "log", xdg.LogFile)
+	printPath(w, "unlock log", xdg.UnlockLogFile)
+	printPath(w, "crash", xdg.CrashFile)
+
+	if confErr != nil {
+		fmt.Fprintln(w, "Config errors:")
+		for _, line := range strings.Split(confErr.Error(), "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+
+	if lines := recentLog(infoLogLines); len(lines) > 0 {
+		fmt.Fprintln(w, "Recent log:")
+		for _, line := range lines {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+}
+
+// version returns the module version and VCS revision of the binary.
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	v := info.Main.Version
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			if s.Value == "true" {
+				modified = ", modified"
+			}
+		}
+	}
+	if revision != "" {
+		v += fmt.Sprintf(" (%s%s)", shortHash(revision), modified)
+	}
+	return v
+}
+
+// watcherInfo tells whether the idle watcher of the current tmux server
+// runs, from the PID file of the tmux plugin, and when it last recorded
+// a heartbeat.
+func watcherInfo(ctx context.Context) string {
+	status := "unknown (not inside tmux)"
+	if os.Getenv("TMUX") != "" {
+		status = "not running"
+		if server, err := tmuxServerPID(ctx); err == nil {
+			pidFile := fmt.Sprintf("/tmp/yule-log-idle-%s.pid", server)
+			if pid, ok := runningPID(pidFile); ok {
+				status = fmt.Sprintf("running (pid %d)", pid)
+			}
+		}
+	}
+
+	path, err := xdg.IdleStatsFile()
+	if err != nil {
+		return status
+	}
+	samples, _ := stats.Load(path, time.Now().Add(-stats.Retention))
+	if len(samples) == 0 {
+		return status
+	}
+	last := samples[len(samples)-1].Time
+	return fmt.Sprintf("%s, last heartbeat %s ago", status, time.Since(last).Round(time.Second))
+}
+
+// runningPID returns the PID in pidFile if that process is alive.
+func runningPID(pidFile string) (int, bool) {
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	err = syscall.Kill(pid, 0)
+	return pid, err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// lockInfo describes the lock state and password.
+func lockInfo() string {
+	password := "no password"
+	if lock.PasswordExists() {
+		password = "password configured" + storedIn(lock.PasswordStore())
+	}
+	if !lock.IsLocked() {
+		return "unlocked, " + password
+	}
+	if d, err := lock.LockDuration(); err == nil {
+		return fmt.Sprintf("locked for %s, %s", d.Round(time.Second), password)
+	}
+	return "locked, " + password
+}
+
+// themeInfo returns the theme the tmux plugin or the [run] config table
+// selects, and where the choice comes from.
+func themeInfo(ctx context.Context) string {
+	if os.Getenv("TMUX") != "" {
+		out, err := exec.CommandContext(ctx, "tmux", "show-option", "-gqv", "@yule-log-mode").Output()
+		if mode := strings.TrimSpace(string(out)); err == nil && mode != "" {
+			return mode + " (@yule-log-mode)"
+		}
+	}
+	if name := os.Getenv(envVarPrefix + "_THEME"); name != "" {
+		return name + " (" + envVarPrefix + "_THEME)"
+	}
+	if path, err := xdg.ConfigFile(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			defaults, _ := config.FlagDefaults(data, "run")
+			switch {
+			case defaults["theme"] != "":
+				return defaults["theme"] + " ([run] theme)"
+			case defaults["contribs"] == "true":
+				return themes.Contribs + " ([run] contribs)"
+			}
+		}
+	}
+	return themes.Fire + " (default)"
+}
+
+// printPath prints a path of the tool and whether it exists.
+func printPath(w io.Writer, name string, path func() (string, error)) {
+	p, err := path()
+	if err != nil {
+		fmt.Fprintf(w, "  %-12s %v\n", name+":", err)
+		return
+	}
+	state := ""
+	if _, err := os.Stat(p); errors.Is(err, fs.ErrNotExist) {
+		state = " (missing)"
+	}
+	fmt.Fprintf(w, "  %-12s %s%s\n", name+":", p, state)
+}
+
+// recentLog returns the last n lines of the log file.
+func recentLog(n int) []string {
+	path, err := xdg.LogFile()
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines
+}
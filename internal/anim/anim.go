@@ -0,0 +1,88 @@
+package anim
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Animation Interface
+// Animations draw the background of the screensaver. The fire simulation
+// lives in the screensaver itself (it reacts to keys and lock state);
+// calmer ambient animations are provided by this package.
+
+// Canvas is the drawing surface. tcell.Screen satisfies it.
+type Canvas interface {
+	SetContent(x, y int, primary rune, combining []rune, style tcell.Style)
+}
+
+// Animation is a self-contained ambient animation.
+// Draw must paint every cell of its area since the screen is not cleared
+// between frames.
+type Animation interface {
+	Resize(width, height int)
+	Step()
+	Draw(c Canvas)
+}
+
+// Options configures animations.
+type Options struct {
+	ASCII bool // Only use ASCII glyphs
+	Rand  *rand.Rand
+}
+
+// Factory creates an animation.
+type Factory func(opts Options) Animation
+
+var registry = map[string]Factory{}
+
+// Register makes an animation available by name. It panics on duplicates.
+func Register(name string, f Factory) {
+	if _, ok := registry[name]; ok {
+		panic("anim: duplicate animation " + name)
+	}
+	registry[name] = f
+}
+
+// New creates the named animation.
+func New(name string, opts Options) (Animation, error) {
+	f, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown animation %q", name)
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewSource(rand.Int63()))
+	}
+	return f(opts), nil
+}
+
+// Names returns the registered animation names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fill paints the whole area with a rune and style.
+func fill(c Canvas, width, height int, r rune, style tcell.Style) {
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c.SetContent(x, y, r, nil, style)
+		}
+	}
+}
+
+// drawString draws s from (x, y), clipped to width.
+func drawString(c Canvas, x, y, width int, s string, style tcell.Style) {
+	for _, r := range s {
+		if x >= 0 && x < width {
+			c.SetContent(x, y, r, nil, style)
+		}
+		x++
+	}
+}
@@ -0,0 +1,211 @@
+package anim
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/ticker"
+)
+
+// gridCanvas records drawn cells and fails on out-of-bounds writes.
+type gridCanvas struct {
+	t             *testing.T
+	width, height int
+	cells         map[[2]int]rune
+}
+
+func newGridCanvas(t *testing.T, width, height int) *gridCanvas {
+	return &gridCanvas{t: t, width: width, height: height, cells: map[[2]int]rune{}}
+}
+
+func (g *gridCanvas) SetContent(x, y int, r rune, _ []rune, _ tcell.Style) {
+	if x < 0 || x >= g.width || y < 0 || y >= g.height {
+		g.t.Errorf("draw out of bounds at (%d, %d)", x, y)
+		return
+	}
+	g.cells[[2]int{x, y}] = r
+}
+
+func TestAnimationsStayInBounds(t *testing.T) {
+	sizes := [][2]int{{80, 24}, {1, 1}, {3, 2}, {200, 60}, {0, 0}}
+
+	for _, name := range Names() {
+		for _, size := range sizes {
+			a, err := New(name, Options{Rand: rand.New(rand.NewSource(1))})
+			require.NoError(t, err)
+
+			canvas := newGridCanvas(t, size[0], size[1])
+			a.Resize(size[0], size[1])
+			for i := 0; i < 200; i++ {
+				a.Step()
+				a.Draw(canvas)
+			}
+			assert.Len(t, canvas.cells, size[0]*size[1], "%s must paint every cell at %v", name, size)
+		}
+	}
+}
+
+func TestASCIIOnly(t *testing.T) {
+	for _, name := range Names() {
+		a, err := New(name, Options{ASCII: true, Rand: rand.New(rand.NewSource(1))})
+		require.NoError(t, err)
+
+		canvas := newGridCanvas(t, 80, 24)
+		a.Resize(80, 24)
+		for i := 0; i < 100; i++ {
+			a.Step()
+			a.Draw(canvas)
+			for pos, r := range canvas.cells {
+				require.Less(t, r, rune(0x80), "%s drew %q at %v", name, r, pos)
+			}
+		}
+	}
+}
+
+func TestNewUnknown(t *testing.T) {
+	_, err := New("volcano", Options{})
+	assert.Error(t, err)
+	assert.Contains(t, Names(), "aquarium")
+	assert.Contains(t, Names(), "lavalamp")
+	assert.Contains(t, Names(), "matrix")
+}
+
+func TestMatrixRain(t *testing.T) {
+	a, err := New("matrix", Options{Rand: rand.New(rand.NewSource(1))})
+	require.NoError(t, err)
+
+	canvas := newGridCanvas(t, 40, 20)
+	a.Resize(40, 20)
+	for i := 0; i < 150; i++ {
+		a.Step()
+	}
+	a.Draw(canvas)
+
+	drawn := 0
+	for _, r := range canvas.cells {
+		if r != ' ' {
+			drawn++
+		}
+	}
+	assert.Positive(t, drawn, "drops are falling")
+	assert.Less(t, drawn, 40*20, "columns have gaps")
+}
+
+func TestStarfieldTickerItems(t *testing.T) {
+	a, err := New("starfield", Options{Rand: rand.New(rand.NewSource(1))})
+	require.NoError(t, err)
+	a.Resize(80, 24)
+
+	listener, ok := a.(TickerListener)
+	require.True(t, ok, "starfield reacts to ticker items")
+
+	sf := a.(*starfield)
+	before := len(sf.shooting)
+	listener.OnTickerItem(ticker.Item{Subject: "feat: stars"})
+	assert.Len(t, sf.shooting, before+1)
+}
+
+func TestSnowIntensity(t *testing.T) {
+	flakesAfter := func(intensity float64) int {
+		a, err := New("snow", Options{Rand: rand.New(rand.NewSource(1))})
+		require.NoError(t, err)
+		listener, ok := a.(IntensityListener)
+		require.True(t, ok, "snow reacts to key presses")
+
+		a.Resize(80, 24)
+		listener.SetIntensity(intensity)
+		for i := 0; i < 20; i++ {
+			a.Step()
+		}
+		return len(a.(*snow).flakes)
+	}
+	assert.Greater(t, flakesAfter(1), flakesAfter(0), "typing makes it snow harder")
+}
+
+func TestSnowAccumulates(t *testing.T) {
+	a, err := New("snow", Options{Rand: rand.New(rand.NewSource(1))})
+	require.NoError(t, err)
+	a.Resize(20, 5)
+	for i := 0; i < 300; i++ {
+		a.Step()
+	}
+
+	canvas := newGridCanvas(t, 20, 5)
+	a.Draw(canvas)
+	piled := 0
+	for x := 0; x < 20; x++ {
+		if canvas.cells[[2]int{x, 4}] != ' ' {
+			piled++
+		}
+	}
+	assert.Positive(t, piled, "snow piles up on the bottom row")
+}
+
+func TestFireworksTickerFeed(t *testing.T) {
+	a, err := New("fireworks", Options{Rand: rand.New(rand.NewSource(1))})
+	require.NoError(t, err)
+	a.Resize(80, 24)
+
+	feed, ok := a.(TickerFeed)
+	require.True(t, ok, "fireworks launch the ticker commits")
+	feed.SetTickerItems([]ticker.Item{
+		{Hash: "a1", Author: "alice"},
+		{Subject: "TODOs: api 3"}, // Not a commit
+		{Hash: "b2", Author: "bob"},
+	})
+
+	fw := a.(*fireworks)
+	require.Len(t, fw.commits, 2)
+	for i := 0; i < 4; i++ {
+		fw.launch()
+	}
+	require.Len(t, fw.rockets, 4)
+	assert.Equal(t, AuthorColor("alice"), fw.rockets[0].color)
+	assert.Equal(t, AuthorColor("bob"), fw.rockets[1].color)
+	assert.Equal(t, fw.rockets[0].color, fw.rockets[2].color, "commits are launched in turn")
+	assert.NotEqual(t, AuthorColor("alice"), AuthorColor("bob"))
+
+	burst := false
+	for i := 0; i < 100 && !burst; i++ {
+		a.Step()
+		burst = len(fw.sparks) > 0
+	}
+	assert.True(t, burst, "rockets burst into sparks")
+}
+
+func TestHueColor(t *testing.T) {
+	for hue, want := range map[float64][3]int32{
+		0:       {255, 63, 63},
+		1.0 / 3: {63, 255, 63},
+		2.0 / 3: {63, 63, 255},
+	} {
+		r, g, b := hueColor(hue).RGB()
+		assert.Equal(t, want, [3]int32{r, g, b}, "hue %g", hue)
+	}
+}
+
+func TestWarp(t *testing.T) {
+	a, err := New("warp", Options{Rand: rand.New(rand.NewSource(1))})
+	require.NoError(t, err)
+	w := a.(*warp)
+
+	a.Resize(80, 24)
+	assert.Len(t, w.stars, 80*24/warpDensity)
+	a.Resize(40, 12)
+	assert.Len(t, w.stars, 40*12/warpDensity, "stars are spread again on resize")
+
+	x0, y0, _ := w.project(0.5, 0.5, 0.9)
+	x1, y1, _ := w.project(0.5, 0.5, 0.7)
+	assert.Greater(t, x1, x0, "closer stars move away from the center")
+	assert.Greater(t, y1, y0)
+
+	_, ok := a.(IntensityListener)
+	require.True(t, ok, "typing engages the warp drive")
+	rest := w.speed()
+	w.SetIntensity(1)
+	assert.InDelta(t, rest*warpBoost, w.speed(), 1e-9)
+}
@@ -0,0 +1,185 @@
+package anim
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Aquarium
+// Drifting fish, rising bubbles and swaying seaweed.
+
+func init() {
+	Register("aquarium", func(opts Options) Animation { return newAquarium(opts) })
+}
+
+var (
+	fishRight = []string{"><>", "><))°>", ">=>", "><(((°>"}
+	fishLeft  = []string{"<><", "<°((><", "<=<", "<°)))><"}
+
+	fishRightASCII = []string{"><>", "><))o>", ">=>", "><(((o>"}
+	fishLeftASCII  = []string{"<><", "<o((><", "<=<", "<o)))><"}
+
+	waterStyle = tcell.StyleDefault.Foreground(tcell.NewRGBColor(20, 60, 110))
+	sandStyle  = tcell.StyleDefault.Foreground(tcell.NewRGBColor(150, 130, 80))
+	weedStyle  = tcell.StyleDefault.Foreground(tcell.NewRGBColor(40, 160, 70))
+	bubbleFg   = tcell.StyleDefault.Foreground(tcell.NewRGBColor(170, 220, 255))
+
+	fishColors = []tcell.Color{
+		tcell.NewRGBColor(255, 140, 0),
+		tcell.NewRGBColor(255, 215, 0),
+		tcell.NewRGBColor(255, 100, 120),
+		tcell.NewRGBColor(120, 200, 255),
+		tcell.NewRGBColor(200, 160, 255),
+	}
+)
+
+type fish struct {
+	x     float64
+	y     int
+	speed float64 // Negative swims left
+	shape int
+	color tcell.Color
+}
+
+type bubble struct {
+	x, y  float64
+	drift float64
+}
+
+type aquarium struct {
+	opts          Options
+	rng           *rand.Rand
+	width, height int
+	fish          []fish
+	bubbles       []bubble
+	weeds         []int // Seaweed columns
+	weedHeights   []int
+	tick          int
+}
+
+func newAquarium(opts Options) *aquarium {
+	return &aquarium{opts: opts, rng: opts.Rand}
+}
+
+func (a *aquarium) Resize(width, height int) {
+	a.width, a.height = width, height
+	a.fish = a.fish[:0]
+	a.bubbles = a.bubbles[:0]
+	a.weeds = a.weeds[:0]
+	a.weedHeights = a.weedHeights[:0]
+	if width <= 0 || height <= 2 {
+		return
+	}
+
+	for i := 0; i < max(width*height/250, 3); i++ {
+		a.fish = append(a.fish, a.newFish(float64(a.rng.Intn(width))))
+	}
+	for x := 2 + a.rng.Intn(6); x < width; x += 5 + a.rng.Intn(10) {
+		a.weeds = append(a.weeds, x)
+		a.weedHeights = append(a.weedHeights, 2+a.rng.Intn(max(height/3, 1)))
+	}
+}
+
+func (a *aquarium) newFish(x float64) fish {
+	f := fish{
+		x:     x,
+		y:     a.rng.Intn(max(a.height-2, 1)),
+		speed: 0.1 + a.rng.Float64()*0.3,
+		shape: a.rng.Intn(len(fishRight)),
+		color: fishColors[a.rng.Intn(len(fishColors))],
+	}
+	if a.rng.Intn(2) == 0 {
+		f.speed = -f.speed
+	}
+	return f
+}
+
+func (a *aquarium) Step() {
+	a.tick++
+	for i := range a.fish {
+		f := &a.fish[i]
+		f.x += f.speed
+		n := len(fishRight[f.shape])
+		if f.x > float64(a.width+n) || f.x < float64(-n*2) {
+			// Respawn on the opposite side
+			*f = a.newFish(0)
+			if f.speed > 0 {
+				f.x = float64(-n)
+			} else {
+				f.x = float64(a.width)
+			}
+		}
+		if a.rng.Intn(120) == 0 {
+			a.bubbles = append(a.bubbles, bubble{x: f.x, y: float64(f.y)})
+		}
+	}
+
+	if a.width > 0 && a.rng.Intn(6) == 0 {
+		a.bubbles = append(a.bubbles, bubble{x: float64(a.rng.Intn(a.width)), y: float64(a.height - 1)})
+	}
+
+	alive := a.bubbles[:0]
+	for _, b := range a.bubbles {
+		b.y -= 0.25
+		b.drift += 0.15
+		if b.y >= 0 {
+			alive = append(alive, b)
+		}
+	}
+	a.bubbles = alive
+}
+
+func (a *aquarium) Draw(c Canvas) {
+	fill(c, a.width, a.height, ' ', waterStyle)
+	if a.height <= 2 {
+		return
+	}
+
+	// Sand
+	for x := 0; x < a.width; x++ {
+		c.SetContent(x, a.height-1, '.', nil, sandStyle)
+	}
+
+	// Seaweed sways with time
+	for i, x := range a.weeds {
+		for h := 0; h < a.weedHeights[i]; h++ {
+			y := a.height - 2 - h
+			if y < 0 {
+				break
+			}
+			r := '('
+			if (h+a.tick/15)%2 == 0 {
+				r = ')'
+			}
+			c.SetContent(x, y, r, nil, weedStyle)
+		}
+	}
+
+	for _, b := range a.bubbles {
+		x := int(b.x + math.Sin(b.drift))
+		r := 'o'
+		if b.y < float64(a.height)/3 {
+			r = '°'
+			if a.opts.ASCII {
+				r = '.'
+			}
+		}
+		if x >= 0 && x < a.width && int(b.y) < a.height-1 {
+			c.SetContent(x, int(b.y), r, nil, bubbleFg)
+		}
+	}
+
+	right, left := fishRight, fishLeft
+	if a.opts.ASCII {
+		right, left = fishRightASCII, fishLeftASCII
+	}
+	for _, f := range a.fish {
+		shape := right[f.shape]
+		if f.speed < 0 {
+			shape = left[f.shape]
+		}
+		drawString(c, int(f.x), f.y, a.width, shape, tcell.StyleDefault.Foreground(f.color))
+	}
+}
@@ -0,0 +1,211 @@
+package anim
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/ticker"
+)
+
+// ---- Fireworks
+// One rocket per recent commit of the ticker, colored by its author, going
+// up from the bottom and bursting into sparks. The commits are launched in
+// turn, over and over; without a ticker the colors are random.
+
+func init() {
+	Register("fireworks", func(opts Options) Animation { return newFireworks(opts) })
+}
+
+// TickerFeed is implemented by animations using every item of the ticker
+// rather than the ones scrolling into view, see TickerListener. It is
+// called again whenever the ticker changes.
+type TickerFeed interface {
+	SetTickerItems(items []ticker.Item)
+}
+
+var (
+	sparkGlyphs      = []rune{'·', '+', '*', '✦'} // Dying to fresh
+	sparkGlyphsASCII = []rune{'.', '+', '*', '*'}
+)
+
+const (
+	fireworksGravity = 0.015
+	fireworksDrag    = 0.96
+	launchMinWait    = 25 // Frames between two rockets
+	launchMaxWait    = 70
+	burstMinSparks   = 20
+	burstMaxSparks   = 40
+	sparkMinLife     = 25 // Frames
+	sparkMaxLife     = 50
+)
+
+type rocket struct {
+	x, y  float64
+	vy    float64
+	apex  float64 // Row at which it bursts
+	color tcell.Color
+}
+
+type spark struct {
+	x, y    float64
+	vx, vy  float64
+	life    int
+	maxLife int
+	r, g, b int32
+}
+
+type fireworks struct {
+	opts          Options
+	rng           *rand.Rand
+	width, height int
+	commits       []ticker.Item
+	next          int // Next commit to launch
+	wait          int // Frames until the next launch
+	rockets       []rocket
+	sparks        []spark
+}
+
+func newFireworks(opts Options) *fireworks {
+	return &fireworks{opts: opts, rng: opts.Rand}
+}
+
+func (f *fireworks) Resize(width, height int) {
+	f.width, f.height = width, height
+	f.rockets = f.rockets[:0]
+	f.sparks = f.sparks[:0]
+	f.wait = 0
+}
+
+// SetTickerItems keeps the commits of the ticker, the other items have no
+// author to color a rocket with.
+func (f *fireworks) SetTickerItems(items []ticker.Item) {
+	f.commits = f.commits[:0]
+	for _, item := range items {
+		if item.Hash != "" {
+			f.commits = append(f.commits, item)
+		}
+	}
+	f.next = 0
+}
+
+// AuthorColor returns a bright color that only depends on the author, so
+// that someone's rockets, and commits in the ticker, are always the same
+// color.
+func AuthorColor(author string) tcell.Color {
+	h := fnv.New32a()
+	h.Write([]byte(author))
+	return hueColor(float64(h.Sum32()%360) / 360)
+}
+
+// hueColor returns the saturated color of hue (0 to 1).
+func hueColor(hue float64) tcell.Color {
+	channel := func(offset float64) int32 {
+		// Distance to the channel's peak on the color wheel, in sixths
+		d := math.Abs(math.Mod(hue*6+offset, 6) - 3)
+		return int32(255 * math.Max(0.25, math.Min(1, d-1)))
+	}
+	return tcell.NewRGBColor(channel(0), channel(4), channel(2))
+}
+
+func (f *fireworks) launch() {
+	color := hueColor(f.rng.Float64())
+	if len(f.commits) > 0 {
+		color = AuthorColor(f.commits[f.next%len(f.commits)].Author)
+		f.next = (f.next + 1) % len(f.commits)
+	}
+	f.rockets = append(f.rockets, rocket{
+		x:     float64(f.width/6 + f.rng.Intn(max(f.width*2/3, 1))),
+		y:     float64(f.height - 1),
+		vy:    -(0.4 + f.rng.Float64()*0.4),
+		apex:  float64(f.height/8 + f.rng.Intn(max(f.height/3, 1))),
+		color: color,
+	})
+}
+
+func (f *fireworks) burst(r rocket) {
+	cr, cg, cb := r.color.RGB()
+	n := burstMinSparks + f.rng.Intn(burstMaxSparks-burstMinSparks+1)
+	for i := 0; i < n; i++ {
+		angle := f.rng.Float64() * 2 * math.Pi
+		speed := 0.2 + f.rng.Float64()*0.5
+		life := sparkMinLife + f.rng.Intn(sparkMaxLife-sparkMinLife+1)
+		f.sparks = append(f.sparks, spark{
+			x: r.x, y: r.y,
+			// Cells are about twice as high as wide: spread wider than high
+			vx: math.Cos(angle) * speed * 2, vy: math.Sin(angle) * speed,
+			life: life, maxLife: life,
+			r: cr, g: cg, b: cb,
+		})
+	}
+}
+
+func (f *fireworks) Step() {
+	if f.width <= 0 || f.height <= 0 {
+		return
+	}
+	if f.wait--; f.wait <= 0 {
+		f.launch()
+		f.wait = launchMinWait + f.rng.Intn(launchMaxWait-launchMinWait+1)
+	}
+
+	flying := f.rockets[:0]
+	for _, r := range f.rockets {
+		r.y += r.vy
+		if r.y <= r.apex {
+			f.burst(r)
+			continue
+		}
+		flying = append(flying, r)
+	}
+	f.rockets = flying
+
+	alive := f.sparks[:0]
+	for _, s := range f.sparks {
+		s.vx *= fireworksDrag
+		s.vy = s.vy*fireworksDrag + fireworksGravity
+		s.x += s.vx
+		s.y += s.vy
+		s.life--
+		if s.life > 0 && s.y < float64(f.height) {
+			alive = append(alive, s)
+		}
+	}
+	f.sparks = alive
+}
+
+func (f *fireworks) Draw(c Canvas) {
+	fill(c, f.width, f.height, ' ', skyStyle)
+
+	glyphs := sparkGlyphs
+	trail := '│'
+	if f.opts.ASCII {
+		glyphs = sparkGlyphsASCII
+		trail = '|'
+	}
+	inside := func(x, y int) bool { return x >= 0 && x < f.width && y >= 0 && y < f.height }
+
+	for _, s := range f.sparks {
+		x, y := int(s.x), int(s.y)
+		if !inside(x, y) {
+			continue
+		}
+		age := float64(s.life) / float64(s.maxLife) // 1 when fresh
+		glyph := glyphs[min(int(age*float64(len(glyphs))), len(glyphs)-1)]
+		fade := func(v int32) int32 { return int32(float64(v) * (0.3 + 0.7*age)) }
+		style := tcell.StyleDefault.Foreground(tcell.NewRGBColor(fade(s.r), fade(s.g), fade(s.b)))
+		c.SetContent(x, y, glyph, nil, style)
+	}
+
+	for _, r := range f.rockets {
+		x, y := int(r.x), int(r.y)
+		if inside(x, y) {
+			c.SetContent(x, y, '^', nil, tcell.StyleDefault.Foreground(r.color).Bold(true))
+		}
+		if inside(x, y+1) {
+			c.SetContent(x, y+1, trail, nil, tcell.StyleDefault.Foreground(tcell.NewRGBColor(120, 100, 80)))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package anim
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Lava Lamp
+// Metaballs slowly rising and sinking, rendered with shade characters.
+
+func init() {
+	Register("lavalamp", func(opts Options) Animation { return newLavaLamp(opts) })
+}
+
+var (
+	shadeRamp      = []rune{' ', '░', '▒', '▓', '█'}
+	shadeRampASCII = []rune{' ', '.', 'o', 'O', '@'}
+)
+
+type blob struct {
+	x, y   float64
+	vy     float64
+	phase  float64
+	radius float64
+}
+
+type lavaLamp struct {
+	opts          Options
+	rng           *rand.Rand
+	width, height int
+	blobs         []blob
+	tick          float64
+}
+
+func newLavaLamp(opts Options) *lavaLamp {
+	return &lavaLamp{opts: opts, rng: opts.Rand}
+}
+
+func (l *lavaLamp) Resize(width, height int) {
+	l.width, l.height = width, height
+	l.blobs = l.blobs[:0]
+	if width <= 0 || height <= 0 {
+		return
+	}
+	for i := 0; i < max(width/12, 3); i++ {
+		l.blobs = append(l.blobs, blob{
+			x:      l.rng.Float64() * float64(width),
+			y:      l.rng.Float64() * float64(height),
+			vy:     (0.05 + l.rng.Float64()*0.1) * float64(1-2*l.rng.Intn(2)),
+			phase:  l.rng.Float64() * math.Pi * 2,
+			radius: 2 + l.rng.Float64()*float64(max(height/5, 2)),
+		})
+	}
+}
+
+func (l *lavaLamp) Step() {
+	l.tick += 0.02
+	for i := range l.blobs {
+		b := &l.blobs[i]
+		b.y += b.vy
+		// Hot wax rises, cools at the top and sinks again
+		if b.y < 0 || b.y > float64(l.height) {
+			b.vy = -b.vy
+			b.y = math.Max(0, math.Min(float64(l.height), b.y))
+		}
+	}
+}
+
+// field returns the metaball field value at (x, y). Cells are about twice
+// as tall as wide, so vertical distance is scaled.
+func (l *lavaLamp) field(x, y float64) float64 {
+	sum := 0.0
+	for _, b := range l.blobs {
+		bx := b.x + math.Sin(l.tick+b.phase)*3
+		dx := x - bx
+		dy := (y - b.y) * 2
+		d2 := dx*dx + dy*dy
+		if d2 < 0.01 {
+			d2 = 0.01
+		}
+		sum += b.radius * b.radius / d2
+	}
+	return sum
+}
+
+func (l *lavaLamp) Draw(c Canvas) {
+	ramp := shadeRamp
+	if l.opts.ASCII {
+		ramp = shadeRampASCII
+	}
+
+	for y := 0; y < l.height; y++ {
+		// Warm at the bottom, cooler at the top
+		t := float64(y) / float64(max(l.height-1, 1))
+		base := tcell.NewRGBColor(int32(140+100*t), int32(30+40*t), int32(120-80*t))
+		for x := 0; x < l.width; x++ {
+			v := l.field(float64(x)+0.5, float64(y)+0.5)
+			level := 0
+			switch {
+			case v > 2.5:
+				level = 4
+			case v > 1.6:
+				level = 3
+			case v > 1.0:
+				level = 2
+			case v > 0.6:
+				level = 1
+			}
+			c.SetContent(x, y, ramp[level], nil, tcell.StyleDefault.Foreground(base))
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package anim
+
+import (
+	"math/rand"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Matrix Rain
+// Columns of green glyphs falling at different speeds, a bright head
+// followed by a fading trail. Glyphs flicker to new ones as they fall.
+
+func init() {
+	Register("matrix", func(opts Options) Animation { return newMatrix(opts) })
+}
+
+var (
+	// Half-width katakana are one column wide, like the ASCII fallback.
+	matrixGlyphs      = []rune("ｦｱｲｳｴｵｶｷｸｹｺｻｼｽｾｿﾀﾁﾂﾃﾄﾅﾆﾇﾈﾉﾊﾋﾌﾍﾎﾏﾐﾑﾒﾓﾔﾕﾖﾗﾘﾙﾚﾛﾜﾝ0123456789")
+	matrixGlyphsASCII = []rune("abcdefghijklmnopqrstuvwxyz0123456789$+-*/=%<>!?#&")
+
+	matrixHeadStyle = tcell.StyleDefault.Foreground(tcell.NewRGBColor(200, 255, 200)).Bold(true)
+)
+
+const (
+	matrixFlicker = 20 // 1 in N glyphs changes every frame
+	matrixIdle    = 60 // Max frames a column stays empty between drops
+)
+
+// drop is a falling glyph trail in one column. Before it enters the
+// screen, wait counts the frames left.
+type drop struct {
+	y      float64
+	speed  float64 // Rows per frame
+	length int
+	wait   int
+}
+
+type matrix struct {
+	opts          Options
+	rng           *rand.Rand
+	glyphs        []rune
+	width, height int
+	drops         []drop // One per column
+	cells         []rune // Glyph of each cell, left behind by the drops
+}
+
+func newMatrix(opts Options) *matrix {
+	glyphs := matrixGlyphs
+	if opts.ASCII {
+		glyphs = matrixGlyphsASCII
+	}
+	return &matrix{opts: opts, rng: opts.Rand, glyphs: glyphs}
+}
+
+func (m *matrix) Resize(width, height int) {
+	m.width, m.height = width, height
+	m.drops = m.drops[:0]
+	m.cells = m.cells[:0]
+	if width <= 0 || height <= 0 {
+		return
+	}
+	m.cells = make([]rune, width*height)
+	for i := range m.cells {
+		m.cells[i] = m.glyph()
+	}
+	for x := 0; x < width; x++ {
+		d := m.newDrop()
+		d.wait = m.rng.Intn(matrixIdle * 2) // Stagger the first drops
+		m.drops = append(m.drops, d)
+	}
+}
+
+func (m *matrix) glyph() rune {
+	return m.glyphs[m.rng.Intn(len(m.glyphs))]
+}
+
+func (m *matrix) newDrop() drop {
+	return drop{
+		speed:  0.3 + m.rng.Float64()*0.7,
+		length: max(m.height/3, 2) + m.rng.Intn(max(m.height/2, 1)),
+		wait:   m.rng.Intn(matrixIdle),
+	}
+}
+
+func (m *matrix) Step() {
+	for i := range m.cells {
+		if m.rng.Intn(matrixFlicker) == 0 {
+			m.cells[i] = m.glyph()
+		}
+	}
+	for x := range m.drops {
+		d := &m.drops[x]
+		if d.wait > 0 {
+			d.wait--
+			continue
+		}
+		d.y += d.speed
+		if int(d.y)-d.length >= m.height {
+			*d = m.newDrop()
+		}
+	}
+}
+
+func (m *matrix) Draw(c Canvas) {
+	fill(c, m.width, m.height, ' ', tcell.StyleDefault)
+
+	for x, d := range m.drops {
+		if d.wait > 0 {
+			continue
+		}
+		head := int(d.y)
+		for i := 0; i <= d.length; i++ {
+			y := head - i
+			if y < 0 || y >= m.height {
+				continue
+			}
+			style := matrixHeadStyle
+			if i > 0 {
+				// Trail fades from bright to dark green
+				g := int32(230 - 190*i/d.length)
+				style = tcell.StyleDefault.Foreground(tcell.NewRGBColor(0, g, g/4))
+			}
+			c.SetContent(x, y, m.cells[y*m.width+x], nil, style)
+		}
+	}
+}
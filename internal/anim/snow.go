@@ -0,0 +1,157 @@
+package anim
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Snow
+// Snowflakes drifting down in a slowly turning wind and piling up on the
+// bottom row. Typing makes it snow harder, like it feeds the fire.
+
+func init() {
+	Register("snow", func(opts Options) Animation { return newSnow(opts) })
+}
+
+// IntensityListener is implemented by animations reacting to key presses
+// the way the fire does. The ratio (0 to 1) is the accumulated key burst,
+// see fire.VisualState.IntensityRatio.
+type IntensityListener interface {
+	SetIntensity(ratio float64)
+}
+
+var (
+	flakeGlyphs      = []rune{'.', '*', '❄', '❅', '❆'}
+	flakeGlyphsASCII = []rune{'.', '*', '+', 'o'}
+	pileRamp         = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+	pileRampASCII    = []rune{' ', '.', '_', '=', '#'}
+
+	nightStyle = tcell.StyleDefault.Foreground(tcell.NewRGBColor(20, 20, 40))
+	pileStyle  = tcell.StyleDefault.Foreground(tcell.NewRGBColor(230, 235, 255))
+)
+
+const (
+	snowDensity   = 60   // Columns per new flake per frame, at rest
+	snowBurst     = 6    // Snowfall multiplier at full intensity
+	pileMeltTicks = 1200 // 1 in N pile levels melts every frame
+)
+
+type flake struct {
+	x, y  float64
+	vy    float64
+	phase float64
+	glyph rune
+}
+
+type snow struct {
+	opts          Options
+	rng           *rand.Rand
+	width, height int
+	flakes        []flake
+	pile          []int // Snow level of each column on the bottom row
+	intensity     float64
+	tick          float64
+	spawn         float64 // Fractional flakes carried to the next frame
+}
+
+func newSnow(opts Options) *snow {
+	return &snow{opts: opts, rng: opts.Rand}
+}
+
+func (s *snow) Resize(width, height int) {
+	s.width, s.height = width, height
+	s.flakes = s.flakes[:0]
+	s.pile = nil
+	if width <= 0 || height <= 0 {
+		return
+	}
+	s.pile = make([]int, width)
+	// Start mid-snowfall rather than with an empty sky
+	for i := 0; i < width*height/snowDensity/4; i++ {
+		f := s.newFlake()
+		f.y = s.rng.Float64() * float64(height)
+		s.flakes = append(s.flakes, f)
+	}
+}
+
+// SetIntensity makes it snow harder after key presses.
+func (s *snow) SetIntensity(ratio float64) {
+	s.intensity = math.Max(0, math.Min(1, ratio))
+}
+
+func (s *snow) ramp() []rune {
+	if s.opts.ASCII {
+		return pileRampASCII
+	}
+	return pileRamp
+}
+
+func (s *snow) newFlake() flake {
+	glyphs := flakeGlyphs
+	if s.opts.ASCII {
+		glyphs = flakeGlyphsASCII
+	}
+	return flake{
+		x:     s.rng.Float64() * float64(s.width),
+		vy:    0.15 + s.rng.Float64()*0.35,
+		phase: s.rng.Float64() * math.Pi * 2,
+		glyph: glyphs[s.rng.Intn(len(glyphs))],
+	}
+}
+
+func (s *snow) Step() {
+	if s.width <= 0 || s.height <= 0 {
+		return
+	}
+	s.tick += 0.01
+	wind := math.Sin(s.tick) * 0.3
+
+	s.spawn += float64(s.width) / snowDensity * (1 + s.intensity*(snowBurst-1))
+	for ; s.spawn >= 1; s.spawn-- {
+		s.flakes = append(s.flakes, s.newFlake())
+	}
+
+	maxLevel := len(s.ramp()) - 1
+	alive := s.flakes[:0]
+	for _, f := range s.flakes {
+		f.y += f.vy
+		f.x += wind + math.Sin(s.tick*20+f.phase)*0.2
+		f.x = math.Mod(f.x+float64(s.width), float64(s.width))
+		if int(f.y) >= s.height-1 {
+			col := int(f.x)
+			s.pile[col] = min(s.pile[col]+1, maxLevel)
+			continue
+		}
+		alive = append(alive, f)
+	}
+	s.flakes = alive
+
+	for x := range s.pile {
+		if s.pile[x] > 0 && s.rng.Intn(pileMeltTicks) == 0 {
+			s.pile[x]--
+		}
+	}
+}
+
+func (s *snow) Draw(c Canvas) {
+	fill(c, s.width, s.height, ' ', nightStyle)
+	if s.width <= 0 || s.height <= 0 {
+		return
+	}
+
+	for _, f := range s.flakes {
+		x, y := int(f.x), int(f.y)
+		if x < 0 || x >= s.width || y < 0 || y >= s.height-1 {
+			continue
+		}
+		v := int32(170 + 85*f.vy/0.5) // Faster flakes are closer and brighter
+		c.SetContent(x, y, f.glyph, nil, tcell.StyleDefault.Foreground(tcell.NewRGBColor(v, v, min(v+20, 255))))
+	}
+
+	ramp := s.ramp()
+	for x, level := range s.pile {
+		c.SetContent(x, s.height-1, ramp[level], nil, pileStyle)
+	}
+}
@@ -0,0 +1,144 @@
+package anim
+
+import (
+	"math/rand"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/ticker"
+)
+
+// ---- Starfield
+// Twinkling stars; every ticker item scrolling into view launches a
+// shooting star, tying the commit stream to the sky.
+
+func init() {
+	Register("starfield", func(opts Options) Animation { return newStarfield(opts) })
+}
+
+// TickerListener is implemented by animations reacting to ticker items
+// entering the screen.
+type TickerListener interface {
+	OnTickerItem(item ticker.Item)
+}
+
+const (
+	shootingTail      = 8
+	spontaneousChance = 900 // 1 in N frames without ticker
+)
+
+var skyStyle = tcell.StyleDefault.Foreground(tcell.NewRGBColor(10, 10, 30))
+
+type star struct {
+	x, y  int
+	phase int
+	glyph rune
+}
+
+type shootingStar struct {
+	x, y   float64
+	vx, vy float64
+	life   int
+}
+
+type starfield struct {
+	opts          Options
+	rng           *rand.Rand
+	width, height int
+	stars         []star
+	shooting      []shootingStar
+	tick          int
+}
+
+func newStarfield(opts Options) *starfield {
+	return &starfield{opts: opts, rng: opts.Rand}
+}
+
+func (s *starfield) Resize(width, height int) {
+	s.width, s.height = width, height
+	s.stars = s.stars[:0]
+	s.shooting = s.shooting[:0]
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	glyphs := []rune{'.', '.', '.', '+', '*'}
+	for i := 0; i < max(width*height/40, 1); i++ {
+		s.stars = append(s.stars, star{
+			x:     s.rng.Intn(width),
+			y:     s.rng.Intn(height),
+			phase: s.rng.Intn(120),
+			glyph: glyphs[s.rng.Intn(len(glyphs))],
+		})
+	}
+}
+
+// OnTickerItem launches a shooting star for a ticker item entering view.
+func (s *starfield) OnTickerItem(ticker.Item) {
+	s.launch()
+}
+
+func (s *starfield) launch() {
+	if s.width <= 0 || s.height <= 0 {
+		return
+	}
+	// Start in the upper right area and fall towards the lower left, the
+	// same direction the ticker scrolls.
+	s.shooting = append(s.shooting, shootingStar{
+		x:    float64(s.width/3 + s.rng.Intn(max(s.width*2/3, 1))),
+		y:    float64(s.rng.Intn(max(s.height/3, 1))),
+		vx:   -(1.2 + s.rng.Float64()),
+		vy:   0.3 + s.rng.Float64()*0.3,
+		life: 25 + s.rng.Intn(20),
+	})
+}
+
+func (s *starfield) Step() {
+	s.tick++
+	if s.rng.Intn(spontaneousChance) == 0 {
+		s.launch()
+	}
+
+	alive := s.shooting[:0]
+	for _, sh := range s.shooting {
+		sh.x += sh.vx
+		sh.y += sh.vy
+		sh.life--
+		if sh.life > 0 && sh.x > -shootingTail && sh.y < float64(s.height) {
+			alive = append(alive, sh)
+		}
+	}
+	s.shooting = alive
+}
+
+func (s *starfield) Draw(c Canvas) {
+	fill(c, s.width, s.height, ' ', skyStyle)
+
+	for _, st := range s.stars {
+		// Slow twinkle: brightness follows a triangle wave
+		t := (s.tick + st.phase) % 120
+		if t > 60 {
+			t = 120 - t
+		}
+		v := int32(80 + t*3)
+		c.SetContent(st.x, st.y, st.glyph, nil, tcell.StyleDefault.Foreground(tcell.NewRGBColor(v, v, v+20)))
+	}
+
+	for _, sh := range s.shooting {
+		for i := shootingTail; i >= 0; i-- {
+			x := int(sh.x - sh.vx*float64(i)*0.5)
+			y := int(sh.y - sh.vy*float64(i)*0.5)
+			if x < 0 || x >= s.width || y < 0 || y >= s.height {
+				continue
+			}
+			glyph := '.'
+			if i == 0 {
+				glyph = '*'
+			} else if i < 3 {
+				glyph = '-'
+			}
+			v := int32(255 - i*25)
+			c.SetContent(x, y, glyph, nil, tcell.StyleDefault.Foreground(tcell.NewRGBColor(v, v, int32(min(255, int(v)+30)))))
+		}
+	}
+}
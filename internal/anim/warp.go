@@ -0,0 +1,120 @@
+package anim
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Warp
+// The classic starfield screensaver: stars fly out of the center of the
+// screen, growing brighter as they come closer. Typing engages the warp
+// drive and stretches them into streaks.
+
+func init() {
+	Register("warp", func(opts Options) Animation { return newWarp(opts) })
+}
+
+var (
+	warpGlyphs      = []rune{'·', '∙', '+', '*'} // Far to near
+	warpGlyphsASCII = []rune{'.', '.', '+', '*'}
+)
+
+const (
+	warpDensity   = 30   // Cells per star
+	warpSpeed     = 0.01 // Depth traveled per frame, at rest
+	warpBoost     = 5    // Speed multiplier at full intensity
+	warpNear      = 0.05 // Depth at which a star has passed the viewer
+	warpStreakMin = 0.2  // Intensity above which stars leave streaks
+)
+
+// warpStar is a star in view space: x and y within -1..1, depth z within
+// warpNear..1.
+type warpStar struct {
+	x, y, z float64
+}
+
+type warp struct {
+	opts          Options
+	rng           *rand.Rand
+	width, height int
+	stars         []warpStar
+	intensity     float64
+}
+
+func newWarp(opts Options) *warp {
+	return &warp{opts: opts, rng: opts.Rand}
+}
+
+func (w *warp) Resize(width, height int) {
+	w.width, w.height = width, height
+	w.stars = w.stars[:0]
+	if width <= 0 || height <= 0 {
+		return
+	}
+	for i := 0; i < max(width*height/warpDensity, 1); i++ {
+		s := w.newStar()
+		s.z = warpNear + w.rng.Float64()*(1-warpNear) // Already on their way
+		w.stars = append(w.stars, s)
+	}
+}
+
+// SetIntensity speeds the stars up after key presses.
+func (w *warp) SetIntensity(ratio float64) {
+	w.intensity = math.Max(0, math.Min(1, ratio))
+}
+
+func (w *warp) newStar() warpStar {
+	return warpStar{x: w.rng.Float64()*2 - 1, y: w.rng.Float64()*2 - 1, z: 1}
+}
+
+// project returns the screen cell of a point in view space.
+func (w *warp) project(x, y, z float64) (int, int, bool) {
+	cx, cy := float64(w.width)/2, float64(w.height)/2
+	sx := int(math.Floor(cx + x/z*cx))
+	sy := int(math.Floor(cy + y/z*cy))
+	return sx, sy, sx >= 0 && sx < w.width && sy >= 0 && sy < w.height
+}
+
+func (w *warp) speed() float64 {
+	return warpSpeed * (1 + w.intensity*(warpBoost-1))
+}
+
+func (w *warp) Step() {
+	speed := w.speed()
+	for i := range w.stars {
+		s := &w.stars[i]
+		s.z -= speed
+		if _, _, visible := w.project(s.x, s.y, s.z); s.z <= warpNear || !visible {
+			*s = w.newStar()
+		}
+	}
+}
+
+func (w *warp) Draw(c Canvas) {
+	fill(c, w.width, w.height, ' ', skyStyle)
+
+	glyphs := warpGlyphs
+	if w.opts.ASCII {
+		glyphs = warpGlyphsASCII
+	}
+	streak := w.intensity > warpStreakMin
+
+	for _, s := range w.stars {
+		near := 1 - s.z // 0 far away, 1 at the viewer
+		v := int32(60 + 195*near)
+		if streak {
+			// The star a few frames ago, dimmer
+			if x, y, ok := w.project(s.x, s.y, math.Min(s.z+w.speed()*4, 1)); ok {
+				c.SetContent(x, y, '.', nil, tcell.StyleDefault.Foreground(tcell.NewRGBColor(v/2, v/2, v/2+20)))
+			}
+		}
+		x, y, ok := w.project(s.x, s.y, s.z)
+		if !ok {
+			continue
+		}
+		glyph := glyphs[min(int(near*float64(len(glyphs))), len(glyphs)-1)]
+		c.SetContent(x, y, glyph, nil, tcell.StyleDefault.Foreground(tcell.NewRGBColor(v, v, min(v+20, 255))))
+	}
+}
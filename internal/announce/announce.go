@@ -0,0 +1,119 @@
+package announce
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ---- Announcement Channel
+// Announcers report state changes as short text, alongside the visual
+// feedback of the renderer, for screen reader friendly setups.
+
+// Event identifies a state change worth announcing.
+type Event int
+
+const (
+	EventLocked Event = iota
+	EventAutoLocked
+	EventWrongPassword
+	EventUnlocked
+	EventLockExpired
+)
+
+// String returns the default announcement text for the event.
+func (e Event) String() string {
+	switch e {
+	case EventLocked:
+		return "Session locked"
+	case EventAutoLocked:
+		return "Session locked after inactivity"
+	case EventWrongPassword:
+		return "Wrong password"
+	case EventUnlocked:
+		return "Session unlocked"
+	case EventLockExpired:
+		return "Lock expired, clients detached"
+	default:
+		return "Unknown event"
+	}
+}
+
+// Announcer delivers announcements. Implementations must not block the
+// render loop for long and must be safe for concurrent use.
+type Announcer interface {
+	Announce(ev Event, msg string)
+}
+
+// Mode names an announcer implementation.
+type Mode string
+
+const (
+	ModeOff    Mode = "off"
+	ModeStderr Mode = "stderr"
+	ModeOSC    Mode = "osc"
+)
+
+// EnvVar enables announcements when the --announce flag is not set.
+const EnvVar = "YULE_LOG_ANNOUNCE"
+
+// New returns the announcer for the given mode.
+func New(mode Mode) (Announcer, error) {
+	switch mode {
+	case "", ModeOff:
+		return Nop{}, nil
+	case ModeStderr:
+		return NewWriter(os.Stderr), nil
+	case ModeOSC:
+		return NewOSC(), nil
+	default:
+		return nil, fmt.Errorf("unknown announce mode %q (want off, stderr or osc)", mode)
+	}
+}
+
+// ModeFromEnv returns flagValue if set, or the mode from EnvVar.
+func ModeFromEnv(flagValue string) Mode {
+	if flagValue != "" {
+		return Mode(flagValue)
+	}
+	return Mode(strings.ToLower(os.Getenv(EnvVar)))
+}
+
+// Nop discards announcements.
+type Nop struct{}
+
+// Announce does nothing.
+func (Nop) Announce(Event, string) {}
+
+// Writer writes one line per announcement.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter returns an announcer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Announce writes msg (or the event's default text) followed by a newline.
+func (a *Writer) Announce(ev Event, msg string) {
+	if msg == "" {
+		msg = ev.String()
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	fmt.Fprintf(a.w, "yule-log: %s\n", msg)
+}
+
+// Multi fans announcements out to several announcers.
+type Multi []Announcer
+
+// Announce forwards to every announcer.
+func (m Multi) Announce(ev Event, msg string) {
+	for _, a := range m {
+		a.Announce(ev, msg)
+	}
+}
@@ -0,0 +1,104 @@
+package announce
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/hooks"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	a := NewWriter(&buf)
+
+	a.Announce(EventLocked, "")
+	a.Announce(EventWrongPassword, "Wrong password, 2 attempts")
+
+	assert.Equal(t, "yule-log: Session locked\nyule-log: Wrong password, 2 attempts\n", buf.String())
+}
+
+func TestOSCSequence(t *testing.T) {
+	assert.Equal(t, "\x1b]9;Session locked\x07", oscSequence("Session locked", false))
+	assert.Equal(t, "\x1bPtmux;\x1b\x1b]9;Unlocked\x07\x1b\\", oscSequence("Unlocked", true))
+	assert.Equal(t, "\x1b]9;evil\x07", oscSequence("ev\x07il\x1b", false), "controls are stripped")
+}
+
+func TestNew(t *testing.T) {
+	for _, mode := range []Mode{"", ModeOff, ModeStderr, ModeOSC} {
+		a, err := New(mode)
+		require.NoError(t, err, mode)
+		assert.NotNil(t, a)
+	}
+
+	_, err := New("braille")
+	assert.Error(t, err)
+}
+
+func TestModeFromEnv(t *testing.T) {
+	t.Setenv(EnvVar, "STDERR")
+	assert.Equal(t, ModeStderr, ModeFromEnv(""))
+	assert.Equal(t, ModeOSC, ModeFromEnv("osc"))
+}
+
+type recorder struct{ events []Event }
+
+func (r *recorder) Announce(ev Event, _ string) { r.events = append(r.events, ev) }
+
+func TestPolicy(t *testing.T) {
+	rec := &recorder{}
+	p := &Policy{Next: rec, FailedThreshold: 3}
+
+	p.Announce(EventLocked, "")
+	p.Announce(EventAutoLocked, "")
+	p.Announce(EventWrongPassword, "")
+	p.Announce(EventWrongPassword, "")
+	p.Announce(EventWrongPassword, "")
+	p.Announce(EventWrongPassword, "")
+	p.Announce(EventUnlocked, "")
+	p.Announce(EventWrongPassword, "")
+	p.Announce(EventLockExpired, "")
+
+	assert.Equal(t, []Event{
+		EventAutoLocked,
+		EventWrongPassword, // 3rd attempt
+		EventWrongPassword, // 4th attempt
+		EventUnlocked,
+		EventLockExpired,
+	}, rec.events)
+}
+
+func TestOSC777Sequence(t *testing.T) {
+	assert.Equal(t, "\x1b]777;notify;tmux yule-log;Unlocked\x07", osc777Sequence("tmux; yule-log", "Unlocked", false))
+}
+
+func TestNewNotifier(t *testing.T) {
+	a, err := NewNotifier(NotifyOff, 3)
+	require.NoError(t, err)
+	assert.Equal(t, Nop{}, a)
+
+	_, err = NewNotifier("pager", 3)
+	assert.Error(t, err)
+}
+
+func TestHooks(t *testing.T) {
+	rec := &recorder{}
+	h := Hooks{Announcer: rec}
+
+	h.OnLock(hooks.Lock{})
+	h.OnLock(hooks.Lock{Auto: true})
+	h.OnUnlock(hooks.Unlock{Failures: 1})
+	h.OnUnlock(hooks.Unlock{OK: true})
+	h.OnUnlock(hooks.Unlock{Expired: true})
+	h.OnFrame(hooks.Frame{})
+
+	assert.Equal(t, []Event{
+		EventLocked,
+		EventAutoLocked,
+		EventWrongPassword,
+		EventUnlocked,
+		EventLockExpired,
+	}, rec.events)
+}
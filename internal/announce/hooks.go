@@ -0,0 +1,31 @@
+package announce
+
+import "yule-log/hooks"
+
+// Hooks delivers lock state changes to an Announcer, plugging the
+// announcements and desktop notifications into the hooks API.
+type Hooks struct {
+	hooks.Base
+	Announcer Announcer
+}
+
+// OnLock announces the lock.
+func (h Hooks) OnLock(l hooks.Lock) {
+	if l.Auto {
+		h.Announcer.Announce(EventAutoLocked, "")
+	} else {
+		h.Announcer.Announce(EventLocked, "")
+	}
+}
+
+// OnUnlock announces the unlock, a wrong password or an expired lock.
+func (h Hooks) OnUnlock(u hooks.Unlock) {
+	switch {
+	case u.Expired:
+		h.Announcer.Announce(EventLockExpired, "")
+	case u.OK:
+		h.Announcer.Announce(EventUnlocked, "")
+	default:
+		h.Announcer.Announce(EventWrongPassword, "")
+	}
+}
@@ -0,0 +1,96 @@
+package announce
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// notificationTitle is the title of desktop notifications.
+const notificationTitle = "tmux yule-log"
+
+// NotifyMode names a desktop notification backend.
+type NotifyMode string
+
+const (
+	NotifyOff     NotifyMode = "off"
+	NotifyDesktop NotifyMode = "desktop" // notify-send (Linux) or osascript (macOS)
+	NotifyOSC777  NotifyMode = "osc777"  // Terminal notification, works over SSH
+)
+
+// NewNotifier returns the desktop notification announcer for mode, wrapped
+// in the notification policy (see Policy). threshold is the number of
+// failed attempts before wrong passwords are reported.
+func NewNotifier(mode NotifyMode, threshold int) (Announcer, error) {
+	var a Announcer
+	switch mode {
+	case "", NotifyOff:
+		return Nop{}, nil
+	case NotifyDesktop:
+		a = Desktop{}
+	case NotifyOSC777:
+		a = NewOSC777()
+	default:
+		return nil, fmt.Errorf("unknown notify mode %q (want off, desktop or osc777)", mode)
+	}
+	return &Policy{Next: a, FailedThreshold: threshold}, nil
+}
+
+// Desktop shows notifications with the platform notification tool.
+// Commands run in the background; failures are ignored.
+type Desktop struct{}
+
+// Announce shows a desktop notification.
+func (Desktop) Announce(ev Event, msg string) {
+	if msg == "" {
+		msg = ev.String()
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := "display notification " + strconv.Quote(msg) + " with title " + strconv.Quote(notificationTitle)
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=yule-log", notificationTitle, msg)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	go cmd.Wait()
+}
+
+// Policy filters events worth a desktop notification: auto-locks, wrong
+// passwords once FailedThreshold consecutive failures are reached, unlocks
+// and expired locks. Manual locks are not reported since the user just caused them.
+type Policy struct {
+	Next            Announcer
+	FailedThreshold int
+
+	mu     sync.Mutex
+	failed int
+}
+
+// Announce forwards the event to Next if the policy allows it.
+func (p *Policy) Announce(ev Event, msg string) {
+	p.mu.Lock()
+	forward := false
+	switch ev {
+	case EventAutoLocked:
+		forward = true
+	case EventWrongPassword:
+		p.failed++
+		forward = p.failed >= max(p.FailedThreshold, 1)
+	case EventUnlocked, EventLockExpired:
+		p.failed = 0
+		forward = true
+	}
+	p.mu.Unlock()
+
+	if forward {
+		p.Next.Announce(ev, msg)
+	}
+}
@@ -0,0 +1,84 @@
+package announce
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// OSC sends announcements as terminal notifications (OSC 9, or OSC 777
+// for rxvt/kitty/foot style desktop notifications), which terminals and
+// assistive tools can surface without touching the cell grid.
+// Inside tmux the sequence is wrapped in a DCS passthrough (requires
+// `set -g allow-passthrough on`).
+type OSC struct {
+	mu    sync.Mutex
+	w     io.Writer
+	tmux  bool
+	notif bool // OSC 777 notify instead of OSC 9
+}
+
+// NewOSC returns an OSC 9 announcer writing to the controlling terminal,
+// falling back to stderr when /dev/tty cannot be opened.
+func NewOSC() *OSC {
+	var w io.Writer = os.Stderr
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		w = tty
+	}
+	return &OSC{w: w, tmux: os.Getenv("TMUX") != ""}
+}
+
+// NewOSC777 returns an announcer sending OSC 777 desktop notifications,
+// which survive SSH so a remote lock can alert the local desktop.
+func NewOSC777() *OSC {
+	a := NewOSC()
+	a.notif = true
+	return a
+}
+
+// Announce emits the notification sequence.
+func (a *OSC) Announce(ev Event, msg string) {
+	if msg == "" {
+		msg = ev.String()
+	}
+	seq := oscSequence(msg, a.tmux)
+	if a.notif {
+		seq = osc777Sequence(notificationTitle, msg, a.tmux)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	io.WriteString(a.w, seq)
+}
+
+// oscSequence builds the OSC 9 notification escape sequence.
+func oscSequence(msg string, tmux bool) string {
+	return wrapPassthrough("\x1b]9;"+stripControls(msg)+"\x07", tmux)
+}
+
+// osc777Sequence builds the OSC 777 notification escape sequence.
+// Semicolons are removed from the title since they separate fields.
+func osc777Sequence(title, msg string, tmux bool) string {
+	title = strings.ReplaceAll(stripControls(title), ";", "")
+	return wrapPassthrough("\x1b]777;notify;"+title+";"+stripControls(msg)+"\x07", tmux)
+}
+
+// stripControls removes control characters from msg so it cannot
+// terminate the sequence early.
+func stripControls(msg string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, msg)
+}
+
+// wrapPassthrough wraps seq in a tmux DCS passthrough when tmux is set.
+func wrapPassthrough(seq string, tmux bool) string {
+	if !tmux {
+		return seq
+	}
+	// tmux passthrough: ESC characters inside the payload are doubled.
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
@@ -0,0 +1,236 @@
+// Package bundle packs the yule-log setup (configuration, optionally the
+// password hash and idle history) into one tar.gz archive, to move it
+// between machines or keep it in a dotfiles repository. Bug reports use
+// the same format.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"yule-log/internal/fsutil"
+)
+
+// Version is the bundle format version written by Export.
+const Version = 1
+
+// manifestName is the first entry of every bundle.
+const manifestName = "manifest.json"
+
+// maxFileSize bounds every entry read from a bundle.
+const maxFileSize = 1 << 20
+
+var (
+	// ErrUnsupportedVersion is returned for bundles written by a newer yule-log.
+	ErrUnsupportedVersion = errors.New("bundle written by a newer version of yule-log")
+	// ErrExists is returned by Install when a file would be overwritten.
+	ErrExists = errors.New("file already exists")
+)
+
+// Manifest describes a bundle.
+type Manifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Files   []string  `json:"files"`
+}
+
+// File maps a bundle entry to its place on disk.
+type File struct {
+	Name string      // Entry name in the bundle, e.g. "config.toml"
+	Path string      // Location on this machine
+	Mode os.FileMode // Permissions when installed
+
+	// Validate checks the content before it is installed (optional).
+	Validate func(data []byte) error
+}
+
+// Bundle is a decoded bundle.
+type Bundle struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// ---- Export
+
+// Entry is the content of one bundle entry.
+type Entry struct {
+	Name string
+	Data []byte
+}
+
+// Export writes the files that exist on disk to w as a bundle and returns
+// the names of the entries written. Missing files are skipped.
+func Export(w io.Writer, files []File, now time.Time) ([]string, error) {
+	var entries []Entry
+	for _, f := range files {
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("reading %s: %w", f.Path, err)
+		}
+		entries = append(entries, Entry{f.Name, data})
+	}
+	return Write(w, entries, now)
+}
+
+// Write writes entries to w as a bundle, after the manifest, and returns
+// their names.
+func Write(w io.Writer, entries []Entry, now time.Time) ([]string, error) {
+	manifest := Manifest{Version: Version, Created: now.UTC()}
+	for _, e := range entries {
+		manifest.Files = append(manifest.Files, e.Name)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+		return nil
+	}
+
+	if err := write(manifestName, manifestData); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if err := write(e.Name, e.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("closing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("closing archive: %w", err)
+	}
+	return manifest.Files, nil
+}
+
+// ---- Import
+
+// Read decodes a bundle and checks its manifest: the format version, and
+// that it lists exactly the entries present.
+func Read(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a bundle: %w", err)
+	}
+	defer gz.Close()
+
+	b := &Bundle{Files: make(map[string][]byte)}
+	var manifestData []byte
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("unexpected entry %q in bundle", hdr.Name)
+		}
+		if hdr.Size > maxFileSize {
+			return nil, fmt.Errorf("entry %q is too large", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		if hdr.Name == manifestName {
+			manifestData = data
+			continue
+		}
+		if _, dup := b.Files[hdr.Name]; dup {
+			return nil, fmt.Errorf("duplicate entry %q in bundle", hdr.Name)
+		}
+		b.Files[hdr.Name] = data
+	}
+
+	if manifestData == nil {
+		return nil, fmt.Errorf("not a bundle: missing %s", manifestName)
+	}
+	if err := json.Unmarshal(manifestData, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", manifestName, err)
+	}
+	switch {
+	case b.Manifest.Version < 1:
+		return nil, fmt.Errorf("invalid bundle version %d", b.Manifest.Version)
+	case b.Manifest.Version > Version:
+		return nil, fmt.Errorf("version %d: %w", b.Manifest.Version, ErrUnsupportedVersion)
+	}
+
+	if len(b.Manifest.Files) != len(b.Files) {
+		return nil, fmt.Errorf("manifest lists %d files, bundle has %d", len(b.Manifest.Files), len(b.Files))
+	}
+	for _, name := range b.Manifest.Files {
+		if _, ok := b.Files[name]; !ok {
+			return nil, fmt.Errorf("manifest lists missing file %q", name)
+		}
+	}
+	return b, nil
+}
+
+// Install writes the bundle entries known in files to their paths and
+// returns the names installed. Entries this version doesn't know are
+// rejected rather than guessed at. Every entry is validated before any is
+// written; existing files are only replaced with force.
+func (b *Bundle) Install(files []File, force bool) ([]string, error) {
+	var install []File
+	for _, name := range b.Manifest.Files {
+		i := slices.IndexFunc(files, func(f File) bool { return f.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown file %q in bundle", name)
+		}
+		f := files[i]
+		if f.Validate != nil {
+			if err := f.Validate(b.Files[name]); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		if !force {
+			if _, err := os.Stat(f.Path); err == nil {
+				return nil, fmt.Errorf("%s: %w", f.Path, ErrExists)
+			}
+		}
+		install = append(install, f)
+	}
+
+	var installed []string
+	for _, f := range install {
+		if err := writeFile(f.Path, b.Files[f.Name], f.Mode); err != nil {
+			return installed, err
+		}
+		installed = append(installed, f.Name)
+	}
+	return installed, nil
+}
+
+// writeFile atomically replaces path with data, so an interrupted import
+// never leaves a truncated file behind.
+func writeFile(path string, data []byte, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	return fsutil.WriteFile(path, data, mode)
+}
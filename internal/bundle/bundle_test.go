@@ -0,0 +1,169 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func testFiles(dir string) []File {
+	return []File{
+		{Name: "config.toml", Path: filepath.Join(dir, "config", "config.toml"), Mode: 0600},
+		{Name: "passwd", Path: filepath.Join(dir, "config", "passwd"), Mode: 0600},
+		{Name: "idle.stats", Path: filepath.Join(dir, "state", "idle.stats"), Mode: 0600},
+	}
+}
+
+func putFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+// rawBundle builds an archive from name/content pairs, in order.
+func rawBundle(t *testing.T, entries ...string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for i := 0; i < len(entries); i += 2 {
+		data := []byte(entries[i+1])
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: entries[i], Mode: 0600, Size: int64(len(data))}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return &buf
+}
+
+func TestRoundTrip(t *testing.T) {
+	src := testFiles(t.TempDir())
+	putFile(t, src[0].Path, "[ticker]\nmax_commits = 5\n")
+	putFile(t, src[2].Path, "1700000000 30 1\n")
+
+	var buf bytes.Buffer
+	names, err := Export(&buf, src, testTime)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config.toml", "idle.stats"}, names, "missing files are skipped")
+
+	b, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, Version, b.Manifest.Version)
+	assert.Equal(t, testTime, b.Manifest.Created)
+
+	dst := testFiles(t.TempDir())
+	installed, err := b.Install(dst, false)
+	require.NoError(t, err)
+	assert.Equal(t, names, installed)
+
+	data, err := os.ReadFile(dst[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "[ticker]\nmax_commits = 5\n", string(data))
+	info, err := os.Stat(dst[2].Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.NoFileExists(t, dst[1].Path)
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	names, err := Write(&buf, []Entry{{"info.txt", []byte("Version: dev\n")}, {"crash.log", nil}}, testTime)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"info.txt", "crash.log"}, names)
+
+	b, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, names, b.Manifest.Files)
+	assert.Equal(t, "Version: dev\n", string(b.Files["info.txt"]))
+	assert.Empty(t, b.Files["crash.log"])
+}
+
+func TestInstallExisting(t *testing.T) {
+	src := testFiles(t.TempDir())
+	putFile(t, src[0].Path, "new")
+	var buf bytes.Buffer
+	_, err := Export(&buf, src, testTime)
+	require.NoError(t, err)
+	b, err := Read(&buf)
+	require.NoError(t, err)
+
+	dst := testFiles(t.TempDir())
+	putFile(t, dst[0].Path, "old")
+
+	_, err = b.Install(dst, false)
+	assert.True(t, errors.Is(err, ErrExists))
+	data, _ := os.ReadFile(dst[0].Path)
+	assert.Equal(t, "old", string(data))
+
+	_, err = b.Install(dst, true)
+	require.NoError(t, err)
+	data, _ = os.ReadFile(dst[0].Path)
+	assert.Equal(t, "new", string(data))
+}
+
+func TestInstallValidates(t *testing.T) {
+	src := testFiles(t.TempDir())
+	putFile(t, src[0].Path, "good")
+	putFile(t, src[1].Path, "bad")
+	var buf bytes.Buffer
+	_, err := Export(&buf, src, testTime)
+	require.NoError(t, err)
+	b, err := Read(&buf)
+	require.NoError(t, err)
+
+	dst := testFiles(t.TempDir())
+	dst[1].Validate = func(data []byte) error {
+		if string(data) == "bad" {
+			return errors.New("invalid")
+		}
+		return nil
+	}
+	_, err = b.Install(dst, false)
+	assert.Error(t, err)
+	assert.NoFileExists(t, dst[0].Path, "nothing is written when an entry is invalid")
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		bundle *bytes.Buffer
+		is     error
+	}{
+		{name: "not gzip", bundle: bytes.NewBufferString("plain text")},
+		{name: "no manifest", bundle: rawBundle(t, "config.toml", "")},
+		{name: "bad manifest", bundle: rawBundle(t, manifestName, "{")},
+		{name: "newer version", bundle: rawBundle(t, manifestName, `{"version": 99}`), is: ErrUnsupportedVersion},
+		{name: "zero version", bundle: rawBundle(t, manifestName, `{"version": 0}`)},
+		{name: "unlisted file", bundle: rawBundle(t, manifestName, `{"version": 1}`, "config.toml", "")},
+		{name: "missing file", bundle: rawBundle(t, manifestName, `{"version": 1, "files": ["config.toml"]}`)},
+		{name: "duplicate", bundle: rawBundle(t, manifestName, `{"version": 1, "files": ["a", "a"]}`, "a", "", "a", "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(tt.bundle)
+			require.Error(t, err)
+			if tt.is != nil {
+				assert.True(t, errors.Is(err, tt.is), err.Error())
+			}
+		})
+	}
+}
+
+func TestInstallUnknownEntry(t *testing.T) {
+	b, err := Read(rawBundle(t, manifestName, `{"version": 1, "files": ["../../etc/passwd"]}`, "../../etc/passwd", "x"))
+	require.NoError(t, err)
+
+	_, err = b.Install(testFiles(t.TempDir()), true)
+	assert.ErrorContains(t, err, "unknown file")
+}
@@ -0,0 +1,130 @@
+package calendar
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---- Calendar Events
+// Upcoming events come from an ICS feed (a URL or a file) or from the
+// tab-separated output of a calendar command line tool such as gcalcli.
+
+// Event is one occurrence of a calendar event.
+type Event struct {
+	Summary string
+	Start   time.Time
+	End     time.Time // Zero if unknown
+	AllDay  bool
+}
+
+// Upcoming returns the timed events starting within window after now,
+// soonest first. Events that started less than a minute ago still count,
+// they are "now".
+func Upcoming(events []Event, now time.Time, window time.Duration) []Event {
+	var upcoming []Event
+	for _, ev := range events {
+		if ev.AllDay || ev.Start.Before(now.Add(-time.Minute)) || ev.Start.After(now.Add(window)) {
+			continue
+		}
+		upcoming = append(upcoming, ev)
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool { return upcoming[i].Start.Before(upcoming[j].Start) })
+	return upcoming
+}
+
+// Label describes when the event starts: "Standup in 12m", "Standup in
+// 1h05m", "Standup now".
+func Label(ev Event, now time.Time) string {
+	d := ev.Start.Sub(now)
+	switch {
+	case d < time.Minute:
+		return ev.Summary + " now"
+	case d < time.Hour:
+		return fmt.Sprintf("%s in %dm", ev.Summary, int(d/time.Minute))
+	default:
+		return fmt.Sprintf("%s in %dh%02dm", ev.Summary, int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+}
+
+// FetchICS reads the events of an ICS feed, from an http(s) URL or a file.
+// Recurring events are expanded between from and to.
+func FetchICS(ctx context.Context, source string, from, to time.Time) ([]Event, error) {
+	var r io.Reader
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, fmt.Errorf("fetching calendar: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetching calendar: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching calendar: %s", resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("reading calendar: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return ParseICS(r, time.Local, from, to)
+}
+
+// RunCommand runs a shell command printing events, see ParseTSV.
+func RunCommand(ctx context.Context, command string) ([]Event, error) {
+	out, err := exec.CommandContext(ctx, "sh", "-c", command).Output()
+	if err != nil {
+		return nil, fmt.Errorf("calendar command: %w", err)
+	}
+	return ParseTSV(string(out), time.Local), nil
+}
+
+// ParseTSV reads events printed one per line as tab-separated fields:
+// start date (2006-01-02), start time (15:04, empty for all-day events),
+// optionally more fields, and the summary last. This is the format of
+// gcalcli agenda --tsv. Lines that don't match, like headers, are skipped.
+func ParseTSV(out string, loc *time.Location) []Event {
+	var events []Event
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		fields := strings.Split(strings.TrimRight(sc.Text(), "\r"), "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		ev := Event{Summary: strings.TrimSpace(fields[len(fields)-1])}
+		if fields[1] == "" {
+			day, err := time.ParseInLocation("2006-01-02", fields[0], loc)
+			if err != nil {
+				continue
+			}
+			ev.Start, ev.AllDay = day, true
+		} else {
+			start, err := time.ParseInLocation("2006-01-02 15:04", fields[0]+" "+fields[1], loc)
+			if err != nil {
+				continue
+			}
+			ev.Start = start
+			// gcalcli adds the end date and time
+			if len(fields) >= 5 {
+				if end, err := time.ParseInLocation("2006-01-02 15:04", fields[2]+" "+fields[3], loc); err == nil {
+					ev.End = end
+				}
+			}
+		}
+		events = append(events, ev)
+	}
+	return events
+}
@@ -0,0 +1,182 @@
+package calendar
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Standup\r\n" +
+	"DTSTART;TZID=Europe/Paris:20240304T093000\r\n" +
+	"DTEND;TZID=Europe/Paris:20240304T094500\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR\r\n" +
+	"EXDATE;TZID=Europe/Paris:20240308T093000\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"SUMMARY:Not an event\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Standup (moved)\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Paris:20240311T093000\r\n" +
+	"DTSTART;TZID=Europe/Paris:20240311T110000\r\n" +
+	"DURATION:PT15M\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:review\r\n" +
+	"SUMMARY:Design review\\, part 2\r\n" +
+	"  with the team\r\n" +
+	"DTSTART:20240306T150000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled\r\n" +
+	"SUMMARY:Retro\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART:20240306T160000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:offsite\r\n" +
+	"SUMMARY:Offsite\r\n" +
+	"DTSTART;VALUE=DATE:20240307\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, paris)
+	to := from.AddDate(0, 0, 8)
+	events, err := ParseICS(strings.NewReader(sampleICS), time.UTC, from, to)
+	require.NoError(t, err)
+
+	var got []string
+	for _, ev := range events {
+		got = append(got, ev.Start.In(paris).Format("Mon 02 15:04")+" "+ev.Summary)
+	}
+	assert.Equal(t, []string{
+		"Mon 04 09:30 Standup",
+		"Wed 06 09:30 Standup",
+		"Wed 06 16:00 Design review, part 2 with the team",
+		"Thu 07 01:00 Offsite", // Floating, in UTC
+		// Friday 8 is excluded, Monday 11 is moved
+		"Mon 11 11:00 Standup (moved)",
+	}, got)
+
+	assert.Equal(t, 15*time.Minute, events[0].End.Sub(events[0].Start))
+	assert.Equal(t, 15*time.Minute, events[4].End.Sub(events[4].Start))
+	assert.True(t, events[3].AllDay)
+}
+
+func TestExpand(t *testing.T) {
+	start := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+	from, to := start, start.AddDate(1, 0, 0)
+
+	tests := []struct {
+		rule string
+		want int
+	}{
+		{"FREQ=DAILY;COUNT=3", 3},
+		{"FREQ=DAILY;INTERVAL=2;UNTIL=20240206T090000Z", 4},
+		{"FREQ=WEEKLY;BYDAY=MO,TU;COUNT=5", 5},
+		{"FREQ=MONTHLY", 8}, // Only months with a 31st
+		{"FREQ=MONTHLY;BYDAY=1MO", 1},
+		{"FREQ=YEARLY", 2},
+		{"FREQ=SECONDLY", 1},
+	}
+	for _, tt := range tests {
+		got := expand(vevent{start: start, rrule: tt.rule}, from, to)
+		assert.Len(t, got, tt.want, tt.rule)
+	}
+
+	// Wall clock time is kept across daylight saving changes
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	start = time.Date(2024, 3, 29, 9, 30, 0, 0, paris)
+	got := expand(vevent{start: start, rrule: "FREQ=DAILY;COUNT=3"}, start, start.AddDate(0, 0, 5))
+	require.Len(t, got, 3)
+	assert.Equal(t, 9, got[2].Hour())
+
+	// Occurrences before from are skipped, but still count
+	got = expand(vevent{start: start, rrule: "FREQ=DAILY;COUNT=3"}, start.AddDate(0, 0, 1), start.AddDate(0, 0, 5))
+	assert.Len(t, got, 2)
+}
+
+func TestParseDuration(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"PT15M":    15 * time.Minute,
+		"PT1H30M":  90 * time.Minute,
+		"P1D":      24 * time.Hour,
+		"P1W":      7 * 24 * time.Hour,
+		"-PT5M":    -5 * time.Minute,
+		"P1DT2H3S": 26*time.Hour + 3*time.Second,
+	} {
+		d, err := parseDuration(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, d, s)
+	}
+	_, err := parseDuration("1H")
+	assert.Error(t, err)
+}
+
+func TestParseTSV(t *testing.T) {
+	out := "start_date\tstart_time\tend_date\tend_time\ttitle\n" +
+		"2024-03-04\t09:30\t2024-03-04\t09:45\tStandup\n" +
+		"2024-03-04\t\t2024-03-05\t\tOffsite\n" +
+		"2024-03-04\t14:00\tLunch & learn\n"
+	events := ParseTSV(out, time.UTC)
+	require.Len(t, events, 3)
+	assert.Equal(t, Event{
+		Summary: "Standup",
+		Start:   time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC),
+		End:     time.Date(2024, 3, 4, 9, 45, 0, 0, time.UTC),
+	}, events[0])
+	assert.True(t, events[1].AllDay)
+	assert.Equal(t, "Lunch & learn", events[2].Summary)
+	assert.True(t, events[2].End.IsZero())
+}
+
+func TestUpcoming(t *testing.T) {
+	now := time.Date(2024, 3, 4, 9, 18, 0, 0, time.UTC)
+	events := []Event{
+		{Summary: "Review", Start: now.Add(2 * time.Hour)},
+		{Summary: "Standup", Start: now.Add(12 * time.Minute)},
+		{Summary: "Started", Start: now.Add(-30 * time.Second)},
+		{Summary: "Past", Start: now.Add(-time.Hour)},
+		{Summary: "Tomorrow", Start: now.Add(30 * time.Hour)},
+		{Summary: "Offsite", Start: now.Add(time.Hour), AllDay: true},
+	}
+	upcoming := Upcoming(events, now, 12*time.Hour)
+	require.Len(t, upcoming, 3)
+
+	var labels []string
+	for _, ev := range upcoming {
+		labels = append(labels, Label(ev, now))
+	}
+	assert.Equal(t, []string{"Started now", "Standup in 12m", "Review in 2h00m"}, labels)
+}
+
+func TestFetchICSFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cal.ics")
+	require.NoError(t, os.WriteFile(path, []byte(sampleICS), 0600))
+
+	from := time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC)
+	events, err := FetchICS(context.Background(), path, from, from.Add(6*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Design review, part 2 with the team", events[0].Summary)
+
+	_, err = FetchICS(context.Background(), filepath.Join(t.TempDir(), "missing.ics"), from, from)
+	assert.Error(t, err)
+}
@@ -0,0 +1,365 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---- ICS Parsing
+// Enough of RFC 5545 for a calendar feed: VEVENT blocks with their time
+// zones (TZID names from the tz database), all-day events, cancelled
+// events, and the common recurrences: daily, weekly (with BYDAY), monthly
+// and yearly rules with INTERVAL, COUNT and UNTIL, EXDATE, and moved or
+// cancelled occurrences (RECURRENCE-ID). Monthly and yearly rules with
+// BY* parts only yield their first occurrence.
+
+// maxPeriods bounds the expansion of a recurring event, in periods of its
+// rule (about 270 years of a daily event).
+const maxPeriods = 100000
+
+// property is one content line: NAME;PARAM=value:VALUE.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// vevent is a VEVENT block.
+type vevent struct {
+	uid          string
+	summary      string
+	cancelled    bool
+	start, end   time.Time
+	duration     time.Duration
+	allDay       bool
+	rrule        string
+	exdates      []time.Time
+	recurrenceID time.Time
+}
+
+// ParseICS reads the events of an ICS calendar, expanding recurring events
+// between from and to. Floating times (without a time zone) are in loc.
+func ParseICS(r io.Reader, loc *time.Location, from, to time.Time) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading calendar: %w", err)
+	}
+
+	var vevents []vevent
+	var cur *vevent
+	nested := 0 // Depth of blocks inside the VEVENT, like VALARM
+	for _, line := range lines {
+		p, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && p.value == "VEVENT":
+			cur, nested = &vevent{}, 0
+			continue
+		case cur == nil:
+			continue
+		case p.name == "BEGIN":
+			nested++
+			continue
+		case p.name == "END" && nested > 0:
+			nested--
+			continue
+		case p.name == "END" && p.value == "VEVENT":
+			if !cur.start.IsZero() {
+				vevents = append(vevents, *cur)
+			}
+			cur = nil
+			continue
+		case nested > 0:
+			continue
+		}
+
+		switch p.name {
+		case "UID":
+			cur.uid = p.value
+		case "SUMMARY":
+			cur.summary = unescape(p.value)
+		case "STATUS":
+			cur.cancelled = strings.EqualFold(p.value, "CANCELLED")
+		case "DTSTART":
+			cur.start, cur.allDay, _ = parseTime(p, loc)
+		case "DTEND":
+			cur.end, _, _ = parseTime(p, loc)
+		case "DURATION":
+			cur.duration, _ = parseDuration(p.value)
+		case "RRULE":
+			cur.rrule = p.value
+		case "EXDATE":
+			for _, v := range strings.Split(p.value, ",") {
+				if t, _, err := parseTime(property{params: p.params, value: v}, loc); err == nil {
+					cur.exdates = append(cur.exdates, t)
+				}
+			}
+		case "RECURRENCE-ID":
+			cur.recurrenceID, _, _ = parseTime(p, loc)
+		}
+	}
+
+	// Occurrences moved or cancelled by an override, by UID
+	overridden := map[string][]time.Time{}
+	for _, v := range vevents {
+		if !v.recurrenceID.IsZero() {
+			overridden[v.uid] = append(overridden[v.uid], v.recurrenceID)
+		}
+	}
+
+	var events []Event
+	for _, v := range vevents {
+		if v.cancelled {
+			continue
+		}
+		length := v.length()
+		emit := func(start time.Time) {
+			ev := Event{Summary: v.summary, Start: start, AllDay: v.allDay}
+			if length > 0 {
+				ev.End = start.Add(length)
+			}
+			events = append(events, ev)
+		}
+		if v.rrule == "" || !v.recurrenceID.IsZero() {
+			if inRange(v.start, length, from, to) {
+				emit(v.start)
+			}
+			continue
+		}
+		skip := append(append([]time.Time{}, v.exdates...), overridden[v.uid]...)
+		for _, start := range expand(v, from, to) {
+			if !containsTime(skip, start) {
+				emit(start)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events, nil
+}
+
+func (v vevent) length() time.Duration {
+	switch {
+	case !v.end.IsZero():
+		return v.end.Sub(v.start)
+	case v.duration > 0:
+		return v.duration
+	case v.allDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// inRange reports whether an event of the given length starting at start
+// overlaps from..to.
+func inRange(start time.Time, length time.Duration, from, to time.Time) bool {
+	return !start.After(to) && !start.Add(length).Before(from)
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, u := range times {
+		if u.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// unfold joins continuation lines (starting with a space or a tab) to
+// the line before.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+// parseProperty splits a content line. Parameter values may be quoted and
+// hold colons.
+func parseProperty(line string) (property, bool) {
+	colon, quoted := -1, false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, false
+	}
+	parts := strings.Split(line[:colon], ";")
+	p := property{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return p, true
+}
+
+// unescape decodes a TEXT value. Line breaks become spaces.
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// parseTime decodes a DATE or DATE-TIME value, in UTC (Z suffix), in its
+// TZID time zone, or else in loc. Unknown time zones fall back to loc.
+func parseTime(p property, loc *time.Location) (time.Time, bool, error) {
+	if tzid := p.params["TZID"]; tzid != "" {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		}
+	}
+	v := p.value
+	switch {
+	case p.params["VALUE"] == "DATE" || len(v) == len("20060102"):
+		t, err := time.ParseInLocation("20060102", v, loc)
+		return t, true, err
+	case strings.HasSuffix(v, "Z"):
+		t, err := time.Parse("20060102T150405Z", v)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", v, loc)
+		return t, false, err
+	}
+}
+
+// parseDuration decodes a DURATION value such as PT1H30M or P1D.
+func parseDuration(s string) (time.Duration, error) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour, 'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+	var d time.Duration
+	n := ""
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 'T':
+		case c >= '0' && c <= '9':
+			n += string(c)
+		default:
+			unit, ok := units[c]
+			v, err := strconv.Atoi(n)
+			if !ok || err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			d += time.Duration(v) * unit
+			n = ""
+		}
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// expand returns the occurrences of a recurring event overlapping
+// from..to. Weeks start on Monday.
+func expand(v vevent, from, to time.Time) []time.Time {
+	rule := map[string]string{}
+	for _, part := range strings.Split(v.rrule, ";") {
+		if k, val, ok := strings.Cut(part, "="); ok {
+			rule[strings.ToUpper(k)] = val
+		}
+	}
+	interval, _ := strconv.Atoi(rule["INTERVAL"])
+	interval = max(interval, 1)
+	count, _ := strconv.Atoi(rule["COUNT"])
+	var until time.Time
+	if rule["UNTIL"] != "" {
+		until, _, _ = parseTime(property{value: rule["UNTIL"]}, v.start.Location())
+	}
+	var byday []int // Days from Monday
+	for _, day := range strings.Split(rule["BYDAY"], ",") {
+		if len(day) >= 2 {
+			if wd, ok := weekdays[strings.ToUpper(day[len(day)-2:])]; ok {
+				byday = append(byday, (int(wd)+6)%7)
+			}
+		}
+	}
+	sort.Ints(byday)
+	hasBy := false
+	for k := range rule {
+		hasBy = hasBy || strings.HasPrefix(k, "BY")
+	}
+
+	length := v.length()
+	start := v.start
+	var out []time.Time
+	n := 0
+	for period := 0; period < maxPeriods; period++ {
+		var candidates []time.Time
+		switch rule["FREQ"] {
+		case "DAILY":
+			candidates = []time.Time{start.AddDate(0, 0, period*interval)}
+		case "WEEKLY":
+			base := start.AddDate(0, 0, period*interval*7)
+			if len(byday) == 0 {
+				candidates = []time.Time{base}
+				break
+			}
+			monday := base.AddDate(0, 0, -((int(base.Weekday()) + 6) % 7))
+			for _, d := range byday {
+				candidates = append(candidates, monday.AddDate(0, 0, d))
+			}
+		case "MONTHLY", "YEARLY":
+			if hasBy && period > 0 {
+				return out
+			}
+			c := start.AddDate(0, period*interval, 0)
+			if rule["FREQ"] == "YEARLY" {
+				c = start.AddDate(period*interval, 0, 0)
+			}
+			if c.Day() != start.Day() {
+				continue // No such day this month, like February 30
+			}
+			candidates = []time.Time{c}
+		default:
+			if period > 0 {
+				return out
+			}
+			candidates = []time.Time{start}
+		}
+
+		for _, c := range candidates {
+			if c.Before(start) {
+				continue
+			}
+			if (!until.IsZero() && c.After(until)) || c.After(to) {
+				return out
+			}
+			if n++; count > 0 && n > count {
+				return out
+			}
+			if inRange(c, length, from, to) {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
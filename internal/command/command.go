@@ -0,0 +1,131 @@
+// Package command parses and completes the one-line commands typed in the
+// screensaver's command palette.
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrEmpty is returned by Parse for a blank line.
+var ErrEmpty = errors.New("empty command")
+
+// Command is a palette command.
+type Command struct {
+	Name  string
+	Usage string          // Arguments shown in help, e.g. "<name>"
+	Args  func() []string // Completion candidates of the argument (nil: none)
+}
+
+// Set is the commands known to a palette.
+type Set []Command
+
+// Lookup returns the command named name.
+func (set Set) Lookup(name string) (Command, bool) {
+	for _, c := range set {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// Names returns the command names, sorted.
+func (set Set) Names() []string {
+	names := make([]string, len(set))
+	for i, c := range set {
+		names[i] = c.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Help describes every command on one line.
+func (set Set) Help() string {
+	var usages []string
+	for _, name := range set.Names() {
+		c, _ := set.Lookup(name)
+		usages = append(usages, strings.TrimSpace(c.Name+" "+c.Usage))
+	}
+	return strings.Join(usages, " · ")
+}
+
+// Parse splits line into a command of the set and its arguments. A unique
+// prefix of a command name selects it, so "q" quits.
+func (set Set) Parse(line string) (Command, []string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Command{}, nil, ErrEmpty
+	}
+	if c, ok := set.Lookup(fields[0]); ok {
+		return c, fields[1:], nil
+	}
+	matches := filter(set.Names(), fields[0])
+	switch len(matches) {
+	case 0:
+		return Command{}, nil, fmt.Errorf("unknown command %q", fields[0])
+	case 1:
+		c, _ := set.Lookup(matches[0])
+		return c, fields[1:], nil
+	default:
+		return Command{}, nil, fmt.Errorf("ambiguous command %q: %s", fields[0], strings.Join(matches, ", "))
+	}
+}
+
+// Complete completes the last word of line: the command name for the
+// first word, the command's argument for the second. The word is extended
+// to the longest prefix shared by the candidates, followed by a space when
+// only one matches. Complete returns the new line and the matching
+// candidates.
+func (set Set) Complete(line string) (string, []string) {
+	fields := strings.Fields(line)
+	word := ""
+	if len(fields) > 0 && !strings.HasSuffix(line, " ") {
+		word = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+
+	var candidates []string
+	switch len(fields) {
+	case 0:
+		candidates = set.Names()
+	case 1:
+		if c, ok := set.Lookup(fields[0]); ok && c.Args != nil {
+			candidates = c.Args()
+		}
+	}
+	matches := filter(candidates, word)
+	if len(matches) == 0 {
+		return line, nil
+	}
+
+	completed := matches[0]
+	for _, m := range matches[1:] {
+		completed = commonPrefix(completed, m)
+	}
+	if len(matches) == 1 {
+		completed += " "
+	}
+	return strings.Join(append(fields, completed), " "), matches
+}
+
+// filter returns the candidates starting with prefix.
+func filter(candidates []string, prefix string) []string {
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
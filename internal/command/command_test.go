@@ -0,0 +1,63 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSet = Set{
+	{Name: "theme", Usage: "<name>", Args: func() []string { return []string{"contribs", "fire", "frost"} }},
+	{Name: "fps", Usage: "<n>"},
+	{Name: "lock"},
+	{Name: "quit"},
+}
+
+func TestParse(t *testing.T) {
+	c, args, err := testSet.Parse("  theme   frost ")
+	require.NoError(t, err)
+	assert.Equal(t, "theme", c.Name)
+	assert.Equal(t, []string{"frost"}, args)
+
+	c, args, err = testSet.Parse("q")
+	require.NoError(t, err)
+	assert.Equal(t, "quit", c.Name, "unique prefix")
+	assert.Empty(t, args)
+
+	_, _, err = testSet.Parse("   ")
+	assert.ErrorIs(t, err, ErrEmpty)
+
+	_, _, err = testSet.Parse("nope")
+	assert.ErrorContains(t, err, "unknown command")
+
+	set := append(Set{{Name: "follow"}}, testSet...)
+	_, _, err = set.Parse("f")
+	assert.ErrorContains(t, err, "ambiguous")
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    string
+		matches []string
+	}{
+		{"th", "theme ", []string{"theme"}},
+		{"", "", []string{"fps", "lock", "quit", "theme"}},
+		{"theme f", "theme f", []string{"fire", "frost"}},
+		{"theme fr", "theme frost ", []string{"frost"}},
+		{"theme ", "theme ", []string{"contribs", "fire", "frost"}},
+		{"fps 2", "fps 2", nil},
+		{"theme fire x", "theme fire x", nil},
+		{"zz", "zz", nil},
+	}
+	for _, tt := range tests {
+		got, matches := testSet.Complete(tt.line)
+		assert.Equal(t, tt.want, got, tt.line)
+		assert.Equal(t, tt.matches, matches, tt.line)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	assert.Equal(t, "fps <n> · lock · quit · theme <name>", testSet.Help())
+}
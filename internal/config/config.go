@@ -0,0 +1,428 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+
+	"yule-log/internal/fire"
+	"yule-log/internal/forge"
+	"yule-log/internal/lock"
+	"yule-log/internal/shader"
+	"yule-log/internal/ticker"
+	"yule-log/internal/xdg"
+)
+
+// RepoFileName is the name of the repository-local configuration file,
+// looked up at the root of the git work tree.
+const RepoFileName = ".yule-log.toml"
+
+// ---- Configuration Layers
+// Settings are layered: global (XDG config dir) < repository < flags.
+// Every field is optional so that a layer only overrides what it sets.
+
+// Ticker holds the commit ticker settings.
+type Ticker struct {
+	Disabled   *bool    `toml:"disabled"`
+	MaxCommits *int     `toml:"max_commits"`
+	MaxWidth   *int     `toml:"max_width"` // Segment width before ellipsis truncation
+	Include    []string `toml:"include"`   // Subject regexps, at least one must match
+	Exclude    []string `toml:"exclude"`   // Subject regexps, none may match
+	Sources    []string `toml:"sources"`   // Item sources, in order (default: commits)
+	Repos      []string `toml:"repos"`     // More repositories interleaved in the commits
+	Command    *string  `toml:"command"`   // Shell command of the command source
+	Feeds      []string `toml:"feeds"`     // RSS or Atom URLs of the feed source
+}
+
+// Daylight holds the location used for sunrise and sunset times by the
+// time-of-day palette shift, in degrees (longitude east positive).
+type Daylight struct {
+	Latitude  *float64 `toml:"latitude"`
+	Longitude *float64 `toml:"longitude"`
+}
+
+// Animation holds the background animation of each screensaver mode,
+// used when --animation is not given.
+type Animation struct {
+	Normal     *string `toml:"normal"`
+	Playground *string `toml:"playground"`
+	Lock       *string `toml:"lock"`
+	Model      *string `toml:"model"` // Fire heat propagation, overrides the theme's
+}
+
+// Forge holds the code hosting service of the forge ticker source.
+type Forge struct {
+	Backend *string `toml:"backend"` // Default: github
+	URL     *string `toml:"url"`     // API base URL, for self-hosted instances
+	User    *string `toml:"user"`    // Contribution calendar owner, default: the token's
+}
+
+// Calendar holds the event sources of the calendar ticker source.
+type Calendar struct {
+	URL     *string `toml:"url"`     // ICS feed, http(s) URL or file
+	Command *string `toml:"command"` // Shell command printing events as TSV
+	Flash   *bool   `toml:"flash"`   // Flash the screen before an event
+}
+
+// Heat holds the heat sources of the fire.
+type Heat struct {
+	Profile *string `toml:"profile"` // Heat path profile of HeatFile, default: default
+}
+
+// Weather holds the source of the weather line, shown with --weather.
+type Weather struct {
+	Location *string `toml:"location"` // wttr.in location, default: from the IP address
+	Command  *string `toml:"command"`  // Shell command printing the line instead
+}
+
+// Effects holds the post-processing of the fire (experimental).
+type Effects struct {
+	Enabled *bool    `toml:"enabled"` // Default: off
+	Chain   []string `toml:"chain"`   // Effect chain, overrides the theme's
+}
+
+// Hooks holds shell commands run on screensaver and lock events, e.g. to
+// pause notifications while locked. Only the global file may set them.
+type Hooks struct {
+	Start   *string `toml:"start"`   // Screensaver start, in any mode
+	Idle    *string `toml:"idle"`    // Idle watcher trigger
+	Lock    *string `toml:"lock"`    // Lock screen start
+	Unlock  *string `toml:"unlock"`  // Password accepted, or lock given up (--max-lock)
+	Failed  *string `toml:"failed"`  // Password rejected
+	Panic   *string `toml:"panic"`   // Panic keys pressed on the lock screen
+	Timeout *string `toml:"timeout"` // Bound of each command, default: 10s
+
+	PanicKeys *string `toml:"panic_keys"` // Special key names, default: F12 F12 F12
+}
+
+// Password holds the lock password settings. Only the global file may set
+// them.
+type Password struct {
+	Storage     *string `toml:"storage"`      // "file" (default) or "keychain"
+	MinLength   *int    `toml:"min_length"`   // Keys, default: 8
+	MinStrength *string `toml:"min_strength"` // weak, fair, strong or very strong
+	RequireKeys *bool   `toml:"require_keys"` // An arrow, function or navigation key
+	DuressExec  *string `toml:"duress_exec"`  // Run on the duress password, default: tmux kill-server
+}
+
+// Config is the content of a configuration file.
+type Config struct {
+	Ticker    Ticker    `toml:"ticker"`
+	Daylight  Daylight  `toml:"daylight"`
+	Animation Animation `toml:"animation"`
+	Forge     Forge     `toml:"forge"`
+	Calendar  Calendar  `toml:"calendar"`
+	Heat      Heat      `toml:"heat"`
+	Weather   Weather   `toml:"weather"`
+	Effects   Effects   `toml:"effects"`
+	Hooks     Hooks     `toml:"hooks"`
+	Password  Password  `toml:"password"`
+}
+
+// Merge overlays the fields set in other on top of c.
+func (c *Config) Merge(other Config) {
+	if other.Ticker.Disabled != nil {
+		c.Ticker.Disabled = other.Ticker.Disabled
+	}
+	if other.Ticker.MaxCommits != nil {
+		c.Ticker.MaxCommits = other.Ticker.MaxCommits
+	}
+	if other.Ticker.MaxWidth != nil {
+		c.Ticker.MaxWidth = other.Ticker.MaxWidth
+	}
+	if other.Ticker.Include != nil {
+		c.Ticker.Include = other.Ticker.Include
+	}
+	if other.Ticker.Exclude != nil {
+		c.Ticker.Exclude = other.Ticker.Exclude
+	}
+	if other.Ticker.Sources != nil {
+		c.Ticker.Sources = other.Ticker.Sources
+	}
+	if other.Ticker.Repos != nil {
+		c.Ticker.Repos = other.Ticker.Repos
+	}
+	if other.Ticker.Command != nil {
+		c.Ticker.Command = other.Ticker.Command
+	}
+	if other.Ticker.Feeds != nil {
+		c.Ticker.Feeds = other.Ticker.Feeds
+	}
+	if other.Daylight.Latitude != nil {
+		c.Daylight.Latitude = other.Daylight.Latitude
+	}
+	if other.Daylight.Longitude != nil {
+		c.Daylight.Longitude = other.Daylight.Longitude
+	}
+	if other.Animation.Normal != nil {
+		c.Animation.Normal = other.Animation.Normal
+	}
+	if other.Animation.Playground != nil {
+		c.Animation.Playground = other.Animation.Playground
+	}
+	if other.Animation.Lock != nil {
+		c.Animation.Lock = other.Animation.Lock
+	}
+	if other.Animation.Model != nil {
+		c.Animation.Model = other.Animation.Model
+	}
+	if other.Forge.Backend != nil {
+		c.Forge.Backend = other.Forge.Backend
+	}
+	if other.Forge.URL != nil {
+		c.Forge.URL = other.Forge.URL
+	}
+	if other.Forge.User != nil {
+		c.Forge.User = other.Forge.User
+	}
+	if other.Calendar.URL != nil {
+		c.Calendar.URL = other.Calendar.URL
+	}
+	if other.Calendar.Command != nil {
+		c.Calendar.Command = other.Calendar.Command
+	}
+	if other.Calendar.Flash != nil {
+		c.Calendar.Flash = other.Calendar.Flash
+	}
+	if other.Heat.Profile != nil {
+		c.Heat.Profile = other.Heat.Profile
+	}
+	if other.Weather.Location != nil {
+		c.Weather.Location = other.Weather.Location
+	}
+	if other.Weather.Command != nil {
+		c.Weather.Command = other.Weather.Command
+	}
+	if other.Effects.Enabled != nil {
+		c.Effects.Enabled = other.Effects.Enabled
+	}
+	if other.Effects.Chain != nil {
+		c.Effects.Chain = other.Effects.Chain
+	}
+	if other.Hooks.Start != nil {
+		c.Hooks.Start = other.Hooks.Start
+	}
+	if other.Hooks.Idle != nil {
+		c.Hooks.Idle = other.Hooks.Idle
+	}
+	if other.Hooks.Lock != nil {
+		c.Hooks.Lock = other.Hooks.Lock
+	}
+	if other.Hooks.Unlock != nil {
+		c.Hooks.Unlock = other.Hooks.Unlock
+	}
+	if other.Hooks.Failed != nil {
+		c.Hooks.Failed = other.Hooks.Failed
+	}
+	if other.Hooks.Panic != nil {
+		c.Hooks.Panic = other.Hooks.Panic
+	}
+	if other.Hooks.Timeout != nil {
+		c.Hooks.Timeout = other.Hooks.Timeout
+	}
+	if other.Hooks.PanicKeys != nil {
+		c.Hooks.PanicKeys = other.Hooks.PanicKeys
+	}
+	if other.Password.Storage != nil {
+		c.Password.Storage = other.Password.Storage
+	}
+	if other.Password.MinLength != nil {
+		c.Password.MinLength = other.Password.MinLength
+	}
+	if other.Password.MinStrength != nil {
+		c.Password.MinStrength = other.Password.MinStrength
+	}
+	if other.Password.RequireKeys != nil {
+		c.Password.RequireKeys = other.Password.RequireKeys
+	}
+	if other.Password.DuressExec != nil {
+		c.Password.DuressExec = other.Password.DuressExec
+	}
+}
+
+// Validate checks that values are in range and filters compile.
+func (c Config) Validate() error {
+	if c.Ticker.MaxCommits != nil && *c.Ticker.MaxCommits <= 0 {
+		return fmt.Errorf("ticker.max_commits must be positive, got %d", *c.Ticker.MaxCommits)
+	}
+	if c.Ticker.MaxWidth != nil && *c.Ticker.MaxWidth < 8 {
+		return fmt.Errorf("ticker.max_width must be at least 8, got %d", *c.Ticker.MaxWidth)
+	}
+	for _, expr := range append(append([]string{}, c.Ticker.Include...), c.Ticker.Exclude...) {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("ticker filter %q: %w", expr, err)
+		}
+	}
+	for _, source := range c.Ticker.Sources {
+		if !ticker.ValidSource(source) {
+			return fmt.Errorf("ticker.sources: unknown source %q (want one of %s)", source, strings.Join(ticker.Sources, ", "))
+		}
+	}
+	for _, url := range c.Ticker.Feeds {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return fmt.Errorf("ticker.feeds: %q is not an http(s) URL", url)
+		}
+	}
+	if lat := c.Daylight.Latitude; lat != nil && (*lat < -90 || *lat > 90) {
+		return fmt.Errorf("daylight.latitude must be within -90..90, got %g", *lat)
+	}
+	if lon := c.Daylight.Longitude; lon != nil && (*lon < -180 || *lon > 180) {
+		return fmt.Errorf("daylight.longitude must be within -180..180, got %g", *lon)
+	}
+	if (c.Daylight.Latitude == nil) != (c.Daylight.Longitude == nil) {
+		return fmt.Errorf("daylight needs both latitude and longitude")
+	}
+	if m := c.Animation.Model; m != nil {
+		if _, err := fire.NewModel(*m); err != nil {
+			return fmt.Errorf("animation.model: %w", err)
+		}
+	}
+	if b := c.Forge.Backend; b != nil {
+		if _, err := forge.New(forge.Backend(*b), forge.Options{}); err != nil {
+			return fmt.Errorf("forge.backend: %w", err)
+		}
+	}
+	if p := c.Heat.Profile; p != nil && strings.TrimSpace(*p) == "" {
+		return fmt.Errorf("heat.profile must not be empty")
+	}
+	if _, err := shader.Parse(c.Effects.Chain); err != nil {
+		return fmt.Errorf("effects.chain: %w", err)
+	}
+	if t := c.Hooks.Timeout; t != nil {
+		if d, err := time.ParseDuration(*t); err != nil || d <= 0 {
+			return fmt.Errorf("hooks.timeout must be a positive duration, e.g. 5s, got %q", *t)
+		}
+	}
+	if k := c.Hooks.PanicKeys; k != nil {
+		if _, err := lock.ParseKeySequence(*k); err != nil {
+			return fmt.Errorf("hooks.panic_keys: %w", err)
+		}
+	}
+	if st := c.Password.Storage; st != nil && *st != "file" && *st != "keychain" {
+		return fmt.Errorf("password.storage must be file or keychain, got %q", *st)
+	}
+	if n := c.Password.MinLength; n != nil && *n < 1 {
+		return fmt.Errorf("password.min_length must be positive, got %d", *n)
+	}
+	if s := c.Password.MinStrength; s != nil {
+		if _, err := lock.ParseStrength(*s); err != nil {
+			return fmt.Errorf("password.min_strength: %w", err)
+		}
+	}
+	return nil
+}
+
+// DefaultHookTimeout bounds hook commands when [hooks] timeout is not set.
+const DefaultHookTimeout = 10 * time.Second
+
+// HookTimeout returns the bound of hook commands.
+func (c Config) HookTimeout() time.Duration {
+	if c.Hooks.Timeout != nil {
+		if d, err := time.ParseDuration(*c.Hooks.Timeout); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultHookTimeout
+}
+
+// FileError reports a configuration file that can't be read or is
+// invalid.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string { return e.Path + ": " + e.Err.Error() }
+func (e *FileError) Unwrap() error { return e.Err }
+
+// LoadFile reads a single configuration file.
+// A missing file yields an empty configuration and no error. Other errors
+// are *FileError.
+func LoadFile(path string) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, &FileError{Path: path, Err: fmt.Errorf("reading: %w", err)}
+	}
+
+	cfg, err = Parse(data)
+	if err != nil {
+		return Config{}, &FileError{Path: path, Err: err}
+	}
+	return cfg, nil
+}
+
+// Parse decodes and validates the content of a configuration file.
+func Parse(data []byte) (Config, error) {
+	var cfg Config
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parsing: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	for _, table := range FlagTables {
+		if _, err := FlagDefaults(data, table); err != nil {
+			return Config{}, err
+		}
+	}
+	return cfg, nil
+}
+
+// Load returns the global configuration overlaid with the repository
+// configuration of the work tree containing dir (if any).
+// Layers that fail to load are skipped; their errors are joined and
+// returned alongside the configuration built from the remaining layers.
+func Load(dir string) (Config, error) {
+	var cfg Config
+	var errs []error
+
+	if path, err := xdg.ConfigFile(); err == nil {
+		global, err := LoadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		cfg.Merge(global)
+	}
+
+	if root := RepoRoot(dir); root != "" {
+		repo, err := LoadFile(filepath.Join(root, RepoFileName))
+		if err != nil {
+			errs = append(errs, err)
+		}
+		// A cloned repository must not run commands
+		repo.Calendar.Command = nil
+		repo.Ticker.Command = nil
+		repo.Weather.Command = nil
+		repo.Hooks = Hooks{}
+		repo.Password = Password{}
+		// nor send the forge token to a host of its choosing
+		repo.Forge = Forge{}
+		cfg.Merge(repo)
+	}
+
+	return cfg, errors.Join(errs...)
+}
+
+// RepoRoot returns the top-level directory of the git work tree containing
+// dir, or "" if dir is not inside a repository.
+func RepoRoot(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
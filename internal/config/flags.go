@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/peterbourgon/ff/v3"
+)
+
+// ---- Flag Defaults
+// The [run], [idle] and [lock] tables of the global configuration file hold
+// defaults for the flags of the matching command, so they don't have to be
+// passed on every invocation:
+//
+//	[run]
+//	contribs = true
+//	cooldown = "slow"
+//
+// Keys are flag names, with dashes or underscores. Command line flags and
+// YULE_LOG_* environment variables take precedence.
+
+// FlagTables lists the tables holding flag defaults.
+var FlagTables = []string{"run", "idle", "lock"}
+
+// FlagParser returns an ff config file parser feeding the keys of table to
+// the command's flags. Other tables are ignored. Errors are *FileError for
+// path, the file ff reads.
+func FlagParser(path, table string) ff.ConfigFileParser {
+	return func(r io.Reader, set func(name, value string) error) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return &FileError{Path: path, Err: fmt.Errorf("reading: %w", err)}
+		}
+		values, err := FlagDefaults(data, table)
+		if err != nil {
+			return &FileError{Path: path, Err: err}
+		}
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := set(name, values[name]); err != nil {
+				return &FileError{Path: path, Err: fmt.Errorf("[%s]: %w", table, err)}
+			}
+		}
+		return nil
+	}
+}
+
+// FlagDefaults returns the flag values of table in a configuration file,
+// keyed by flag name. Lists become comma-separated values.
+func FlagDefaults(data []byte, table string) (map[string]string, error) {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	values := make(map[string]string)
+	if !tree.Has(table) {
+		return values, nil
+	}
+	sub, ok := tree.Get(table).(*toml.Tree)
+	if !ok {
+		return nil, fmt.Errorf("config: %s must be a table", table)
+	}
+	for key, v := range sub.ToMap() {
+		value, err := flagValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("config [%s] %s: %w", table, key, err)
+		}
+		values[strings.ReplaceAll(key, "_", "-")] = value
+	}
+	return values, nil
+}
+
+func flagValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string, bool, int64, float64:
+		return fmt.Sprint(v), nil
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			s, err := flagValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/peterbourgon/ff/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagDefaults(t *testing.T) {
+	data := []byte(`
+[ticker]
+max_commits = 5
+
+[run]
+contribs = true
+cooldown = "slow"
+intensity = 60
+ember_after = "5m"
+events = ["sparks", "wind"]
+
+[idle]
+timeout = 600
+`)
+
+	run, err := FlagDefaults(data, "run")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"contribs":    "true",
+		"cooldown":    "slow",
+		"intensity":   "60",
+		"ember-after": "5m",
+		"events":      "sparks,wind",
+	}, run)
+
+	idle, err := FlagDefaults(data, "idle")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"timeout": "600"}, idle)
+
+	lock, err := FlagDefaults(data, "lock")
+	require.NoError(t, err)
+	assert.Empty(t, lock)
+}
+
+func TestFlagDefaultsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "bad toml", data: "[run"},
+		{name: "not a table", data: "run = 1"},
+		{name: "nested table", data: "[run.sub]\nx = 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FlagDefaults([]byte(tt.data), "run")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFlagParserPrecedence(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "config.toml", `
+[run]
+contribs = true
+cooldown = "slow"
+intensity = 60
+`)
+
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	contribs := fs.Bool("contribs", false, "")
+	cooldown := fs.String("cooldown", "medium", "")
+	intensity := fs.Int("intensity", 75, "")
+
+	t.Setenv("TEST_YULE_LOG_INTENSITY", "40")
+	err := ff.Parse(fs, []string{"--cooldown", "fast"},
+		ff.WithEnvVarPrefix("TEST_YULE_LOG"),
+		ff.WithConfigFile(path),
+		ff.WithConfigFileParser(FlagParser(path, "run")),
+	)
+	require.NoError(t, err)
+	assert.True(t, *contribs, "from the file")
+	assert.Equal(t, "fast", *cooldown, "flags win over the file")
+	assert.Equal(t, 40, *intensity, "environment wins over the file")
+}
+
+func TestFlagParserUnknownFlag(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "config.toml", "[run]\ncontrib = true\n")
+
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.Bool("contribs", false, "")
+	err := ff.Parse(fs, nil, ff.WithConfigFile(path), ff.WithConfigFileParser(FlagParser(path, "run")))
+	assert.ErrorContains(t, err, "contrib")
+	var fileErr *FileError
+	assert.ErrorAs(t, err, &fileErr)
+}
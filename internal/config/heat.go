@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pelletier/go-toml"
+
+	"yule-log/internal/fire"
+	"yule-log/internal/fsutil"
+)
+
+// ---- Heat Paths
+// The heat paths drawn in playground mode are saved in their own file of
+// the config directory, one table per profile, so [heat] profile can pick
+// one per repository:
+//
+//	[default]
+//	strokes = ["0,100 100,100", "0,60 0,100"]
+
+// DefaultHeatProfile is the profile used when [heat] profile is not set.
+const DefaultHeatProfile = "default"
+
+// heatProfile is a table of the heat paths file.
+type heatProfile struct {
+	Strokes []string `toml:"strokes"`
+}
+
+// HeatProfile returns the selected heat path profile.
+func (c Config) HeatProfile() string {
+	if c.Heat.Profile != nil {
+		return *c.Heat.Profile
+	}
+	return DefaultHeatProfile
+}
+
+// LoadHeatPath returns the heat path of profile in the file at path. A
+// missing file or profile yields no path and no error.
+func LoadHeatPath(path, profile string) (fire.Path, error) {
+	profiles, err := readHeatProfiles(path)
+	if err != nil {
+		return nil, err
+	}
+	var p fire.Path
+	for _, s := range profiles[profile].Strokes {
+		stroke, err := fire.ParseStroke(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, profile, err)
+		}
+		p = append(p, stroke)
+	}
+	return p, nil
+}
+
+// SaveHeatPath stores p as the heat path of profile in the file at path,
+// keeping the other profiles. An empty path removes the profile.
+func SaveHeatPath(path, profile string, p fire.Path) error {
+	return fsutil.Update(path, 0600, func(data []byte) ([]byte, error) {
+		profiles := map[string]heatProfile{}
+		if err := toml.Unmarshal(data, &profiles); err != nil {
+			return nil, fmt.Errorf("%s: parsing: %w", path, err)
+		}
+		if len(p) == 0 {
+			delete(profiles, profile)
+		} else {
+			strokes := make([]string, len(p))
+			for i, stroke := range p {
+				strokes[i] = stroke.String()
+			}
+			profiles[profile] = heatProfile{Strokes: strokes}
+		}
+		return toml.Marshal(profiles)
+	})
+}
+
+func readHeatProfiles(path string) (map[string]heatProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var profiles map[string]heatProfile
+	if err := toml.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("%s: parsing: %w", path, err)
+	}
+	return profiles, nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/fire"
+)
+
+func TestHeatPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heat.toml")
+	p, err := LoadHeatPath(path, DefaultHeatProfile)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	desk := fire.Path{
+		{{X: 0, Y: 100}, {X: 100, Y: 100}},
+		{{X: 0, Y: 60}, {X: 0, Y: 100}},
+	}
+	require.NoError(t, SaveHeatPath(path, "desk", desk))
+	require.NoError(t, SaveHeatPath(path, DefaultHeatProfile, fire.Path{{{X: 12.5, Y: 100}}}))
+
+	p, err = LoadHeatPath(path, "desk")
+	require.NoError(t, err)
+	assert.Equal(t, desk, p)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"0,100 100,100"`)
+
+	// An empty path removes the profile
+	require.NoError(t, SaveHeatPath(path, "desk", nil))
+	p, err = LoadHeatPath(path, "desk")
+	require.NoError(t, err)
+	assert.Nil(t, p)
+	p, err = LoadHeatPath(path, DefaultHeatProfile)
+	require.NoError(t, err)
+	assert.Len(t, p, 1)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[bad]
+strokes = ["0,100 150,100"]`), 0600))
+	_, err = LoadHeatPath(path, "bad")
+	assert.ErrorContains(t, err, "150,100")
+}
+
+func TestHeatProfile(t *testing.T) {
+	assert.Equal(t, DefaultHeatProfile, Config{}.HeatProfile())
+	cfg, err := Parse([]byte(`[heat]
+profile = "desk"`))
+	require.NoError(t, err)
+	assert.Equal(t, "desk", cfg.HeatProfile())
+
+	_, err = Parse([]byte(`[heat]
+profile = " "`))
+	assert.Error(t, err)
+}
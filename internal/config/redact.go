@@ -0,0 +1,88 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ---- Redaction
+// Bug reports carry the effective configuration. Commands, feed and
+// calendar URLs (private calendar feeds embed their token), the location
+// and the panic keys are replaced by a marker: what helps triage is
+// whether they are set, not their value.
+
+// RedactedValue replaces redacted values.
+const RedactedValue = "<redacted>"
+
+// Redacted returns a copy of c safe to share.
+func (c Config) Redacted() Config {
+	redacted := RedactedValue
+	hide := func(s *string) *string {
+		if s == nil {
+			return nil
+		}
+		return &redacted
+	}
+	hideURL := func(s *string) *string {
+		if s == nil {
+			return nil
+		}
+		u := RedactURL(*s)
+		return &u
+	}
+
+	c.Ticker.Command = hide(c.Ticker.Command)
+	if c.Ticker.Feeds != nil {
+		feeds := make([]string, len(c.Ticker.Feeds))
+		for i, feed := range c.Ticker.Feeds {
+			feeds[i] = RedactURL(feed)
+		}
+		c.Ticker.Feeds = feeds
+	}
+	c.Forge.URL = hideURL(c.Forge.URL)
+	c.Calendar.URL = hideURL(c.Calendar.URL)
+	c.Calendar.Command = hide(c.Calendar.Command)
+	c.Weather.Location = hide(c.Weather.Location)
+	c.Weather.Command = hide(c.Weather.Command)
+	c.Hooks.Start = hide(c.Hooks.Start)
+	c.Hooks.Idle = hide(c.Hooks.Idle)
+	c.Hooks.Lock = hide(c.Hooks.Lock)
+	c.Hooks.Unlock = hide(c.Hooks.Unlock)
+	c.Hooks.Failed = hide(c.Hooks.Failed)
+	c.Hooks.Panic = hide(c.Hooks.Panic)
+	c.Hooks.PanicKeys = hide(c.Hooks.PanicKeys)
+	c.Password.DuressExec = hide(c.Password.DuressExec)
+	if c.Daylight.Latitude != nil || c.Daylight.Longitude != nil {
+		zero := 0.0
+		c.Daylight.Latitude, c.Daylight.Longitude = &zero, &zero
+	}
+	return c
+}
+
+// RedactURL keeps the scheme and host of an http(s) URL, which tell the
+// service apart, and redacts the rest. Anything else (a file path) is
+// redacted whole.
+func RedactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return RedactedValue
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if strings.TrimSuffix(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+		redacted += "/" + RedactedValue
+	}
+	return redacted
+}
+
+// RedactFlags returns flag defaults (see FlagDefaults) with the values
+// of the flags running commands redacted.
+func RedactFlags(values map[string]string) map[string]string {
+	redacted := make(map[string]string, len(values))
+	for name, value := range values {
+		if value != "" && (strings.HasSuffix(name, "exec") || strings.HasSuffix(name, "-cmd") || strings.HasSuffix(name, "command")) {
+			value = RedactedValue
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedacted(t *testing.T) {
+	c, err := Parse([]byte(`
+[ticker]
+max_commits = 5
+command = "curl -H 'Authorization: Bearer s3cret' https://example.com"
+feeds = ["https://example.com/feed.xml?token=s3cret", "https://blog.example.org/"]
+
+[forge]
+url = "https://git.example.com/api/v1"
+user = "me"
+
+[calendar]
+url = "https://calendar.example.com/private-s3cret/basic.ics"
+
+[weather]
+location = "Paris"
+
+[password]
+duress_exec = "rm -rf ~/.ssh"
+
+[daylight]
+latitude = 48.85
+longitude = 2.35
+`))
+	require.NoError(t, err)
+
+	r := c.Redacted()
+	assert.Equal(t, 5, *r.Ticker.MaxCommits)
+	assert.Equal(t, RedactedValue, *r.Ticker.Command)
+	assert.Equal(t, []string{"https://example.com/" + RedactedValue, "https://blog.example.org"}, r.Ticker.Feeds)
+	assert.Equal(t, "https://git.example.com/"+RedactedValue, *r.Forge.URL)
+	assert.Equal(t, "me", *r.Forge.User)
+	assert.Equal(t, "https://calendar.example.com/"+RedactedValue, *r.Calendar.URL)
+	assert.Nil(t, r.Calendar.Command, "unset stays unset")
+	assert.Equal(t, RedactedValue, *r.Weather.Location)
+	assert.Equal(t, RedactedValue, *r.Password.DuressExec)
+	assert.Zero(t, *r.Daylight.Latitude)
+
+	assert.Equal(t, "Paris", *c.Weather.Location, "the original is left alone")
+	assert.Equal(t, "https://example.com/feed.xml?token=s3cret", c.Ticker.Feeds[0])
+}
+
+// TestRedactedCommands sets every command of the configuration, and every
+// hook setting but the timeout, and checks that none comes out in clear.
+func TestRedactedCommands(t *testing.T) {
+	var c Config
+	secret := "s3cret"
+	var fields []string
+	v := reflect.ValueOf(&c).Elem()
+	for i := range v.NumField() {
+		table := v.Type().Field(i)
+		for j := range table.Type.NumField() {
+			field := table.Type.Field(j)
+			tag := field.Tag.Get("toml")
+			isCommand := strings.HasSuffix(tag, "command") || strings.HasSuffix(tag, "exec")
+			isHook := table.Name == "Hooks" && tag != "timeout"
+			if field.Type != reflect.TypeOf(&secret) || !(isCommand || isHook) {
+				continue
+			}
+			v.Field(i).Field(j).Set(reflect.ValueOf(&secret))
+			fields = append(fields, table.Name+"."+field.Name)
+		}
+	}
+	require.Contains(t, fields, "Hooks.PanicKeys")
+	require.Contains(t, fields, "Password.DuressExec")
+
+	r := reflect.ValueOf(c.Redacted())
+	for _, name := range fields {
+		table, field, _ := strings.Cut(name, ".")
+		got := r.FieldByName(table).FieldByName(field).Interface().(*string)
+		assert.Equal(t, RedactedValue, *got, name)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	assert.Equal(t, "https://example.com", RedactURL("https://example.com"))
+	assert.Equal(t, "https://example.com/"+RedactedValue, RedactURL("https://user:pw@example.com/x"))
+	assert.Equal(t, RedactedValue, RedactURL("/home/me/calendar.ics"))
+	assert.Equal(t, RedactedValue, RedactURL("::"))
+}
+
+func TestRedactFlags(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"theme":             "aurora",
+		"ticker-cmd":        RedactedValue,
+		"exec":              RedactedValue,
+		"max-lock-exec":     RedactedValue,
+		"ticker-click-exec": "",
+	}, RedactFlags(map[string]string{
+		"theme":             "aurora",
+		"ticker-cmd":        "cat ~/notes",
+		"exec":              "notify-send idle",
+		"max-lock-exec":     "systemctl suspend",
+		"ticker-click-exec": "",
+	}))
+}
@@ -0,0 +1,118 @@
+// Package daylight computes a color temperature shift from the time of
+// day: cooler around the morning, warmer in the evening, like f.lux.
+// Sunrise and sunset come from a location when one is configured, and
+// default to 7:00 and 19:00 otherwise.
+package daylight
+
+import (
+	"math"
+	"time"
+)
+
+// Default sun times (hours after local midnight) without a location, or
+// during polar days and nights.
+const (
+	DefaultSunrise = 7.0
+	DefaultSunset  = 19.0
+)
+
+// Location is a position on Earth in degrees, longitude east positive.
+type Location struct {
+	Latitude, Longitude float64
+}
+
+// Sun holds the sunrise and sunset of a day, in hours after local
+// midnight.
+type Sun struct {
+	Rise, Set float64
+}
+
+// DefaultSun is used when no location is known.
+var DefaultSun = Sun{Rise: DefaultSunrise, Set: DefaultSunset}
+
+// ---- Sunrise Equation
+// See https://en.wikipedia.org/wiki/Sunrise_equation. Accurate to a few
+// minutes, which is plenty for tinting a fire.
+
+const (
+	julianUnixEpoch = 2440587.5 // Julian date of 1970-01-01 00:00 UTC
+	julianJ2000     = 2451545.0 // Julian date of 2000-01-01 12:00 UTC
+	earthTilt       = 23.4397   // Degrees
+	sunAltitude     = -0.833    // Degrees, refraction and solar disc
+)
+
+// SunAt returns the sunrise and sunset of the day of t at loc, in the
+// time zone of t. ok is false during polar days and nights.
+func SunAt(t time.Time, loc Location) (sun Sun, ok bool) {
+	noon := time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, t.Location())
+	jd := float64(noon.Unix())/86400 + julianUnixEpoch
+
+	n := math.Round(jd - julianJ2000)
+	meanNoon := n - loc.Longitude/360
+	anomaly := math.Mod(357.5291+0.98560028*meanNoon, 360)
+	center := 1.9148*sin(anomaly) + 0.02*sin(2*anomaly) + 0.0003*sin(3*anomaly)
+	longitude := math.Mod(anomaly+center+180+102.9372, 360)
+	transit := julianJ2000 + meanNoon + 0.0053*sin(anomaly) - 0.0069*sin(2*longitude)
+
+	sinDecl := sin(longitude) * sin(earthTilt)
+	cosDecl := math.Cos(math.Asin(sinDecl))
+	cosHour := (sin(sunAltitude) - sin(loc.Latitude)*sinDecl) / (cos(loc.Latitude) * cosDecl)
+	if cosHour < -1 || cosHour > 1 {
+		return Sun{}, false
+	}
+	hourAngle := math.Acos(cosHour) * 180 / math.Pi
+
+	return Sun{
+		Rise: localHours(transit-hourAngle/360, noon),
+		Set:  localHours(transit+hourAngle/360, noon),
+	}, true
+}
+
+// localHours converts a Julian date to hours after midnight of the day of
+// noon, in its time zone.
+func localHours(jd float64, noon time.Time) float64 {
+	secs := (jd - julianUnixEpoch) * 86400
+	t := time.Unix(int64(secs), 0).In(noon.Location())
+	midnight := time.Date(noon.Year(), noon.Month(), noon.Day(), 0, 0, 0, 0, noon.Location())
+	return t.Sub(midnight).Hours()
+}
+
+func sin(deg float64) float64 { return math.Sin(deg * math.Pi / 180) }
+func cos(deg float64) float64 { return math.Cos(deg * math.Pi / 180) }
+
+// ---- Temperature Shift
+
+// Shift returns the temperature shift at t for the given sun times, from
+// -1 (coolest, shortly after sunrise) to 1 (warmest, from sunset to
+// midnight). It is 0 through the middle of the day and changes linearly
+// between those, so the palette drifts over hours rather than flipping.
+func Shift(t time.Time, sun Sun) float64 {
+	h := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+
+	// Control points (hour, shift), in order over the day.
+	points := [...][2]float64{
+		{0, 1},
+		{sun.Rise - 1, 0},
+		{sun.Rise + 1, -1},
+		{sun.Rise + 4, 0},
+		{sun.Set - 2, 0},
+		{sun.Set + 1, 1},
+		{24, 1},
+	}
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if h > b[0] {
+			continue
+		}
+		if b[0] <= a[0] {
+			return b[1]
+		}
+		k := (h - a[0]) / (b[0] - a[0])
+		return clamp(a[1] + (b[1]-a[1])*k)
+	}
+	return 1
+}
+
+func clamp(v float64) float64 {
+	return math.Min(1, math.Max(-1, v))
+}
@@ -0,0 +1,84 @@
+package daylight
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSunAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		day       time.Time
+		loc       Location
+		rise, set float64 // Hours after midnight, UTC
+	}{
+		// Reference times from the NOAA solar calculator.
+		{name: "Paris solstice", day: time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC), loc: Location{48.8566, 2.3522}, rise: 3 + 47.0/60, set: 19 + 58.0/60},
+		{name: "Paris winter", day: time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC), loc: Location{48.8566, 2.3522}, rise: 7 + 42.0/60, set: 15 + 56.0/60},
+		{name: "Sydney", day: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), loc: Location{-33.8688, 151.2093}, rise: 18 + 58.0/60 - 24, set: 9 + 9.0/60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sun, ok := SunAt(tt.day, tt.loc)
+			require.True(t, ok)
+			assert.InDelta(t, tt.rise, sun.Rise, 0.1)
+			assert.InDelta(t, tt.set, sun.Set, 0.1)
+		})
+	}
+}
+
+func TestSunAtTimeZone(t *testing.T) {
+	paris := time.FixedZone("CEST", 2*3600)
+	sun, ok := SunAt(time.Date(2024, 6, 21, 15, 0, 0, 0, paris), Location{48.8566, 2.3522})
+	require.True(t, ok)
+	assert.InDelta(t, 5+47.0/60, sun.Rise, 0.1)
+	assert.InDelta(t, 21+58.0/60, sun.Set, 0.1)
+}
+
+func TestSunAtPolar(t *testing.T) {
+	svalbard := Location{78.2232, 15.6267}
+	_, ok := SunAt(time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC), svalbard)
+	assert.False(t, ok, "midnight sun")
+	_, ok = SunAt(time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC), svalbard)
+	assert.False(t, ok, "polar night")
+}
+
+func TestShift(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2024, 3, 1, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		t    time.Time
+		want float64
+	}{
+		{at(0, 0), 1},
+		{at(6, 0), 0},
+		{at(7, 0), -0.5},
+		{at(8, 0), -1},
+		{at(11, 0), 0},
+		{at(14, 0), 0},
+		{at(17, 0), 0},
+		{at(18, 30), 0.5},
+		{at(20, 0), 1},
+		{at(23, 59), 1},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.want, Shift(tt.t, DefaultSun), 0.01, tt.t.Format("15:04"))
+	}
+}
+
+func TestShiftContinuous(t *testing.T) {
+	suns := []Sun{DefaultSun, {Rise: 3.8, Set: 22}, {Rise: 0.5, Set: 23.5}}
+	for _, sun := range suns {
+		prev := Shift(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), sun)
+		for m := 1; m < 24*60; m++ {
+			s := Shift(time.Date(2024, 3, 1, 0, m, 0, 0, time.UTC), sun)
+			assert.GreaterOrEqual(t, s, -1.0)
+			assert.LessOrEqual(t, s, 1.0)
+			assert.InDelta(t, prev, s, 0.05, "%v at minute %d", sun, m)
+			prev = s
+		}
+	}
+}
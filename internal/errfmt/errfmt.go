@@ -0,0 +1,139 @@
+// Package errfmt turns the errors of yule-log commands into what main
+// prints: the error, a hint on what to do about it, and an exit status
+// telling scripts which kind of problem it was.
+//
+// Modules report problems with sentinel or typed errors (lock.ErrNoPassword,
+// *tmuxcmd.VersionError, *config.FileError...) wrapped with context on the
+// way up; Classify looks through the wrapping. A new error worth a hint
+// adds a rule to the table below.
+package errfmt
+
+import (
+	"errors"
+	"strings"
+
+	"yule-log/internal/bundle"
+	"yule-log/internal/config"
+	"yule-log/internal/gitdata"
+	"yule-log/internal/keyring"
+	"yule-log/internal/lock"
+	"yule-log/internal/prompt"
+	"yule-log/internal/soak"
+	"yule-log/internal/themes"
+	"yule-log/internal/tmuxcmd"
+)
+
+// Exit statuses, by kind of error.
+const (
+	ExitError       = 1   // Anything else
+	ExitUsage       = 2   // Bad flag value or combination
+	ExitConfig      = 3   // Invalid configuration, theme or data file
+	ExitLock        = 4   // Lock or password problem
+	ExitTmux        = 5   // tmux missing, too old or not reachable
+	ExitGit         = 6   // git missing or not a repository
+	ExitInterrupted = 130 // Ctrl+C, as shells report SIGINT
+)
+
+// Kind names a class of errors, stable for scripts and JSON output.
+type Kind string
+
+const (
+	KindError       Kind = "error"
+	KindUsage       Kind = "usage"
+	KindConfig      Kind = "config"
+	KindLock        Kind = "lock"
+	KindTmux        Kind = "tmux"
+	KindGit         Kind = "git"
+	KindInterrupted Kind = "interrupted"
+)
+
+// codes maps kinds to exit statuses.
+var codes = map[Kind]int{
+	KindError:       ExitError,
+	KindUsage:       ExitUsage,
+	KindConfig:      ExitConfig,
+	KindLock:        ExitLock,
+	KindTmux:        ExitTmux,
+	KindGit:         ExitGit,
+	KindInterrupted: ExitInterrupted,
+}
+
+// Class is what Classify knows about an error.
+type Class struct {
+	Kind Kind
+	Code int    // Exit status
+	Hint string // What to do about it, "" when nothing specific
+}
+
+// rule matches errors of one kind, with an optional hint.
+type rule struct {
+	match func(error) bool
+	kind  Kind
+	hint  string
+}
+
+func is(target error) func(error) bool {
+	return func(err error) bool { return errors.Is(err, target) }
+}
+
+func as[T error]() func(error) bool {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// rules are tried in order; the first match wins.
+var rules = []rule{
+	{is(prompt.ErrInterrupted), KindInterrupted, ""},
+
+	{is(lock.ErrNoPassword), KindLock, "set one with: yule-log lock set-password"},
+	{is(lock.ErrDuressMatchesPassword), KindLock, "pick a duress password different from the lock password"},
+	{is(lock.ErrWeakPassword), KindLock, "choose a longer, less predictable password; [password] min_length, min_strength and require_keys set the policy"},
+	{is(lock.ErrPasswordExists), KindLock, "add --force to replace it"},
+	{is(lock.ErrLockHeld), KindLock, "unlock it from its lock screen, or lift it with: yule-log lock recover --force"},
+	{is(lock.ErrNotLocked), KindLock, ""},
+	{is(lock.ErrSystemAuthUnsupported), KindUsage, "use --auth file and set a password with: yule-log lock set-password"},
+	{is(lock.ErrKeychainPassword), KindLock, "a bundle can't carry a keychain item: leave out --password, or move the hash to the file with [password] storage = \"file\" and yule-log lock set-password --force"},
+	{is(keyring.ErrNotFound), KindLock, "set the password again with: yule-log lock set-password --force"},
+	{is(keyring.ErrUnsupported), KindLock, "install secret-tool (libsecret), or set [password] storage = \"file\""},
+	{is(lock.ErrUnsupportedVersion), KindConfig, "upgrade yule-log: a newer version wrote this file"},
+	{is(bundle.ErrUnsupportedVersion), KindConfig, "upgrade yule-log: a newer version wrote this bundle"},
+	{is(lock.ErrInvalidFormat), KindConfig, "set the password again with: yule-log lock set-password --force"},
+
+	{is(lock.ErrNoTmuxSocket), KindTmux, "run it inside tmux"},
+	{is(lock.ErrInvalidTmuxSocket), KindTmux, "run it inside tmux"},
+	{is(lock.ErrSocketNotFound), KindTmux, "is the tmux server still running?"},
+	{as[*tmuxcmd.VersionError](), KindTmux, "upgrade tmux"},
+	{is(tmuxcmd.ErrNotFound), KindTmux, "install tmux, or add it to PATH"},
+
+	{is(gitdata.ErrNotInstalled), KindGit, "install git, or add it to PATH"},
+	{is(gitdata.ErrNotRepository), KindGit, "run it inside a repository, or point --dir to one"},
+
+	{as[*config.FileError](), KindConfig, "fix the file, or move it aside to start from the defaults"},
+	{is(themes.ErrUnknown), KindUsage, ""},
+	{is(bundle.ErrExists), KindError, "add --force to replace existing files"},
+	{is(soak.ErrLeak), KindError, ""},
+}
+
+// Classify returns the kind, exit status and hint of err.
+func Classify(err error) Class {
+	for _, r := range rules {
+		if r.match(err) {
+			return Class{Kind: r.kind, Code: codes[r.kind], Hint: r.hint}
+		}
+	}
+	return Class{Kind: KindError, Code: ExitError}
+}
+
+// Format returns the message printed for err, on one or two lines, and
+// the exit status.
+func Format(err error) (string, int) {
+	class := Classify(err)
+	var b strings.Builder
+	b.WriteString("error: " + err.Error())
+	if class.Hint != "" {
+		b.WriteString("\nhint: " + class.Hint)
+	}
+	return b.String(), class.Code
+}
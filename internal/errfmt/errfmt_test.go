@@ -0,0 +1,64 @@
+package errfmt
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"yule-log/internal/config"
+	"yule-log/internal/gitdata"
+	"yule-log/internal/lock"
+	"yule-log/internal/prompt"
+	"yule-log/internal/tmuxcmd"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind Kind
+		code int
+	}{
+		{"other", errors.New("boom"), KindError, ExitError},
+		{"no password", fmt.Errorf("--lock: %w", lock.ErrNoPassword), KindLock, ExitLock},
+		{"lock held", fmt.Errorf("recover: %w", lock.ErrLockHeld), KindLock, ExitLock},
+		{"tmux version", &tmuxcmd.VersionError{Feature: "popups", Need: tmuxcmd.PopupVersion}, KindTmux, ExitTmux},
+		{"no tmux", tmuxcmd.ErrNotFound, KindTmux, ExitTmux},
+		{"not in tmux", fmt.Errorf("locking: %w", lock.ErrNoTmuxSocket), KindTmux, ExitTmux},
+		{"not a repository", fmt.Errorf("git log: %w", gitdata.ErrNotRepository), KindGit, ExitGit},
+		{"config file", fmt.Errorf("loading: %w", &config.FileError{Path: "config.toml", Err: errors.New("bad")}), KindConfig, ExitConfig},
+		{"newer file", fmt.Errorf("password file: %w", lock.ErrUnsupportedVersion), KindConfig, ExitConfig},
+		{"interrupted", prompt.ErrInterrupted, KindInterrupted, ExitInterrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class := Classify(tt.err)
+			assert.Equal(t, tt.kind, class.Kind)
+			assert.Equal(t, tt.code, class.Code)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	msg, code := Format(errors.New("boom"))
+	assert.Equal(t, "error: boom", msg)
+	assert.Equal(t, ExitError, code)
+
+	found, err := tmuxcmd.ParseVersion("tmux 3.0")
+	assert.NoError(t, err)
+	msg, code = Format(&tmuxcmd.VersionError{Feature: "popups", Need: tmuxcmd.PopupVersion, Found: found})
+	assert.Equal(t, "error: tmux ≥3.2 required for popups, found 3.0\nhint: upgrade tmux", msg)
+	assert.Equal(t, ExitTmux, code)
+
+	msg, _ = Format(fmt.Errorf("--lock: %w", lock.ErrNoPassword))
+	assert.Equal(t, "error: --lock: no password configured\nhint: set one with: yule-log lock set-password", msg)
+}
+
+func TestEveryKindHasACode(t *testing.T) {
+	for _, r := range rules {
+		_, ok := codes[r.kind]
+		assert.True(t, ok, r.kind)
+	}
+}
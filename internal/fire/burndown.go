@@ -0,0 +1,46 @@
+package fire
+
+import (
+	"math"
+	"time"
+)
+
+// ---- Burn Down
+// A yule log burns through the evening: it roars for a while after being
+// lit, then slowly settles into embers. BurnDown is that arc as a base
+// heat envelope over the run of the screensaver.
+
+// burnDownRoar is the fraction of the duration the fire roars at its peak
+// before burning down.
+const burnDownRoar = 0.1
+
+// BurnDown is the base heat envelope of a fire burning down from Peak to
+// Ember over Duration.
+type BurnDown struct {
+	Duration    time.Duration
+	Peak, Ember int
+}
+
+// NewBurnDown returns the envelope burning down over d from a roaring fire
+// half again as high as base heat, to ember heat.
+func NewBurnDown(d time.Duration, base, ember int) BurnDown {
+	return BurnDown{Duration: d, Peak: base + base/2, Ember: min(ember, base)}
+}
+
+// Heat returns the base heat after elapsed: Peak while roaring, then
+// easing down to Ember, where it stays once Duration is over.
+func (b BurnDown) Heat(elapsed time.Duration) int {
+	if b.Duration <= 0 {
+		return b.Peak
+	}
+	t := float64(elapsed) / float64(b.Duration)
+	switch {
+	case t <= burnDownRoar:
+		return b.Peak
+	case t >= 1:
+		return b.Ember
+	}
+	t = (t - burnDownRoar) / (1 - burnDownRoar)
+	ease := (1 + math.Cos(math.Pi*t)) / 2 // 1 down to 0, slow at both ends
+	return b.Ember + int(math.Round(float64(b.Peak-b.Ember)*ease))
+}
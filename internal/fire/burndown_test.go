@@ -0,0 +1,28 @@
+package fire
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBurnDown(t *testing.T) {
+	b := NewBurnDown(100*time.Minute, 60, 20)
+	assert.Equal(t, BurnDown{Duration: 100 * time.Minute, Peak: 90, Ember: 20}, b)
+
+	assert.Equal(t, 90, b.Heat(0))
+	assert.Equal(t, 90, b.Heat(10*time.Minute), "roars for a tenth of the duration")
+	assert.Equal(t, 55, b.Heat(55*time.Minute), "halfway down halfway through the burn")
+	assert.Equal(t, 20, b.Heat(100*time.Minute))
+	assert.Equal(t, 20, b.Heat(5*time.Hour), "embers once over")
+
+	prev := b.Heat(0)
+	for m := 1; m <= 100; m++ {
+		heat := b.Heat(time.Duration(m) * time.Minute)
+		assert.LessOrEqual(t, heat, prev, "never flares back up (minute %d)", m)
+		prev = heat
+	}
+
+	assert.Equal(t, 15, NewBurnDown(time.Hour, 15, 20).Ember, "embers never above the base heat")
+}
@@ -0,0 +1,179 @@
+package fire
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// ---- Random Events
+// Small random events keep long-running screensavers from looking like a
+// perfect loop: a log pops with a shower of sparks, a brief flare, or a
+// gust of wind bending the flames.
+
+// EventKind names a random event.
+type EventKind string
+
+const (
+	EventSparks EventKind = "sparks"
+	EventFlare  EventKind = "flare"
+	EventWind   EventKind = "wind"
+)
+
+// AllEvents lists every event kind, in the order used by ParseEventKinds.
+var AllEvents = []EventKind{EventSparks, EventFlare, EventWind}
+
+// ParseEventKinds parses a comma-separated list of event kinds.
+// "all" enables every event; "none" or "" disables them.
+func ParseEventKinds(s string) ([]EventKind, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	switch s {
+	case "", "none", "off":
+		return nil, nil
+	case "all":
+		return append([]EventKind(nil), AllEvents...), nil
+	}
+
+	var kinds []EventKind
+	for _, name := range strings.Split(s, ",") {
+		kind := EventKind(strings.TrimSpace(name))
+		known := false
+		for _, k := range AllEvents {
+			if k == kind {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown event %q (want sparks, flare, wind, all or none)", kind)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+// EventScheduler picks a random enabled event every MinInterval to
+// MaxInterval frames.
+type EventScheduler struct {
+	kinds       []EventKind
+	minInterval int
+	maxInterval int
+	rng         *rand.Rand
+	countdown   int
+}
+
+// NewEventScheduler creates a scheduler for the given kinds. Intervals are
+// in frames; maxInterval is raised to minInterval if lower.
+func NewEventScheduler(kinds []EventKind, minInterval, maxInterval int, rng *rand.Rand) *EventScheduler {
+	minInterval = max(minInterval, 1)
+	maxInterval = max(maxInterval, minInterval)
+	s := &EventScheduler{
+		kinds:       kinds,
+		minInterval: minInterval,
+		maxInterval: maxInterval,
+		rng:         rng,
+	}
+	s.rearm()
+	return s
+}
+
+func (s *EventScheduler) rearm() {
+	s.countdown = s.minInterval + s.rng.Intn(s.maxInterval-s.minInterval+1)
+}
+
+// Tick advances the scheduler by one frame and returns the event to start,
+// if any.
+func (s *EventScheduler) Tick() (EventKind, bool) {
+	if len(s.kinds) == 0 {
+		return "", false
+	}
+	s.countdown--
+	if s.countdown > 0 {
+		return "", false
+	}
+	s.rearm()
+	return s.kinds[s.rng.Intn(len(s.kinds))], true
+}
+
+// ---- Wind
+
+// Wind bends the flames sideways for a limited number of frames.
+type Wind struct {
+	Direction int // -1 left, +1 right, 0 calm
+	Frames    int // Frames remaining
+	Period    int // Shift the heat field every Period frames
+}
+
+// Gust starts a gust in a random direction.
+func (w *Wind) Gust(rng *rand.Rand, frames int) {
+	w.Direction = 1
+	if rng.Intn(2) == 0 {
+		w.Direction = -1
+	}
+	w.Frames = frames
+	w.Period = 2 + rng.Intn(3)
+}
+
+// Step advances the gust and returns the horizontal shift to apply to the
+// heat field this frame (-1, 0 or +1).
+func (w *Wind) Step(frame int) int {
+	if w.Frames <= 0 {
+		return 0
+	}
+	w.Frames--
+	if w.Period > 0 && frame%w.Period != 0 {
+		return 0
+	}
+	return w.Direction
+}
+
+// ---- Sparks
+
+// Spark is a single glowing particle.
+type Spark struct {
+	X, Y   float64
+	VX, VY float64
+	Life   int // Frames remaining
+}
+
+// Particles is a simple spark particle system. Y grows downwards.
+type Particles struct {
+	sparks []Spark
+}
+
+// Burst emits n sparks from (x, y) flying upwards.
+func (p *Particles) Burst(rng *rand.Rand, x, y float64, n int) {
+	for i := 0; i < n; i++ {
+		p.sparks = append(p.sparks, Spark{
+			X:    x,
+			Y:    y,
+			VX:   (rng.Float64() - 0.5) * 1.2,
+			VY:   -0.4 - rng.Float64()*0.8,
+			Life: 20 + rng.Intn(30),
+		})
+	}
+}
+
+// sparkGravity slows sparks down as they rise.
+const sparkGravity = 0.03
+
+// Step moves sparks and drops dead ones. wind pushes sparks sideways.
+func (p *Particles) Step(wind int) {
+	alive := p.sparks[:0]
+	for _, s := range p.sparks {
+		s.X += s.VX + float64(wind)*0.5
+		s.Y += s.VY
+		s.VY += sparkGravity
+		s.VX *= 0.97
+		s.Life--
+		if s.Life > 0 {
+			alive = append(alive, s)
+		}
+	}
+	p.sparks = alive
+}
+
+// Sparks returns the live sparks.
+func (p *Particles) Sparks() []Spark {
+	return p.sparks
+}
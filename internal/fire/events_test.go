@@ -0,0 +1,81 @@
+package fire
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEventKinds(t *testing.T) {
+	kinds, err := ParseEventKinds("all")
+	require.NoError(t, err)
+	assert.Equal(t, AllEvents, kinds)
+
+	kinds, err = ParseEventKinds("none")
+	require.NoError(t, err)
+	assert.Empty(t, kinds)
+
+	kinds, err = ParseEventKinds(" Sparks, wind ")
+	require.NoError(t, err)
+	assert.Equal(t, []EventKind{EventSparks, EventWind}, kinds)
+
+	_, err = ParseEventKinds("sparks,earthquake")
+	assert.Error(t, err)
+}
+
+func TestEventSchedulerInterval(t *testing.T) {
+	s := NewEventScheduler([]EventKind{EventFlare}, 10, 20, rand.New(rand.NewSource(1)))
+
+	last := 0
+	for frame := 1; frame <= 1000; frame++ {
+		kind, ok := s.Tick()
+		if !ok {
+			continue
+		}
+		assert.Equal(t, EventFlare, kind)
+		gap := frame - last
+		assert.GreaterOrEqual(t, gap, 10)
+		assert.LessOrEqual(t, gap, 20)
+		last = frame
+	}
+	assert.NotZero(t, last)
+}
+
+func TestEventSchedulerDisabled(t *testing.T) {
+	s := NewEventScheduler(nil, 1, 1, rand.New(rand.NewSource(1)))
+	for i := 0; i < 10; i++ {
+		_, ok := s.Tick()
+		assert.False(t, ok)
+	}
+}
+
+func TestParticles(t *testing.T) {
+	var p Particles
+	p.Burst(rand.New(rand.NewSource(1)), 10, 20, 5)
+	require.Len(t, p.Sparks(), 5)
+
+	p.Step(0)
+	for _, s := range p.Sparks() {
+		assert.Less(t, s.Y, 20.0, "sparks rise")
+	}
+
+	for i := 0; i < 100; i++ {
+		p.Step(1)
+	}
+	assert.Empty(t, p.Sparks(), "sparks burn out")
+}
+
+func TestWind(t *testing.T) {
+	var w Wind
+	assert.Equal(t, 0, w.Step(0))
+
+	w.Gust(rand.New(rand.NewSource(1)), 10)
+	shifted := 0
+	for frame := 0; frame < 20; frame++ {
+		shifted += w.Step(frame)
+	}
+	assert.NotZero(t, shifted)
+	assert.Equal(t, 0, w.Frames)
+}
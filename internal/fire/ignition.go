@@ -0,0 +1,173 @@
+package fire
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// ---- Pane Snapshots
+// A snapshot is the text of the pane underneath the screensaver, as printed
+// by `tmux capture-pane -p`. Transitions burn it away or reveal it.
+
+// Snapshot holds captured pane text, one rune slice per row.
+type Snapshot [][]rune
+
+// ParseSnapshot splits captured pane text into rows. Tabs are expanded and
+// any other control character becomes a space, so the snapshot can be
+// drawn cell by cell.
+func ParseSnapshot(text string) Snapshot {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.Split(text, "\n")
+	snap := make(Snapshot, len(lines))
+	for i, line := range lines {
+		var row []rune
+		for _, r := range line {
+			switch {
+			case r == '\t':
+				for n := 8 - len(row)%8; n > 0; n-- {
+					row = append(row, ' ')
+				}
+			case unicode.IsControl(r) || !unicode.IsPrint(r):
+				row = append(row, ' ')
+			default:
+				row = append(row, r)
+			}
+		}
+		snap[i] = row
+	}
+	return snap
+}
+
+// At returns the rune at (col, row), or a space outside the snapshot.
+func (s Snapshot) At(col, row int) rune {
+	if row < 0 || row >= len(s) || col < 0 || col >= len(s[row]) {
+		return ' '
+	}
+	return s[row][col]
+}
+
+// ---- Ignition
+// Ignition burns a snapshot away from the bottom up: each character turns
+// into a heat source for a few frames, then disappears, leaving the fire.
+
+// CellState is the state of a snapshot cell during a transition.
+type CellState int
+
+const (
+	CellIntact CellState = iota
+	CellBurning
+	CellGone
+)
+
+const (
+	// burnFrames is how long a character burns before it disappears.
+	burnFrames = 6
+	// igniteJitter spreads the ignition of a row over a few frames so the
+	// front looks ragged rather than a straight line.
+	igniteJitter = 8
+)
+
+// Ignition animates a snapshot catching fire.
+type Ignition struct {
+	snap   Snapshot
+	width  int
+	height int
+	frame  int
+	last   int
+	ignite []int // Frame at which each cell ignites, -1 for blanks
+}
+
+// NewIgnition prepares the ignition of snap over a width x height area.
+// The burn front takes about frames frames to climb from the bottom row to
+// the top one.
+func NewIgnition(snap Snapshot, width, height, frames int, rng *rand.Rand) *Ignition {
+	ig := &Ignition{
+		snap:   snap,
+		width:  width,
+		height: height,
+		ignite: make([]int, width*height),
+	}
+	frames = max(frames, 1)
+	for row := 0; row < height; row++ {
+		base := (height - 1 - row) * frames / max(height, 1)
+		for col := 0; col < width; col++ {
+			i := row*width + col
+			if unicode.IsSpace(snap.At(col, row)) {
+				ig.ignite[i] = -1
+				continue
+			}
+			ig.ignite[i] = base + rng.Intn(igniteJitter)
+			ig.last = max(ig.last, ig.ignite[i])
+		}
+	}
+	return ig
+}
+
+// Step advances the ignition by one frame.
+func (ig *Ignition) Step() {
+	ig.frame++
+}
+
+// Done reports whether every character has burnt away.
+func (ig *Ignition) Done() bool {
+	return ig.frame > ig.last+burnFrames
+}
+
+// Cell returns the snapshot rune at (col, row) and its current state.
+func (ig *Ignition) Cell(col, row int) (rune, CellState) {
+	if col < 0 || col >= ig.width || row < 0 || row >= ig.height {
+		return ' ', CellGone
+	}
+	at := ig.ignite[row*ig.width+col]
+	switch {
+	case at < 0:
+		return ' ', CellGone
+	case ig.frame < at:
+		return ig.snap.At(col, row), CellIntact
+	case ig.frame < at+burnFrames:
+		return ig.snap.At(col, row), CellBurning
+	default:
+		return ' ', CellGone
+	}
+}
+
+// ---- Reveal
+// Reveal is the reverse of Ignition: once unlocked, the flames die down and
+// the pane content shows through wherever the remaining heat is low enough.
+
+// Reveal shows a snapshot through dying flames.
+type Reveal struct {
+	snap    Snapshot
+	frame   int
+	frames  int
+	maxHeat int
+}
+
+// NewReveal prepares the reveal of snap over frames frames. The heat mask
+// threshold rises linearly from 0 to maxHeat, so every cell is visible by
+// the last frame even if the fire hasn't fully died.
+func NewReveal(snap Snapshot, frames, maxHeat int) *Reveal {
+	return &Reveal{snap: snap, frames: max(frames, 1), maxHeat: maxHeat}
+}
+
+// Step advances the reveal by one frame.
+func (r *Reveal) Step() {
+	r.frame++
+}
+
+// Done reports whether the whole snapshot is visible.
+func (r *Reveal) Done() bool {
+	return r.frame >= r.frames
+}
+
+// Cell returns the snapshot rune at (col, row) and whether it shows through
+// the given heat.
+func (r *Reveal) Cell(col, row, heat int) (rune, bool) {
+	threshold := r.frame * r.maxHeat / r.frames
+	return r.snap.At(col, row), heat <= threshold
+}
@@ -0,0 +1,84 @@
+package fire
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSnapshot(t *testing.T) {
+	snap := ParseSnapshot("$ ls\n\tmain.go\x07\n\n")
+
+	assert.Len(t, snap, 2)
+	assert.Equal(t, "$ ls", string(snap[0]))
+	assert.Equal(t, "        main.go ", string(snap[1]))
+	assert.Equal(t, 'l', snap.At(2, 0))
+	assert.Equal(t, ' ', snap.At(40, 0))
+	assert.Equal(t, ' ', snap.At(0, 5))
+
+	assert.Nil(t, ParseSnapshot("\n"))
+}
+
+func TestIgnitionBurnsBottomUp(t *testing.T) {
+	snap := ParseSnapshot("top\n\n\nbot")
+	ig := NewIgnition(snap, 3, 4, 40, rand.New(rand.NewSource(1)))
+
+	r, state := ig.Cell(0, 0)
+	assert.Equal(t, 't', r)
+	assert.Equal(t, CellIntact, state)
+
+	_, state = ig.Cell(0, 1)
+	assert.Equal(t, CellGone, state, "blank cells never burn")
+
+	// Run until the bottom row is burning: the top row must still be intact.
+	for {
+		if _, state := ig.Cell(0, 3); state != CellIntact {
+			break
+		}
+		ig.Step()
+	}
+	for col := 0; col < 3; col++ {
+		_, state := ig.Cell(col, 0)
+		assert.Equal(t, CellIntact, state)
+	}
+
+	frames := 0
+	for !ig.Done() {
+		ig.Step()
+		frames++
+		assert.Less(t, frames, 1000)
+	}
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 3; col++ {
+			_, state := ig.Cell(col, row)
+			assert.Equal(t, CellGone, state)
+		}
+	}
+}
+
+func TestRevealThreshold(t *testing.T) {
+	r := NewReveal(ParseSnapshot("ok"), 10, 100)
+
+	ch, visible := r.Cell(0, 0, 50)
+	assert.Equal(t, 'o', ch)
+	assert.False(t, visible)
+	_, visible = r.Cell(0, 0, 0)
+	assert.True(t, visible, "cold cells show immediately")
+
+	for i := 0; i < 5; i++ {
+		r.Step()
+	}
+	_, visible = r.Cell(1, 0, 50)
+	assert.True(t, visible)
+	_, visible = r.Cell(1, 0, 51)
+	assert.False(t, visible)
+	assert.False(t, r.Done())
+
+	for i := 0; i < 5; i++ {
+		r.Step()
+	}
+	assert.True(t, r.Done())
+	_, visible = r.Cell(1, 0, 100)
+	assert.True(t, visible)
+}
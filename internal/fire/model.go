@@ -0,0 +1,195 @@
+package fire
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// ---- Heat Propagation
+// The heat field is a grid of cells, hottest at the bottom row where heat
+// sources are fed. Each frame a propagation model carries the heat upward
+// and cools it down:
+//
+//   - average: every cell becomes the average of itself and the cells
+//     right, below and below-right (the original yule-log look)
+//   - doom: the DOOM PSX fire, every cell copies the one below it,
+//     shifted sideways at random and a little cooler
+//   - buoyant: heat rises from a blur of the three cells below, with
+//     random cooling, for softer, taller flames
+
+// ModelName names a propagation model.
+type ModelName string
+
+const (
+	ModelAverage ModelName = "average"
+	ModelDoom    ModelName = "doom"
+	ModelBuoyant ModelName = "buoyant"
+)
+
+// Models lists every propagation model name.
+var Models = []ModelName{ModelAverage, ModelDoom, ModelBuoyant}
+
+// Model carries heat upward one frame.
+type Model interface {
+	Step(sim *Simulation)
+}
+
+// NewModel returns the model called name; empty is average.
+func NewModel(name string) (Model, error) {
+	switch ModelName(strings.ToLower(strings.TrimSpace(name))) {
+	case "", ModelAverage:
+		return Average{}, nil
+	case ModelDoom:
+		return Doom{}, nil
+	case ModelBuoyant:
+		return Buoyant{}, nil
+	}
+	names := make([]string, len(Models))
+	for i, m := range Models {
+		names[i] = string(m)
+	}
+	return nil, fmt.Errorf("unknown fire model %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// Simulation is the heat field of the fire.
+type Simulation struct {
+	Width, Height int
+	// Heat holds Width*Height cells row by row from the top, followed by
+	// Width+1 cold cells so models can read past the bottom row.
+	Heat  []int
+	Model Model
+	Rand  *rand.Rand
+
+	// Heat kept by each row after a step, out of coolingScale; nil keeps
+	// it all. See SetFlameHeight.
+	cooling []int
+}
+
+// NewSimulation returns a cold heat field.
+func NewSimulation(width, height int, model Model, rng *rand.Rand) *Simulation {
+	if model == nil {
+		model = Average{}
+	}
+	return &Simulation{
+		Width:  width,
+		Height: height,
+		Heat:   make([]int, width*height+width+1),
+		Model:  model,
+		Rand:   rng,
+	}
+}
+
+// Step propagates the heat one frame with the model, then cools the rows
+// above the flame height.
+func (s *Simulation) Step() {
+	if s.Width <= 0 || s.Height <= 0 {
+		return
+	}
+	s.Model.Step(s)
+	for y, keep := range s.cooling {
+		if keep == coolingScale {
+			continue
+		}
+		row := s.Heat[y*s.Width : (y+1)*s.Width]
+		for x := range row {
+			row[x] = row[x] * keep / coolingScale
+		}
+	}
+}
+
+const (
+	coolingScale = 256
+	// coolingStart is the share of the flame height where cooling starts,
+	// ramping up to put out anything reaching the top of the flames.
+	coolingStart = 0.6
+)
+
+// SetFlameHeight limits how far up the screen flames reach, as a share of
+// the height: rows cool down more and more past coolingStart of it, and
+// keep nothing above it. 1 or more lifts the limit.
+func (s *Simulation) SetFlameHeight(ratio float64) {
+	s.cooling = nil
+	if ratio >= 1 || s.Height <= 0 {
+		return
+	}
+	s.cooling = make([]int, s.Height)
+	flames := ratio * float64(s.Height)
+	for y := range s.cooling {
+		up := float64(s.Height-1-y) / flames // 0 at the bottom row, 1 at the limit
+		keep := 1 - (up-coolingStart)/(1-coolingStart)
+		s.cooling[y] = int(max(0, min(1, keep)) * coolingScale)
+	}
+}
+
+// ParseFlameHeight parses a --flame-height value, a share of the screen
+// height such as "60%", within 1..100%.
+func ParseFlameHeight(s string) (float64, error) {
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || pct < 1 || pct > 100 {
+		return 0, fmt.Errorf("invalid flame height %q (want a percentage within 1%%..100%%, e.g. 60%%)", s)
+	}
+	return pct / 100, nil
+}
+
+// Average is the 4-cell average model.
+type Average struct{}
+
+func (Average) Step(sim *Simulation) {
+	h, w := sim.Heat, sim.Width
+	for i := 0; i < w*sim.Height; i++ {
+		h[i] = (h[i] + h[i+1] + h[i+w] + h[i+w+1]) / 4
+	}
+}
+
+// doomReach is how far flames climb, in heat lost per cell of screen
+// height: a source at the default heat power fades out around mid-screen.
+const doomReach = 300
+
+// Doom is the DOOM PSX fire model: each cell takes the heat of a cell
+// below it, picked one cell left or right at random, decayed by a random
+// amount.
+type Doom struct{}
+
+func (Doom) Step(sim *Simulation) {
+	h, w, height := sim.Heat, sim.Width, sim.Height
+	maxDecay := max(2, doomReach/height) // Same flame share on any screen
+	for y := 0; y < height-1; y++ {
+		for x := 0; x < w; x++ {
+			src := (y+1)*w + min(max(x+sim.Rand.Intn(3)-1, 0), w-1)
+			h[y*w+x] = max(h[src]-sim.Rand.Intn(maxDecay+1), 0)
+		}
+	}
+	// The heat sources smolder along the bottom row, a bed of embers like
+	// the always lit bottom row of the original.
+	bottom := h[(height-1)*w : height*w]
+	prev := bottom[0]
+	for x := range bottom {
+		cur := bottom[x]
+		bottom[x] = max((prev+2*cur+bottom[min(x+1, w-1)])/4-1, 0)
+		prev = cur
+	}
+}
+
+// Buoyant is the blur and buoyancy model: each cell mixes itself with the
+// three cells below, the one right below weighing most, and cools by one
+// now and then.
+type Buoyant struct{}
+
+func (Buoyant) Step(sim *Simulation) {
+	h, w, height := sim.Heat, sim.Width, sim.Height
+	for y := 0; y < height; y++ {
+		for x := 0; x < w; x++ {
+			below := (y + 1) * w // The cold padding under the bottom row
+			left, right := max(x-1, 0), min(x+1, w-1)
+			i := y*w + x
+			sum := h[i] + h[below+left] + 2*h[below+x] + h[below+right]
+			v := sum / 5
+			if sim.Rand.Intn(2) == 0 {
+				v--
+			}
+			h[i] = max(v, 0)
+		}
+	}
+}
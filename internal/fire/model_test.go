@@ -0,0 +1,109 @@
+package fire
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewModel(t *testing.T) {
+	for name, want := range map[string]Model{"": Average{}, "average": Average{}, "DOOM": Doom{}, "buoyant": Buoyant{}} {
+		m, err := NewModel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, m, name)
+	}
+	_, err := NewModel("lava")
+	assert.ErrorContains(t, err, "average, doom, buoyant")
+}
+
+func TestAverageStep(t *testing.T) {
+	sim := NewSimulation(3, 2, nil, nil)
+	copy(sim.Heat, []int{
+		0, 0, 0,
+		8, 4, 0,
+	})
+	sim.Step()
+	assert.Equal(t, []int{
+		3, 1, 2, // (0+0+8+4)/4, (0+0+4+0)/4, right wraps to the next row
+		3, 1, 0, // The padding below is cold
+	}, sim.Heat[:6])
+}
+
+func TestModelsCarryHeatUp(t *testing.T) {
+	const width, height, power = 30, 12, 75
+	for _, name := range Models {
+		m, err := NewModel(string(name))
+		require.NoError(t, err)
+		sim := NewSimulation(width, height, m, rand.New(rand.NewSource(1)))
+
+		// A cold field stays cold
+		sim.Step()
+		assert.Equal(t, make([]int, len(sim.Heat)), sim.Heat, name)
+
+		rng := rand.New(rand.NewSource(2))
+		for frame := 0; frame < 50; frame++ {
+			for i := 0; i < width/6; i++ {
+				sim.Heat[(height-1)*width+rng.Intn(width)] = power
+			}
+			sim.Step()
+			for i, v := range sim.Heat {
+				require.True(t, v >= 0 && v <= power, "%s: cell %d is %d", name, i, v)
+			}
+		}
+
+		above := 0
+		for _, v := range sim.Heat[(height-3)*width : (height-2)*width] {
+			above += v
+		}
+		assert.Positive(t, above, "%s: no heat above the sources", name)
+	}
+}
+
+func TestSimulationEmpty(t *testing.T) {
+	for _, name := range Models {
+		m, _ := NewModel(string(name))
+		NewSimulation(0, 0, m, rand.New(rand.NewSource(1))).Step()
+		NewSimulation(1, 1, m, rand.New(rand.NewSource(1))).Step()
+	}
+}
+
+func TestParseFlameHeight(t *testing.T) {
+	for s, want := range map[string]float64{"60%": 0.6, "100%": 1, " 25 ": 0.25, "12.5%": 0.125} {
+		got, err := ParseFlameHeight(s)
+		require.NoError(t, err, s)
+		assert.InDelta(t, want, got, 1e-9, s)
+	}
+	for _, s := range []string{"", "0%", "150%", "tall", "-5%"} {
+		_, err := ParseFlameHeight(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestFlameHeight(t *testing.T) {
+	const width, height = 20, 40
+	burn := func(ratio float64) (top int) {
+		m, _ := NewModel(string(ModelBuoyant))
+		sim := NewSimulation(width, height, m, rand.New(rand.NewSource(1)))
+		sim.SetFlameHeight(ratio)
+		top = height
+		for frame := 0; frame < 300; frame++ {
+			for x := 0; x < width; x++ {
+				sim.Heat[(height-1)*width+x] = 200 // Roaring fire reaching the top
+			}
+			sim.Step()
+			for i, v := range sim.Heat[:width*height] {
+				if v > 0 {
+					top = min(top, i/width)
+				}
+			}
+		}
+		return top
+	}
+
+	assert.Equal(t, 0, burn(1), "no limit")
+	top := burn(0.5)
+	assert.GreaterOrEqual(t, top, height/2, "flames stay in the bottom half")
+	assert.Less(t, top, height-height/4, "but still climb")
+}
@@ -0,0 +1,155 @@
+package fire
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ---- Heat Paths
+// A heat path replaces the random heat sources along the bottom row with
+// lines drawn by the user, along the bottom row and up the sides. Points
+// are kept in percent of the screen size so a path fits any terminal.
+
+// Point is a position in percent of the screen size, from 0,0 top-left to
+// 100,100 bottom-right.
+type Point struct {
+	X, Y float64
+}
+
+// Stroke is a line through its points.
+type Stroke []Point
+
+// Path is the set of strokes heat sources are fed along.
+type Path []Stroke
+
+// PointAt returns the point of the cell at x, y on a screen of the size.
+func PointAt(x, y, width, height int) Point {
+	return Point{X: percent(x, width), Y: percent(y, height)}
+}
+
+// percent returns the position of cell i of n in percent, rounded to a
+// tenth.
+func percent(i, n int) float64 {
+	if n <= 1 {
+		return 0
+	}
+	return math.Round(float64(i)*1000/float64(n-1)) / 10
+}
+
+// cell returns the cell at p on a screen of the size.
+func (p Point) cell(width, height int) (x, y int) {
+	at := func(v float64, n int) int {
+		return min(max(int(math.Round(v*float64(n-1)/100)), 0), n-1)
+	}
+	return at(p.X, width), at(p.Y, height)
+}
+
+// Cells returns the heat field index of every cell along the path on a
+// screen of the size, each once, in drawing order. Consecutive points of
+// a stroke are joined by a straight line.
+func (p Path) Cells(width, height int) []int {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	var cells []int
+	seen := make(map[int]bool)
+	add := func(x, y int) {
+		if i := y*width + x; !seen[i] {
+			seen[i] = true
+			cells = append(cells, i)
+		}
+	}
+	for _, stroke := range p {
+		for i, pt := range stroke {
+			x1, y1 := pt.cell(width, height)
+			if i == 0 {
+				add(x1, y1)
+				continue
+			}
+			x0, y0 := stroke[i-1].cell(width, height)
+			line(x0, y0, x1, y1, add)
+		}
+	}
+	return cells
+}
+
+// line calls plot for every cell from x0, y0 to x1, y1 (Bresenham).
+func line(x0, y0, x1, y1 int, plot func(x, y int)) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	err := dx + dy
+	for {
+		plot(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * err; e2 >= dy {
+			err += dy
+			x0 += sx
+		} else {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	return max(v, -v)
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+// SnapToEdge returns the cell closest to x, y on the bottom row or on the
+// left or right column, where heat paths are drawn.
+func SnapToEdge(x, y, width, height int) (int, int) {
+	x = min(max(x, 0), width-1)
+	y = min(max(y, 0), height-1)
+	left, right, bottom := x, width-1-x, height-1-y
+	switch {
+	case bottom <= left && bottom <= right:
+		return x, height - 1
+	case left <= right:
+		return 0, y
+	default:
+		return width - 1, y
+	}
+}
+
+// String formats the stroke as space separated x,y points, the format of
+// ParseStroke.
+func (s Stroke) String() string {
+	points := make([]string, len(s))
+	for i, p := range s {
+		points[i] = strconv.FormatFloat(p.X, 'f', -1, 64) + "," + strconv.FormatFloat(p.Y, 'f', -1, 64)
+	}
+	return strings.Join(points, " ")
+}
+
+// ParseStroke parses space separated x,y points in percent, such as
+// "0,100 50,100".
+func ParseStroke(s string) (Stroke, error) {
+	var stroke Stroke
+	for _, field := range strings.Fields(s) {
+		xs, ys, ok := strings.Cut(field, ",")
+		x, errX := strconv.ParseFloat(xs, 64)
+		y, errY := strconv.ParseFloat(ys, 64)
+		if !ok || errX != nil || errY != nil || x < 0 || x > 100 || y < 0 || y > 100 {
+			return nil, fmt.Errorf("invalid heat path point %q (want x,y in percent, e.g. 50,100)", field)
+		}
+		stroke = append(stroke, Point{X: x, Y: y})
+	}
+	if len(stroke) == 0 {
+		return nil, fmt.Errorf("empty heat path stroke")
+	}
+	return stroke, nil
+}
@@ -0,0 +1,59 @@
+package fire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathCells(t *testing.T) {
+	const width, height = 5, 4
+	p := Path{
+		{{X: 0, Y: 100}, {X: 100, Y: 100}},  // The bottom row
+		{{X: 100, Y: 100}, {X: 100, Y: 50}}, // Up the right side to row 2, overlapping
+		{{X: 0, Y: 0}},
+	}
+	assert.Equal(t, []int{15, 16, 17, 18, 19, 14, 0}, p.Cells(width, height))
+
+	// Points scale with the screen
+	assert.Equal(t, []int{1, 3}, Path{{{X: 50, Y: 0}}, {{X: 0, Y: 100}}}.Cells(3, 2))
+	assert.Empty(t, p.Cells(0, 0))
+	assert.Empty(t, Path(nil).Cells(width, height))
+}
+
+func TestPointAt(t *testing.T) {
+	assert.Equal(t, Point{X: 0, Y: 100}, PointAt(0, 23, 80, 24))
+	assert.Equal(t, Point{X: 50, Y: 100}, PointAt(2, 3, 5, 4))
+	assert.Equal(t, Point{X: 33.3, Y: 0}, PointAt(1, 0, 4, 1))
+
+	// A point maps back to its cell
+	x, y := PointAt(57, 11, 80, 24).cell(80, 24)
+	assert.Equal(t, [2]int{57, 11}, [2]int{x, y})
+}
+
+func TestSnapToEdge(t *testing.T) {
+	const width, height = 20, 10
+	for _, tt := range []struct{ x, y, wantX, wantY int }{
+		{5, 9, 5, 9},   // On the bottom row
+		{5, 7, 5, 9},   // Closer to the bottom
+		{1, 3, 0, 3},   // Closer to the left
+		{18, 2, 19, 2}, // Closer to the right
+		{-4, 30, 0, 9}, // Off screen
+	} {
+		x, y := SnapToEdge(tt.x, tt.y, width, height)
+		assert.Equal(t, [2]int{tt.wantX, tt.wantY}, [2]int{x, y}, "%d,%d", tt.x, tt.y)
+	}
+}
+
+func TestParseStroke(t *testing.T) {
+	s, err := ParseStroke(" 0,100  12.5,100 ")
+	require.NoError(t, err)
+	assert.Equal(t, Stroke{{X: 0, Y: 100}, {X: 12.5, Y: 100}}, s)
+	assert.Equal(t, "0,100 12.5,100", s.String())
+
+	for _, bad := range []string{"", "0", "0,101", "x,1", "-1,50"} {
+		_, err := ParseStroke(bad)
+		assert.Error(t, err, bad)
+	}
+}
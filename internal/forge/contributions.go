@@ -0,0 +1,62 @@
+package forge
+
+import (
+	"time"
+)
+
+// ---- Contributions
+// The contribution calendar of the user, the graph of their profile page:
+// one intensity level per day, from no contributions to the busiest days.
+
+// MaxLevel is the level of the busiest days.
+const MaxLevel = 4
+
+// Day is one day of the contribution calendar.
+type Day struct {
+	Date  time.Time `json:"date"`
+	Count int       `json:"count"`
+	Level int       `json:"level"` // 0 (none) to MaxLevel
+}
+
+// Weeks returns the mean level of every week of days, from 0 (quiet) to 1
+// (every day at MaxLevel), oldest first. Weeks start on Sunday, like the
+// calendar; days must be sorted.
+func Weeks(days []Day) []float64 {
+	var weeks []float64
+	sum, n := 0, 0
+	for i, d := range days {
+		if i > 0 && d.Date.Weekday() == time.Sunday && n > 0 {
+			weeks = append(weeks, float64(sum)/float64(n*MaxLevel))
+			sum, n = 0, 0
+		}
+		sum += min(max(d.Level, 0), MaxLevel)
+		n++
+	}
+	if n > 0 {
+		weeks = append(weeks, float64(sum)/float64(n*MaxLevel))
+	}
+	return weeks
+}
+
+// ContributionsCached is the last contribution calendar of a user.
+type ContributionsCached struct {
+	Fetched time.Time `json:"fetched"`
+	Days    []Day     `json:"days"`
+}
+
+// ContributionsKey identifies the calendar of user on a forge in the
+// cache file.
+func ContributionsKey(backend Backend, url, user string) string {
+	return CacheKey(backend, url) + " " + user
+}
+
+// LoadContributions returns the cached calendar identified by key.
+func LoadContributions(path, key string) (ContributionsCached, bool) {
+	return loadEntry[ContributionsCached](path, key)
+}
+
+// SaveContributions stores the calendar identified by key, keeping the
+// others of the cache file.
+func SaveContributions(path, key string, cached ContributionsCached) error {
+	return saveEntry(path, key, cached)
+}
@@ -0,0 +1,140 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"yule-log/internal/fsutil"
+)
+
+// ---- Forge Backends
+// A forge is the code hosting service of the user: the screensaver asks it
+// for the pull requests and issues waiting on them, and keeps the answer in
+// a cache file so that it shows up right away and survives rate limits.
+
+// Backend names a forge implementation.
+type Backend string
+
+const (
+	BackendGitHub Backend = "github"
+)
+
+// Kind tells pull requests from issues.
+type Kind string
+
+const (
+	KindPullRequest Kind = "pr"
+	KindIssue       Kind = "issue"
+)
+
+// Item is an open pull request or issue.
+type Item struct {
+	Kind    Kind      `json:"kind"`
+	Repo    string    `json:"repo"` // owner/name
+	Number  int       `json:"number"`
+	Title   string    `json:"title"`
+	URL     string    `json:"url"`
+	Created time.Time `json:"created"`
+}
+
+// Forge is a code hosting service.
+type Forge interface {
+	// Inbox returns the open pull requests awaiting the user's review and
+	// the open issues assigned to them, newest first.
+	Inbox(ctx context.Context) ([]Item, error)
+	// Contributions returns the contribution calendar of user (empty for
+	// the token owner) over the last year, oldest day first.
+	Contributions(ctx context.Context, user string) ([]Day, error)
+}
+
+// Options configures a forge.
+type Options struct {
+	URL    string // API base URL, empty for the public service
+	Token  string
+	Client *http.Client // nil for a client with a timeout
+}
+
+// clientTimeout bounds API requests made with the default client.
+const clientTimeout = 10 * time.Second
+
+// New returns the forge of the given backend.
+func New(backend Backend, opts Options) (Forge, error) {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: clientTimeout}
+	}
+	switch backend {
+	case "", BackendGitHub:
+		return newGitHub(opts), nil
+	default:
+		return nil, fmt.Errorf("unknown forge backend %q (want github)", backend)
+	}
+}
+
+// Token returns the API token of the backend from the environment.
+func Token(backend Backend) string {
+	switch backend {
+	case "", BackendGitHub:
+		return githubToken()
+	default:
+		return ""
+	}
+}
+
+// ---- Cache
+
+// Cached is the last answer of a forge.
+type Cached struct {
+	Fetched time.Time `json:"fetched"`
+	Items   []Item    `json:"items"`
+}
+
+// CacheKey identifies a forge in the cache file.
+func CacheKey(backend Backend, url string) string {
+	if backend == "" {
+		backend = BackendGitHub
+	}
+	return string(backend) + " " + url
+}
+
+// LoadCache returns the cached answer of the forge identified by key. A
+// missing or corrupt cache file holds nothing.
+func LoadCache(path, key string) (Cached, bool) {
+	return loadEntry[Cached](path, key)
+}
+
+// SaveCache stores the answer of the forge identified by key, keeping the
+// other forges of the cache file.
+func SaveCache(path, key string, cached Cached) error {
+	return saveEntry(path, key, cached)
+}
+
+// loadEntry reads one entry of a JSON cache file keyed by forge.
+func loadEntry[T any](path, key string) (T, bool) {
+	var zero T
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return zero, false
+	}
+	var cache map[string]T
+	if json.Unmarshal(data, &cache) != nil {
+		return zero, false
+	}
+	entry, ok := cache[key]
+	return entry, ok
+}
+
+// saveEntry replaces one entry of a JSON cache file, keeping the others.
+func saveEntry[T any](path, key string, entry T) error {
+	return fsutil.Update(path, 0600, func(data []byte) ([]byte, error) {
+		var cache map[string]T
+		if json.Unmarshal(data, &cache) != nil || cache == nil {
+			cache = map[string]T{}
+		}
+		cache[key] = entry
+		return json.Marshal(cache)
+	})
+}
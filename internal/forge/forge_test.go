@@ -0,0 +1,181 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubInbox(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search/issues", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		q := r.URL.Query().Get("q")
+		switch {
+		case strings.Contains(q, "review-requested:@me"):
+			w.Write([]byte(`{"items": [{"number": 12, "title": "Fix resize crash",
+				"html_url": "https://github.com/gfanton/yule/pull/12",
+				"repository_url": "https://api.github.com/repos/gfanton/yule",
+				"created_at": "2024-03-01T10:00:00Z"}]}`))
+		case strings.Contains(q, "assignee:@me"):
+			w.Write([]byte(`{"items": [{"number": 7, "title": "Snow melts too fast",
+				"html_url": "https://github.com/gfanton/yule/issues/7",
+				"repository_url": "https://api.github.com/repos/gfanton/yule",
+				"created_at": "2024-03-05T10:00:00Z"}]}`))
+		default:
+			t.Errorf("unexpected query %q", q)
+		}
+	}))
+	defer srv.Close()
+
+	f, err := New(BackendGitHub, Options{URL: srv.URL + "/", Token: "secret"})
+	require.NoError(t, err)
+	items, err := f.Inbox(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, Item{
+		Kind:    KindIssue,
+		Repo:    "gfanton/yule",
+		Number:  7,
+		Title:   "Snow melts too fast",
+		URL:     "https://github.com/gfanton/yule/issues/7",
+		Created: time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC),
+	}, items[0], "newest first")
+	assert.Equal(t, KindPullRequest, items[1].Kind)
+	assert.Equal(t, 12, items[1].Number)
+}
+
+func TestGitHubErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	f, err := New(BackendGitHub, Options{URL: srv.URL, Token: "secret"})
+	require.NoError(t, err)
+	_, err = f.Inbox(context.Background())
+	assert.ErrorContains(t, err, "403")
+
+	f, err = New(BackendGitHub, Options{URL: srv.URL})
+	require.NoError(t, err)
+	_, err = f.Inbox(context.Background())
+	assert.ErrorContains(t, err, "no token")
+
+	_, err = New("sourceforge", Options{})
+	assert.Error(t, err)
+}
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "forge.json")
+	key := CacheKey("", "")
+	assert.Equal(t, CacheKey(BackendGitHub, ""), key)
+
+	_, ok := LoadCache(path, key)
+	assert.False(t, ok)
+
+	fetched := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	cached := Cached{Fetched: fetched, Items: []Item{{Kind: KindIssue, Repo: "a/b", Number: 1, Title: "t", Created: fetched}}}
+	require.NoError(t, SaveCache(path, key, cached))
+	require.NoError(t, SaveCache(path, CacheKey(BackendGitHub, "https://ghe.example.com"), Cached{}))
+
+	got, ok := LoadCache(path, key)
+	require.True(t, ok)
+	assert.Equal(t, cached, got)
+}
+
+func TestGitHubContributions(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch body.Variables["login"] {
+		case "gfanton":
+			assert.Contains(t, body.Query, "user(login: $login)")
+			w.Write([]byte(`{"data": {"user": {"contributionsCollection": {"contributionCalendar": {"weeks": [
+				{"contributionDays": [
+					{"date": "2024-03-02", "contributionCount": 0, "contributionLevel": "NONE"}]},
+				{"contributionDays": [
+					{"date": "2024-03-03", "contributionCount": 12, "contributionLevel": "FOURTH_QUARTILE"},
+					{"date": "2024-03-04", "contributionCount": 3, "contributionLevel": "SECOND_QUARTILE"}]}
+			]}}}}}`))
+		case "":
+			assert.Contains(t, body.Query, "viewer")
+			w.Write([]byte(`{"data": {"viewer": {"contributionsCollection": {"contributionCalendar": {"weeks": []}}}}}`))
+		default:
+			w.Write([]byte(`{"data": {"user": null}, "errors": [{"message": "Could not resolve to a User"}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	f, err := New(BackendGitHub, Options{URL: srv.URL, Token: "secret"})
+	require.NoError(t, err)
+	days, err := f.Contributions(context.Background(), "gfanton")
+	require.NoError(t, err)
+	assert.Equal(t, []Day{
+		{Date: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Count: 0, Level: 0},
+		{Date: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC), Count: 12, Level: 4},
+		{Date: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Count: 3, Level: 2},
+	}, days)
+
+	days, err = f.Contributions(context.Background(), "")
+	require.NoError(t, err)
+	assert.Empty(t, days)
+
+	_, err = f.Contributions(context.Background(), "nobody")
+	assert.ErrorContains(t, err, "Could not resolve")
+	assert.Equal(t, "/graphql", paths[0])
+
+	// GitHub Enterprise serves GraphQL next to its REST API
+	f, err = New(BackendGitHub, Options{URL: srv.URL + "/api/v3", Token: "secret"})
+	require.NoError(t, err)
+	_, _ = f.Contributions(context.Background(), "gfanton")
+	assert.Equal(t, "/api/graphql", paths[len(paths)-1])
+
+	f, err = New(BackendGitHub, Options{URL: srv.URL})
+	require.NoError(t, err)
+	_, err = f.Contributions(context.Background(), "gfanton")
+	assert.ErrorContains(t, err, "no token")
+}
+
+func TestWeeks(t *testing.T) {
+	day := func(d, level int) Day {
+		return Day{Date: time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC), Level: level}
+	}
+	// March 3rd 2024 is a Sunday
+	days := []Day{day(1, 4), day(2, 2), day(3, 4), day(4, 4), day(5, 0), day(6, 0), day(7, 4), day(8, 4), day(9, 4), day(10, 9)}
+	assert.Equal(t, []float64{0.75, 20.0 / 28, 1}, Weeks(days))
+	assert.Empty(t, Weeks(nil))
+}
+
+func TestContributionsCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contribs.json")
+	key := ContributionsKey("", "", "gfanton")
+	_, ok := LoadContributions(path, key)
+	assert.False(t, ok)
+
+	cached := ContributionsCached{
+		Fetched: time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC),
+		Days:    []Day{{Date: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Count: 3, Level: 2}},
+	}
+	require.NoError(t, SaveContributions(path, key, cached))
+	require.NoError(t, SaveContributions(path, ContributionsKey("", "", ""), ContributionsCached{}))
+
+	got, ok := LoadContributions(path, key)
+	require.True(t, ok)
+	assert.Equal(t, cached, got)
+}
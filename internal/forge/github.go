@@ -0,0 +1,257 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---- GitHub
+// Pull requests and issues come from the search API, which covers every
+// repository the token can see in one request each.
+
+const (
+	githubAPI = "https://api.github.com"
+
+	// githubPerPage is the number of results asked per search.
+	githubPerPage = 20
+)
+
+var githubQueries = []struct {
+	kind  Kind
+	query string
+}{
+	{KindPullRequest, "is:open is:pr review-requested:@me archived:false"},
+	{KindIssue, "is:open is:issue assignee:@me archived:false"},
+}
+
+type github struct {
+	opts Options
+}
+
+func newGitHub(opts Options) *github {
+	if opts.URL == "" {
+		opts.URL = githubAPI
+	}
+	opts.URL = strings.TrimSuffix(opts.URL, "/")
+	return &github{opts: opts}
+}
+
+// githubToken returns $GITHUB_TOKEN, $GH_TOKEN or the token of the gh
+// command line tool.
+func githubToken() string {
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(env); token != "" {
+			return token
+		}
+	}
+	out, err := exec.Command("gh", "auth", "token").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// githubSearch is the part of a search API response used here.
+type githubSearch struct {
+	Items []struct {
+		Number        int       `json:"number"`
+		Title         string    `json:"title"`
+		HTMLURL       string    `json:"html_url"`
+		RepositoryURL string    `json:"repository_url"`
+		CreatedAt     time.Time `json:"created_at"`
+	} `json:"items"`
+}
+
+func (g *github) Inbox(ctx context.Context) ([]Item, error) {
+	if g.opts.Token == "" {
+		return nil, fmt.Errorf("github: no token (set GITHUB_TOKEN or log in with gh)")
+	}
+	var items []Item
+	for _, q := range githubQueries {
+		found, err := g.search(ctx, q.kind, q.query)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, found...)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Created.After(items[j].Created) })
+	return items, nil
+}
+
+func (g *github) search(ctx context.Context, kind Kind, query string) ([]Item, error) {
+	params := url.Values{
+		"q":        {query},
+		"sort":     {"created"},
+		"order":    {"desc"},
+		"per_page": {fmt.Sprint(githubPerPage)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.opts.URL+"/search/issues?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("github: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.opts.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := g.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github: searching %ss: %s", kind, resp.Status)
+	}
+
+	var result githubSearch
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("github: decoding %s search: %w", kind, err)
+	}
+	items := make([]Item, 0, len(result.Items))
+	for _, it := range result.Items {
+		items = append(items, Item{
+			Kind:    kind,
+			Repo:    repoName(it.RepositoryURL),
+			Number:  it.Number,
+			Title:   it.Title,
+			URL:     it.HTMLURL,
+			Created: it.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// repoName returns "owner/name" from an API repository URL ending in
+// /repos/owner/name.
+func repoName(apiURL string) string {
+	if _, name, ok := strings.Cut(apiURL, "/repos/"); ok {
+		return name
+	}
+	return apiURL
+}
+
+// githubContributionsQuery asks for the contribution calendar of a user,
+// or of the token owner (the viewer) when no login is given.
+const githubContributionsQuery = `query($login: String!) {
+  user(login: $login) { ...calendar }
+}
+fragment calendar on User {
+  contributionsCollection { contributionCalendar { weeks { contributionDays {
+    date contributionCount contributionLevel
+  } } } }
+}`
+
+const githubViewerContributionsQuery = `query {
+  viewer { ...calendar }
+}
+fragment calendar on User {
+  contributionsCollection { contributionCalendar { weeks { contributionDays {
+    date contributionCount contributionLevel
+  } } } }
+}`
+
+// githubLevels maps the GraphQL ContributionLevel enum to levels.
+var githubLevels = map[string]int{
+	"NONE":            0,
+	"FIRST_QUARTILE":  1,
+	"SECOND_QUARTILE": 2,
+	"THIRD_QUARTILE":  3,
+	"FOURTH_QUARTILE": 4,
+}
+
+// githubCalendarUser is the part of a user in the GraphQL response used
+// here.
+type githubCalendarUser struct {
+	ContributionsCollection struct {
+		ContributionCalendar struct {
+			Weeks []struct {
+				ContributionDays []struct {
+					Date              string `json:"date"`
+					ContributionCount int    `json:"contributionCount"`
+					ContributionLevel string `json:"contributionLevel"`
+				} `json:"contributionDays"`
+			} `json:"weeks"`
+		} `json:"contributionCalendar"`
+	} `json:"contributionsCollection"`
+}
+
+func (g *github) Contributions(ctx context.Context, user string) ([]Day, error) {
+	if g.opts.Token == "" {
+		return nil, fmt.Errorf("github: no token (set GITHUB_TOKEN or log in with gh)")
+	}
+	body := map[string]any{"query": githubViewerContributionsQuery}
+	if user != "" {
+		body = map[string]any{"query": githubContributionsQuery, "variables": map[string]string{"login": user}}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("github: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.graphQLURL(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("github: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.opts.Token)
+
+	resp, err := g.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github: fetching contributions: %s", resp.Status)
+	}
+
+	var result struct {
+		Data struct {
+			User   *githubCalendarUser `json:"user"`
+			Viewer *githubCalendarUser `json:"viewer"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("github: decoding contributions: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("github: fetching contributions: %s", result.Errors[0].Message)
+	}
+	u := result.Data.User
+	if user == "" {
+		u = result.Data.Viewer
+	}
+	if u == nil {
+		return nil, fmt.Errorf("github: fetching contributions: no such user %q", user)
+	}
+
+	var days []Day
+	for _, week := range u.ContributionsCollection.ContributionCalendar.Weeks {
+		for _, d := range week.ContributionDays {
+			date, err := time.Parse("2006-01-02", d.Date)
+			if err != nil {
+				continue
+			}
+			days = append(days, Day{Date: date, Count: d.ContributionCount, Level: githubLevels[d.ContributionLevel]})
+		}
+	}
+	return days, nil
+}
+
+// graphQLURL returns the GraphQL endpoint: api.github.com/graphql, or
+// <host>/api/graphql for GitHub Enterprise, whose REST API is /api/v3.
+func (g *github) graphQLURL() string {
+	if base, ok := strings.CutSuffix(g.opts.URL, "/api/v3"); ok {
+		return base + "/api/graphql"
+	}
+	return g.opts.URL + "/graphql"
+}
@@ -0,0 +1,141 @@
+// Package fsutil writes state files safely when several yule-log processes
+// share them: the idle watcher daemon, lock popups and one-shot commands.
+//
+// Writes go to a temporary file in the same directory which is synced and
+// renamed over the target, so readers see the old content or the new one,
+// never a torn file, even if the writer dies halfway. Read-modify-write
+// cycles additionally hold an flock on a "<file>.lock" sibling so two
+// writers can't lose each other's updates.
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// beforeRename is called between writing the temporary file and renaming it
+// into place. Tests use it to inject crashes.
+var beforeRename = func(tmp string) error { return nil }
+
+// WriteFile atomically replaces path with data.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	done := false
+	defer func() {
+		if !done {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("syncing %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := beforeRename(tmp.Name()); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+	done = true
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes a rename to disk (best effort: not every filesystem
+// supports syncing directories).
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+}
+
+// ---- Locking
+
+// Lock takes an exclusive lock associated with path, blocking until it is
+// available, and returns the function releasing it. The lock lives on a
+// "<path>.lock" sibling: path itself is replaced by renames, which would
+// leave a lock on the old inode behind.
+func Lock(path string) (unlock func(), err error) {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if !errors.Is(err, syscall.EINTR) {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// Update replaces the content of path with fn's result while holding its
+// lock. A missing file reads as nil. When fn fails, the file is left
+// untouched and its error is returned as is.
+func Update(path string, perm os.FileMode, fn func(data []byte) ([]byte, error)) error {
+	unlock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	data, err = fn(data)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, data, perm)
+}
+
+// Append adds data at the end of path, creating it if needed, while holding
+// its lock so it can't race an Update.
+func Append(path string, data []byte, perm os.FileMode) error {
+	unlock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, perm)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	require.NoError(t, WriteFile(path, []byte("one"), 0600))
+	require.NoError(t, WriteFile(path, []byte("two"), 0640))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "two", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	assertNoTemp(t, filepath.Dir(path))
+}
+
+func TestWriteFileFailureKeepsOld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, WriteFile(path, []byte("old"), 0600))
+
+	injected := errors.New("injected")
+	beforeRename = func(string) error { return injected }
+	defer func() { beforeRename = func(string) error { return nil } }()
+
+	err := WriteFile(path, []byte("new"), 0600)
+	assert.ErrorIs(t, err, injected)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+	assertNoTemp(t, filepath.Dir(path))
+}
+
+// TestWriteFileCrash kills a writer process between writing the new
+// content and renaming it: the target must keep its old content.
+func TestWriteFileCrash(t *testing.T) {
+	if path := os.Getenv("FSUTIL_CRASH_PATH"); path != "" {
+		beforeRename = func(string) error { os.Exit(3); return nil }
+		_ = WriteFile(path, []byte(`{"locked": false}`), 0600)
+		os.Exit(0) // Not reached
+	}
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, WriteFile(path, []byte(`{"locked": true}`), 0600))
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestWriteFileCrash$")
+	cmd.Env = append(os.Environ(), "FSUTIL_CRASH_PATH="+path)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 3, exitErr.ExitCode())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"locked": true}`, string(data))
+
+	// The crashed writer's temporary file doesn't get in the way.
+	require.NoError(t, WriteFile(path, []byte(`{"locked": false}`), 0600))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"locked": false}`, string(data))
+}
+
+func TestUpdateConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+	const writers = 20
+
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(path, 0600, func(data []byte) ([]byte, error) {
+				n, _ := strconv.Atoi(string(data))
+				return []byte(strconv.Itoa(n + 1)), nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(writers), string(data))
+}
+
+func TestUpdateError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	require.NoError(t, WriteFile(path, []byte("keep"), 0600))
+
+	injected := errors.New("injected")
+	err := Update(path, 0600, func([]byte) ([]byte, error) { return nil, injected })
+	assert.Equal(t, injected, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "keep", string(data))
+}
+
+func TestAppendAndUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Append(path, []byte("x\n"), 0600))
+		}()
+		go func() {
+			defer wg.Done()
+			// Rewrites the file unchanged: appends must not get lost.
+			assert.NoError(t, Update(path, 0600, func(data []byte) ([]byte, error) { return data, nil }))
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, data, 20)
+}
+
+func assertNoTemp(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
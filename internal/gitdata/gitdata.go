@@ -0,0 +1,215 @@
+// Package gitdata runs the git queries of the screensaver under hard
+// limits, so that no feature can stall it on an enormous repository.
+//
+// Every query has a timeout and a cap on the output it keeps. A query cut
+// short by either returns what it read so far, complete lines only, marked
+// Partial: a ticker with the commits read in time beats an empty one.
+package gitdata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds one query.
+	DefaultTimeout = 2 * time.Second
+	// DefaultMaxOutput bounds the output kept from one query, in bytes.
+	DefaultMaxOutput = 1 << 20
+	// killGrace is how long a killed git may take to release its output.
+	killGrace = 100 * time.Millisecond
+	// maxStderr bounds the error output kept, to classify failures.
+	maxStderr = 4 << 10
+)
+
+var (
+	// ErrTimeout is returned when a query times out before printing anything.
+	ErrTimeout = errors.New("git query timed out")
+	// ErrNotRepository is returned for a directory outside any repository.
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrNotInstalled is returned when git isn't on PATH.
+	ErrNotInstalled = errors.New("git not found")
+)
+
+// Limits bound one query. Zero fields take the defaults.
+type Limits struct {
+	Timeout   time.Duration
+	MaxOutput int
+}
+
+func (l Limits) timeout() time.Duration {
+	if l.Timeout > 0 {
+		return l.Timeout
+	}
+	return DefaultTimeout
+}
+
+func (l Limits) maxOutput() int {
+	if l.MaxOutput > 0 {
+		return l.MaxOutput
+	}
+	return DefaultMaxOutput
+}
+
+// Repo runs queries in the repository at Dir ("" for the current
+// directory).
+type Repo struct {
+	Dir    string
+	Limits Limits
+}
+
+// Result is the output of a query.
+type Result struct {
+	Out     string
+	Partial bool // Cut by a limit, Out holds complete lines only
+}
+
+// Run runs git with args. Exit errors are returned as *exec.ExitError
+// (wrapped) so callers can tell "no match" statuses apart; outside a
+// repository they also match ErrNotRepository.
+func (r Repo) Run(ctx context.Context, args ...string) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Limits.timeout())
+	defer cancel()
+
+	out := &cappedBuffer{max: r.Limits.maxOutput(), full: cancel}
+	stderr := &cappedBuffer{max: maxStderr, full: func() {}}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.Dir
+	cmd.Stdout = out
+	cmd.Stderr = stderr
+	cmd.WaitDelay = killGrace
+	err := cmd.Run()
+
+	switch {
+	case out.cut:
+		return partial(out.String()), nil
+	case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		if res := partial(out.String()); res.Out != "" {
+			return res, nil
+		}
+		return Result{}, fmt.Errorf("git %s: %w", args[0], ErrTimeout)
+	case errors.Is(err, exec.ErrNotFound):
+		return Result{}, ErrNotInstalled
+	case err != nil && strings.Contains(stderr.String(), "not a git repository"):
+		return Result{}, fmt.Errorf("git %s: %w: %w", args[0], ErrNotRepository, err)
+	case err != nil:
+		return Result{}, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return Result{Out: out.String()}, nil
+}
+
+// Output runs git with args and returns its trimmed output, empty on
+// failure or timeout.
+func (r Repo) Output(ctx context.Context, args ...string) string {
+	res, err := r.Run(ctx, args...)
+	if err != nil || res.Partial {
+		return ""
+	}
+	return strings.TrimSpace(res.Out)
+}
+
+// partial keeps the complete lines of a query cut short.
+func partial(out string) Result {
+	if i := strings.LastIndexByte(out, '\n'); i >= 0 {
+		return Result{Out: out[:i+1], Partial: true}
+	}
+	return Result{Partial: true}
+}
+
+// cappedBuffer keeps the first max bytes written and calls full once when
+// more arrive, to stop the writer. It has no ReadFrom, so that io.Copy
+// goes through Write.
+type cappedBuffer struct {
+	buf  bytes.Buffer
+	max  int
+	cut  bool
+	full func()
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:max(room, 0)])
+		if !b.cut {
+			b.cut = true
+			b.full()
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
+
+// ---- Incremental History
+// Reading the whole history of a monorepo takes minutes. History reads the
+// newest commits once, then on every Update only those committed since,
+// so a feature polling the log costs one short query per refresh.
+
+// History is the newest commits of a repository, newest first, kept up to
+// date incrementally.
+type History struct {
+	Repo   Repo
+	Format string // git log --pretty format of one commit, on one line
+	Max    int    // Commits kept
+
+	head  string
+	lines []string
+}
+
+// Update reads the commits made since the last update (the newest Max the
+// first time) and returns the formatted commits, newest first. After a
+// rewritten history (the previous head is gone), it starts over.
+func (h *History) Update(ctx context.Context) ([]string, bool, error) {
+	head := h.Repo.Output(ctx, "rev-parse", "--verify", "-q", "HEAD")
+	if head == "" {
+		return nil, false, fmt.Errorf("reading HEAD of %q", h.Repo.Dir)
+	}
+	if head == h.head {
+		return h.lines, false, nil
+	}
+
+	args := []string{"log", "-n", strconv.Itoa(h.Max), "--pretty=format:" + h.Format}
+	if h.head != "" && h.isAncestor(ctx, head) {
+		args = append(args, h.head+".."+head)
+	} else {
+		args = append(args, head)
+		h.lines = nil
+	}
+	res, err := h.Repo.Run(ctx, args...)
+	if err != nil {
+		return h.lines, false, err
+	}
+
+	var fresh []string
+	for _, line := range strings.Split(res.Out, "\n") {
+		if line != "" {
+			fresh = append(fresh, line)
+		}
+	}
+	if res.Partial {
+		// Some new commits are missing: read them again next time.
+		lines := append(fresh, h.lines...)
+		return lines[:min(len(lines), h.Max)], true, nil
+	}
+	h.head = head
+	h.lines = append(fresh, h.lines...)
+	if len(h.lines) > h.Max {
+		h.lines = h.lines[:h.Max]
+	}
+	return h.lines, false, nil
+}
+
+// isAncestor reports whether the last head read is an ancestor of head,
+// i.e. the history was only extended since.
+func (h *History) isAncestor(ctx context.Context, head string) bool {
+	_, err := h.Repo.Run(ctx, "merge-base", "--is-ancestor", h.head, head)
+	return err == nil
+}
@@ -0,0 +1,117 @@
+package gitdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepo returns a repository with n empty commits, subjects "commit 1"
+// to "commit n", and a function committing more.
+func testRepo(t *testing.T, n int) (string, func(subject string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=alice", "-c", "user.email=a@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	commit := func(subject string) { git("commit", "-q", "--allow-empty", "-m", subject) }
+	for i := 1; i <= n; i++ {
+		commit(fmt.Sprintf("commit %d", i))
+	}
+	return dir, commit
+}
+
+func TestRun(t *testing.T) {
+	dir, _ := testRepo(t, 5)
+	ctx := context.Background()
+
+	res, err := Repo{Dir: dir}.Run(ctx, "log", "--pretty=format:%s")
+	require.NoError(t, err)
+	assert.False(t, res.Partial)
+	assert.Equal(t, "commit 5\ncommit 4\ncommit 3\ncommit 2\ncommit 1", res.Out)
+
+	t.Run("output limit", func(t *testing.T) {
+		res, err := Repo{Dir: dir, Limits: Limits{MaxOutput: 20}}.Run(ctx, "log", "--pretty=format:%s")
+		require.NoError(t, err)
+		assert.True(t, res.Partial)
+		assert.Equal(t, "commit 5\ncommit 4\n", res.Out, "complete lines only")
+	})
+
+	t.Run("exit status", func(t *testing.T) {
+		_, err := Repo{Dir: dir}.Run(ctx, "grep", "-q", "nothing-matches-this")
+		var exitErr *exec.ExitError
+		require.True(t, errors.As(err, &exitErr))
+		assert.Equal(t, 1, exitErr.ExitCode())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		limits := Limits{Timeout: 100 * time.Millisecond}
+		start := time.Now()
+		_, err := Repo{Dir: dir, Limits: limits}.Run(ctx, "-c", "alias.slow=!sleep 5", "slow")
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.Less(t, time.Since(start), 2*time.Second)
+
+		res, err := Repo{Dir: dir, Limits: limits}.Run(ctx, "-c", "alias.slow=!echo one; echo two; printf thr; sleep 5", "slow")
+		require.NoError(t, err)
+		assert.True(t, res.Partial)
+		assert.Equal(t, "one\ntwo\n", res.Out)
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		_, err := Repo{Dir: t.TempDir()}.Run(ctx, "log", "-1")
+		assert.ErrorIs(t, err, ErrNotRepository)
+		var exitErr *exec.ExitError
+		assert.True(t, errors.As(err, &exitErr), "exit status kept")
+	})
+
+	t.Run("git missing", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		_, err := Repo{Dir: dir}.Run(ctx, "log", "-1")
+		assert.ErrorIs(t, err, ErrNotInstalled)
+	})
+
+	assert.Equal(t, "commit 5", Repo{Dir: dir}.Output(ctx, "log", "-1", "--pretty=format:%s"))
+	assert.Empty(t, Repo{Dir: t.TempDir()}.Output(ctx, "log", "-1"), "not a repository")
+}
+
+func TestHistory(t *testing.T) {
+	dir, commit := testRepo(t, 4)
+	ctx := context.Background()
+	h := &History{Repo: Repo{Dir: dir}, Format: "%s", Max: 3}
+
+	lines, partial, err := h.Update(ctx)
+	require.NoError(t, err)
+	assert.False(t, partial)
+	assert.Equal(t, []string{"commit 4", "commit 3", "commit 2"}, lines)
+
+	commit("commit 5")
+	lines, _, err = h.Update(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"commit 5", "commit 4", "commit 3"}, lines)
+
+	lines, _, err = h.Update(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"commit 5", "commit 4", "commit 3"}, lines, "nothing new")
+
+	// A rewritten history starts over.
+	out, err := exec.Command("git", "-C", dir, "reset", "-q", "--hard", "HEAD~2").CombinedOutput()
+	require.NoError(t, err, string(out))
+	lines, _, err = h.Update(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"commit 3", "commit 2", "commit 1"}, lines)
+
+	_, _, err = (&History{Repo: Repo{Dir: t.TempDir()}, Max: 3}).Update(ctx)
+	assert.Error(t, err, "not a repository")
+}
@@ -0,0 +1,50 @@
+package idlectl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---- Shell Hooks
+// Prompt hooks calling "yule-log idle notify" in the background each time
+// the shell shows a prompt, inside tmux only.
+
+// Shells lists the shells Hook supports.
+var Shells = []string{"zsh", "bash", "fish"}
+
+// Hook returns the prompt hook snippet for shell, running the yule-log
+// binary at exe.
+func Hook(shell, exe string) (string, error) {
+	cmd := quote(exe) + " idle notify"
+	switch shell {
+	case "zsh":
+		return fmt.Sprintf(`# yule-log: report shell activity to the idle watcher
+_yule_log_precmd() { [[ -n $TMUX ]] && { %s >/dev/null 2>&1 &! }; }
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd _yule_log_precmd
+`, cmd), nil
+	case "bash":
+		return fmt.Sprintf(`# yule-log: report shell activity to the idle watcher
+_yule_log_prompt() { [[ -n $TMUX ]] && { %s >/dev/null 2>&1 & disown; }; }
+PROMPT_COMMAND="_yule_log_prompt${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+`, cmd), nil
+	case "fish":
+		return fmt.Sprintf(`# yule-log: report shell activity to the idle watcher
+function __yule_log_prompt --on-event fish_prompt
+    set -q TMUX; and begin; %s >/dev/null 2>&1 &; disown; end
+end
+`, cmd), nil
+	}
+	return "", fmt.Errorf("unknown shell %q (want %s)", shell, strings.Join(Shells, ", "))
+}
+
+// quote quotes s for POSIX shells and fish, which both keep single-quoted
+// text as is (fish also unescapes \' and \\ in it).
+func quote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("/._-+", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,115 @@
+// Package idlectl is the control socket of the idle watcher. Shells report
+// activity on it from their prompt hook, so output that tmux's
+// client_activity misses, like a long build finishing in another window,
+// still counts as activity.
+package idlectl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dialTimeout bounds a notification, so a stuck watcher never slows a
+// prompt down.
+const dialTimeout = 200 * time.Millisecond
+
+// cmdActivity is the message reporting shell activity.
+const cmdActivity = "activity"
+
+// SocketPath returns the control socket of the idle watcher of the tmux
+// server with the given PID, in dir (the runtime directory).
+func SocketPath(dir, server string) string {
+	return filepath.Join(dir, "idle-"+server+".sock")
+}
+
+// Listener receives activity reports on the control socket.
+type Listener struct {
+	ln   net.Listener
+	path string
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Listen creates the control socket at path, replacing a stale one, and
+// starts receiving reports. Only the owner can connect.
+func Listen(path string) (*Listener, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("removing stale control socket: %w", err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("creating control socket: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("creating control socket: %w", err)
+	}
+	l := &Listener{ln: ln, path: path}
+	go l.serve()
+	return l, nil
+}
+
+func (l *Listener) serve() {
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			return // Closed
+		}
+		go l.handle(conn)
+	}
+}
+
+func (l *Listener) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == cmdActivity {
+			l.mu.Lock()
+			l.last = time.Now()
+			l.mu.Unlock()
+		}
+	}
+}
+
+// LastActivity returns when activity was last reported, zero if never.
+func (l *Listener) LastActivity() time.Time {
+	if l == nil {
+		return time.Time{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last
+}
+
+// Close stops receiving reports and removes the socket.
+func (l *Listener) Close() error {
+	if l == nil {
+		return nil
+	}
+	err := l.ln.Close()
+	_ = os.Remove(l.path)
+	return err
+}
+
+// Notify reports shell activity to the watcher listening at path.
+func Notify(path string) error {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("reaching the idle watcher: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+	if _, err := conn.Write([]byte(cmdActivity + "\n")); err != nil {
+		return fmt.Errorf("reaching the idle watcher: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package idlectl
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socketDir returns a short temporary directory: unix socket paths are
+// limited to about 100 bytes, which t.TempDir can exceed.
+func socketDir(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "yl")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestListenNotify(t *testing.T) {
+	path := SocketPath(socketDir(t), "1234")
+	require.NoError(t, os.WriteFile(path, nil, 0600), "stale socket")
+
+	l, err := Listen(path)
+	require.NoError(t, err)
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.True(t, l.LastActivity().IsZero())
+
+	before := time.Now()
+	require.NoError(t, Notify(path))
+	assert.Eventually(t, func() bool { return !l.LastActivity().Before(before) }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, l.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "socket removed")
+	assert.Error(t, Notify(path))
+}
+
+func TestNilListener(t *testing.T) {
+	var l *Listener
+	assert.True(t, l.LastActivity().IsZero())
+	assert.NoError(t, l.Close())
+}
+
+func TestHook(t *testing.T) {
+	for _, shell := range Shells {
+		snippet, err := Hook(shell, "/usr/bin/yule-log")
+		require.NoError(t, err, shell)
+		assert.Contains(t, snippet, "/usr/bin/yule-log idle notify", shell)
+		assert.Contains(t, snippet, "TMUX", shell)
+	}
+	_, err := Hook("tcsh", "yule-log")
+	assert.Error(t, err)
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "/usr/local/bin/yule-log", quote("/usr/local/bin/yule-log"))
+	assert.Equal(t, "'/opt/my tools/yule-log'", quote("/opt/my tools/yule-log"))
+	assert.Equal(t, `'/it'"'"'s/yule-log'`, quote("/it's/yule-log"))
+	assert.Equal(t, "''", quote(""))
+}
@@ -0,0 +1,203 @@
+// Package idlesrc reads how long the user has been idle. The tmux source
+// only sees keys typed into tmux clients; the system source asks the
+// desktop (X11, Wayland compositors, macOS), so typing in a browser next
+// to the terminal counts as activity too.
+package idlesrc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source names, as given to idle --source.
+const (
+	NameTmux   = "tmux"   // Activity of the tmux client
+	NameSystem = "system" // Activity anywhere on the machine
+	NameBoth   = "both"   // Idle only when both agree
+)
+
+// Names lists the accepted source names.
+var Names = []string{NameTmux, NameSystem, NameBoth}
+
+// ErrUnavailable is returned when no probe of the system source works on
+// this machine.
+var ErrUnavailable = errors.New("system idle time unavailable")
+
+// probeTimeout bounds one probe command.
+const probeTimeout = time.Second
+
+// Source reports how long the user has been idle.
+type Source interface {
+	Idle(ctx context.Context) (time.Duration, error)
+}
+
+// New returns the source named name ("" for tmux).
+func New(name string) (Source, error) {
+	switch name {
+	case "", NameTmux:
+		return Tmux{}, nil
+	case NameSystem:
+		return &System{}, nil
+	case NameBoth:
+		return Both{Tmux{}, &System{}}, nil
+	}
+	return nil, fmt.Errorf("unknown idle source %q (want %s)", name, strings.Join(Names, ", "))
+}
+
+// ---- tmux
+
+// Tmux reads the last activity of the current tmux client.
+type Tmux struct{}
+
+func (Tmux) Idle(ctx context.Context) (time.Duration, error) {
+	out, err := exec.CommandContext(ctx, "tmux", "display-message", "-p", "#{client_activity}").Output()
+	if err != nil {
+		return 0, fmt.Errorf("get client activity: %w", err)
+	}
+
+	activityStr := strings.TrimSpace(string(out))
+	if activityStr == "" {
+		return 0, fmt.Errorf("empty activity timestamp")
+	}
+
+	activityTime, err := strconv.ParseInt(activityStr, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse activity timestamp: %w", err)
+	}
+
+	return max(time.Duration(time.Now().Unix()-activityTime)*time.Second, 0), nil
+}
+
+// ---- System
+
+// probe is one way of reading the system idle time: a command and the
+// parser of its output.
+type probe struct {
+	name  string
+	args  []string
+	parse func(string) (time.Duration, error)
+	env   string // Environment variable the probe needs, e.g. DISPLAY
+}
+
+// System reads the idle time of the desktop session, with the first probe
+// that works on this machine (see systemProbes). It sticks to that probe
+// once found.
+type System struct {
+	probes []probe // nil for systemProbes
+	found  *probe
+}
+
+func (s *System) Idle(ctx context.Context) (time.Duration, error) {
+	if s.found != nil {
+		return run(ctx, *s.found)
+	}
+	var errs []error
+	probes := s.probes
+	if probes == nil {
+		probes = systemProbes()
+	}
+	for _, p := range probes {
+		d, err := run(ctx, p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.name, err))
+			continue
+		}
+		s.found = &p
+		return d, nil
+	}
+	return 0, fmt.Errorf("%w: %w", ErrUnavailable, errors.Join(errs...))
+}
+
+// Probe returns the name of the probe in use, "" before the first
+// successful read.
+func (s *System) Probe() string {
+	if s.found == nil {
+		return ""
+	}
+	return s.found.name
+}
+
+func run(ctx context.Context, p probe) (time.Duration, error) {
+	if p.env != "" && os.Getenv(p.env) == "" {
+		return 0, fmt.Errorf("$%s not set", p.env)
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, p.args[0], p.args[1:]...).Output()
+	if err != nil {
+		return 0, err
+	}
+	return p.parse(string(out))
+}
+
+// ---- Both
+
+// Both is idle for as long as all of its sources are: activity on any of
+// them counts. Sources that fail are left out, so the tmux source keeps
+// working on a machine without a desktop.
+type Both []Source
+
+func (b Both) Idle(ctx context.Context) (time.Duration, error) {
+	idle := time.Duration(-1)
+	var errs []error
+	for _, src := range b {
+		d, err := src.Idle(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if idle < 0 || d < idle {
+			idle = d
+		}
+	}
+	if idle < 0 {
+		return 0, errors.Join(errs...)
+	}
+	return idle, nil
+}
+
+// ---- Parsers
+
+// parseMillis parses a bare number of milliseconds (xprintidle).
+func parseMillis(out string) (time.Duration, error) {
+	ms, err := strconv.ParseUint(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing idle time %q: %w", strings.TrimSpace(out), err)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// parseDBusMillis parses the milliseconds returned by a dbus-send
+// --print-reply call, e.g. "method return ...\n   uint64 12345".
+func parseDBusMillis(out string) (time.Duration, error) {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && (fields[0] == "uint32" || fields[0] == "uint64") {
+			return parseMillis(fields[1])
+		}
+	}
+	return 0, fmt.Errorf("no idle time in D-Bus reply %q", strings.TrimSpace(out))
+}
+
+// parseIoreg parses the HIDIdleTime of ioreg -c IOHIDSystem, in
+// nanoseconds, e.g. `    "HIDIdleTime" = 1234567890`.
+func parseIoreg(out string) (time.Duration, error) {
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) != `"HIDIdleTime"` {
+			continue
+		}
+		ns, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parsing HIDIdleTime %q: %w", strings.TrimSpace(value), err)
+		}
+		return time.Duration(ns), nil
+	}
+	return 0, errors.New("no HIDIdleTime in ioreg output")
+}
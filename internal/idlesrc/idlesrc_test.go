@@ -0,0 +1,87 @@
+package idlesrc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsers(t *testing.T) {
+	d, err := parseMillis("12345\n")
+	require.NoError(t, err)
+	assert.Equal(t, 12345*time.Millisecond, d)
+
+	_, err = parseMillis("couldn't open display")
+	assert.Error(t, err)
+
+	d, err = parseDBusMillis("method return time=1.2 sender=:1.10 -> destination=:1.99 serial=4 reply_serial=2\n   uint64 90000\n")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, d)
+
+	_, err = parseDBusMillis("Error org.freedesktop.DBus.Error.NotSupported: not supported\n")
+	assert.Error(t, err)
+
+	d, err = parseIoreg(`+-o IOHIDSystem  <class IOHIDSystem>
+    {
+      "HIDIdleTime" = 4500000000
+      "HIDIdleTimeDelta" = 1
+    }
+`)
+	require.NoError(t, err)
+	assert.Equal(t, 4500*time.Millisecond, d)
+
+	_, err = parseIoreg("")
+	assert.Error(t, err)
+}
+
+func TestSystemPicksFirstWorkingProbe(t *testing.T) {
+	s := &System{probes: []probe{
+		{name: "missing", args: []string{"yule-log-no-such-command"}, parse: parseMillis},
+		{name: "no-display", args: []string{"echo", "1"}, parse: parseMillis, env: "YULE_LOG_TEST_UNSET_VAR"},
+		{name: "echo", args: []string{"echo", "2500"}, parse: parseMillis},
+	}}
+	assert.Empty(t, s.Probe())
+
+	d, err := s.Idle(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2500*time.Millisecond, d)
+	assert.Equal(t, "echo", s.Probe())
+
+	none := &System{probes: []probe{{name: "missing", args: []string{"yule-log-no-such-command"}, parse: parseMillis}}}
+	_, err = none.Idle(context.Background())
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "missing")
+}
+
+type fixed struct {
+	d   time.Duration
+	err error
+}
+
+func (f fixed) Idle(context.Context) (time.Duration, error) { return f.d, f.err }
+
+func TestBoth(t *testing.T) {
+	d, err := Both{fixed{d: time.Minute}, fixed{d: 5 * time.Second}}.Idle(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, d, "activity on either source counts")
+
+	d, err = Both{fixed{d: time.Minute}, fixed{err: ErrUnavailable}}.Idle(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, d, "failing source left out")
+
+	_, err = Both{fixed{err: errors.New("no tmux")}, fixed{err: ErrUnavailable}}.Idle(context.Background())
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range append(Names, "") {
+		_, err := New(name)
+		assert.NoError(t, err, name)
+	}
+	_, err := New("x11")
+	assert.ErrorContains(t, err, "unknown idle source")
+}
@@ -0,0 +1,9 @@
+package idlesrc
+
+// systemProbes reads the idle time of the HID system, which sees every
+// keyboard and mouse event of the session.
+func systemProbes() []probe {
+	return []probe{
+		{name: "ioreg", args: []string{"ioreg", "-c", "IOHIDSystem", "-d", "4", "-r", "-k", "HIDIdleTime"}, parse: parseIoreg},
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !darwin
+
+package idlesrc
+
+// systemProbes asks X11 first, then the Wayland compositors: GNOME's
+// Mutter idle monitor and the freedesktop screensaver interface of KDE
+// (both answer in milliseconds). They all need the session D-Bus or
+// display, which tmux servers started from the desktop inherit.
+func systemProbes() []probe {
+	return []probe{
+		{name: "xprintidle", args: []string{"xprintidle"}, parse: parseMillis, env: "DISPLAY"},
+		{name: "mutter", args: []string{
+			"dbus-send", "--session", "--print-reply", "--dest=org.gnome.Mutter.IdleMonitor",
+			"/org/gnome/Mutter/IdleMonitor/Core", "org.gnome.Mutter.IdleMonitor.GetIdletime",
+		}, parse: parseDBusMillis, env: "DBUS_SESSION_BUS_ADDRESS"},
+		{name: "freedesktop", args: []string{
+			"dbus-send", "--session", "--print-reply", "--dest=org.freedesktop.ScreenSaver",
+			"/org/freedesktop/ScreenSaver", "org.freedesktop.ScreenSaver.GetSessionIdleTime",
+		}, parse: parseDBusMillis, env: "DBUS_SESSION_BUS_ADDRESS"},
+	}
+}
@@ -0,0 +1,159 @@
+// Package instance tracks the running screensavers. Each one listens on a
+// socket of its own in the runtime directory, through which "yule-log
+// dismiss" asks it to exit. A lock screen refuses: only the password ends
+// it.
+package instance
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// dialTimeout bounds the exchange with one instance.
+const dialTimeout = 500 * time.Millisecond
+
+// Messages of the protocol: a request line and a reply line.
+const (
+	cmdDismiss   = "dismiss"
+	replyOK      = "ok"
+	replyRefused = "locked"
+)
+
+// SocketPath returns the socket of the instance with the given PID, in dir
+// (the runtime directory).
+func SocketPath(dir string, pid int) string {
+	return filepath.Join(dir, "screensaver-"+strconv.Itoa(pid)+".sock")
+}
+
+// Instance is the socket of a running screensaver.
+type Instance struct {
+	ln        net.Listener
+	path      string
+	locked    bool
+	dismissed chan struct{}
+}
+
+// Listen creates the socket of the current process in dir. A locked
+// instance refuses to be dismissed.
+func Listen(dir string, locked bool) (*Instance, error) {
+	path := SocketPath(dir, os.Getpid())
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("removing stale instance socket: %w", err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("creating instance socket: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("creating instance socket: %w", err)
+	}
+	in := &Instance{ln: ln, path: path, locked: locked, dismissed: make(chan struct{}, 1)}
+	go in.serve()
+	return in, nil
+}
+
+func (in *Instance) serve() {
+	for {
+		conn, err := in.ln.Accept()
+		if err != nil {
+			return // Closed
+		}
+		go in.handle(conn)
+	}
+}
+
+func (in *Instance) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != cmdDismiss {
+		return
+	}
+	if in.locked {
+		_, _ = conn.Write([]byte(replyRefused + "\n"))
+		return
+	}
+	select {
+	case in.dismissed <- struct{}{}:
+	default: // Already asked
+	}
+	_, _ = conn.Write([]byte(replyOK + "\n"))
+}
+
+// Dismissed is signaled when the instance is asked to exit. It is nil on
+// a nil Instance, so never ready.
+func (in *Instance) Dismissed() <-chan struct{} {
+	if in == nil {
+		return nil
+	}
+	return in.dismissed
+}
+
+// Close removes the socket.
+func (in *Instance) Close() error {
+	if in == nil {
+		return nil
+	}
+	err := in.ln.Close()
+	_ = os.Remove(in.path)
+	return err
+}
+
+// Result counts the instances reached by Dismiss.
+type Result struct {
+	Dismissed int // Exiting
+	Locked    int // Lock screens, left running
+}
+
+// Dismiss asks every instance with a socket in dir to exit. Sockets left
+// behind by instances that died are removed.
+func Dismiss(dir string) (Result, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "screensaver-*.sock"))
+	if err != nil {
+		return Result{}, err
+	}
+	var res Result
+	var errs []error
+	for _, path := range paths {
+		reply, err := request(path)
+		switch {
+		case errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, os.ErrNotExist):
+			_ = os.Remove(path)
+		case err != nil:
+			errs = append(errs, err)
+		case reply == replyOK:
+			res.Dismissed++
+		case reply == replyRefused:
+			res.Locked++
+		}
+	}
+	return res, errors.Join(errs...)
+}
+
+// request sends the dismiss request to the instance at path and returns
+// its reply.
+func request(path string) (string, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+	if _, err := conn.Write([]byte(cmdDismiss + "\n")); err != nil {
+		return "", fmt.Errorf("dismissing %s: %w", filepath.Base(path), err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("dismissing %s: %w", filepath.Base(path), err)
+	}
+	return strings.TrimSpace(reply), nil
+}
@@ -0,0 +1,82 @@
+package instance
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socketDir returns a short temporary directory: unix socket paths are
+// limited to about 100 bytes, which t.TempDir can exceed.
+func socketDir(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "yl")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestDismiss(t *testing.T) {
+	dir := socketDir(t)
+	in, err := Listen(dir, false)
+	require.NoError(t, err)
+	defer in.Close()
+
+	info, err := os.Stat(SocketPath(dir, os.Getpid()))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	res, err := Dismiss(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Dismissed: 1}, res)
+	select {
+	case <-in.Dismissed():
+	case <-time.After(time.Second):
+		t.Fatal("instance not dismissed")
+	}
+
+	require.NoError(t, in.Close())
+	res, err = Dismiss(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Result{}, res)
+}
+
+func TestDismissLocked(t *testing.T) {
+	dir := socketDir(t)
+	in, err := Listen(dir, true)
+	require.NoError(t, err)
+	defer in.Close()
+
+	res, err := Dismiss(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Locked: 1}, res)
+	select {
+	case <-in.Dismissed():
+		t.Fatal("lock screen dismissed")
+	default:
+	}
+}
+
+func TestDismissStale(t *testing.T) {
+	dir := socketDir(t)
+	stale := SocketPath(dir, 1)
+	ln, err := net.Listen("unix", stale)
+	require.NoError(t, err)
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, ln.Close())
+
+	res, err := Dismiss(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Result{}, res)
+	_, err = os.Stat(stale)
+	assert.True(t, os.IsNotExist(err), "stale socket removed")
+}
+
+func TestNilInstance(t *testing.T) {
+	var in *Instance
+	assert.Nil(t, in.Dismissed())
+	assert.NoError(t, in.Close())
+}
@@ -0,0 +1,136 @@
+// Package inventory lists the tmux sessions and their windows, with the
+// activity tmux tracks for them, for the session overlay of the
+// screensaver.
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is one tmux window.
+type Window struct {
+	ID       string // Unique id, like @3
+	Index    int
+	Name     string
+	Active   bool      // Current window of its session
+	Activity time.Time // Last output in the window
+	Flagged  bool      // Activity flag set by monitor-activity
+	Bell     bool
+	Silence  bool // Silence flag set by monitor-silence
+}
+
+// Session is a tmux session and its windows, in index order.
+type Session struct {
+	Name     string
+	Attached bool
+	Windows  []Window
+}
+
+// Activity markers, as in the tmux status line.
+const (
+	MarkBell     = '!'
+	MarkActivity = '#'
+	MarkSilence  = '~'
+)
+
+// Marker returns the activity marker of the window, 0 for none. Output
+// after since counts as activity even without monitor-activity.
+func (w Window) Marker(since time.Time) rune {
+	switch {
+	case w.Bell:
+		return MarkBell
+	case w.Flagged, !since.IsZero() && w.Activity.After(since):
+		return MarkActivity
+	case w.Silence:
+		return MarkSilence
+	default:
+		return 0
+	}
+}
+
+// format prints one window per line, the name last as it may hold tabs.
+const format = "#{session_name}\t#{session_attached}\t#{window_id}\t#{window_index}\t#{window_active}\t#{window_activity}\t" +
+	"#{window_activity_flag}\t#{window_bell_flag}\t#{window_silence_flag}\t#{window_name}"
+
+// List returns the sessions of the tmux server.
+func List(ctx context.Context) ([]Session, error) {
+	out, err := exec.CommandContext(ctx, "tmux", "list-windows", "-a", "-F", format).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing tmux windows: %w", err)
+	}
+	return Parse(string(out)), nil
+}
+
+// Parse reads the output of list-windows with format. Sessions keep the
+// order tmux lists them in; malformed lines are skipped.
+func Parse(out string) []Session {
+	var sessions []Session
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 10)
+		if len(fields) < 10 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[3])
+		if err != nil {
+			continue
+		}
+		w := Window{
+			ID:      fields[2],
+			Index:   index,
+			Name:    fields[9],
+			Active:  fields[4] == "1",
+			Flagged: fields[6] == "1",
+			Bell:    fields[7] == "1",
+			Silence: fields[8] == "1",
+		}
+		if sec, err := strconv.ParseInt(fields[5], 10, 64); err == nil && sec > 0 {
+			w.Activity = time.Unix(sec, 0)
+		}
+
+		name := fields[0]
+		if n := len(sessions); n == 0 || sessions[n-1].Name != name {
+			attached, _ := strconv.Atoi(fields[1])
+			sessions = append(sessions, Session{Name: name, Attached: attached > 0})
+		}
+		last := &sessions[len(sessions)-1]
+		last.Windows = append(last.Windows, w)
+	}
+	return sessions
+}
+
+// Alert is a window whose bell or activity flag is set.
+type Alert struct {
+	Session string
+	Window  Window
+	Mark    rune // MarkBell or MarkActivity
+}
+
+// Key identifies the alert: the same window raising another flag is a
+// new alert.
+func (a Alert) Key() string {
+	return a.Window.ID + string(a.Mark)
+}
+
+// Alerts returns the windows flagged by a bell or by monitor-activity,
+// bells first, leaving out the window with id skip (the screensaver's
+// own). Plain output does not count: only what tmux itself flags.
+func Alerts(sessions []Session, skip string) []Alert {
+	var bells, activity []Alert
+	for _, sess := range sessions {
+		for _, w := range sess.Windows {
+			switch {
+			case w.ID == skip:
+			case w.Bell:
+				bells = append(bells, Alert{Session: sess.Name, Window: w, Mark: MarkBell})
+			case w.Flagged:
+				activity = append(activity, Alert{Session: sess.Name, Window: w, Mark: MarkActivity})
+			}
+		}
+	}
+	return append(bells, activity...)
+}
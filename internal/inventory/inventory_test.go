@@ -0,0 +1,69 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	out := "work\t1\t@1\t1\t1\t1709543000\t0\t0\t0\tvim\n" +
+		"work\t1\t@2\t2\t0\t1709545000\t0\t0\t0\tbuild\n" +
+		"work\t1\t@3\t3\t0\t1709540000\t0\t1\t0\tlogs\n" +
+		"music\t0\t@4\t0\t1\t0\t0\t0\t1\tplayer\ttabs\n" +
+		"garbage\n" +
+		"work\t1\t@5\tx\t0\t0\t0\t0\t0\tbad index\n"
+	sessions := Parse(out)
+	require.Len(t, sessions, 2)
+
+	work := sessions[0]
+	assert.Equal(t, "work", work.Name)
+	assert.True(t, work.Attached)
+	require.Len(t, work.Windows, 3)
+	assert.Equal(t, Window{ID: "@1", Index: 1, Name: "vim", Active: true, Activity: time.Unix(1709543000, 0)}, work.Windows[0])
+	assert.True(t, work.Windows[2].Bell)
+
+	music := sessions[1]
+	assert.False(t, music.Attached)
+	assert.Equal(t, "player\ttabs", music.Windows[0].Name)
+	assert.True(t, music.Windows[0].Activity.IsZero())
+}
+
+func TestMarker(t *testing.T) {
+	since := time.Unix(1709544000, 0)
+	markers := map[rune]Window{
+		0:            {Activity: since.Add(-time.Hour)},
+		MarkActivity: {Activity: since.Add(time.Minute)},
+		MarkBell:     {Bell: true, Silence: true},
+		MarkSilence:  {Silence: true},
+	}
+	for want, w := range markers {
+		assert.Equal(t, want, w.Marker(since), "%+v", w)
+	}
+	assert.Equal(t, rune(MarkActivity), Window{Flagged: true}.Marker(since))
+
+	// Without a start time, only the tmux flags count
+	assert.Equal(t, rune(0), markers[MarkActivity].Marker(time.Time{}))
+}
+
+func TestAlerts(t *testing.T) {
+	sessions := []Session{
+		{Name: "work", Windows: []Window{
+			{ID: "@1", Name: "vim", Activity: time.Now()},
+			{ID: "@2", Name: "build", Flagged: true},
+			{ID: "@3", Name: "logs", Bell: true, Flagged: true},
+			{ID: "@4", Name: "yule", Bell: true},
+		}},
+		{Name: "music", Windows: []Window{{ID: "@5", Name: "player", Silence: true}}},
+	}
+	alerts := Alerts(sessions, "@4")
+	require.Len(t, alerts, 2)
+	assert.Equal(t, Alert{Session: "work", Window: sessions[0].Windows[2], Mark: MarkBell}, alerts[0], "bells first")
+	assert.Equal(t, "@3!", alerts[0].Key())
+	assert.Equal(t, "build", alerts[1].Window.Name)
+	assert.Equal(t, "@2#", alerts[1].Key())
+
+	assert.Empty(t, Alerts(nil, ""))
+}
@@ -0,0 +1,92 @@
+// Package keyring stores secrets in the OS keychain: the macOS Keychain
+// through security(1), and the Secret Service (GNOME Keyring, KWallet)
+// through secret-tool(1) elsewhere. Secrets never appear on a command
+// line, where other users could read them.
+package keyring
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Service is the keychain service of every yule-log secret.
+const Service = "tmux-yule-log"
+
+var (
+	ErrUnsupported = errors.New("no keychain available")
+	ErrNotFound    = errors.New("secret not found in the keychain")
+)
+
+// Available reports why the keychain can't be used, nil if it can.
+func Available() error {
+	if _, err := exec.LookPath(tool); err != nil {
+		return fmt.Errorf("%w: %s not found", ErrUnsupported, tool)
+	}
+	return nil
+}
+
+// Set stores secret under account, replacing the previous one. Secrets are
+// stored base64-encoded: security -i reads one command per line.
+func Set(account string, secret []byte) error {
+	if err := Available(); err != nil {
+		return err
+	}
+	cmd := setCmd(account, base64.StdEncoding.EncodeToString(secret))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("storing secret with %s: %w%s", tool, err, detail(out))
+	}
+	return nil
+}
+
+// Get returns the secret stored under account, ErrNotFound if there is
+// none.
+func Get(account string) ([]byte, error) {
+	if err := Available(); err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := getCmd(account)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if notFound(err, stderr.Bytes()) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("reading secret with %s: %w%s", tool, err, detail(stderr.Bytes()))
+	}
+	encoded := strings.TrimSpace(stdout.String())
+	if encoded == "" {
+		return nil, ErrNotFound
+	}
+	secret, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Delete removes the secret stored under account. Deleting a missing
+// secret is not an error.
+func Delete(account string) error {
+	if err := Available(); err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd := deleteCmd(account)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil && !notFound(err, stderr.Bytes()) {
+		return fmt.Errorf("deleting secret with %s: %w%s", tool, err, detail(stderr.Bytes()))
+	}
+	return nil
+}
+
+// detail formats the output of a failed command for an error message.
+func detail(out []byte) string {
+	if s := strings.TrimSpace(string(out)); s != "" {
+		return ": " + s
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const tool = "security"
+
+// errSecItemNotFound is the exit status of security for a missing item.
+const errSecItemNotFound = 44
+
+// setCmd adds the item through security's interactive mode, reading the
+// command from stdin: add-generic-password only takes the secret as an
+// argument.
+func setCmd(account, value string) *exec.Cmd {
+	cmd := exec.Command(tool, "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %q -a %q -w %q\n", Service, account, value))
+	return cmd
+}
+
+func getCmd(account string) *exec.Cmd {
+	return exec.Command(tool, "find-generic-password", "-s", Service, "-a", account, "-w")
+}
+
+func deleteCmd(account string) *exec.Cmd {
+	return exec.Command(tool, "delete-generic-password", "-s", Service, "-a", account)
+}
+
+func notFound(err error, _ []byte) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound
+}
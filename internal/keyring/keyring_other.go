@@ -0,0 +1,33 @@
+//go:build !darwin
+
+package keyring
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+const tool = "secret-tool"
+
+// setCmd stores the item; secret-tool reads the secret from stdin.
+func setCmd(account, value string) *exec.Cmd {
+	cmd := exec.Command(tool, "store", "--label", Service+" "+account, "service", Service, "account", account)
+	cmd.Stdin = strings.NewReader(value)
+	return cmd
+}
+
+func getCmd(account string) *exec.Cmd {
+	return exec.Command(tool, "lookup", "service", Service, "account", account)
+}
+
+func deleteCmd(account string) *exec.Cmd {
+	return exec.Command(tool, "clear", "service", Service, "account", account)
+}
+
+// notFound reports a lookup or clear of a missing item: secret-tool exits
+// with status 1 and prints nothing.
+func notFound(err error, stderr []byte) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(strings.TrimSpace(string(stderr))) == 0
+}
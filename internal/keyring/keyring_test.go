@@ -0,0 +1,66 @@
+//go:build !darwin
+
+package keyring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretTool puts a secret-tool on PATH keeping items in files, as
+// "<service>-<account>".
+const fakeSecretTool = `#!/bin/sh
+cmd=$1; shift
+[ "$1" = "--label" ] && shift 2
+item="$STORE/$2-$4"
+case $cmd in
+store) cat > "$item" ;;
+lookup) [ -f "$item" ] || exit 1; cat "$item" ;;
+clear) [ -f "$item" ] || exit 1; rm "$item" ;;
+*) echo "unknown command" >&2; exit 2 ;;
+esac
+`
+
+func installFake(t *testing.T) string {
+	t.Helper()
+	bin, store := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, tool), []byte(fakeSecretTool), 0700))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STORE", store)
+	return store
+}
+
+func TestSetGetDelete(t *testing.T) {
+	store := installFake(t)
+	secret := []byte("yule-log password v2\nhash=$argon2id$...\n")
+
+	_, err := Get("password")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, Set("password", secret))
+	got, err := Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, secret, got)
+
+	// Stored encoded, on one line
+	raw, err := os.ReadFile(filepath.Join(store, Service+"-password"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "\n")
+
+	require.NoError(t, Delete("password"))
+	_, err = Get("password")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, Delete("password"), "deleting a missing secret")
+}
+
+func TestUnavailable(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	assert.ErrorIs(t, Available(), ErrUnsupported)
+	assert.ErrorIs(t, Set("password", []byte("x")), ErrUnsupported)
+	_, err := Get("password")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
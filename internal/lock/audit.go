@@ -0,0 +1,92 @@
+package lock
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"yule-log/internal/fsutil"
+)
+
+// ---- Audit Log
+// Every unlock attempt is appended to a plain text file in the state dir,
+// "<RFC 3339 time> <result>" per line, so a user coming back can tell
+// whether someone tried their keyboard. The log is never rewritten: lines
+// are only added, each with a single O_APPEND write.
+
+// Result is the outcome of an unlock attempt.
+type Result string
+
+const (
+	ResultOK      Result = "ok"      // The password was accepted
+	ResultFailed  Result = "failed"  // The password was rejected
+	ResultExpired Result = "expired" // The lock gave up (--max-lock)
+	ResultPanic   Result = "panic"   // The panic keys were pressed
+)
+
+// Attempt is one entry of the audit log.
+type Attempt struct {
+	Time   time.Time
+	Result Result
+}
+
+// RecordAttempt appends an attempt to the audit log at path.
+func RecordAttempt(path string, a Attempt) error {
+	line := fmt.Sprintf("%s %s\n", a.Time.Format(time.RFC3339), a.Result)
+	if err := fsutil.Append(path, []byte(line), 0600); err != nil {
+		return fmt.Errorf("unlock audit log: %w", err)
+	}
+	return nil
+}
+
+// RecentAttempts returns the last n attempts of the audit log at path,
+// oldest first (all of them if n <= 0). Malformed lines are skipped. A
+// missing file yields no attempts.
+func RecentAttempts(path string, n int) ([]Attempt, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening unlock audit log: %w", err)
+	}
+	defer f.Close()
+
+	var attempts []Attempt
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		a, ok := parseAttempt(scanner.Text())
+		if !ok {
+			continue
+		}
+		attempts = append(attempts, a)
+		if n > 0 && len(attempts) > 2*n {
+			attempts = append(attempts[:0], attempts[len(attempts)-n:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading unlock audit log: %w", err)
+	}
+	if n > 0 && len(attempts) > n {
+		attempts = attempts[len(attempts)-n:]
+	}
+	return attempts, nil
+}
+
+func parseAttempt(line string) (Attempt, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 {
+		return Attempt{}, false
+	}
+	t, err := time.Parse(time.RFC3339, fields[0])
+	if err != nil {
+		return Attempt{}, false
+	}
+	switch r := Result(fields[1]); r {
+	case ResultOK, ResultFailed, ResultExpired, ResultPanic:
+		return Attempt{Time: t, Result: r}, true
+	}
+	return Attempt{}, false
+}
@@ -0,0 +1,47 @@
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "unlock.log")
+
+	attempts, err := RecentAttempts(path, 10)
+	require.NoError(t, err)
+	assert.Empty(t, attempts, "missing log")
+
+	now := time.Unix(1_700_000_000, 0).UTC()
+	results := []Result{ResultFailed, ResultFailed, ResultOK, ResultFailed, ResultPanic, ResultExpired}
+	for i, r := range results {
+		require.NoError(t, RecordAttempt(path, Attempt{Time: now.Add(time.Duration(i) * time.Minute), Result: r}))
+	}
+
+	// Garbage lines are ignored
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, _ = f.WriteString("not an attempt\n2023-11-14T22:13:20Z maybe\n")
+	require.NoError(t, f.Close())
+
+	attempts, err = RecentAttempts(path, 0)
+	require.NoError(t, err)
+	require.Len(t, attempts, 6)
+	assert.Equal(t, Attempt{Time: now, Result: ResultFailed}, attempts[0])
+
+	attempts, err = RecentAttempts(path, 2)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, ResultPanic, attempts[0].Result)
+	assert.Equal(t, ResultExpired, attempts[1].Result)
+	assert.True(t, attempts[1].Time.Equal(now.Add(5*time.Minute)))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
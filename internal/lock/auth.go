@@ -0,0 +1,94 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// ---- Authentication Backends
+// The lock screen checks the typed password against the yule-log password
+// file by default. On macOS it can check the user's login password instead,
+// so there is no second password to remember.
+
+// Auth selects what the lock screen checks passwords against.
+type Auth string
+
+const (
+	AuthFile   Auth = "file"   // The password set with lock set-password
+	AuthSystem Auth = "system" // The macOS login password
+)
+
+// ErrSystemAuthUnsupported is returned for --auth system outside macOS.
+var ErrSystemAuthUnsupported = errors.New("system authentication is only supported on macOS")
+
+// ParseAuth parses an --auth flag value. Empty selects the password file.
+func ParseAuth(s string) (Auth, error) {
+	switch Auth(s) {
+	case "", AuthFile:
+		return AuthFile, nil
+	case AuthSystem:
+		if runtime.GOOS != "darwin" {
+			return "", ErrSystemAuthUnsupported
+		}
+		return AuthSystem, nil
+	default:
+		return "", fmt.Errorf("unknown auth backend %q (want %s or %s)", s, AuthFile, AuthSystem)
+	}
+}
+
+// Configured reports whether the backend can check passwords: the
+// password file exists, or the system is asked.
+func (a Auth) Configured() bool {
+	if a == AuthSystem {
+		return true
+	}
+	return PasswordExists()
+}
+
+// Check verifies password with the backend.
+func (a Auth) Check(password []byte) (bool, error) {
+	match, err := a.Verify(password)
+	return match == MatchPassword, err
+}
+
+// Verify returns the password that password matches: the password file
+// also holds the duress password, the system backend has none.
+func (a Auth) Verify(password []byte) (Match, error) {
+	if a == AuthSystem {
+		ok, err := checkSystemPassword(password)
+		if err != nil || !ok {
+			return MatchNone, err
+		}
+		return MatchPassword, nil
+	}
+	return CheckPasswords(password)
+}
+
+// Verifier checks the passwords typed on the lock screen.
+type Verifier interface {
+	Verify(password []byte) (Match, error)
+}
+
+// Load returns the verifier of a lock screen. The password hashes are read
+// once, as the lock starts: a keychain out of reach later on (locked, or
+// no D-Bus session in a popup) must not fail every attempt.
+func (a Auth) Load() (Verifier, error) {
+	if a == AuthSystem {
+		return a, nil
+	}
+	file, err := loadPasswordFile()
+	if err != nil {
+		return nil, err
+	}
+	return storedPasswords{hash: file.Hash, duress: file.Duress}, nil
+}
+
+// storedPasswords are the lock and duress password hashes, loaded.
+type storedPasswords struct {
+	hash, duress string
+}
+
+func (p storedPasswords) Verify(password []byte) (Match, error) {
+	return VerifyPasswords(password, p.hash, p.duress)
+}
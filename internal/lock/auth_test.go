@@ -0,0 +1,41 @@
+package lock
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuth(t *testing.T) {
+	for _, s := range []string{"", "file"} {
+		auth, err := ParseAuth(s)
+		require.NoError(t, err)
+		assert.Equal(t, AuthFile, auth)
+	}
+
+	auth, err := ParseAuth("system")
+	if runtime.GOOS == "darwin" {
+		require.NoError(t, err)
+		assert.Equal(t, AuthSystem, auth)
+	} else {
+		assert.ErrorIs(t, err, ErrSystemAuthUnsupported)
+	}
+
+	_, err = ParseAuth("pam")
+	assert.Error(t, err)
+}
+
+func TestAuthConfigured(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	assert.False(t, AuthFile.Configured())
+	assert.True(t, AuthSystem.Configured())
+
+	_, err := SavePassword([]byte("hunter2"), nil, StoreFile)
+	require.NoError(t, err)
+	assert.True(t, AuthFile.Configured())
+	ok, err := AuthFile.Check([]byte("hunter2"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,73 @@
+package lock
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ---- Attached Clients
+// The lock screen opens over one client. Other clients attached to the
+// server keep working behind it, so locking warns about them first.
+
+// ClientFormat is the tmux list-clients format read by ParseClients.
+const ClientFormat = "#{client_name}\t#{client_pid}\t#{client_session}"
+
+// Client is a tmux client attached to the server.
+type Client struct {
+	Name    string // e.g. /dev/pts/3
+	PID     int
+	Session string
+	Remote  string // SSH peer address, "" for local clients or when unknown
+}
+
+// String describes the client on one line, e.g.
+// "/dev/pts/3 (session main, from 10.0.0.5)".
+func (c Client) String() string {
+	desc := "session " + c.Session
+	if c.Remote != "" {
+		desc += ", from " + c.Remote
+	}
+	return fmt.Sprintf("%s (%s)", c.Name, desc)
+}
+
+// ParseClients reads tmux list-clients output printed with ClientFormat,
+// leaving out the client named self.
+func ParseClients(out, self string) []Client {
+	var clients []Client
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[0] == "" || fields[0] == self {
+			continue
+		}
+		pid, _ := strconv.Atoi(fields[1])
+		clients = append(clients, Client{Name: fields[0], PID: pid, Session: fields[2]})
+	}
+	return clients
+}
+
+// procDir is where RemoteAddr reads process environments.
+var procDir = "/proc"
+
+// RemoteAddr returns the SSH peer address of the client process pid, read
+// from its SSH_CONNECTION. It is "" for local clients and where process
+// environments can't be read (outside Linux).
+func RemoteAddr(pid int) string {
+	if pid <= 0 {
+		return ""
+	}
+	env, err := os.ReadFile(fmt.Sprintf("%s/%d/environ", procDir, pid))
+	if err != nil {
+		return ""
+	}
+	for _, v := range bytes.Split(env, []byte{0}) {
+		if value, ok := bytes.CutPrefix(v, []byte("SSH_CONNECTION=")); ok {
+			if fields := strings.Fields(string(value)); len(fields) > 0 {
+				return fields[0]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClients(t *testing.T) {
+	out := "/dev/pts/1\t100\tmain\n/dev/pts/5\t200\twork\nbroken line\n/dev/pts/7\tx\tmain\n"
+	clients := ParseClients(out, "/dev/pts/1")
+	require.Len(t, clients, 2)
+	assert.Equal(t, Client{Name: "/dev/pts/5", PID: 200, Session: "work"}, clients[0])
+	assert.Equal(t, 0, clients[1].PID, "unreadable pid")
+
+	assert.Equal(t, "/dev/pts/5 (session work)", clients[0].String())
+	clients[0].Remote = "10.0.0.5"
+	assert.Equal(t, "/dev/pts/5 (session work, from 10.0.0.5)", clients[0].String())
+}
+
+func TestRemoteAddr(t *testing.T) {
+	dir := t.TempDir()
+	old := procDir
+	procDir = dir
+	t.Cleanup(func() { procDir = old })
+
+	write := func(pid, env string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, pid), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, pid, "environ"), []byte(env), 0600))
+	}
+	write("200", "HOME=/home/me\x00SSH_CONNECTION=10.0.0.5 52311 10.0.0.1 22\x00TERM=xterm\x00")
+	write("300", "HOME=/home/me\x00TERM=xterm\x00")
+
+	assert.Equal(t, "10.0.0.5", RemoteAddr(200))
+	assert.Empty(t, RemoteAddr(300), "local client")
+	assert.Empty(t, RemoteAddr(400), "no such process")
+	assert.Empty(t, RemoteAddr(0))
+}
@@ -0,0 +1,153 @@
+package lock
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// ---- Duress Password
+// A second password, set with set-password --duress, that the lock screen
+// accepts in place of the real one when someone forces the user to
+// unlock: instead of unlocking, it kills the tmux server (or runs the
+// [password] duress_exec command). Its hash sits next to the password
+// hash, in the password file or the keychain item.
+//
+// Neither action unlocks the session: the lock is only lifted once the
+// tmux server is gone, and duress_exec runs behind the lock screen.
+//
+// Every check computes both hashes, with a decoy for the duress hash when
+// there is none, and combines the results in constant time: how long a
+// check takes tells nothing about which password matched, nor whether a
+// duress password is set.
+
+// ErrDuressMatchesPassword is returned when the duress password and the
+// lock password would be the same.
+var ErrDuressMatchesPassword = errors.New("the duress password must differ from the lock password")
+
+// Match is the password a typed password matched.
+type Match int
+
+const (
+	MatchNone     Match = iota // Neither password
+	MatchPassword              // The lock password
+	MatchDuress                // The duress password
+)
+
+// VerifyPasswords checks password against the lock password hash and the
+// duress hash, which may be empty. A duress hash that can't be checked is
+// only an error when the lock password doesn't match, so that it never
+// locks the user out.
+func VerifyPasswords(password []byte, hash, duress string) (Match, error) {
+	hasDuress := 1
+	if duress == "" {
+		hasDuress, duress = 0, hash // Decoy: same cost, result discarded
+	}
+
+	matchPassword, err := verifyHash(password, hash)
+	if err != nil {
+		return MatchNone, err
+	}
+	matchDuress, err := verifyHash(password, duress)
+	if err != nil {
+		if matchPassword == 1 {
+			return MatchPassword, nil
+		}
+		return MatchNone, fmt.Errorf("duress password: %w", err)
+	}
+	matchDuress &= hasDuress
+
+	match := subtle.ConstantTimeSelect(matchDuress, int(MatchDuress), int(MatchNone))
+	match = subtle.ConstantTimeSelect(matchPassword, int(MatchPassword), match)
+	return Match(match), nil
+}
+
+// CheckPasswords verifies password against the stored lock and duress
+// passwords.
+func CheckPasswords(password []byte) (Match, error) {
+	file, err := loadPasswordFile()
+	if err != nil {
+		return MatchNone, err
+	}
+	return VerifyPasswords(password, file.Hash, file.Duress)
+}
+
+// SetDuressPassword stores the hash of the duress password next to the
+// lock password, which must be set and differ from it.
+func SetDuressPassword(password []byte) error {
+	file, err := loadPasswordFile()
+	if err != nil {
+		return err
+	}
+	same, err := VerifyPassword(password, file.Hash)
+	if err != nil {
+		return err
+	}
+	if same {
+		return ErrDuressMatchesPassword
+	}
+
+	if file.Duress, err = HashPassword(password); err != nil {
+		return fmt.Errorf("hashing duress password: %w", err)
+	}
+	if _, err := writePasswordFile(file, PasswordStore()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveDuressPassword removes the duress password, if any.
+func RemoveDuressPassword() error {
+	file, err := loadPasswordFile()
+	if err != nil {
+		return err
+	}
+	if file.Duress == "" {
+		return nil
+	}
+	file.Duress = ""
+	_, err = writePasswordFile(file, PasswordStore())
+	return err
+}
+
+// HasDuressPassword reports whether a duress password is set.
+func HasDuressPassword() bool {
+	file, err := loadPasswordFile()
+	return err == nil && file.Duress != ""
+}
+
+// KillServer ends the tmux server with kill on the duress password. A
+// restricted socket (socketPath, empty when not restricted) is opened to
+// let kill reach the server, and restricted again if the server survives,
+// so that the session stays locked. The lock is lifted once the server is
+// gone.
+func KillServer(socketPath string, perm os.FileMode, kill func() error) error {
+	if socketPath != "" {
+		if err := RestoreSocket(socketPath, perm); err != nil {
+			return err
+		}
+	}
+	if err := kill(); err != nil {
+		if socketPath != "" {
+			if _, restrictErr := RestrictSocket(socketPath); restrictErr != nil {
+				return errors.Join(fmt.Errorf("killing tmux server: %w", err), restrictErr)
+			}
+		}
+		return fmt.Errorf("killing tmux server: %w", err)
+	}
+	return Unlock()
+}
+
+// RunDuressExec runs the [password] duress_exec command in place of
+// killing the tmux server. The lock and the socket are left alone.
+func RunDuressExec(command string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("duress command: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPasswords(t *testing.T) {
+	hash, err := HashPassword([]byte("lock password"))
+	require.NoError(t, err)
+	duress, err := HashPassword([]byte("duress password"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		duress   string
+		want     Match
+	}{
+		{"lock password", "lock password", duress, MatchPassword},
+		{"duress password", "duress password", duress, MatchDuress},
+		{"wrong password", "guess", duress, MatchNone},
+		{"no duress password", "lock password", "", MatchPassword},
+		{"no duress password, wrong", "duress password", "", MatchNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := VerifyPasswords([]byte(tt.password), hash, tt.duress)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, match)
+		})
+	}
+
+	t.Run("malformed duress hash", func(t *testing.T) {
+		for _, bad := range []string{"$argon2id$bad", "$scrypt$v=1$m=8,t=1,p=1$c2FsdA$aGFzaA"} {
+			match, err := VerifyPasswords([]byte("lock password"), hash, bad)
+			require.NoError(t, err, "the lock password still unlocks")
+			assert.Equal(t, MatchPassword, match)
+
+			match, err = VerifyPasswords([]byte("guess"), hash, bad)
+			assert.Error(t, err)
+			assert.Equal(t, MatchNone, match)
+		}
+		_, err := VerifyPasswords([]byte("guess"), hash, "$argon2id$bad")
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+}
+
+func TestDuressPassword(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	assert.ErrorIs(t, SetDuressPassword([]byte("duress")), ErrNoPassword, "needs the lock password")
+
+	_, err := SavePassword([]byte("lock password"), nil, StoreFile)
+	require.NoError(t, err)
+	assert.False(t, HasDuressPassword())
+
+	assert.ErrorIs(t, SetDuressPassword([]byte("lock password")), ErrDuressMatchesPassword)
+	require.NoError(t, SetDuressPassword([]byte("duress")))
+	assert.True(t, HasDuressPassword())
+
+	match, err := CheckPasswords([]byte("duress"))
+	require.NoError(t, err)
+	assert.Equal(t, MatchDuress, match)
+	ok, err := CheckPassword([]byte("duress"))
+	require.NoError(t, err)
+	assert.False(t, ok, "the duress password never unlocks")
+
+	// Changing the lock password keeps the duress password.
+	_, err = SavePassword([]byte("duress"), nil, StoreFile)
+	assert.ErrorIs(t, err, ErrDuressMatchesPassword)
+	_, err = SavePassword([]byte("new password"), nil, StoreFile)
+	require.NoError(t, err)
+	match, err = CheckPasswords([]byte("duress"))
+	require.NoError(t, err)
+	assert.Equal(t, MatchDuress, match)
+
+	require.NoError(t, RemoveDuressPassword())
+	assert.False(t, HasDuressPassword())
+	match, err = CheckPasswords([]byte("duress"))
+	require.NoError(t, err)
+	assert.Equal(t, MatchNone, match)
+	match, err = CheckPasswords([]byte("new password"))
+	require.NoError(t, err)
+	assert.Equal(t, MatchPassword, match)
+}
+
+// lockedSocket locks with a fake socket and restricts it.
+func lockedSocket(t *testing.T) (string, os.FileMode) {
+	t.Helper()
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	socket := fakeSocket(t, 0770)
+	perm, err := OriginalSocketPerm(socket)
+	require.NoError(t, err)
+	_, err = Lock(socket, perm, AuthSystem)
+	require.NoError(t, err)
+	perm, err = RestrictSocket(socket)
+	require.NoError(t, err)
+	return socket, perm
+}
+
+func TestKillServer(t *testing.T) {
+	t.Run("killed", func(t *testing.T) {
+		socket, perm := lockedSocket(t)
+		var reachable bool
+		require.NoError(t, KillServer(socket, perm, func() error {
+			current, err := GetSocketPermissions(socket)
+			reachable = err == nil && current == perm
+			return nil
+		}))
+		assert.True(t, reachable, "the socket is open to kill the server")
+		assert.False(t, IsLocked())
+	})
+
+	t.Run("server survives", func(t *testing.T) {
+		socket, perm := lockedSocket(t)
+		err := KillServer(socket, perm, func() error { return errors.New("no server") })
+		require.Error(t, err)
+		current, err := GetSocketPermissions(socket)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0), current, "the socket is restricted again")
+		assert.True(t, IsLocked(), "still locked")
+	})
+}
+
+func TestRunDuressExec(t *testing.T) {
+	socket, _ := lockedSocket(t)
+	out := filepath.Join(t.TempDir(), "ran")
+
+	require.NoError(t, RunDuressExec("touch "+out))
+	assert.FileExists(t, out)
+	current, err := GetSocketPermissions(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0), current, "the socket stays restricted")
+	assert.True(t, IsLocked(), "still locked")
+
+	assert.Error(t, RunDuressExec("exit 1"))
+}
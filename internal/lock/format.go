@@ -0,0 +1,184 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ---- File Format Versions
+// Every file the lock writes carries a version so a newer yule-log can
+// upgrade it in place instead of asking users to set their password again.
+// A migration upgrades a file from version N to N+1; files are migrated
+// step by step up to the current version when loaded.
+
+// ErrUnsupportedVersion is returned for files written by a newer yule-log.
+var ErrUnsupportedVersion = errors.New("file written by a newer version of yule-log")
+
+// migrate applies migrations to v, starting at version from. migrations[i]
+// upgrades version i+1 to i+2, so the current version is len(migrations)+1.
+func migrate[T any](v *T, from int, migrations []func(*T) error) (int, error) {
+	current := len(migrations) + 1
+	if from < 1 {
+		return from, fmt.Errorf("invalid version %d", from)
+	}
+	if from > current {
+		return from, fmt.Errorf("version %d: %w", from, ErrUnsupportedVersion)
+	}
+	for version := from; version < current; version++ {
+		if err := migrations[version-1](v); err != nil {
+			return version, fmt.Errorf("migrating from version %d: %w", version, err)
+		}
+	}
+	return current, nil
+}
+
+// ---- Password File
+// Version 1 is a single PHC line (as written by older releases):
+//
+//	$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+//
+// Version 2 adds a header and key=value lines:
+//
+//	yule-log password v2
+//	hash=$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+//	rhythm=120,85,240   (optional, see rhythm.go)
+//
+// Version 3 adds store=keychain: the file only points to the OS keychain,
+// whose item holds the version 3 file itself (see StoreKeychain).
+//
+// Version 4 adds duress=<PHC hash>, the optional duress password (see
+// duress.go).
+
+const passwordHeader = "yule-log password v"
+
+// passwordMigrations upgrade the password file, see migrate.
+var passwordMigrations = []func(*PasswordFile) error{
+	// 1 -> 2: header and key=value lines, nothing to convert.
+	func(*PasswordFile) error { return nil },
+	// 2 -> 3: store line, absent from files holding the hash.
+	func(*PasswordFile) error { return nil },
+	// 3 -> 4: duress line, absent until a duress password is set.
+	func(*PasswordFile) error { return nil },
+}
+
+// PasswordFileVersion is the password file version written by SavePassword.
+var PasswordFileVersion = len(passwordMigrations) + 1
+
+// PasswordFile is the decoded password file.
+type PasswordFile struct {
+	Version int
+	Hash    string
+	Rhythm  Rhythm // Typing rhythm profile, nil when not recorded
+	Store   Store  // StoreKeychain when the hash is in the keychain, "" otherwise
+	Duress  string // Duress password hash, "" when none is set
+
+	// Unknown key=value lines, kept so rewriting the file doesn't drop them.
+	extra []string
+}
+
+// ParsePasswordFile decodes a password file of any known version and
+// migrates it to PasswordFileVersion. migrated reports whether the file
+// should be rewritten.
+func ParsePasswordFile(data []byte) (f *PasswordFile, migrated bool, err error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return nil, false, ErrInvalidFormat
+	}
+
+	f = &PasswordFile{}
+	first := strings.TrimSpace(lines[0])
+	if version, ok := strings.CutPrefix(first, passwordHeader); ok {
+		f.Version, err = strconv.Atoi(version)
+		if err != nil {
+			return nil, false, ErrInvalidFormat
+		}
+		for _, line := range lines[1:] {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, false, ErrInvalidFormat
+			}
+			switch key {
+			case "hash":
+				f.Hash = value
+			case "rhythm":
+				if f.Rhythm, err = ParseRhythm(value); err != nil {
+					return nil, false, fmt.Errorf("%w: %w", ErrInvalidFormat, err)
+				}
+			case "duress":
+				f.Duress = value
+			case "store":
+				if Store(value) != StoreKeychain {
+					return nil, false, fmt.Errorf("%w: unknown store %q", ErrInvalidFormat, value)
+				}
+				f.Store = StoreKeychain
+			default:
+				f.extra = append(f.extra, line)
+			}
+		}
+	} else {
+		if len(lines) != 1 {
+			return nil, false, ErrInvalidFormat
+		}
+		f.Version = 1
+		f.Hash = first
+	}
+
+	from := f.Version
+	f.Version, err = migrate(f, from, passwordMigrations)
+	if err != nil {
+		return nil, false, fmt.Errorf("password file: %w", err)
+	}
+	if (f.Hash == "") == (f.Store == "") {
+		return nil, false, ErrInvalidFormat // Needs the hash, or where it is
+	}
+	if f.Duress != "" && f.Store != "" {
+		return nil, false, ErrInvalidFormat // Kept with the hash
+	}
+	return f, f.Version != from, nil
+}
+
+// Encode returns the file contents in the current format.
+func (f *PasswordFile) Encode() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%d\n", passwordHeader, PasswordFileVersion)
+	if f.Store != "" {
+		fmt.Fprintf(&b, "store=%s\n", f.Store)
+	}
+	if f.Hash != "" {
+		fmt.Fprintf(&b, "hash=%s\n", f.Hash)
+	}
+	if f.Duress != "" {
+		fmt.Fprintf(&b, "duress=%s\n", f.Duress)
+	}
+	if f.Rhythm != nil {
+		fmt.Fprintf(&b, "rhythm=%s\n", f.Rhythm)
+	}
+	for _, line := range f.extra {
+		b.WriteString(line + "\n")
+	}
+	return []byte(b.String())
+}
+
+// ---- Lock State File
+// Version 1 is the JSON state without a version field; version 2 adds it;
+// version 3 adds the sealed session phrase; version 4 the PID of the lock
+// process.
+
+// stateMigrations upgrade the lock state file, see migrate.
+var stateMigrations = []func(*State) error{
+	// 1 -> 2: version field, nothing to convert.
+	func(*State) error { return nil },
+	// 2 -> 3: session phrase, absent from locks started before.
+	func(*State) error { return nil },
+	// 3 -> 4: lock process, unknown for locks started before.
+	func(*State) error { return nil },
+}
+
+// StateFileVersion is the lock state version written by Lock.
+var StateFileVersion = len(stateMigrations) + 1
@@ -0,0 +1,118 @@
+package lock
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/xdg"
+)
+
+const testPHC = "$argon2id$v=19$m=19456,t=2,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaGhhc2hoYXNoaGFzaGhhc2hoYXNoaGFzaGhhc2g"
+
+func TestParsePasswordFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		migrated bool
+		err      error
+	}{
+		{name: "legacy single line", data: testPHC + "\n", migrated: true},
+		{name: "version 2", data: "yule-log password v2\nhash=" + testPHC + "\n", migrated: true},
+		{name: "version 3", data: "yule-log password v3\nhash=" + testPHC + "\n", migrated: true},
+		{name: "current version", data: "yule-log password v4\nhash=" + testPHC + "\n"},
+		{name: "duress", data: "yule-log password v4\nhash=" + testPHC + "\nduress=" + testPHC + "\n"},
+		{name: "duress and store", data: "yule-log password v4\nstore=keychain\nduress=" + testPHC + "\n", err: ErrInvalidFormat},
+		{name: "unknown keys kept", data: "yule-log password v4\nhash=" + testPHC + "\npepper=abc\n"},
+		{name: "rhythm", data: "yule-log password v4\nhash=" + testPHC + "\nrhythm=120,80\n"},
+		{name: "bad rhythm", data: "yule-log password v4\nhash=" + testPHC + "\nrhythm=fast\n", err: ErrInvalidFormat},
+		{name: "empty", data: "\n", err: ErrInvalidFormat},
+		{name: "header without hash", data: "yule-log password v4\n", err: ErrInvalidFormat},
+		{name: "unknown store", data: "yule-log password v4\nstore=vault\n", err: ErrInvalidFormat},
+		{name: "store and hash", data: "yule-log password v4\nstore=keychain\nhash=" + testPHC + "\n", err: ErrInvalidFormat},
+		{name: "legacy with extra lines", data: testPHC + "\n" + testPHC + "\n", err: ErrInvalidFormat},
+		{name: "bad version", data: "yule-log password vX\nhash=" + testPHC, err: ErrInvalidFormat},
+		{name: "newer version", data: "yule-log password v99\nhash=" + testPHC, err: ErrUnsupportedVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, migrated, err := ParsePasswordFile([]byte(tt.data))
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, PasswordFileVersion, f.Version)
+			assert.Equal(t, testPHC, f.Hash)
+			assert.Equal(t, tt.migrated, migrated)
+		})
+	}
+}
+
+func TestPasswordFileEncodeRoundTrip(t *testing.T) {
+	f, _, err := ParsePasswordFile([]byte("yule-log password v4\nhash=" + testPHC + "\npepper=abc\n"))
+	require.NoError(t, err)
+
+	encoded := f.Encode()
+	assert.Equal(t, "yule-log password v4\nhash="+testPHC+"\npepper=abc\n", string(encoded))
+
+	again, migrated, err := ParsePasswordFile(encoded)
+	require.NoError(t, err)
+	assert.False(t, migrated)
+	assert.Equal(t, f, again)
+}
+
+func TestPasswordFileKeychainPointer(t *testing.T) {
+	f := &PasswordFile{Version: PasswordFileVersion, Store: StoreKeychain}
+	assert.Equal(t, "yule-log password v4\nstore=keychain\n", string(f.Encode()))
+
+	again, migrated, err := ParsePasswordFile(f.Encode())
+	require.NoError(t, err)
+	assert.False(t, migrated)
+	assert.Equal(t, f, again)
+}
+
+func TestPasswordFileRhythmRoundTrip(t *testing.T) {
+	f := &PasswordFile{Version: PasswordFileVersion, Hash: testPHC, Rhythm: Rhythm{120 * time.Millisecond, 80 * time.Millisecond}}
+	assert.Contains(t, string(f.Encode()), "\nrhythm=120,80\n")
+
+	again, _, err := ParsePasswordFile(f.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, f.Rhythm, again.Rhythm)
+
+	f.Rhythm = nil
+	assert.NotContains(t, string(f.Encode()), "rhythm")
+}
+
+func TestLoadPasswordHashMigratesInPlace(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := xdg.PasswordFile()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(testPHC+"\n"), 0600))
+
+	hash, err := LoadPasswordHash()
+	require.NoError(t, err)
+	assert.Equal(t, testPHC, hash)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "yule-log password v4\nhash="+testPHC+"\n", string(data))
+}
+
+func TestLoadStateMigratesLegacy(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	require.NoError(t, saveState(&State{Locked: true}))
+	state, err := LoadState()
+	require.NoError(t, err)
+	assert.Equal(t, StateFileVersion, state.Version)
+	assert.True(t, state.Locked)
+
+	require.NoError(t, saveState(&State{Version: 99, Locked: true}))
+	_, err = LoadState()
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
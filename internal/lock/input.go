@@ -7,9 +7,11 @@ import (
 	"github.com/gdamore/tcell/v2"
 )
 
-// ---- Arrow Key Markers
-// Internal markers for arrow keys in passphrase (won't appear in normal input).
-// Uses NULL + control character sequences that can't be typed.
+// ---- Special Key Markers
+// Internal markers for arrow, function and navigation keys in passphrase
+// (won't appear in normal input). Uses NULL + control character sequences
+// that can't be typed. Marker bytes are part of stored password hashes:
+// existing ones must never change.
 
 const (
 	ArrowUpMarker    = "\x00\x01" // NULL + SOH
@@ -18,56 +20,102 @@ const (
 	ArrowRightMarker = "\x00\x04" // NULL + EOT
 )
 
-// ArrowKeyMarker returns the marker string for an arrow key.
-func ArrowKeyMarker(key tcell.Key) string {
-	switch key {
-	case tcell.KeyUp:
-		return ArrowUpMarker
-	case tcell.KeyDown:
-		return ArrowDownMarker
-	case tcell.KeyLeft:
-		return ArrowLeftMarker
-	case tcell.KeyRight:
-		return ArrowRightMarker
-	default:
-		return ""
-	}
-}
-
-// ArrowKeyDisplay returns a display character for an arrow key.
-func ArrowKeyDisplay(key tcell.Key) rune {
-	switch key {
-	case tcell.KeyUp:
-		return '\u2191' // ↑
-	case tcell.KeyDown:
-		return '\u2193' // ↓
-	case tcell.KeyLeft:
-		return '\u2190' // ←
-	case tcell.KeyRight:
-		return '\u2192' // →
-	default:
-		return ' '
-	}
-}
-
-// IsArrowKey checks if the given key is an arrow key.
-func IsArrowKey(key tcell.Key) bool {
-	return key == tcell.KeyUp || key == tcell.KeyDown ||
-		key == tcell.KeyLeft || key == tcell.KeyRight
-}
-
-// IsArrowMarkerSuffix checks if data ends with an arrow marker.
+// markerLen is the length of every marker.
+const markerLen = 2
+
+// specialKey is a key stored as a marker, with its echo glyphs.
+type specialKey struct {
+	key     tcell.Key
+	marker  string
+	display rune
+	ascii   rune // For terminals whose font lacks the display glyph
+}
+
+// specialKeys lists the keys usable in passwords besides characters.
+// Function keys echo as circled numbers (ASCII: the digit row), navigation
+// keys as their arrows (ASCII: brackets).
+var specialKeys = []specialKey{
+	{tcell.KeyUp, ArrowUpMarker, '\u2191', '^'},       // ↑
+	{tcell.KeyDown, ArrowDownMarker, '\u2193', 'v'},   // ↓
+	{tcell.KeyLeft, ArrowLeftMarker, '\u2190', '<'},   // ←
+	{tcell.KeyRight, ArrowRightMarker, '\u2192', '>'}, // →
+	{tcell.KeyF1, "\x00\x05", '\u2460', '1'},          // ①
+	{tcell.KeyF2, "\x00\x06", '\u2461', '2'},
+	{tcell.KeyF3, "\x00\x07", '\u2462', '3'},
+	{tcell.KeyF4, "\x00\x08", '\u2463', '4'},
+	{tcell.KeyF5, "\x00\x09", '\u2464', '5'},
+	{tcell.KeyF6, "\x00\x0a", '\u2465', '6'},
+	{tcell.KeyF7, "\x00\x0b", '\u2466', '7'},
+	{tcell.KeyF8, "\x00\x0c", '\u2467', '8'},
+	{tcell.KeyF9, "\x00\x0d", '\u2468', '9'},
+	{tcell.KeyF10, "\x00\x0e", '\u2469', '0'},
+	{tcell.KeyF11, "\x00\x0f", '\u246a', '-'},
+	{tcell.KeyF12, "\x00\x10", '\u246b', '='},  // ⑫
+	{tcell.KeyHome, "\x00\x11", '\u21f1', '['}, // ⇱
+	{tcell.KeyEnd, "\x00\x12", '\u21f2', ']'},  // ⇲
+	{tcell.KeyPgUp, "\x00\x13", '\u21de', '{'}, // ⇞
+	{tcell.KeyPgDn, "\x00\x14", '\u21df', '}'}, // ⇟
+}
+
+func lookupKey(key tcell.Key) (specialKey, bool) {
+	for _, k := range specialKeys {
+		if k.key == key {
+			return k, true
+		}
+	}
+	return specialKey{}, false
+}
+
+func lookupMarker(s string) (specialKey, bool) {
+	for _, k := range specialKeys {
+		if k.marker == s {
+			return k, true
+		}
+	}
+	return specialKey{}, false
+}
+
+func isMarker(s string) bool {
+	_, ok := lookupMarker(s)
+	return ok
+}
+
+// KeyMarker returns the marker string for a special key, "" for others.
+func KeyMarker(key tcell.Key) string {
+	k, _ := lookupKey(key)
+	return k.marker
+}
+
+// KeyDisplay returns a display character for a special key.
+func KeyDisplay(key tcell.Key) rune {
+	if k, ok := lookupKey(key); ok {
+		return k.display
+	}
+	return ' '
+}
+
+// KeyDisplayASCII returns an ASCII-only display character for a special
+// key, for terminals whose font lacks the other glyphs.
+func KeyDisplayASCII(key tcell.Key) rune {
+	if k, ok := lookupKey(key); ok {
+		return k.ascii
+	}
+	return ' '
+}
+
+// IsMarkerKey checks if the given key is stored as a marker.
+func IsMarkerKey(key tcell.Key) bool {
+	_, ok := lookupKey(key)
+	return ok
+}
+
+// IsMarkerSuffix checks if data ends with a special key marker.
 // Returns true and the marker length (2) if found, false and 1 otherwise.
-func IsArrowMarkerSuffix(data []byte) (bool, int) {
-	if len(data) < 2 {
+func IsMarkerSuffix(data []byte) (bool, int) {
+	if len(data) < markerLen || !isMarker(string(data[len(data)-markerLen:])) {
 		return false, 1
 	}
-	last2 := string(data[len(data)-2:])
-	switch last2 {
-	case ArrowUpMarker, ArrowDownMarker, ArrowLeftMarker, ArrowRightMarker:
-		return true, 2
-	}
-	return false, 1
+	return true, markerLen
 }
 
 // ClearBytes securely wipes a byte slice by overwriting with zeros.
@@ -78,121 +126,181 @@ func ClearBytes(b []byte) {
 }
 
 // ---- Secure Input Buffer
+// The password being typed lives in a memguard LockedBuffer at all times:
+// locked memory is never swapped out, is left out of core dumps, and sits
+// between guard pages. It grows by moving to a larger locked buffer and
+// destroying the old one; single keys are encoded on the stack and wiped.
+
+// initialBufferSize is the capacity of the first locked buffer. memguard
+// rounds allocations up to whole pages anyway.
+const initialBufferSize = 256
 
 // SecureBuffer wraps memguard for secure password handling.
 type SecureBuffer struct {
 	enclave *memguard.Enclave
-	data    []byte
+	buf     *memguard.LockedBuffer // nil until the first key
+	n       int                    // Bytes of buf in use
 }
 
 // NewSecureBuffer creates a new secure buffer for password input.
 func NewSecureBuffer() *SecureBuffer {
-	return &SecureBuffer{
-		data: make([]byte, 0, 256),
+	return &SecureBuffer{}
+}
+
+// live returns the typed bytes, in locked memory.
+func (sb *SecureBuffer) live() []byte {
+	if sb.buf == nil {
+		return nil
+	}
+	return sb.buf.Bytes()[:sb.n]
+}
+
+// grow makes room for extra more bytes, moving the content to a larger
+// locked buffer when needed.
+func (sb *SecureBuffer) grow(extra int) {
+	size := 0
+	if sb.buf != nil {
+		size = sb.buf.Size()
+	}
+	if sb.n+extra <= size {
+		return
+	}
+	size = max(initialBufferSize, 2*size, sb.n+extra)
+	buf := memguard.NewBuffer(size)
+	copy(buf.Bytes(), sb.live())
+	if sb.buf != nil {
+		sb.buf.Destroy() // Wipes the old copy
 	}
+	sb.buf = buf
 }
 
 // Append adds data to the secure buffer.
 func (sb *SecureBuffer) Append(data []byte) {
-	sb.data = append(sb.data, data...)
+	sb.grow(len(data))
+	sb.n += copy(sb.buf.Bytes()[sb.n:], data)
 }
 
 // AppendRune adds a rune to the secure buffer, properly encoding UTF-8.
 func (sb *SecureBuffer) AppendRune(r rune) {
 	var buf [utf8.UTFMax]byte
 	n := utf8.EncodeRune(buf[:], r)
-	sb.data = append(sb.data, buf[:n]...)
+	sb.Append(buf[:n])
+	ClearBytes(buf[:])
 }
 
 // AppendString adds a string to the secure buffer.
 func (sb *SecureBuffer) AppendString(s string) {
-	sb.data = append(sb.data, s...)
+	sb.grow(len(s))
+	sb.n += copy(sb.buf.Bytes()[sb.n:], s)
 }
 
-// Backspace removes the last character from the buffer.
-// Handles multi-byte arrow markers correctly.
-// Returns true if data was removed.
+// Backspace removes the last character from the buffer: a whole UTF-8
+// character or key marker. Returns true if data was removed.
 func (sb *SecureBuffer) Backspace() bool {
-	if len(sb.data) == 0 {
+	data := sb.live()
+	if len(data) == 0 {
 		return false
 	}
 
-	isArrow, removeLen := IsArrowMarkerSuffix(sb.data)
-	if isArrow {
-		sb.data = sb.data[:len(sb.data)-removeLen]
-	} else {
-		sb.data = sb.data[:len(sb.data)-1]
+	n := 1
+	if isMarker, removeLen := IsMarkerSuffix(data); isMarker {
+		n = removeLen
+	} else if _, size := utf8.DecodeLastRune(data); size > 1 {
+		n = size
 	}
+	ClearBytes(data[len(data)-n:])
+	sb.n -= n
 	return true
 }
 
-// Clear securely wipes and resets the buffer.
+// Clear securely wipes and resets the buffer. The locked memory is kept
+// for the next password.
 func (sb *SecureBuffer) Clear() {
-	// Overwrite with zeros before truncating
-	for i := range sb.data {
-		sb.data[i] = 0
+	if sb.buf != nil {
+		sb.buf.Wipe()
 	}
-	sb.data = sb.data[:0]
+	sb.n = 0
 }
 
 // Len returns the length of the buffer.
 func (sb *SecureBuffer) Len() int {
-	return len(sb.data)
+	return sb.n
 }
 
-// Bytes returns a copy of the buffer contents.
+// Bytes returns a copy of the buffer contents, outside locked memory.
 // The returned slice should be cleared after use.
 func (sb *SecureBuffer) Bytes() []byte {
-	result := make([]byte, len(sb.data))
-	copy(result, sb.data)
+	result := make([]byte, sb.n)
+	copy(result, sb.live())
 	return result
 }
 
 // Seal moves the data into a memguard enclave for secure storage.
 // After calling Seal, the buffer is cleared and cannot be used until Open.
 func (sb *SecureBuffer) Seal() {
-	if len(sb.data) == 0 {
+	if sb.n == 0 {
 		return
 	}
 
-	sb.enclave = memguard.NewEnclave(sb.data)
+	sb.enclave = memguard.NewEnclave(sb.live()) // Wipes the source
 	sb.Clear()
 }
 
-// Open retrieves the data from the enclave.
-// Returns a LockedBuffer that should be destroyed after use.
+// Open retrieves the data from the enclave, or the live buffer when not
+// sealed. Returns a LockedBuffer that should be destroyed after use.
 func (sb *SecureBuffer) Open() (*memguard.LockedBuffer, error) {
 	if sb.enclave == nil {
-		return memguard.NewBufferFromBytes(sb.data), nil
+		buf := memguard.NewBuffer(sb.n)
+		copy(buf.Bytes(), sb.live())
+		return buf, nil
 	}
 	return sb.enclave.Open()
 }
 
-// Destroy securely wipes all data.
+// Destroy securely wipes all data and releases the locked memory.
 // The enclave is set to nil, allowing GC to collect it.
 // memguard.Enclave doesn't expose a Destroy method; its internal
 // LockedBuffer is wiped when the Enclave is garbage collected.
 func (sb *SecureBuffer) Destroy() {
-	sb.Clear()
+	if sb.buf != nil {
+		sb.buf.Destroy()
+		sb.buf = nil
+	}
+	sb.n = 0
 	sb.enclave = nil
 }
 
-// VisualLen returns the number of visual characters (arrows count as 1).
+// VisualLen returns the number of visual characters (special keys count
+// as 1).
 func (sb *SecureBuffer) VisualLen() int {
-	count := 0
-	data := sb.data
-	for len(data) > 0 {
-		if len(data) >= 2 {
-			marker := string(data[:2])
-			if marker == ArrowUpMarker || marker == ArrowDownMarker ||
-				marker == ArrowLeftMarker || marker == ArrowRightMarker {
-				count++
-				data = data[2:]
-				continue
-			}
+	return len(sb.Masks(false))
+}
+
+// Mask is the echo of one typed key.
+type Mask struct {
+	Glyph rune // '*' for characters, the key glyph for special keys
+	Key   bool // A special key
+}
+
+// Masks returns the echo of every key in the buffer, with ASCII glyphs
+// for special keys if ascii is set.
+func (sb *SecureBuffer) Masks(ascii bool) []Mask {
+	var masks []Mask
+	eachKey(sb.live(), func(k passwordKey) {
+		switch {
+		case k.special == nil:
+			masks = append(masks, Mask{Glyph: '*'})
+		case ascii:
+			masks = append(masks, Mask{Glyph: k.special.ascii, Key: true})
+		default:
+			masks = append(masks, Mask{Glyph: k.special.display, Key: true})
 		}
-		count++
-		data = data[1:]
-	}
-	return count
+	})
+	return masks
+}
+
+// Strength estimates the strength of the password typed so far, see
+// EstimateStrength.
+func (sb *SecureBuffer) Strength() Strength {
+	return EstimateStrength(sb.live())
 }
@@ -1,13 +1,15 @@
 package lock
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestIsArrowMarkerSuffix(t *testing.T) {
+func TestIsMarkerSuffix(t *testing.T) {
 	tests := []struct {
 		name       string
 		data       []byte
@@ -69,8 +71,20 @@ func TestIsArrowMarkerSuffix(t *testing.T) {
 			wantRemove: 2,
 		},
 		{
-			name:       "null byte but wrong second byte",
+			name:       "ends with F1 marker",
 			data:       []byte{'a', 0x00, 0x05},
+			wantMatch:  true,
+			wantRemove: 2,
+		},
+		{
+			name:       "ends with PageDown marker",
+			data:       []byte{0x00, 0x14},
+			wantMatch:  true,
+			wantRemove: 2,
+		},
+		{
+			name:       "null byte but wrong second byte",
+			data:       []byte{'a', 0x00, 0x7f},
 			wantMatch:  false,
 			wantRemove: 1,
 		},
@@ -78,7 +92,7 @@ func TestIsArrowMarkerSuffix(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotMatch, gotRemove := IsArrowMarkerSuffix(tt.data)
+			gotMatch, gotRemove := IsMarkerSuffix(tt.data)
 			assert.Equal(t, tt.wantMatch, gotMatch, "match result")
 			assert.Equal(t, tt.wantRemove, gotRemove, "remove length")
 		})
@@ -135,6 +149,19 @@ func TestSecureBuffer_Backspace(t *testing.T) {
 		assert.False(t, sb.Backspace(), "Backspace() on empty should return false")
 	})
 
+	t.Run("backspace removes a whole UTF-8 character", func(t *testing.T) {
+		sb := NewSecureBuffer()
+		sb.AppendRune('a')
+		sb.AppendRune('é') // 2 bytes
+		sb.AppendRune('€') // 3 bytes
+		assert.Equal(t, 3, sb.VisualLen())
+
+		require.True(t, sb.Backspace())
+		assert.Equal(t, "aé", string(sb.Bytes()))
+		require.True(t, sb.Backspace())
+		assert.Equal(t, "a", string(sb.Bytes()))
+	})
+
 	t.Run("backspace with multiple arrow markers", func(t *testing.T) {
 		sb := NewSecureBuffer()
 		sb.AppendString(ArrowDownMarker)  // 2 bytes
@@ -155,6 +182,71 @@ func TestSecureBuffer_Backspace(t *testing.T) {
 	})
 }
 
+func TestSecureBuffer_Clear(t *testing.T) {
+	sb := NewSecureBuffer()
+	sb.AppendString("secret")
+	sb.Backspace()
+	sb.Backspace()
+
+	sb.Clear()
+	assert.Equal(t, 0, sb.Len())
+	assert.Equal(t, 0, sb.VisualLen())
+	for i, b := range sb.buf.Bytes() {
+		require.Zero(t, b, "byte %d left in the locked buffer", i)
+	}
+}
+
+func TestSecureBuffer_Grow(t *testing.T) {
+	sb := NewSecureBuffer()
+	defer sb.Destroy()
+
+	want := strings.Repeat("pässwörd", 100) // Well past the first buffer
+	for _, r := range want {
+		sb.AppendRune(r)
+	}
+	assert.Equal(t, want, string(sb.Bytes()))
+	assert.GreaterOrEqual(t, sb.buf.Size(), len(want))
+
+	sb.Backspace()
+	assert.Equal(t, want[:len(want)-1], string(sb.Bytes()))
+	assert.Zero(t, sb.buf.Bytes()[len(want)-1], "removed byte not wiped")
+}
+
+func TestSecureBuffer_Destroy(t *testing.T) {
+	sb := NewSecureBuffer()
+	sb.AppendString("secret")
+	buf := sb.buf
+
+	sb.Destroy()
+	assert.False(t, buf.IsAlive())
+	assert.Equal(t, 0, sb.Len())
+	assert.Empty(t, sb.Bytes())
+
+	// The buffer can be reused after Destroy.
+	sb.AppendString("again")
+	assert.Equal(t, "again", string(sb.Bytes()))
+	sb.Destroy()
+}
+
+func TestSecureBuffer_SealOpen(t *testing.T) {
+	sb := NewSecureBuffer()
+	defer sb.Destroy()
+	sb.AppendString("secret")
+
+	open, err := sb.Open()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(open.Bytes()))
+	open.Destroy()
+	assert.Equal(t, "secret", string(sb.Bytes()), "Open consumed the live buffer")
+
+	sb.Seal()
+	assert.Equal(t, 0, sb.Len())
+	open, err = sb.Open()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(open.Bytes()))
+	open.Destroy()
+}
+
 func TestSecureBuffer_VisualLen(t *testing.T) {
 	t.Run("empty buffer", func(t *testing.T) {
 		sb := NewSecureBuffer()
@@ -196,3 +288,46 @@ func TestSecureBuffer_VisualLen(t *testing.T) {
 		assert.Equal(t, 4, sb.VisualLen())
 	})
 }
+
+func TestSpecialKeys(t *testing.T) {
+	markers := map[string]bool{}
+	displays := map[rune]bool{}
+	asciis := map[rune]bool{}
+	for _, k := range specialKeys {
+		assert.Len(t, k.marker, markerLen)
+		assert.Equal(t, byte(0), k.marker[0])
+		assert.False(t, markers[k.marker], "duplicate marker for %s", tcell.KeyNames[k.key])
+		assert.False(t, displays[k.display], "duplicate glyph for %s", tcell.KeyNames[k.key])
+		assert.False(t, asciis[k.ascii], "duplicate ASCII glyph for %s", tcell.KeyNames[k.key])
+		assert.Less(t, k.ascii, rune(0x80))
+		markers[k.marker], displays[k.display], asciis[k.ascii] = true, true, true
+	}
+
+	// Stored passwords depend on these.
+	assert.Equal(t, ArrowUpMarker, KeyMarker(tcell.KeyUp))
+	assert.Equal(t, "\x00\x05", KeyMarker(tcell.KeyF1))
+	assert.Equal(t, "\x00\x10", KeyMarker(tcell.KeyF12))
+	assert.Equal(t, "\x00\x14", KeyMarker(tcell.KeyPgDn))
+	assert.Empty(t, KeyMarker(tcell.KeyInsert))
+
+	assert.Equal(t, '⑤', KeyDisplay(tcell.KeyF5))
+	assert.Equal(t, '5', KeyDisplayASCII(tcell.KeyF5))
+	assert.Equal(t, '⇱', KeyDisplay(tcell.KeyHome))
+	assert.Equal(t, ' ', KeyDisplay(tcell.KeyInsert))
+	assert.True(t, IsMarkerKey(tcell.KeyEnd))
+	assert.False(t, IsMarkerKey(tcell.KeyRune))
+}
+
+func TestSecureBuffer_SpecialKeys(t *testing.T) {
+	sb := NewSecureBuffer()
+	defer sb.Destroy()
+	sb.AppendRune('a')
+	sb.AppendString(KeyMarker(tcell.KeyF12))
+	sb.AppendString(KeyMarker(tcell.KeyHome))
+	assert.Equal(t, 3, sb.VisualLen())
+
+	require.True(t, sb.Backspace())
+	assert.Equal(t, "a"+KeyMarker(tcell.KeyF12), string(sb.Bytes()))
+	require.True(t, sb.Backspace())
+	assert.Equal(t, "a", string(sb.Bytes()))
+}
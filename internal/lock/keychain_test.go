@@ -0,0 +1,104 @@
+//go:build !darwin
+
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/xdg"
+)
+
+// fakeSecretTool is a secret-tool keeping items in files of $STORE.
+const fakeSecretTool = `#!/bin/sh
+cmd=$1; shift
+[ "$1" = "--label" ] && shift 2
+item="$STORE/$2-$4"
+case $cmd in
+store) cat > "$item" ;;
+lookup) [ -f "$item" ] || exit 1; cat "$item" ;;
+clear) [ -f "$item" ] || exit 1; rm "$item" ;;
+esac
+`
+
+func TestKeychainStore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	bin, store := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "secret-tool"), []byte(fakeSecretTool), 0700))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STORE", store)
+
+	used, err := SavePassword([]byte("hunter2"), nil, StoreKeychain)
+	require.NoError(t, err)
+	assert.Equal(t, StoreKeychain, used)
+	assert.Equal(t, StoreKeychain, PasswordStore())
+
+	path, err := xdg.PasswordFile()
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "argon2", "the file only points to the keychain")
+
+	ok, err := CheckPassword([]byte("hunter2"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Back to the file: the keychain item goes away
+	used, err = SavePassword([]byte("hunter3"), nil, StoreFile)
+	require.NoError(t, err)
+	assert.Equal(t, StoreFile, used)
+	items, err := os.ReadDir(store)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	_, err = SavePassword([]byte("hunter2"), nil, StoreKeychain)
+	require.NoError(t, err)
+	require.NoError(t, RemovePassword())
+	assert.False(t, PasswordExists())
+	items, err = os.ReadDir(store)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestKeychainFallback(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("PATH", t.TempDir()) // No secret-tool
+
+	used, err := SavePassword([]byte("hunter2"), nil, StoreKeychain)
+	require.NoError(t, err)
+	assert.Equal(t, StoreFile, used)
+
+	ok, err := CheckPassword([]byte("hunter2"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestAuthLoadKeychain(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	bin, store := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "secret-tool"), []byte(fakeSecretTool), 0700))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STORE", store)
+	_, err := SavePassword([]byte("hunter2"), nil, StoreKeychain)
+	require.NoError(t, err)
+
+	passwords, err := AuthFile.Load()
+	require.NoError(t, err)
+
+	// The keychain goes out of reach: the loaded hashes still check.
+	t.Setenv("PATH", t.TempDir())
+	match, err := passwords.Verify([]byte("hunter2"))
+	require.NoError(t, err)
+	assert.Equal(t, MatchPassword, match)
+	match, err = passwords.Verify([]byte("hunter3"))
+	require.NoError(t, err)
+	assert.Equal(t, MatchNone, match)
+
+	// Nor can a lock start
+	_, err = AuthFile.Load()
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package lock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Panic Keys
+// A user who suspects someone watched them type can leave a trap: a
+// sequence of special keys pressed on the lock screen ("F12 F12 F12" by
+// default) silently fires the panic hook. Nothing changes on screen: the
+// keys of the sequence are held back from the password as they come, and
+// dropped once it completes.
+
+// DefaultPanicKeys is the panic sequence when the configuration sets a
+// panic hook without keys.
+const DefaultPanicKeys = "F12 F12 F12"
+
+// PanicKeyGap is the longest pause between two keys of the sequence.
+const PanicKeyGap = 2 * time.Second
+
+// KeySequence is a sequence of special keys.
+type KeySequence []tcell.Key
+
+// ParseKeySequence parses space-separated special key names, as shown by
+// tmux and tcell: Up, Down, Left, Right, F1-F12, Home, End, PgUp, PgDn.
+func ParseKeySequence(s string) (KeySequence, error) {
+	var seq KeySequence
+	for _, name := range strings.Fields(s) {
+		key, ok := keyByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q (want arrows, F1-F12, Home, End, PgUp or PgDn)", name)
+		}
+		seq = append(seq, key)
+	}
+	if len(seq) == 0 {
+		return nil, fmt.Errorf("empty key sequence")
+	}
+	return seq, nil
+}
+
+func keyByName(name string) (tcell.Key, bool) {
+	for _, k := range specialKeys {
+		if strings.EqualFold(tcell.KeyNames[k.key], name) {
+			return k.key, true
+		}
+	}
+	return 0, false
+}
+
+func (seq KeySequence) String() string {
+	names := make([]string, len(seq))
+	for i, key := range seq {
+		names[i] = tcell.KeyNames[key]
+	}
+	return strings.Join(names, " ")
+}
+
+// KeyPress is a special key pressed at a given time.
+type KeyPress struct {
+	Key tcell.Key
+	At  time.Time
+}
+
+// Detection is what a key fed to a SequenceDetector turned out to be.
+type Detection struct {
+	Released []KeyPress // Keys held back earlier that aren't the sequence after all, in order
+	Held     bool       // The key is part of the sequence so far: kept out of the password
+	Fired    bool       // The key completed the sequence
+}
+
+// SequenceDetector spots a key sequence in the keys fed to it, each key
+// within PanicKeyGap of the previous one. The keys of a sequence in
+// progress are held back, so that a completed sequence leaves no trace in
+// the password; they are released once they can't be part of it.
+type SequenceDetector struct {
+	Keys KeySequence
+
+	fail    []int      // KMP failure function of Keys
+	matched int        // Keys of the sequence matched so far
+	held    []KeyPress // The matched keys
+	last    time.Time  // Time of the last matched key
+}
+
+// Feed records a key pressed at the given time.
+func (d *SequenceDetector) Feed(key tcell.Key, at time.Time) Detection {
+	if len(d.Keys) == 0 {
+		return Detection{}
+	}
+	if len(d.fail) != len(d.Keys) {
+		d.fail = failure(d.Keys)
+	}
+
+	var det Detection
+	if d.matched > 0 && at.Sub(d.last) > PanicKeyGap {
+		det.Released = d.Reset()
+	}
+	// Fall back to the longest matched suffix that is still a prefix of
+	// the sequence, releasing the keys before it.
+	for d.matched > 0 && key != d.Keys[d.matched] {
+		next := d.fail[d.matched-1]
+		drop := d.matched - next
+		det.Released = append(det.Released, d.held[:drop]...)
+		d.held = append(d.held[:0], d.held[drop:]...)
+		d.matched = next
+	}
+	if key != d.Keys[d.matched] {
+		return det
+	}
+
+	det.Held = true
+	d.matched++
+	d.held = append(d.held, KeyPress{Key: key, At: at})
+	d.last = at
+	if d.matched == len(d.Keys) {
+		det.Fired = true
+		d.matched, d.held = 0, d.held[:0]
+	}
+	return det
+}
+
+// Reset forgets the sequence in progress and returns the keys it held.
+func (d *SequenceDetector) Reset() []KeyPress {
+	held := append([]KeyPress(nil), d.held...)
+	d.matched, d.held = 0, d.held[:0]
+	return held
+}
+
+// failure returns the KMP failure function of keys: for each prefix, the
+// length of its longest proper suffix that is also a prefix of keys.
+func failure(keys KeySequence) []int {
+	fail := make([]int, len(keys))
+	k := 0
+	for i := 1; i < len(keys); i++ {
+		for k > 0 && keys[i] != keys[k] {
+			k = fail[k-1]
+		}
+		if keys[i] == keys[k] {
+			k++
+		}
+		fail[i] = k
+	}
+	return fail
+}
@@ -0,0 +1,129 @@
+package lock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeySequence(t *testing.T) {
+	seq, err := ParseKeySequence(DefaultPanicKeys)
+	require.NoError(t, err)
+	assert.Equal(t, KeySequence{tcell.KeyF12, tcell.KeyF12, tcell.KeyF12}, seq)
+
+	seq, err = ParseKeySequence(" up  pgdn f1 ")
+	require.NoError(t, err)
+	assert.Equal(t, "Up PgDn F1", seq.String())
+
+	_, err = ParseKeySequence("F12 a")
+	assert.ErrorContains(t, err, `unknown key "a"`)
+	_, err = ParseKeySequence("Enter")
+	assert.Error(t, err, "not a password key")
+	_, err = ParseKeySequence("  ")
+	assert.Error(t, err)
+}
+
+func TestSequenceDetector(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	press := func(key tcell.Key, ms int) KeyPress { return KeyPress{Key: key, At: at(ms)} }
+	f1, f2, f12 := tcell.KeyF1, tcell.KeyF2, tcell.KeyF12
+
+	tests := []struct {
+		name    string
+		keys    KeySequence
+		presses []KeyPress
+		fired   []int      // Indexes of the presses completing the sequence
+		typed   []KeyPress // Presses that reach the password, in order
+	}{
+		{
+			name:    "repeated key",
+			keys:    KeySequence{f12, f12, f12},
+			presses: []KeyPress{press(f12, 0), press(f12, 300), press(f12, 600), press(f12, 900)},
+			fired:   []int{2}, // Starts over after a match
+		},
+		{
+			name:    "interrupted",
+			keys:    KeySequence{f12, f12},
+			presses: []KeyPress{press(f12, 0), press(tcell.KeyRune, 100), press(f12, 200), press(f12, 300)},
+			fired:   []int{3},
+			typed:   []KeyPress{press(f12, 0), press(tcell.KeyRune, 100)},
+		},
+		{
+			name:    "restart on the first key",
+			keys:    KeySequence{tcell.KeyUp, tcell.KeyDown},
+			presses: []KeyPress{press(tcell.KeyUp, 0), press(tcell.KeyUp, 100), press(tcell.KeyDown, 200)},
+			fired:   []int{2},
+			typed:   []KeyPress{press(tcell.KeyUp, 0)},
+		},
+		{
+			name:    "overlapping prefix",
+			keys:    KeySequence{f1, f1, f2},
+			presses: []KeyPress{press(f1, 0), press(f1, 100), press(f1, 200), press(f2, 300)},
+			fired:   []int{3},
+			typed:   []KeyPress{press(f1, 0)},
+		},
+		{
+			name:    "overlapping longer prefix",
+			keys:    KeySequence{f1, f2, f1, f1},
+			presses: []KeyPress{press(f1, 0), press(f2, 100), press(f1, 200), press(f2, 300), press(f1, 400), press(f1, 500)},
+			fired:   []int{5},
+			typed:   []KeyPress{press(f1, 0), press(f2, 100)},
+		},
+		{
+			name:    "broken prefix",
+			keys:    KeySequence{f1, f1, f2},
+			presses: []KeyPress{press(f1, 0), press(f1, 100), press(f12, 200)},
+			typed:   []KeyPress{press(f1, 0), press(f1, 100), press(f12, 200)},
+		},
+		{
+			name:    "too slow",
+			keys:    KeySequence{f12, f12},
+			presses: []KeyPress{press(f12, 0), press(f12, 3000), press(f12, 3500)},
+			fired:   []int{2}, // Over PanicKeyGap: the first key of a new try
+			typed:   []KeyPress{press(f12, 0)},
+		},
+		{
+			name:    "timed out mid-sequence",
+			keys:    KeySequence{f1, f1, f2},
+			presses: []KeyPress{press(f1, 0), press(f1, 100), press(f2, 2200)},
+			typed:   []KeyPress{press(f1, 0), press(f1, 100), press(f2, 2200)},
+		},
+		{
+			name:    "no keys",
+			presses: []KeyPress{press(f12, 0)},
+			typed:   []KeyPress{press(f12, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &SequenceDetector{Keys: tt.keys}
+			var fired []int
+			var typed []KeyPress
+			for i, p := range tt.presses {
+				det := d.Feed(p.Key, p.At)
+				typed = append(typed, det.Released...)
+				if !det.Held {
+					typed = append(typed, p)
+				}
+				if det.Fired {
+					assert.True(t, det.Held, "a completing key is held")
+					fired = append(fired, i)
+				}
+			}
+			assert.Equal(t, tt.fired, fired)
+			assert.Equal(t, tt.typed, typed)
+		})
+	}
+}
+
+func TestSequenceDetector_Reset(t *testing.T) {
+	d := &SequenceDetector{Keys: KeySequence{tcell.KeyF12, tcell.KeyF12}}
+	at := time.Unix(1_700_000_000, 0)
+	assert.True(t, d.Feed(tcell.KeyF12, at).Held)
+	assert.Equal(t, []KeyPress{{Key: tcell.KeyF12, At: at}}, d.Reset())
+	assert.False(t, d.Feed(tcell.KeyF12, at).Fired, "the held key is forgotten")
+}
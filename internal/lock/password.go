@@ -7,10 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"golang.org/x/crypto/argon2"
 
+	"yule-log/internal/fsutil"
+	"yule-log/internal/keyring"
 	"yule-log/internal/xdg"
 )
 
@@ -22,16 +25,36 @@ const (
 	argon2Threads = 1         // parallelism
 	argon2KeyLen  = 32        // output length
 	saltLen       = 16        // salt length
+
+	// Upper bounds on the parameters of a stored hash, at interactive
+	// scale, so that a corrupted file can't exhaust memory or hang the
+	// lock screen on Enter
+	maxArgon2Memory = 1024 * 1024 // 1 GiB
+	maxArgon2Time   = 10
+	maxArgon2KeyLen = 64
 )
 
 var (
 	ErrNoPassword     = errors.New("no password configured")
 	ErrInvalidFormat  = errors.New("invalid password file format")
 	ErrPasswordExists = errors.New("password already configured")
+	// ErrKeychainPassword is returned for a password file that only points
+	// to the keychain where it is needed whole, e.g. in a bundle.
+	ErrKeychainPassword = errors.New("the password hash is kept in the keychain")
 )
 
-// ---- Password Storage Format
+// ---- Password Hash Format
 // Format: $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+// The file around it is versioned, see format.go. New hashes use the
+// constants above; verification uses the parameters of the stored hash,
+// so hashes from older versions or other tools still verify.
+
+// argon2Params are the cost parameters of an Argon2id hash.
+type argon2Params struct {
+	memory  uint32 // KiB
+	time    uint32
+	threads uint8
+}
 
 // HashPassword creates an Argon2id hash of the password.
 // Returns the hash in PHC string format.
@@ -55,82 +78,281 @@ func HashPassword(password []byte) (string, error) {
 // VerifyPassword checks if the password matches the stored hash.
 // Uses constant-time comparison to prevent timing attacks.
 func VerifyPassword(password []byte, encoded string) (bool, error) {
-	salt, storedHash, err := parseEncodedHash(encoded)
+	match, err := verifyHash(password, encoded)
+	return match == 1, err
+}
+
+// verifyHash returns 1 if the password matches the stored hash, 0
+// otherwise, for constant-time combination with subtle.
+func verifyHash(password []byte, encoded string) (int, error) {
+	params, salt, storedHash, err := parseEncodedHash(encoded)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
 
-	computedHash := argon2.IDKey(password, salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	computedHash := argon2.IDKey(password, salt, params.time, params.memory, params.threads, uint32(len(storedHash)))
 
-	return subtle.ConstantTimeCompare(storedHash, computedHash) == 1, nil
+	return subtle.ConstantTimeCompare(storedHash, computedHash), nil
 }
 
-// parseEncodedHash extracts salt and hash from PHC string format.
-func parseEncodedHash(encoded string) ([]byte, []byte, error) {
+// parseEncodedHash extracts the parameters, salt and hash from PHC string
+// format.
+func parseEncodedHash(encoded string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
 	parts := strings.Split(encoded, "$")
 	if len(parts) != 6 {
-		return nil, nil, ErrInvalidFormat
+		return params, nil, nil, ErrInvalidFormat
 	}
 
 	if parts[1] != "argon2id" {
-		return nil, nil, ErrInvalidFormat
+		return params, nil, nil, ErrInvalidFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("%w: version %q", ErrInvalidFormat, parts[2])
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("%w: unsupported argon2 version %d", ErrInvalidFormat, version)
+	}
+
+	params, err := parseArgon2Params(parts[3])
+	if err != nil {
+		return params, nil, nil, err
 	}
 
 	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
 	if err != nil {
-		return nil, nil, fmt.Errorf("decoding salt: %w", err)
+		return params, nil, nil, fmt.Errorf("decoding salt: %w", err)
 	}
 
 	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
 	if err != nil {
-		return nil, nil, fmt.Errorf("decoding hash: %w", err)
+		return params, nil, nil, fmt.Errorf("decoding hash: %w", err)
+	}
+	if len(hash) == 0 {
+		return params, nil, nil, fmt.Errorf("%w: empty hash", ErrInvalidFormat)
+	}
+	if len(hash) > maxArgon2KeyLen {
+		return params, nil, nil, fmt.Errorf("%w: hash length %d exceeds %d", ErrInvalidFormat, len(hash), maxArgon2KeyLen)
 	}
 
-	return salt, hash, nil
+	return params, salt, hash, nil
+}
+
+// parseArgon2Params parses the "m=19456,t=2,p=1" parameter segment.
+// Other tools may order the keys differently; all three are required.
+func parseArgon2Params(s string) (argon2Params, error) {
+	var params argon2Params
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || seen[key] {
+			return params, fmt.Errorf("%w: parameters %q", ErrInvalidFormat, s)
+		}
+		seen[key] = true
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || n == 0 {
+			return params, fmt.Errorf("%w: parameter %q", ErrInvalidFormat, field)
+		}
+		switch key {
+		case "m":
+			params.memory = uint32(n)
+		case "t":
+			params.time = uint32(n)
+		case "p":
+			if n > 255 {
+				return params, fmt.Errorf("%w: parameter %q", ErrInvalidFormat, field)
+			}
+			params.threads = uint8(n)
+		default:
+			return params, fmt.Errorf("%w: unknown parameter %q", ErrInvalidFormat, field)
+		}
+	}
+	if !seen["m"] || !seen["t"] || !seen["p"] {
+		return params, fmt.Errorf("%w: parameters %q", ErrInvalidFormat, s)
+	}
+	if params.memory > maxArgon2Memory {
+		return params, fmt.Errorf("%w: memory parameter %d KiB exceeds %d", ErrInvalidFormat, params.memory, maxArgon2Memory)
+	}
+	if params.time > maxArgon2Time {
+		return params, fmt.Errorf("%w: time parameter %d exceeds %d", ErrInvalidFormat, params.time, maxArgon2Time)
+	}
+	return params, nil
+}
+
+// ---- Password Storage
+// The hash lives in the password file of the config directory, or in the
+// OS keychain ([password] storage = "keychain"), where it is out of reach of
+// anything that reads files. With the keychain, the password file only
+// points to it, so PasswordExists and the lock state stay file based.
+
+// Store names where the password hash is kept.
+type Store string
+
+const (
+	StoreFile     Store = "file"     // The password file
+	StoreKeychain Store = "keychain" // The OS keychain, see keyring
+)
+
+// keychainAccount is the keychain account of the password item.
+const keychainAccount = "password"
+
+// ParseStore parses a [password] storage value. Empty selects the file.
+func ParseStore(s string) (Store, error) {
+	switch Store(s) {
+	case "", StoreFile:
+		return StoreFile, nil
+	case StoreKeychain:
+		return StoreKeychain, nil
+	default:
+		return "", fmt.Errorf("unknown password storage %q (want %s or %s)", s, StoreFile, StoreKeychain)
+	}
 }
 
 // ---- Password File Operations
 
-// SavePassword stores the password hash to the config file.
-func SavePassword(password []byte) error {
-	path, err := xdg.PasswordFile()
+// SavePassword stores the password hash, with the typing rhythm profile
+// when not nil, in store. Without a keychain on this system the file is
+// used instead; the store actually used is returned. A duress password
+// already set is kept, and must differ from the new password.
+func SavePassword(password []byte, rhythm Rhythm, store Store) (Store, error) {
+	hash, err := HashPassword(password)
 	if err != nil {
-		return fmt.Errorf("getting password file path: %w", err)
+		return "", fmt.Errorf("hashing password: %w", err)
 	}
 
-	hash, err := HashPassword(password)
+	file := &PasswordFile{Version: PasswordFileVersion, Hash: hash, Rhythm: rhythm}
+	if previous, err := loadPasswordFile(); err == nil && previous.Duress != "" {
+		same, err := VerifyPassword(password, previous.Duress)
+		if err != nil {
+			return "", fmt.Errorf("checking duress password: %w", err)
+		}
+		if same {
+			return "", ErrDuressMatchesPassword
+		}
+		file.Duress = previous.Duress
+	}
+	return writePasswordFile(file, store)
+}
+
+// writePasswordFile writes file to store, falling back to the password
+// file without a keychain; the store actually used is returned.
+func writePasswordFile(file *PasswordFile, store Store) (Store, error) {
+	path, err := xdg.PasswordFile()
 	if err != nil {
-		return fmt.Errorf("hashing password: %w", err)
+		return "", fmt.Errorf("getting password file path: %w", err)
 	}
 
-	if err := os.WriteFile(path, []byte(hash+"\n"), 0600); err != nil {
-		return fmt.Errorf("writing password file: %w", err)
+	previous := PasswordStore()
+	if store == StoreKeychain {
+		err := keyring.Set(keychainAccount, file.Encode())
+		switch {
+		case err == nil:
+			file = &PasswordFile{Version: PasswordFileVersion, Store: StoreKeychain}
+		case errors.Is(err, keyring.ErrUnsupported):
+			store = StoreFile
+		default:
+			return "", fmt.Errorf("storing password in the keychain: %w", err)
+		}
 	}
 
-	return nil
+	if err := fsutil.WriteFile(path, file.Encode(), 0600); err != nil {
+		return "", fmt.Errorf("writing password file: %w", err)
+	}
+	if previous == StoreKeychain && store != StoreKeychain {
+		// Best effort: the file no longer points to the old item.
+		_ = keyring.Delete(keychainAccount)
+	}
+
+	return store, nil
+}
+
+// PasswordStore returns where the configured password hash is kept,
+// StoreFile when there is none.
+func PasswordStore() Store {
+	path, err := xdg.PasswordFile()
+	if err != nil {
+		return StoreFile
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return StoreFile
+	}
+	file, _, err := ParsePasswordFile(data)
+	if err != nil || file.Store == "" {
+		return StoreFile
+	}
+	return file.Store
 }
 
 // LoadPasswordHash reads the stored password hash from the config file.
+// Files written by older versions are upgraded in place.
 func LoadPasswordHash() (string, error) {
+	file, err := loadPasswordFile()
+	if err != nil {
+		return "", err
+	}
+	return file.Hash, nil
+}
+
+// LoadRhythm reads the stored typing rhythm profile, nil when none was
+// recorded.
+func LoadRhythm() (Rhythm, error) {
+	file, err := loadPasswordFile()
+	if err != nil {
+		return nil, err
+	}
+	return file.Rhythm, nil
+}
+
+func loadPasswordFile() (*PasswordFile, error) {
 	path, err := xdg.PasswordFile()
 	if err != nil {
-		return "", fmt.Errorf("getting password file path: %w", err)
+		return nil, fmt.Errorf("getting password file path: %w", err)
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", ErrNoPassword
+			return nil, ErrNoPassword
 		}
-		return "", fmt.Errorf("reading password file: %w", err)
+		return nil, fmt.Errorf("reading password file: %w", err)
 	}
 
-	hash := strings.TrimSpace(string(data))
-	if hash == "" {
-		return "", ErrInvalidFormat
+	file, migrated, err := ParsePasswordFile(data)
+	if err != nil {
+		return nil, err
+	}
+	if migrated {
+		// Best effort: the hash is usable even if the upgrade can't be saved.
+		_ = fsutil.WriteFile(path, file.Encode(), 0600)
 	}
+	if file.Store == StoreKeychain {
+		return loadKeychainPassword()
+	}
+	return file, nil
+}
 
-	return hash, nil
+// loadKeychainPassword reads the password file kept in the keychain.
+func loadKeychainPassword() (*PasswordFile, error) {
+	data, err := keyring.Get(keychainAccount)
+	if err != nil {
+		return nil, fmt.Errorf("reading password from the keychain: %w", err)
+	}
+	defer ClearBytes(data)
+
+	file, migrated, err := ParsePasswordFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("keychain password: %w", err)
+	}
+	if file.Store != "" {
+		return nil, fmt.Errorf("keychain password: %w", ErrInvalidFormat)
+	}
+	if migrated {
+		_ = keyring.Set(keychainAccount, file.Encode())
+	}
+	return file, nil
 }
 
 // PasswordExists checks if a password has been configured.
@@ -151,6 +373,11 @@ func RemovePassword() error {
 		return fmt.Errorf("getting password file path: %w", err)
 	}
 
+	if PasswordStore() == StoreKeychain {
+		if err := keyring.Delete(keychainAccount); err != nil && !errors.Is(err, keyring.ErrUnsupported) {
+			return fmt.Errorf("removing password from the keychain: %w", err)
+		}
+	}
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("removing password file: %w", err)
 	}
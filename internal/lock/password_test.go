@@ -1,11 +1,14 @@
 package lock
 
 import (
+	"encoding/base64"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
 )
 
 func TestHashAndVerifyPassword(t *testing.T) {
@@ -106,6 +109,36 @@ func TestVerifyPassword_InvalidFormat(t *testing.T) {
 			encoded: "$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$!!!invalid!!!",
 			wantErr: true,
 		},
+		{
+			name:    "unsupported version",
+			encoded: "$argon2id$v=16$m=19456,t=2,p=1$c2FsdA$aGFzaA",
+			wantErr: true,
+		},
+		{
+			name:    "missing parameter",
+			encoded: "$argon2id$v=19$m=19456,t=2$c2FsdA$aGFzaA",
+			wantErr: true,
+		},
+		{
+			name:    "zero parameter",
+			encoded: "$argon2id$v=19$m=19456,t=0,p=1$c2FsdA$aGFzaA",
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			encoded: "$argon2id$v=19$m=19456,t=2,p=1,x=3$c2FsdA$aGFzaA",
+			wantErr: true,
+		},
+		{
+			name:    "memory too large",
+			encoded: "$argon2id$v=19$m=99999999,t=2,p=1$c2FsdA$aGFzaA",
+			wantErr: true,
+		},
+		{
+			name:    "empty hash",
+			encoded: "$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$",
+			wantErr: true,
+		},
 		{
 			name:    "empty string",
 			encoded: "",
@@ -151,3 +184,48 @@ func TestHashPassword_Format(t *testing.T) {
 	assert.Equal(t, "v=19", parts[2], "version")
 	assert.Equal(t, "m=19456,t=2,p=1", parts[3], "params")
 }
+
+func TestParseEncodedHash_Bounds(t *testing.T) {
+	hash := func(n int) string { return base64.RawStdEncoding.EncodeToString(make([]byte, n)) }
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{"memory at bound", "$argon2id$v=19$m=1048576,t=2,p=1$c2FsdA$" + hash(32), false},
+		{"memory over bound", "$argon2id$v=19$m=1048577,t=2,p=1$c2FsdA$" + hash(32), true},
+		{"time at bound", "$argon2id$v=19$m=19456,t=10,p=1$c2FsdA$" + hash(32), false},
+		{"time over bound", "$argon2id$v=19$m=19456,t=11,p=1$c2FsdA$" + hash(32), true},
+		{"key length at bound", "$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$" + hash(64), false},
+		{"key length over bound", "$argon2id$v=19$m=19456,t=2,p=1$c2FsdA$" + hash(65), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := parseEncodedHash(tt.encoded)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidFormat)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVerifyPassword_StoredParameters(t *testing.T) {
+	password := []byte("correct horse")
+	salt := []byte("0123456789abcdef")
+
+	// Parameters other than ours, in another key order, with a longer key
+	key := argon2.IDKey(password, salt, 3, 8*1024, 2, 64)
+	encoded := fmt.Sprintf("$argon2id$v=19$t=3,p=2,m=%d$%s$%s", 8*1024,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+
+	ok, err := VerifyPassword(password, encoded)
+	require.NoError(t, err)
+	assert.True(t, ok, "hash with stored parameters should verify")
+
+	ok, err = VerifyPassword([]byte("wrong"), encoded)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,131 @@
+package lock
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// ---- Session Phrase
+// Every lock picks a random phrase, shown when the lock starts and again
+// while the password is typed. A program imitating the lock screen to phish
+// the password can't know it: if the phrase at unlock isn't the one seen at
+// lock time, don't type the password.
+//
+// The phrase is kept in the lock state sealed with AES-GCM under a key
+// derived from the password hash. This keeps it out of plain view (backups,
+// `cat`), not away from code running as the same user, which can read the
+// hash as well. With system authentication there is no hash: the phrase is
+// only kept from being read at a glance.
+
+// phraseWords are short, distinct words for session phrases.
+var phraseWords = strings.Fields(`
+	acorn amber anchor antler apple arrow aspen aurora badger banjo basil
+	beacon birch bison bramble breeze brook cabin candle canoe cedar cinder
+	clover comet copper coral cricket crystal cypress dawn delta dune eagle
+	ember falcon fern fjord flint forest fox frost garnet geyser ginger
+	glacier granite harbor hazel heron hickory holly honey icicle iris
+	ivory jasper juniper kettle kiwi lantern larch lemon lilac lynx maple
+	marble meadow mesa mint moose moss nectar nutmeg oak oasis olive onyx
+	orchid otter owl pebble pepper pine plum pollen poppy prairie quartz
+	quill raven reef ridge river robin saffron sage sequoia spruce starling
+	stone summit swan thistle thyme tide timber topaz tulip tundra velvet
+	walnut willow wren yarrow yew zephyr
+`)
+
+// phraseLength is the number of words in a session phrase.
+const phraseLength = 3
+
+// NewPhrase returns a random session phrase.
+func NewPhrase() (string, error) {
+	words := make([]string, phraseLength)
+	for i := range words {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(phraseWords))))
+		if err != nil {
+			return "", fmt.Errorf("generating session phrase: %w", err)
+		}
+		words[i] = phraseWords[n.Int64()]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// phraseKey derives the phrase sealing key from the password hash.
+func phraseKey(passwordHash string) []byte {
+	sum := sha256.Sum256([]byte("yule-log session phrase\x00" + passwordHash))
+	return sum[:]
+}
+
+func phraseAEAD(passwordHash string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(phraseKey(passwordHash))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealPhrase encrypts phrase for the lock state.
+func sealPhrase(phrase, passwordHash string) (string, error) {
+	aead, err := phraseAEAD(passwordHash)
+	if err != nil {
+		return "", fmt.Errorf("sealing session phrase: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("sealing session phrase: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(phrase), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openPhrase decrypts a phrase sealed by sealPhrase.
+func openPhrase(sealed, passwordHash string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("decoding session phrase: %w", err)
+	}
+	aead, err := phraseAEAD(passwordHash)
+	if err != nil {
+		return "", fmt.Errorf("opening session phrase: %w", err)
+	}
+	if len(data) < aead.NonceSize() {
+		return "", errors.New("session phrase is truncated")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	phrase, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("opening session phrase: %w", err)
+	}
+	return string(phrase), nil
+}
+
+// Phrase returns the session phrase of the current lock.
+func Phrase() (string, error) {
+	state, err := LoadState()
+	if err != nil {
+		return "", err
+	}
+	if state.Phrase == "" {
+		return "", errors.New("lock has no session phrase")
+	}
+	hash, err := phraseSecret(state.Auth)
+	if err != nil {
+		return "", err
+	}
+	return openPhrase(state.Phrase, hash)
+}
+
+// phraseSecret returns what the phrase key derives from for the auth
+// backend of the lock.
+func phraseSecret(auth Auth) (string, error) {
+	if auth == AuthSystem {
+		return "", nil
+	}
+	return LoadPasswordHash()
+}
@@ -0,0 +1,73 @@
+package lock
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/xdg"
+)
+
+func TestNewPhrase(t *testing.T) {
+	phrase, err := NewPhrase()
+	require.NoError(t, err)
+	words := strings.Fields(phrase)
+	require.Len(t, words, phraseLength)
+	for _, w := range words {
+		assert.True(t, slices.Contains(phraseWords, w), w)
+	}
+}
+
+func TestSealPhrase(t *testing.T) {
+	sealed, err := sealPhrase("holly ember spruce", testPHC)
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "holly")
+
+	phrase, err := openPhrase(sealed, testPHC)
+	require.NoError(t, err)
+	assert.Equal(t, "holly ember spruce", phrase)
+
+	_, err = openPhrase(sealed, testPHC+"x")
+	assert.Error(t, err, "another password hash can't open it")
+	_, err = openPhrase("AAAA", testPHC)
+	assert.Error(t, err)
+	_, err = openPhrase("not base64!", testPHC)
+	assert.Error(t, err)
+}
+
+func TestLockPhrase(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	path, err := xdg.PasswordFile()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(testPHC+"\n"), 0600))
+
+	phrase, err := Lock("", 0, AuthFile)
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(phrase), phraseLength)
+
+	statePath, err := xdg.LockStateFile()
+	require.NoError(t, err)
+	data, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), phrase, "stored sealed")
+
+	got, err := Phrase()
+	require.NoError(t, err)
+	assert.Equal(t, phrase, got)
+}
+
+func TestLockPhraseSystemAuth(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	phrase, err := Lock("", 0, AuthSystem)
+	require.NoError(t, err, "no password file needed")
+	got, err := Phrase()
+	require.NoError(t, err)
+	assert.Equal(t, phrase, got)
+}
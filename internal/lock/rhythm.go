@@ -0,0 +1,143 @@
+package lock
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---- Typing Rhythm (experimental)
+// An optional second factor: the intervals between the keystrokes of the
+// password, recorded by set-password and compared at unlock. Rhythms are
+// normalized by their total duration, so typing uniformly faster or slower
+// still matches; what counts is which keys come quickly after each other.
+//
+// The profile is stored in the clear next to the hash, which gives away the
+// number of keystrokes to anyone able to read the password file.
+
+// DefaultRhythmTolerance is the largest RhythmDistance accepted at unlock.
+const DefaultRhythmTolerance = 0.35
+
+// Rhythm is the time between consecutive keystrokes of a password.
+type Rhythm []time.Duration
+
+// RhythmRecorder records keystroke times as a password is typed.
+// A nil recorder records nothing.
+type RhythmRecorder struct {
+	times []time.Time
+}
+
+// Key records a keystroke added to the password at t.
+func (r *RhythmRecorder) Key(t time.Time) {
+	if r != nil {
+		r.times = append(r.times, t)
+	}
+}
+
+// Backspace forgets the last keystroke.
+func (r *RhythmRecorder) Backspace() {
+	if r != nil && len(r.times) > 0 {
+		r.times = r.times[:len(r.times)-1]
+	}
+}
+
+// Reset forgets every keystroke.
+func (r *RhythmRecorder) Reset() {
+	if r != nil {
+		r.times = r.times[:0]
+	}
+}
+
+// Rhythm returns the intervals between the recorded keystrokes.
+func (r *RhythmRecorder) Rhythm() Rhythm {
+	if r == nil || len(r.times) < 2 {
+		return Rhythm{}
+	}
+	rhythm := make(Rhythm, len(r.times)-1)
+	for i := range rhythm {
+		rhythm[i] = max(r.times[i+1].Sub(r.times[i]), 0)
+	}
+	return rhythm
+}
+
+// AverageRhythm averages two recordings of the same password, e.g. the
+// password and its confirmation.
+func AverageRhythm(a, b Rhythm) (Rhythm, error) {
+	if len(a) != len(b) {
+		return nil, fmt.Errorf("rhythm recordings have %d and %d intervals", len(a), len(b))
+	}
+	avg := make(Rhythm, len(a))
+	for i := range a {
+		avg[i] = (a[i] + b[i]) / 2
+	}
+	return avg, nil
+}
+
+// normalized returns each interval as a share of the total duration.
+// Intervals typed too fast to measure count as equal shares.
+func (r Rhythm) normalized() []float64 {
+	var total time.Duration
+	for _, d := range r {
+		total += d
+	}
+	shares := make([]float64, len(r))
+	for i, d := range r {
+		if total <= 0 {
+			shares[i] = 1 / float64(len(r))
+			continue
+		}
+		shares[i] = float64(d) / float64(total)
+	}
+	return shares
+}
+
+// RhythmDistance compares two rhythms, from 0 (same rhythm, whatever the
+// speed) to 1 (all the time spent on different keystrokes). Rhythms of
+// different lengths are at distance 1.
+func RhythmDistance(a, b Rhythm) float64 {
+	if len(a) != len(b) {
+		return 1
+	}
+	if len(a) == 0 {
+		return 0
+	}
+	na, nb := a.normalized(), b.normalized()
+	sum := 0.0
+	for i := range na {
+		sum += math.Abs(na[i] - nb[i])
+	}
+	return sum / 2
+}
+
+// RhythmMatches reports whether sample is within tolerance of profile.
+func RhythmMatches(profile, sample Rhythm, tolerance float64) bool {
+	return RhythmDistance(profile, sample) <= tolerance
+}
+
+// String encodes the rhythm as comma-separated milliseconds.
+func (r Rhythm) String() string {
+	ms := make([]string, len(r))
+	for i, d := range r {
+		ms[i] = strconv.FormatInt(d.Milliseconds(), 10)
+	}
+	return strings.Join(ms, ",")
+}
+
+// ParseRhythm decodes a rhythm encoded by Rhythm.String.
+func ParseRhythm(s string) (Rhythm, error) {
+	if s == "" {
+		return Rhythm{}, nil
+	}
+	fields := strings.Split(s, ",")
+	rhythm := make(Rhythm, len(fields))
+	for i, f := range fields {
+		ms, err := strconv.ParseInt(f, 10, 64)
+		if err != nil || ms < 0 {
+			return nil, fmt.Errorf("invalid rhythm interval %q", f)
+		}
+		rhythm[i] = time.Duration(ms) * time.Millisecond
+	}
+	return rhythm, nil
+}
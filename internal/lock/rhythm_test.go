@@ -0,0 +1,75 @@
+package lock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ms(values ...int) Rhythm {
+	r := make(Rhythm, len(values))
+	for i, v := range values {
+		r[i] = time.Duration(v) * time.Millisecond
+	}
+	return r
+}
+
+func TestRhythmRecorder(t *testing.T) {
+	start := time.Now()
+	var r RhythmRecorder
+	r.Key(start)
+	r.Key(start.Add(100 * time.Millisecond))
+	r.Key(start.Add(300 * time.Millisecond))
+	r.Backspace()
+	r.Key(start.Add(450 * time.Millisecond))
+	assert.Equal(t, ms(100, 350), r.Rhythm())
+
+	r.Reset()
+	assert.Equal(t, Rhythm{}, r.Rhythm())
+
+	var nilRecorder *RhythmRecorder
+	nilRecorder.Key(start)
+	nilRecorder.Backspace()
+	nilRecorder.Reset()
+	assert.Equal(t, Rhythm{}, nilRecorder.Rhythm())
+}
+
+func TestRhythmDistance(t *testing.T) {
+	profile := ms(100, 300, 100)
+
+	assert.InDelta(t, 0, RhythmDistance(profile, ms(200, 600, 200)), 1e-9, "speed doesn't matter")
+	assert.Less(t, RhythmDistance(profile, ms(120, 280, 90)), DefaultRhythmTolerance, "small drift matches")
+	assert.Greater(t, RhythmDistance(profile, ms(300, 100, 100)), DefaultRhythmTolerance, "different rhythm")
+	assert.Equal(t, 1.0, RhythmDistance(profile, ms(100, 300)), "different length")
+	assert.Equal(t, 0.0, RhythmDistance(Rhythm{}, Rhythm{}))
+	assert.InDelta(t, 0, RhythmDistance(ms(0, 0), ms(0, 0)), 1e-9, "pasted input")
+
+	assert.True(t, RhythmMatches(profile, ms(110, 310, 95), DefaultRhythmTolerance))
+	assert.False(t, RhythmMatches(profile, ms(300, 100, 100), DefaultRhythmTolerance))
+}
+
+func TestAverageRhythm(t *testing.T) {
+	avg, err := AverageRhythm(ms(100, 200), ms(200, 400))
+	require.NoError(t, err)
+	assert.Equal(t, ms(150, 300), avg)
+
+	_, err = AverageRhythm(ms(100), ms(100, 200))
+	assert.Error(t, err)
+}
+
+func TestParseRhythm(t *testing.T) {
+	r, err := ParseRhythm(ms(120, 0, 85).String())
+	require.NoError(t, err)
+	assert.Equal(t, ms(120, 0, 85), r)
+
+	r, err = ParseRhythm("")
+	require.NoError(t, err)
+	assert.NotNil(t, r, "an empty profile is still a profile")
+
+	for _, bad := range []string{"a", "1,,2", "-5"} {
+		_, err := ParseRhythm(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -42,10 +42,41 @@ func GetSocketPermissions(socketPath string) (os.FileMode, error) {
 	return info.Mode().Perm(), nil
 }
 
+// DefaultSocketPerm is the mode tmux creates its socket with. A socket
+// found restricted with no record of its mode is restored to it.
+const DefaultSocketPerm os.FileMode = 0660
+
+// restricted reports whether perm is the mode of a restricted socket: no
+// read or write access. tmux itself sets the execute bits while clients
+// are attached.
+func restricted(perm os.FileMode) bool {
+	return perm&0666 == 0
+}
+
+// OriginalSocketPerm returns the permissions to restore the tmux socket to
+// on unlock. A socket already restricted was left so by a lock that never
+// restored it, e.g. after a crash: taking its mode as the original would
+// keep the socket unusable for good, so the mode recorded by that lock is
+// reused, or DefaultSocketPerm without one.
+func OriginalSocketPerm(socketPath string) (os.FileMode, error) {
+	perm, err := GetSocketPermissions(socketPath)
+	if err != nil {
+		return 0, err
+	}
+	if !restricted(perm) {
+		return perm, nil
+	}
+	if state, err := LoadState(); err == nil && state.SocketPath == socketPath && !restricted(state.SocketPerm) {
+		return state.SocketPerm, nil
+	}
+	return DefaultSocketPerm, nil
+}
+
 // RestrictSocket sets the tmux socket permissions to 000, preventing new connections.
-// Returns the original permissions so they can be restored later.
+// Returns the original permissions so they can be restored later, see
+// OriginalSocketPerm.
 func RestrictSocket(socketPath string) (os.FileMode, error) {
-	originalPerm, err := GetSocketPermissions(socketPath)
+	originalPerm, err := OriginalSocketPerm(socketPath)
 	if err != nil {
 		return 0, err
 	}
@@ -72,7 +103,7 @@ func RestoreSocketFromState() error {
 		return err
 	}
 
-	if state.SocketPath == "" || state.SocketPerm == 0 {
+	if state.SocketPath == "" || restricted(state.SocketPerm) {
 		return nil
 	}
 
@@ -2,9 +2,11 @@ package lock
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetTmuxSocketPath(t *testing.T) {
@@ -92,3 +94,86 @@ func TestGetTmuxSocketPath(t *testing.T) {
 		})
 	}
 }
+
+// fakeSocket returns a file standing for the tmux socket, with mode perm.
+func fakeSocket(t *testing.T, perm os.FileMode) string {
+	path := filepath.Join(t.TempDir(), "default")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	require.NoError(t, os.Chmod(path, perm))
+	return path
+}
+
+func TestRestrictSocket(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	socket := fakeSocket(t, 0770)
+
+	perm, err := RestrictSocket(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0770), perm)
+	current, err := GetSocketPermissions(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0), current)
+
+	require.NoError(t, RestoreSocket(socket, perm))
+	current, err = GetSocketPermissions(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0770), current)
+}
+
+func TestRelockAfterCrash(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	socket := fakeSocket(t, 0770)
+
+	// The first lock records the mode, restricts the socket and dies.
+	perm, err := OriginalSocketPerm(socket)
+	require.NoError(t, err)
+	_, err = Lock(socket, perm, AuthSystem)
+	require.NoError(t, err)
+	_, err = RestrictSocket(socket)
+	require.NoError(t, err)
+
+	// tmux adds execute bits while clients are attached.
+	require.NoError(t, os.Chmod(socket, 0100))
+
+	// The next lock restores the recorded mode, not the restricted one.
+	perm, err = OriginalSocketPerm(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0770), perm)
+	_, err = Lock(socket, perm, AuthSystem)
+	require.NoError(t, err)
+	perm, err = RestrictSocket(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0770), perm)
+
+	require.NoError(t, RestoreSocketFromState())
+	current, err := GetSocketPermissions(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0770), current)
+}
+
+func TestRelockAfterCrashNoState(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	socket := fakeSocket(t, 0)
+
+	perm, err := OriginalSocketPerm(socket)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSocketPerm, perm, "no record of the mode")
+
+	// A state of another socket, or recorded by a lock that already took
+	// the restricted mode for the original, is no record either.
+	_, err = Lock("/elsewhere/default", 0700, AuthSystem)
+	require.NoError(t, err)
+	perm, err = OriginalSocketPerm(socket)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSocketPerm, perm)
+
+	_, err = Lock(socket, 0, AuthSystem)
+	require.NoError(t, err)
+	perm, err = OriginalSocketPerm(socket)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSocketPerm, perm)
+	require.NoError(t, RestoreSocketFromState(), "nothing to restore")
+	current, err := GetSocketPermissions(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0), current)
+}
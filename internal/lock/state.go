@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"syscall"
 	"time"
 
+	"yule-log/internal/fsutil"
 	"yule-log/internal/xdg"
 )
 
@@ -14,22 +16,48 @@ var ErrNotLocked = errors.New("session is not locked")
 
 // State represents the current lock state.
 type State struct {
+	Version    int         `json:"version"`
 	Locked     bool        `json:"locked"`
 	LockedAt   time.Time   `json:"locked_at"`
 	SocketPath string      `json:"socket_path,omitempty"`
 	SocketPerm os.FileMode `json:"socket_perm,omitempty"`
+	Phrase     string      `json:"phrase,omitempty"` // Sealed session phrase, see Phrase
+	Auth       Auth        `json:"auth,omitempty"`   // Empty for the password file
+	PID        int         `json:"pid,omitempty"`    // Process that engaged the lock, see Stale
 }
 
-// Lock creates a lock state file indicating the session is locked.
-func Lock(socketPath string, socketPerm os.FileMode) error {
+// Lock creates a lock state file indicating the session is locked, and
+// returns the session phrase picked for this lock.
+func Lock(socketPath string, socketPerm os.FileMode, auth Auth) (string, error) {
+	hash, err := phraseSecret(auth)
+	if err != nil {
+		return "", err
+	}
+	phrase, err := NewPhrase()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := sealPhrase(phrase, hash)
+	if err != nil {
+		return "", err
+	}
+
 	state := State{
+		Version:    StateFileVersion,
 		Locked:     true,
 		LockedAt:   time.Now(),
 		SocketPath: socketPath,
 		SocketPerm: socketPerm,
+		Phrase:     sealed,
+		PID:        os.Getpid(),
 	}
-
-	return saveState(&state)
+	if auth == AuthSystem {
+		state.Auth = auth
+	}
+	if err := saveState(&state); err != nil {
+		return "", err
+	}
+	return phrase, nil
 }
 
 // Unlock removes the lock state file.
@@ -75,6 +103,16 @@ func LoadState() (*State, error) {
 		return nil, fmt.Errorf("parsing lock state: %w", err)
 	}
 
+	// Version 1 predates the version field.
+	from := max(state.Version, 1)
+	state.Version, err = migrate(&state, from, stateMigrations)
+	if err != nil {
+		return nil, fmt.Errorf("lock state: %w", err)
+	}
+	if state.Version != from {
+		_ = saveState(&state) // Best effort, the state is usable anyway
+	}
+
 	return &state, nil
 }
 
@@ -90,13 +128,49 @@ func saveState(state *State) error {
 		return fmt.Errorf("marshaling lock state: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := fsutil.WriteFile(path, data, 0600); err != nil {
 		return fmt.Errorf("writing lock state file: %w", err)
 	}
 
 	return nil
 }
 
+// Stale reports whether the process that engaged the lock is gone, e.g.
+// killed with SIGKILL: nothing will lift the lock or restore the socket.
+// Locks recorded without a PID are never stale.
+func (s *State) Stale() bool {
+	if s.PID <= 0 {
+		return false
+	}
+	err := syscall.Kill(s.PID, 0)
+	return err != nil && !errors.Is(err, syscall.EPERM)
+}
+
+// ErrLockHeld is returned by Recover for a lock whose process still runs.
+var ErrLockHeld = errors.New("lock held by a running process")
+
+// Recover lifts a stale lock: it restores the socket permissions recorded
+// in the lock state and removes the state. Unless force is set, a lock
+// whose process still runs is left alone with ErrLockHeld. It returns the
+// lifted state; without one, the error is ErrNotLocked.
+func Recover(force bool) (*State, error) {
+	state, err := LoadState()
+	if err != nil {
+		return nil, err
+	}
+	if !force && !state.Stale() {
+		return state, ErrLockHeld
+	}
+	// The tmux server may be gone along with its socket.
+	if err := RestoreSocketFromState(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return state, err
+	}
+	if err := Unlock(); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
 // LockDuration returns how long the session has been locked.
 func LockDuration() (time.Duration, error) {
 	state, err := LoadState()
@@ -0,0 +1,79 @@
+package lock
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadPID returns the PID of a process that has exited.
+func deadPID(t *testing.T) int {
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+func TestStale(t *testing.T) {
+	assert.False(t, (&State{PID: os.Getpid()}).Stale())
+	assert.False(t, (&State{}).Stale(), "no PID recorded")
+	assert.True(t, (&State{PID: deadPID(t)}).Stale())
+}
+
+func TestRecover(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	socket := fakeSocket(t, 0770)
+
+	_, err := Recover(false)
+	assert.ErrorIs(t, err, ErrNotLocked)
+
+	// A lock whose process runs (this one) is left alone.
+	_, err = Lock(socket, 0770, AuthSystem)
+	require.NoError(t, err)
+	_, err = RestrictSocket(socket)
+	require.NoError(t, err)
+	_, err = Recover(false)
+	assert.ErrorIs(t, err, ErrLockHeld)
+	assert.True(t, IsLocked())
+
+	// The lock process was killed.
+	state, err := LoadState()
+	require.NoError(t, err)
+	state.PID = deadPID(t)
+	require.NoError(t, saveState(state))
+
+	got, err := Recover(false)
+	require.NoError(t, err)
+	assert.Equal(t, socket, got.SocketPath)
+	assert.False(t, IsLocked())
+	perm, err := GetSocketPermissions(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0770), perm)
+}
+
+func TestRecoverForce(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	socket := fakeSocket(t, 0700)
+
+	_, err := Lock(socket, 0700, AuthSystem)
+	require.NoError(t, err)
+	_, err = RestrictSocket(socket)
+	require.NoError(t, err)
+
+	_, err = Recover(true)
+	require.NoError(t, err)
+	assert.False(t, IsLocked())
+	perm, err := GetSocketPermissions(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), perm)
+
+	// The tmux server went away with its socket.
+	_, err = Lock(socket, 0700, AuthSystem)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(socket))
+	_, err = Recover(true)
+	require.NoError(t, err)
+	assert.False(t, IsLocked())
+}
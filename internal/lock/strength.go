@@ -0,0 +1,246 @@
+package lock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ---- Password Strength
+// A zxcvbn-style estimate of how hard a password is to guess, on the same
+// 0-4 scale. Each key is worth the log2 of the pool of keys of the classes
+// used (lowercase, uppercase, digits, symbols, other characters, special
+// keys); repeated keys and runs like "abc" or "321" are worth little, and
+// common passwords (with trailing digits and symbols dropped) next to
+// nothing. The password is read in place and never copied.
+
+// Strength is a password strength score, from StrengthVeryWeak to
+// StrengthVeryStrong.
+type Strength int
+
+const (
+	StrengthVeryWeak Strength = iota
+	StrengthWeak
+	StrengthFair
+	StrengthStrong
+	StrengthVeryStrong
+)
+
+var strengthNames = [...]string{"very weak", "weak", "fair", "strong", "very strong"}
+
+func (s Strength) String() string {
+	if s < StrengthVeryWeak || s > StrengthVeryStrong {
+		return fmt.Sprintf("strength(%d)", int(s))
+	}
+	return strengthNames[s]
+}
+
+// ParseStrength parses a strength name, e.g. "fair".
+func ParseStrength(s string) (Strength, error) {
+	for i, name := range strengthNames {
+		if s == name {
+			return Strength(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown strength %q (want weak, fair, strong or very strong)", s)
+}
+
+// strengthBits are the guess bits needed for each score above very weak.
+// They are well above zxcvbn's thresholds (10^3 to 10^10 guesses, 10 to
+// 33 bits): without its dictionaries, pool bits overrate words and names.
+var strengthBits = [...]float64{20, 35, 50, 65}
+
+// Bits credited to keys that repeat the previous key or continue a run.
+const (
+	repeatBits   = 1
+	sequenceBits = 2
+	commonBits   = 8 // A common password, whatever its length
+)
+
+// commonPasswords are matched case-insensitively against passwords
+// stripped of trailing digits and symbols.
+var commonPasswords = []string{
+	"password", "passw0rd", "qwerty", "qwertyuiop", "azerty", "asdf",
+	"asdfgh", "zxcvbn", "letmein", "welcome", "admin", "login", "secret",
+	"iloveyou", "monkey", "dragon", "master", "sunshine", "princess",
+	"football", "baseball", "superman", "trustno1", "abc", "abcd", "abcdef",
+	"changeme", "tmux", "yulelog", "christmas", "santa",
+}
+
+// Key classes, with the size of their pool.
+type keyClass int
+
+const (
+	classLower keyClass = iota
+	classUpper
+	classDigit
+	classSymbol
+	classOther
+	classSpecial
+)
+
+var classPools = [...]int{26, 26, 10, 33, 100, len(specialKeys)}
+
+// passwordKey is one key of a password: a character, or a special key
+// identified by its display glyph.
+type passwordKey struct {
+	r       rune
+	special *specialKey
+}
+
+func (k passwordKey) class() keyClass {
+	switch {
+	case k.special != nil:
+		return classSpecial
+	case k.r >= 'a' && k.r <= 'z':
+		return classLower
+	case k.r >= 'A' && k.r <= 'Z':
+		return classUpper
+	case k.r >= '0' && k.r <= '9':
+		return classDigit
+	case k.r < utf8.RuneSelf:
+		return classSymbol
+	default:
+		return classOther
+	}
+}
+
+// eachKey calls fn with every key of data, in order.
+func eachKey(data []byte, fn func(passwordKey)) {
+	for len(data) > 0 {
+		if len(data) >= markerLen {
+			if k, ok := lookupMarker(string(data[:markerLen])); ok {
+				fn(passwordKey{r: k.display, special: &k})
+				data = data[markerLen:]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRune(data)
+		fn(passwordKey{r: r})
+		data = data[size:]
+	}
+}
+
+// guessBits estimates the log2 of the number of guesses for password.
+func guessBits(password []byte) float64 {
+	var used [len(classPools)]bool
+	eachKey(password, func(k passwordKey) { used[k.class()] = true })
+	pool := 0
+	for class, ok := range used {
+		if ok {
+			pool += classPools[class]
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	perKey := math.Log2(float64(pool))
+
+	var bits float64
+	var prev passwordKey
+	first := true
+	eachKey(password, func(k passwordKey) {
+		switch {
+		case first:
+			bits += perKey
+		case k.r == prev.r:
+			bits += repeatBits
+		case k.class() == prev.class() && (k.r == prev.r+1 || k.r == prev.r-1):
+			bits += sequenceBits
+		default:
+			bits += perKey
+		}
+		prev, first = k, false
+	})
+
+	if isCommon(password) {
+		bits = min(bits, commonBits)
+	}
+	return bits
+}
+
+// isCommon reports whether password is a common one, possibly followed by
+// digits and symbols.
+func isCommon(password []byte) bool {
+	end := len(password)
+	for end > 0 {
+		c := password[end-1]
+		if c >= utf8.RuneSelf || unicode.IsLetter(rune(c)) || c < bytePrintable {
+			break
+		}
+		end--
+	}
+	for _, word := range commonPasswords {
+		if bytes.EqualFold(password[:end], []byte(word)) {
+			return true
+		}
+	}
+	return false
+}
+
+// bytePrintable is the first printable ASCII byte: lower bytes belong to
+// special key markers.
+const bytePrintable = 0x20
+
+// EstimateStrength scores password on the zxcvbn 0-4 scale.
+func EstimateStrength(password []byte) Strength {
+	bits := guessBits(password)
+	score := StrengthVeryWeak
+	for _, threshold := range strengthBits {
+		if bits < threshold {
+			break
+		}
+		score++
+	}
+	return score
+}
+
+// ---- Password Policy
+// set-password refuses passwords below the policy of the global
+// configuration file ([password] min_length, min_strength, require_keys).
+
+// DefaultMinLength is the minimum number of keys when the configuration
+// doesn't set one.
+const DefaultMinLength = 8
+
+// ErrWeakPassword is returned for passwords that don't meet the policy.
+var ErrWeakPassword = errors.New("password too weak")
+
+// Policy is what a new password must meet.
+type Policy struct {
+	MinLength   int      // Keys, special keys counting as one
+	MinStrength Strength // EstimateStrength score
+	RequireKeys bool     // At least one arrow, function or navigation key
+}
+
+// DefaultPolicy only enforces the minimum length.
+var DefaultPolicy = Policy{MinLength: DefaultMinLength}
+
+// KeyCount returns the number of keys in password.
+func KeyCount(password []byte) int {
+	n := 0
+	eachKey(password, func(passwordKey) { n++ })
+	return n
+}
+
+// Check returns an error wrapping ErrWeakPassword when password doesn't
+// meet the policy.
+func (p Policy) Check(password []byte) error {
+	if n := KeyCount(password); n < p.MinLength {
+		return fmt.Errorf("%w: %d keys, at least %d needed", ErrWeakPassword, n, p.MinLength)
+	}
+	if p.RequireKeys {
+		special := false
+		eachKey(password, func(k passwordKey) { special = special || k.special != nil })
+		if !special {
+			return fmt.Errorf("%w: it needs an arrow, function or navigation key", ErrWeakPassword)
+		}
+	}
+	if s := EstimateStrength(password); s < p.MinStrength {
+		return fmt.Errorf("%w: %s, at least %s needed", ErrWeakPassword, s, p.MinStrength)
+	}
+	return nil
+}
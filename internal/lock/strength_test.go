@@ -0,0 +1,97 @@
+package lock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateStrength(t *testing.T) {
+	tests := []struct {
+		password string
+		want     Strength
+	}{
+		{"", StrengthVeryWeak},
+		{"a", StrengthVeryWeak},
+		{"password", StrengthVeryWeak},
+		{"Password123!", StrengthVeryWeak}, // Common, with digits and symbols appended
+		{"qwerty2024", StrengthVeryWeak},
+		{"abcdefgh", StrengthVeryWeak}, // A run
+		{"aaaaaaaaaaaa", StrengthVeryWeak},
+		{"12345678", StrengthVeryWeak},
+		{"hunter2x", StrengthFair},
+		{"k3#Vq9!z", StrengthStrong},
+		{"Tr0ub4dor&3", StrengthVeryStrong},
+		{"correct horse battery staple", StrengthVeryStrong},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, EstimateStrength([]byte(tt.password)), "%q", tt.password)
+	}
+}
+
+func TestEstimateStrength_SpecialKeys(t *testing.T) {
+	plain := guessBits([]byte("x7z9"))
+	keys := guessBits([]byte("x7" + ArrowUpMarker + f5Marker(t) + "z9"))
+	assert.Greater(t, keys, plain+10, "special keys add to the strength")
+
+	// Repeated arrows are worth little.
+	assert.Equal(t, StrengthVeryWeak, EstimateStrength([]byte(ArrowUpMarker+ArrowUpMarker+ArrowUpMarker+ArrowUpMarker)))
+}
+
+// f5Marker returns the marker of the F5 key.
+func f5Marker(t *testing.T) string {
+	t.Helper()
+	for _, k := range specialKeys {
+		if k.display == '⑤' {
+			return k.marker
+		}
+	}
+	t.Fatal("no F5 key")
+	return ""
+}
+
+func TestParseStrength(t *testing.T) {
+	s, err := ParseStrength("very strong")
+	require.NoError(t, err)
+	assert.Equal(t, StrengthVeryStrong, s)
+	assert.Equal(t, "very strong", s.String())
+
+	_, err = ParseStrength("epic")
+	assert.Error(t, err)
+}
+
+func TestPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		password string
+		wantErr  string
+	}{
+		{"default, long enough", DefaultPolicy, "hunter2x", ""},
+		{"default, too short", DefaultPolicy, "hunter2", "7 keys, at least 8 needed"},
+		{"special keys count as one", Policy{MinLength: 3}, "a" + ArrowUpMarker + ArrowDownMarker, ""},
+		{"keys required", Policy{RequireKeys: true}, "k3#Vq9!z", "arrow, function or navigation key"},
+		{"keys present", Policy{RequireKeys: true}, "k3" + ArrowLeftMarker, ""},
+		{"too weak", Policy{MinStrength: StrengthStrong}, "hunter2x", "fair, at least strong needed"},
+		{"strong enough", Policy{MinStrength: StrengthStrong}, "k3#Vq9!z", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check([]byte(tt.password))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrWeakPassword)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSecureBuffer_Strength(t *testing.T) {
+	sb := NewSecureBuffer()
+	defer sb.Destroy()
+	sb.AppendString("k3#Vq9!z")
+	assert.Equal(t, StrengthStrong, sb.Strength())
+}
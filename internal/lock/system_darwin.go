@@ -0,0 +1,38 @@
+package lock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"os/user"
+	"syscall"
+)
+
+// checkSystemPassword asks OpenDirectory, through dscl, whether password
+// is the login password of the current user. dscl prompts for the
+// password when it is left off the command line, where other users could
+// read it; without a controlling terminal the prompt reads stdin.
+func checkSystemPassword(password []byte) (bool, error) {
+	u, err := user.Current()
+	if err != nil {
+		return false, fmt.Errorf("getting current user: %w", err)
+	}
+
+	input := append(append([]byte{}, password...), '\n')
+	defer ClearBytes(input)
+
+	cmd := exec.Command("/usr/bin/dscl", "/Search", "-authonly", u.Username)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr):
+		return false, nil // Wrong password
+	default:
+		return false, fmt.Errorf("running dscl: %w", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package lock
+
+// checkSystemPassword is only available on macOS, see ParseAuth.
+func checkSystemPassword([]byte) (bool, error) {
+	return false, ErrSystemAuthUnsupported
+}
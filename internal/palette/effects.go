@@ -0,0 +1,189 @@
+package palette
+
+import (
+	"fmt"
+	"math"
+)
+
+// ---- Effects
+// An effect transforms a color. Effects taking an intensity are the
+// identity at 0 and full strength at 1; intensities outside 0..1 are
+// clamped.
+
+// Effect transforms a color.
+type Effect func(RGB) RGB
+
+// Identity leaves colors unchanged.
+func Identity(c RGB) RGB { return c }
+
+// Chain applies effects in order.
+func Chain(effects ...Effect) Effect {
+	return func(c RGB) RGB {
+		for _, e := range effects {
+			c = e(c)
+		}
+		return c
+	}
+}
+
+// RedShift shifts colors toward bright red, with an extra glow past half
+// intensity. Used for the wrong password animation.
+func RedShift(intensity float64) Effect {
+	t := clamp01(intensity)
+	if t == 0 {
+		return Identity
+	}
+	return func(c RGB) RGB {
+		r := float64(c.R) + t*100
+		g := float64(c.G) * (1 - t*0.7)
+		b := float64(c.B) * (1 - t*0.8)
+
+		if t > 0.5 {
+			glow := (t - 0.5) * 2
+			r += glow * 50
+			g += glow * 20
+		}
+		return rgb(r, g, b)
+	}
+}
+
+// Intensity shift stages: orange warms up until shiftMagenta, turns
+// red/magenta until shiftWhite, then blue/white hot; shiftBoost is where
+// the overall brightness boost starts.
+const (
+	shiftMagenta = 0.5
+	shiftWhite   = 0.85
+	shiftBoost   = 0.9
+)
+
+// IntensityShift shifts fire colors with heat: a subtle warm-up, then
+// red/magenta, then blue/white at the highest intensities.
+func IntensityShift(intensity float64) Effect {
+	t := clamp01(intensity)
+	if t == 0 {
+		return Identity
+	}
+	return func(c RGB) RGB {
+		r, g, b := float64(c.R), float64(c.G), float64(c.B)
+
+		switch {
+		case t < shiftMagenta:
+			k := t / shiftMagenta
+			r = math.Min(255, r*(1+k*0.1))
+			g *= 1 - k*0.05
+		case t < shiftWhite:
+			k := (t - shiftMagenta) / (shiftWhite - shiftMagenta)
+			r = math.Min(255, r*1.1)
+			g *= 0.95 * (1 - k*0.6)
+			b = math.Min(255, b+k*60)
+		default:
+			k := (t - shiftWhite) / (1 - shiftWhite)
+			r = math.Min(255, r*1.1) * (1 - k*0.4)
+			g = math.Min(255, g*0.95*0.4+k*80)
+			b = math.Min(255, math.Min(255, b+60)+k*120)
+		}
+
+		if t > shiftBoost {
+			boost := (t - shiftBoost) / (1 - shiftBoost) * 50
+			r, g, b = r+boost, g+boost, b+boost
+		}
+		return rgb(r, g, b)
+	}
+}
+
+// Temperature tints colors warmer (shift > 0, toward deep orange) or
+// cooler (shift < 0, toward blue), keeping their brightness. Shifts are
+// clamped to -1..1 and interpolate continuously, so slowly drifting shifts
+// don't make the palette jump.
+func Temperature(shift float64) Effect {
+	shift = math.Min(1, math.Max(-1, shift))
+	if shift == 0 {
+		return Identity
+	}
+	tint, k := warmTint, shift*temperatureStrength
+	if shift < 0 {
+		tint, k = coolTint, -shift*temperatureStrength
+	}
+	return func(c RGB) RGB {
+		// Scale the tint to the color's brightness so dark cells stay dark.
+		scale := c.Luma() / tint.Luma()
+		target := rgb(float64(tint.R)*scale, float64(tint.G)*scale, float64(tint.B)*scale)
+		return Lerp(c, target, k)
+	}
+}
+
+// Temperature tints and how far Temperature(±1) moves colors toward them.
+var (
+	warmTint = RGB{255, 120, 20}
+	coolTint = RGB{120, 170, 255}
+)
+
+const temperatureStrength = 0.35
+
+// Dim darkens colors toward black.
+func Dim(amount float64) Effect {
+	k := 1 - clamp01(amount)
+	return func(c RGB) RGB {
+		return rgb(float64(c.R)*k, float64(c.G)*k, float64(c.B)*k)
+	}
+}
+
+// Desaturate moves colors toward the gray of the same luma.
+func Desaturate(amount float64) Effect {
+	k := clamp01(amount)
+	return func(c RGB) RGB {
+		gray := c.Luma()
+		return rgb(
+			float64(c.R)+(gray-float64(c.R))*k,
+			float64(c.G)+(gray-float64(c.G))*k,
+			float64(c.B)+(gray-float64(c.B))*k,
+		)
+	}
+}
+
+func clamp01(v float64) float64 {
+	return math.Min(1, math.Max(0, v))
+}
+
+// rgb converts float channels to a color, clamping to 0..255.
+func rgb(r, g, b float64) RGB {
+	return RGB{channel(r), channel(g), channel(b)}
+}
+
+func channel(v float64) uint8 {
+	return uint8(math.Min(255, math.Max(0, v)))
+}
+
+// Levels adjusts gamma, then contrast around mid-gray, then brightness.
+// 1 leaves a setting unchanged; gamma above 1 brightens mid-tones, contrast
+// and brightness are multipliers. It is the identity when all are 1.
+func Levels(brightness, contrast, gamma float64) Effect {
+	if brightness == 1 && contrast == 1 && gamma == 1 {
+		return Identity
+	}
+	var lut [256]uint8
+	for i := range lut {
+		v := float64(i)
+		if gamma > 0 {
+			v = 255 * math.Pow(v/255, 1/gamma)
+		}
+		v = (v-128)*contrast + 128
+		lut[i] = channel(v * brightness)
+	}
+	return func(c RGB) RGB {
+		return RGB{lut[c.R], lut[c.G], lut[c.B]}
+	}
+}
+
+// ValidateLevels checks user supplied Levels settings.
+func ValidateLevels(brightness, contrast, gamma float64) error {
+	switch {
+	case brightness < 0:
+		return fmt.Errorf("brightness must be >= 0, got %g", brightness)
+	case contrast < 0:
+		return fmt.Errorf("contrast must be >= 0, got %g", contrast)
+	case gamma <= 0:
+		return fmt.Errorf("gamma must be > 0, got %g", gamma)
+	}
+	return nil
+}
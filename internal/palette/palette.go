@@ -0,0 +1,225 @@
+// Package palette maps heat values to colors. A palette is built from color
+// stops into a lookup table, banded or as a smooth gradient, then transformed by composable effects (red
+// shift, intensity shift, temperature, dim, desaturate).
+package palette
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RGB is a 24-bit color.
+type RGB struct {
+	R, G, B uint8
+}
+
+// Luma returns the perceived brightness of c (Rec. 601), from 0 to 255.
+func (c RGB) Luma() float64 {
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+// Lerp interpolates between a (t = 0) and b (t = 1); t is clamped.
+func Lerp(a, b RGB, t float64) RGB {
+	t = clamp01(t)
+	return rgb(
+		float64(a.R)+(float64(b.R)-float64(a.R))*t,
+		float64(a.G)+(float64(b.G)-float64(a.G))*t,
+		float64(a.B)+(float64(b.B)-float64(a.B))*t,
+	)
+}
+
+// Stop starts a color band: values from At up to the next stop get Color.
+type Stop struct {
+	At    int
+	Color RGB
+}
+
+// Palette is a lookup table from values 0..Max() to colors.
+type Palette struct {
+	lut []RGB
+}
+
+// New builds a palette for values 0..maxValue from stops sorted by At.
+// Values below the first stop get the first stop's color.
+func New(stops []Stop, maxValue int) Palette {
+	lut := make([]RGB, max(maxValue, 0)+1)
+	if len(stops) == 0 {
+		return Palette{lut: lut}
+	}
+	stop := 0
+	for v := range lut {
+		for stop+1 < len(stops) && stops[stop+1].At <= v {
+			stop++
+		}
+		lut[v] = stops[stop].Color
+	}
+	return Palette{lut: lut}
+}
+
+// NewGradient builds a palette for values 0..maxValue from stops sorted by
+// At, interpolating between consecutive stops instead of banding. Values
+// below the first stop get its color, values past the last one its color.
+func NewGradient(stops []Stop, maxValue int) Palette {
+	lut := make([]RGB, max(maxValue, 0)+1)
+	if len(stops) == 0 {
+		return Palette{lut: lut}
+	}
+	stop := 0
+	for v := range lut {
+		for stop+1 < len(stops) && stops[stop+1].At <= v {
+			stop++
+		}
+		if v < stops[0].At || stop+1 == len(stops) {
+			lut[v] = stops[stop].Color
+			continue
+		}
+		a, b := stops[stop], stops[stop+1]
+		lut[v] = Lerp(a.Color, b.Color, float64(v-a.At)/float64(b.At-a.At))
+	}
+	return Palette{lut: lut}
+}
+
+// Gradient selects between banded and gradient palettes.
+type Gradient string
+
+const (
+	GradientAuto Gradient = "auto" // Gradient on truecolor terminals
+	GradientOn   Gradient = "on"
+	GradientOff  Gradient = "off"
+)
+
+// ParseGradient validates a --gradient value.
+func ParseGradient(s string) (Gradient, error) {
+	switch g := Gradient(strings.ToLower(strings.TrimSpace(s))); g {
+	case "", GradientAuto:
+		return GradientAuto, nil
+	case GradientOn, GradientOff:
+		return g, nil
+	}
+	return "", fmt.Errorf("unknown gradient %q (want auto, on or off)", s)
+}
+
+// Smooth reports whether the gradient applies on a terminal showing colors
+// colors (see tcell.Screen.Colors). Below 24-bit color, neighboring shades
+// of the gradient would be rounded to the same few palette entries, so
+// auto keeps the bands.
+func (g Gradient) Smooth(colors int) bool {
+	switch g {
+	case GradientOn:
+		return true
+	case GradientOff:
+		return false
+	default:
+		return colors >= 1<<24
+	}
+}
+
+// Max returns the highest value in the table.
+func (p Palette) Max() int {
+	return len(p.lut) - 1
+}
+
+// At returns the color of v, clamped to 0..Max().
+func (p Palette) At(v int) RGB {
+	if len(p.lut) == 0 {
+		return RGB{}
+	}
+	return p.lut[min(max(v, 0), len(p.lut)-1)]
+}
+
+// Map returns a copy of p with e applied to every color.
+func (p Palette) Map(e Effect) Palette {
+	return p.MapValues(func(_ int) Effect { return e })
+}
+
+// MapValues returns a copy of p where the color of each value v is
+// transformed by the effect returned for v.
+func (p Palette) MapValues(effectFor func(v int) Effect) Palette {
+	lut := make([]RGB, len(p.lut))
+	for v, c := range p.lut {
+		lut[v] = effectFor(v)(c)
+	}
+	return Palette{lut: lut}
+}
+
+// ---- Indexed Palettes
+// Terminals without truecolor round RGB colors to their own palette, which
+// with 16 colors turns most of a fire into the same red. Themes can list
+// explicit palette colors for them instead.
+
+// Depth is the number of colors a terminal can show.
+type Depth int
+
+const (
+	DepthAuto Depth = 0 // Detected from the terminal
+	Depth16   Depth = 16
+	Depth256  Depth = 256
+	DepthTrue Depth = 1 << 24
+)
+
+// ParseDepth validates a --colors value: auto, truecolor, 256 or 16.
+func ParseDepth(s string) (Depth, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "auto":
+		return DepthAuto, nil
+	case "truecolor", "24bit":
+		return DepthTrue, nil
+	case "256":
+		return Depth256, nil
+	case "16", "8":
+		return Depth16, nil
+	}
+	return 0, fmt.Errorf("unknown color depth %q (want auto, truecolor, 256 or 16)", s)
+}
+
+// Resolve returns d, or the depth of a terminal showing colors colors (see
+// tcell.Screen.Colors) when d is DepthAuto.
+func (d Depth) Resolve(colors int) Depth {
+	switch {
+	case d != DepthAuto:
+		return d
+	case colors >= int(DepthTrue):
+		return DepthTrue
+	case colors >= int(Depth256):
+		return Depth256
+	default:
+		return Depth16
+	}
+}
+
+// IndexStop starts a band of a terminal palette color: 0-15 for the ANSI
+// colors, up to 255 for the xterm 256-color palette.
+type IndexStop struct {
+	At    int
+	Index int
+}
+
+// Indexed is a lookup table from values 0..Max() to palette color indexes.
+type Indexed struct {
+	lut []int
+}
+
+// NewIndexed builds an indexed palette for values 0..maxValue from stops
+// sorted by At, in bands like New.
+func NewIndexed(stops []IndexStop, maxValue int) Indexed {
+	lut := make([]int, max(maxValue, 0)+1)
+	if len(stops) == 0 {
+		return Indexed{lut: lut}
+	}
+	stop := 0
+	for v := range lut {
+		for stop+1 < len(stops) && stops[stop+1].At <= v {
+			stop++
+		}
+		lut[v] = stops[stop].Index
+	}
+	return Indexed{lut: lut}
+}
+
+// At returns the color index of v, clamped to the table.
+func (p Indexed) At(v int) int {
+	if len(p.lut) == 0 {
+		return 0
+	}
+	return p.lut[min(max(v, 0), len(p.lut)-1)]
+}
@@ -0,0 +1,219 @@
+package palette
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testStops = []Stop{
+	{At: 0, Color: RGB{128, 0, 0}},
+	{At: 2, Color: RGB{200, 30, 0}},
+	{At: 5, Color: RGB{255, 100, 0}},
+	{At: 10, Color: RGB{255, 160, 0}},
+	{At: 16, Color: RGB{255, 200, 50}},
+}
+
+func TestNewStops(t *testing.T) {
+	p := New(testStops, 20)
+
+	assert.Equal(t, 20, p.Max())
+	assert.Equal(t, testStops[0].Color, p.At(0))
+	assert.Equal(t, testStops[0].Color, p.At(1))
+	assert.Equal(t, testStops[1].Color, p.At(2))
+	assert.Equal(t, testStops[2].Color, p.At(9))
+	assert.Equal(t, testStops[4].Color, p.At(16))
+	assert.Equal(t, testStops[4].Color, p.At(20))
+
+	// Out of range values are clamped.
+	assert.Equal(t, p.At(0), p.At(-5))
+	assert.Equal(t, p.At(20), p.At(1000))
+
+	assert.Equal(t, RGB{}, New(nil, 5).At(3))
+	assert.Equal(t, RGB{}, Palette{}.At(3))
+}
+
+func TestPaletteMonotonic(t *testing.T) {
+	p := New(testStops, 40)
+	for v := 1; v <= p.Max(); v++ {
+		assert.GreaterOrEqual(t, p.At(v).Luma(), p.At(v-1).Luma(), "value %d", v)
+	}
+
+	// Heat-dependent intensity shift keeps hotter cells at least as bright
+	// up to the white-hot stage.
+	shifted := p.MapValues(func(v int) Effect { return IntensityShift(float64(v) / 40 * shiftWhite) })
+	for v := 1; v <= shifted.Max(); v++ {
+		assert.GreaterOrEqual(t, shifted.At(v).R, shifted.At(v-1).R, "value %d", v)
+	}
+}
+
+func TestEffectsIdentityAtZero(t *testing.T) {
+	c := RGB{255, 100, 0}
+	for name, e := range map[string]Effect{
+		"red shift":       RedShift(0),
+		"intensity shift": IntensityShift(0),
+		"dim":             Dim(0),
+		"desaturate":      Desaturate(0),
+		"temperature":     Temperature(0),
+		"empty chain":     Chain(),
+	} {
+		assert.Equal(t, c, e(c), name)
+	}
+}
+
+func TestEffectsRange(t *testing.T) {
+	colors := []RGB{{0, 0, 0}, {255, 255, 255}, {255, 0, 0}, {128, 64, 200}, {255, 200, 50}}
+	for _, c := range colors {
+		lo := min(c.R, c.G, c.B)
+		hi := max(c.R, c.G, c.B)
+		for _, k := range []float64{-1, 0.25, 0.5, 0.75, 0.9, 0.95, 1, 2} {
+			// Channels saturate at 255 instead of wrapping around.
+			assert.GreaterOrEqual(t, RedShift(k)(c).R, c.R, "%v %v", c, k)
+
+			dim := Dim(k)(c)
+			assert.LessOrEqual(t, dim.R, c.R)
+			assert.LessOrEqual(t, dim.G, c.G)
+			assert.LessOrEqual(t, dim.B, c.B)
+
+			gray := Desaturate(k)(c)
+			for _, ch := range []uint8{gray.R, gray.G, gray.B} {
+				assert.GreaterOrEqual(t, ch, lo)
+				assert.LessOrEqual(t, ch, hi)
+			}
+		}
+		assert.GreaterOrEqual(t, IntensityShift(1)(c).B, uint8(230), "white hot is mostly blue")
+	}
+}
+
+func TestEffects(t *testing.T) {
+	orange := RGB{255, 100, 0}
+
+	red := RedShift(1)(orange)
+	assert.Equal(t, uint8(255), red.R)
+	assert.Less(t, red.G, orange.G)
+
+	white := IntensityShift(1)(orange)
+	assert.Greater(t, white.B, orange.B, "hottest shifts toward blue/white")
+
+	assert.Equal(t, RGB{}, Dim(1)(orange))
+	assert.Equal(t, RGB{127, 50, 0}, Dim(0.5)(orange))
+
+	gray := Desaturate(1)(orange)
+	assert.Equal(t, gray.R, gray.G)
+	assert.Equal(t, gray.G, gray.B)
+
+	assert.Equal(t, Dim(1)(RedShift(1)(orange)), Chain(RedShift(1), Dim(1))(orange))
+	assert.NotEqual(t, Chain(Dim(1), RedShift(1))(orange), Chain(RedShift(1), Dim(1))(orange), "order matters")
+}
+
+func TestLerp(t *testing.T) {
+	a, b := RGB{0, 100, 200}, RGB{200, 100, 0}
+	assert.Equal(t, a, Lerp(a, b, 0))
+	assert.Equal(t, b, Lerp(a, b, 1))
+	assert.Equal(t, RGB{100, 100, 100}, Lerp(a, b, 0.5))
+	assert.Equal(t, b, Lerp(a, b, 3), "clamped")
+}
+
+func TestTemperature(t *testing.T) {
+	orange := RGB{255, 160, 0}
+
+	warm := Temperature(1)(orange)
+	assert.Less(t, warm.G, orange.G, "warmer is redder")
+
+	cool := Temperature(-1)(orange)
+	assert.Greater(t, cool.B, orange.B, "cooler is bluer")
+	assert.Less(t, cool.R, orange.R)
+
+	assert.Equal(t, RGB{}, Temperature(-1)(RGB{}), "black stays black")
+	assert.Equal(t, Temperature(1)(orange), Temperature(5)(orange), "clamped")
+
+	// Small shift changes move colors by small amounts.
+	for s := -1.0; s < 1; s += 0.01 {
+		a, b := Temperature(s)(orange), Temperature(s+0.01)(orange)
+		assert.InDelta(t, float64(a.B), float64(b.B), 2, "shift %.2f", s)
+		assert.InDelta(t, float64(a.G), float64(b.G), 2, "shift %.2f", s)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	c := RGB{200, 100, 10}
+	assert.Equal(t, c, Levels(1, 1, 1)(c))
+
+	brighter := Levels(1.2, 1, 1)(c)
+	assert.Equal(t, RGB{240, 120, 12}, brighter)
+	assert.Equal(t, uint8(255), Levels(2, 1, 1)(c).R, "saturates")
+
+	flat := Levels(1, 0, 1)(c)
+	assert.Equal(t, RGB{128, 128, 128}, flat)
+
+	lifted := Levels(1, 1, 2)(c)
+	assert.Greater(t, lifted.G, c.G, "gamma > 1 brightens mid-tones")
+	assert.Equal(t, uint8(0), Levels(1, 1, 2)(RGB{}).R)
+	assert.Equal(t, uint8(255), Levels(1, 1, 0.5)(RGB{255, 255, 255}).R)
+
+	assert.NoError(t, ValidateLevels(1, 1, 1))
+	assert.Error(t, ValidateLevels(-1, 1, 1))
+	assert.Error(t, ValidateLevels(1, -1, 1))
+	assert.Error(t, ValidateLevels(1, 1, 0))
+}
+
+func TestNewGradient(t *testing.T) {
+	p := NewGradient(testStops, 20)
+
+	assert.Equal(t, 20, p.Max())
+	assert.Equal(t, testStops[0].Color, p.At(0))
+	assert.Equal(t, RGB{164, 15, 0}, p.At(1)) // Halfway to the second stop
+	assert.Equal(t, testStops[1].Color, p.At(2))
+	assert.Equal(t, testStops[4].Color, p.At(16))
+	assert.Equal(t, testStops[4].Color, p.At(20))
+
+	// Every value gets its own shade, hotter is brighter
+	for v := 1; v <= 16; v++ {
+		assert.Greater(t, p.At(v).Luma(), p.At(v-1).Luma(), "value %d", v)
+	}
+
+	// Values before a first stop above 0 are flat
+	late := NewGradient([]Stop{{At: 4, Color: RGB{R: 100}}, {At: 8, Color: RGB{R: 200}}}, 10)
+	assert.Equal(t, RGB{R: 100}, late.At(2))
+	assert.Equal(t, RGB{R: 150}, late.At(6))
+
+	assert.Equal(t, RGB{}, NewGradient(nil, 5).At(3))
+}
+
+func TestParseGradient(t *testing.T) {
+	for s, want := range map[string]Gradient{"": GradientAuto, "auto": GradientAuto, "ON": GradientOn, "off": GradientOff} {
+		g, err := ParseGradient(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, g, s)
+	}
+	_, err := ParseGradient("smooth")
+	assert.Error(t, err)
+
+	assert.True(t, GradientAuto.Smooth(1<<24))
+	assert.False(t, GradientAuto.Smooth(256))
+	assert.True(t, GradientOn.Smooth(8))
+	assert.False(t, GradientOff.Smooth(1<<24))
+}
+
+func TestDepth(t *testing.T) {
+	for s, want := range map[string]Depth{"": DepthAuto, "auto": DepthAuto, "TrueColor": DepthTrue, "256": Depth256, "16": Depth16} {
+		d, err := ParseDepth(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, d, s)
+	}
+	_, err := ParseDepth("88")
+	assert.Error(t, err)
+
+	assert.Equal(t, DepthTrue, DepthAuto.Resolve(1<<24))
+	assert.Equal(t, Depth256, DepthAuto.Resolve(256))
+	assert.Equal(t, Depth16, DepthAuto.Resolve(8))
+	assert.Equal(t, Depth16, Depth16.Resolve(1<<24))
+}
+
+func TestNewIndexed(t *testing.T) {
+	p := NewIndexed([]IndexStop{{At: 0, Index: 1}, {At: 2, Index: 9}, {At: 5, Index: 11}}, 10)
+	assert.Equal(t, []int{1, 1, 9, 9, 11, 11}, []int{p.At(0), p.At(1), p.At(2), p.At(4), p.At(5), p.At(10)})
+	assert.Equal(t, 1, p.At(-3))
+	assert.Equal(t, 11, p.At(99))
+	assert.Equal(t, 0, Indexed{}.At(3))
+}
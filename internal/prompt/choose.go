@@ -0,0 +1,61 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// ---- Single-Key Choice
+// Confirmations answered with one key, no Enter, e.g. "[l]ock anyway,
+// [d]etach them, [c]ancel".
+
+// Choose writes question to out and reads keys from the terminal on in
+// until one of keys is typed (case-insensitive), which it returns. Escape
+// and Ctrl+C return 0.
+func Choose(in *os.File, out io.Writer, question, keys string) (rune, error) {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return 0, fmt.Errorf("stdin is not a terminal")
+	}
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return 0, fmt.Errorf("entering raw mode: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	fmt.Fprint(out, question)
+	key, err := readChoice(in, keys)
+	if key != 0 {
+		fmt.Fprintf(out, "%c", key)
+	}
+	fmt.Fprint(out, "\r\n")
+	return key, err
+}
+
+// readChoice reads r until one of keys, Escape or Ctrl+C.
+func readChoice(r io.Reader, keys string) (rune, error) {
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			switch k := unicode.ToLower(rune(b)); {
+			case b == byteEscape || b == byteCtrlC:
+				return 0, nil
+			case strings.ContainsRune(keys, k):
+				return k, nil
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, nil
+			}
+			return 0, fmt.Errorf("reading input: %w", err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadChoice(t *testing.T) {
+	tests := []struct {
+		input string
+		want  rune
+	}{
+		{"l", 'l'},
+		{"xyD", 'd'}, // Other keys ignored, case folded
+		{"\x1b", 0},
+		{"q\x03l", 0},
+		{"", 0}, // EOF
+	}
+	for _, tt := range tests {
+		got, err := readChoice(strings.NewReader(tt.input), "ldc")
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got, "%q", tt.input)
+	}
+}
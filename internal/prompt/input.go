@@ -0,0 +1,114 @@
+package prompt
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/lock"
+)
+
+// ---- Secure Input
+// Input is the password entry shared by set-password and the lock screen,
+// so that a password is stored the same way whichever typed it: characters
+// as UTF-8, arrow, function and navigation keys as lock key markers. Each
+// side only translates its input to key events and draws the masks.
+
+// Outcome is the effect of a key on an Input.
+type Outcome int
+
+const (
+	OutcomeIgnored   Outcome = iota // Not password input, e.g. Tab or Escape
+	OutcomeEdited                   // The password changed
+	OutcomeSubmitted                // Enter pressed
+)
+
+// Scroll keys page through a view shown next to the input, such as the
+// soft lock pane. Input ignores them: control keys are never password
+// keys, so a password can always be typed whole.
+const (
+	KeyScrollUp     = tcell.KeyCtrlU // Page back
+	KeyScrollDown   = tcell.KeyCtrlD // Page forward
+	KeyScrollTop    = tcell.KeyCtrlT // Oldest content
+	KeyScrollBottom = tcell.KeyCtrlE // Latest content
+)
+
+// Input is a password being typed.
+type Input struct {
+	Buffer *lock.SecureBuffer
+	Rhythm *lock.RhythmRecorder // Records keystroke times when not nil
+}
+
+// NewInput returns an empty input recording keystroke times to rhythm,
+// which may be nil.
+func NewInput(rhythm *lock.RhythmRecorder) *Input {
+	return &Input{Buffer: lock.NewSecureBuffer(), Rhythm: rhythm}
+}
+
+// HandleKey applies a key pressed at when: r is the typed character of
+// tcell.KeyRune keys.
+func (in *Input) HandleKey(key tcell.Key, r rune, when time.Time) Outcome {
+	switch key {
+	case tcell.KeyEnter:
+		return OutcomeSubmitted
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if in.Buffer.Backspace() {
+			in.Rhythm.Backspace()
+		}
+		return OutcomeEdited
+	case tcell.KeyRune:
+		in.Buffer.AppendRune(r)
+		in.Rhythm.Key(when)
+		return OutcomeEdited
+	}
+	if lock.IsMarkerKey(key) {
+		in.Buffer.AppendString(lock.KeyMarker(key))
+		in.Rhythm.Key(when)
+		return OutcomeEdited
+	}
+	return OutcomeIgnored
+}
+
+// Len returns the number of keys typed.
+func (in *Input) Len() int {
+	return in.Buffer.VisualLen()
+}
+
+// Bytes returns a copy of the password, to clear after use.
+func (in *Input) Bytes() []byte {
+	return in.Buffer.Bytes()
+}
+
+// Clear wipes the password and its keystroke times.
+func (in *Input) Clear() {
+	in.Buffer.Clear()
+	in.Rhythm.Reset()
+}
+
+// Destroy wipes the password for good. The keystroke times are kept, for
+// the caller to read the rhythm of a submitted password.
+func (in *Input) Destroy() {
+	in.Buffer.Destroy()
+}
+
+// Draw draws the masks of the password on screen from column x of row y,
+// within width columns, special keys in keyStyle, and returns the number
+// of columns used. A password wider than width shows its last keys.
+func (in *Input) Draw(screen tcell.Screen, x, y, width int, ascii bool, style, keyStyle tcell.Style) int {
+	masks := in.Buffer.Masks(ascii)
+	first, clipped := MaskWindow(len(masks), width)
+	col := x
+	if clipped {
+		screen.SetContent(col, y, ellipsis(ascii), nil, style)
+		col++
+	}
+	for _, m := range masks[first:] {
+		st := style
+		if m.Key {
+			st = keyStyle
+		}
+		screen.SetContent(col, y, m.Glyph, nil, st)
+		col++
+	}
+	return col - x
+}
@@ -0,0 +1,81 @@
+package prompt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/lock"
+)
+
+func TestInputHandleKey(t *testing.T) {
+	in := NewInput(&lock.RhythmRecorder{})
+	now := time.Now()
+
+	assert.Equal(t, OutcomeEdited, in.HandleKey(tcell.KeyRune, 'a', now))
+	assert.Equal(t, OutcomeEdited, in.HandleKey(tcell.KeyRune, 'é', now))
+	assert.Equal(t, OutcomeEdited, in.HandleKey(tcell.KeyF3, 0, now))
+	assert.Equal(t, OutcomeIgnored, in.HandleKey(tcell.KeyTab, 0, now))
+	assert.Equal(t, OutcomeIgnored, in.HandleKey(tcell.KeyEscape, 0, now))
+	assert.Equal(t, 3, in.Len())
+	assert.Equal(t, "aé"+lock.KeyMarker(tcell.KeyF3), string(in.Bytes()))
+
+	// Backspace removes whole keys: the marker, then both bytes of é
+	in.HandleKey(tcell.KeyBackspace2, 0, now)
+	in.HandleKey(tcell.KeyBackspace, 0, now)
+	assert.Equal(t, "a", string(in.Bytes()))
+	assert.Len(t, in.Rhythm.Rhythm(), 0, "one key left, no interval")
+
+	assert.Equal(t, OutcomeSubmitted, in.HandleKey(tcell.KeyEnter, 0, now))
+	in.Clear()
+	assert.Equal(t, 0, in.Len())
+}
+
+func TestInputIgnoresScrollKeys(t *testing.T) {
+	in := NewInput(nil)
+	for _, key := range []tcell.Key{KeyScrollUp, KeyScrollDown, KeyScrollTop, KeyScrollBottom} {
+		assert.False(t, lock.IsMarkerKey(key), tcell.KeyNames[key])
+		assert.Equal(t, OutcomeIgnored, in.HandleKey(key, rune(key), time.Now()), tcell.KeyNames[key])
+	}
+	assert.Equal(t, 0, in.Len())
+}
+
+func TestInputDraw(t *testing.T) {
+	sim := tcell.NewSimulationScreen("UTF-8")
+	require.NoError(t, sim.Init())
+	defer sim.Fini()
+	sim.SetSize(20, 2)
+
+	style := tcell.StyleDefault.Dim(true)
+	keyStyle := style.Foreground(tcell.ColorYellow)
+	in := NewInput(nil)
+	for _, r := range "pässword" {
+		in.HandleKey(tcell.KeyRune, r, time.Now())
+	}
+	in.HandleKey(tcell.KeyUp, 0, time.Now())
+
+	row := func() string {
+		sim.Show()
+		var line []rune
+		for x := 0; x < 20; x++ {
+			r, _, _, _ := sim.GetContent(x, 1)
+			line = append(line, r)
+		}
+		return string(line)
+	}
+
+	assert.Equal(t, 9, in.Draw(sim, 2, 1, 18, false, style, keyStyle))
+	assert.Equal(t, "  ********↑         ", row())
+	_, _, st, _ := sim.GetContent(10, 1)
+	assert.Equal(t, keyStyle, st, "special keys stand out")
+	_, _, st, _ = sim.GetContent(2, 1)
+	assert.Equal(t, style, st)
+
+	// Too narrow: the last keys after an ellipsis
+	sim.Clear()
+	assert.Equal(t, 5, in.Draw(sim, 0, 1, 5, true, style, keyStyle))
+	assert.Equal(t, "<***^               ", row())
+}
@@ -0,0 +1,45 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"yule-log/internal/lock"
+)
+
+// maxLineLen bounds non-interactive password input.
+const maxLineLen = 4096
+
+// ReadPasswordLine reads a password from the first line of r, for
+// non-interactive provisioning. The line ending (\n or \r\n) is stripped;
+// everything else, including surrounding spaces, is part of the password.
+func ReadPasswordLine(r io.Reader) ([]byte, error) {
+	br := bufio.NewReaderSize(io.LimitReader(r, maxLineLen+2), maxLineLen+2)
+
+	line, err := br.ReadSlice('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("password longer than %d bytes", maxLineLen)
+		}
+		return nil, fmt.Errorf("reading password: %w", err)
+	}
+
+	n := len(line)
+	if n > 0 && line[n-1] == '\n' {
+		n--
+	}
+	if n > 0 && line[n-1] == '\r' {
+		n--
+	}
+	if n > maxLineLen {
+		lock.ClearBytes(line)
+		return nil, fmt.Errorf("password longer than %d bytes", maxLineLen)
+	}
+
+	password := make([]byte, n)
+	copy(password, line[:n])
+	lock.ClearBytes(line)
+	return password, nil
+}
@@ -0,0 +1,38 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPasswordLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "newline", in: "secret\n", want: "secret"},
+		{name: "crlf", in: "secret\r\n", want: "secret"},
+		{name: "no newline", in: "secret", want: "secret"},
+		{name: "only first line", in: "first\nsecond\n", want: "first"},
+		{name: "spaces kept", in: " pass word \n", want: " pass word "},
+		{name: "empty", in: "", want: ""},
+		{name: "too long", in: strings.Repeat("x", maxLineLen+10), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPasswordLine(strings.NewReader(tt.in))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,455 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+	"golang.org/x/term"
+
+	"yule-log/internal/lock"
+)
+
+// ---- Raw-Mode Password Prompt
+// A minimal line editor for password prompts. It only switches the
+// terminal to raw mode and echoes masks inline: no alternate screen, so
+// the user's terminal content is left untouched.
+
+// Terminal input byte values
+const (
+	byteEscape         = 0x1b
+	byteCtrlC          = 0x03
+	byteBackspace      = 0x7f
+	byteDelete         = 0x08
+	bytePrintableStart = 0x20
+	bytePrintableEnd   = 0x7f
+)
+
+// ErrInterrupted is returned when the user presses Ctrl+C.
+var ErrInterrupted = errors.New("interrupted")
+
+// finalKeys maps the final byte of an ESC [ or ESC O sequence to its key.
+var finalKeys = map[byte]tcell.Key{
+	'A': tcell.KeyUp,
+	'B': tcell.KeyDown,
+	'C': tcell.KeyRight,
+	'D': tcell.KeyLeft,
+	'H': tcell.KeyHome,
+	'F': tcell.KeyEnd,
+	'P': tcell.KeyF1,
+	'Q': tcell.KeyF2,
+	'R': tcell.KeyF3,
+	'S': tcell.KeyF4,
+}
+
+// tildeKeys maps the number of an ESC [ <n> ~ sequence to its key
+// (xterm, rxvt and the Linux console). Insert and Delete are ignored by
+// the input, as on the lock screen.
+var tildeKeys = map[string]tcell.Key{
+	"1": tcell.KeyHome, "7": tcell.KeyHome,
+	"2": tcell.KeyInsert, "3": tcell.KeyDelete,
+	"4": tcell.KeyEnd, "8": tcell.KeyEnd,
+	"5": tcell.KeyPgUp, "6": tcell.KeyPgDn,
+	"11": tcell.KeyF1, "12": tcell.KeyF2, "13": tcell.KeyF3, "14": tcell.KeyF4,
+	"15": tcell.KeyF5, "17": tcell.KeyF6, "18": tcell.KeyF7, "19": tcell.KeyF8,
+	"20": tcell.KeyF9, "21": tcell.KeyF10, "23": tcell.KeyF11, "24": tcell.KeyF12,
+}
+
+// maxKeySequence bounds the length of a key escape sequence, long enough
+// for a modified key such as ESC [ 24 ; 5 ~.
+const maxKeySequence = 8
+
+// parseKey parses the special key escape sequence at the start of buf, and
+// returns it with the sequence length. An unknown ESC [ <n> ~ sequence,
+// such as a modified key, is still a key press: it is returned as
+// tcell.KeyNUL, which the input ignores, rather than taken for Escape.
+func parseKey(buf []byte) (tcell.Key, int, bool) {
+	if len(buf) < 3 || buf[0] != byteEscape || (buf[1] != '[' && buf[1] != 'O') {
+		return 0, 0, false
+	}
+	if key, ok := finalKeys[buf[2]]; ok {
+		return key, 3, true
+	}
+	if buf[1] != '[' {
+		return 0, 0, false
+	}
+	for i := 2; i < min(len(buf), maxKeySequence); i++ {
+		switch b := buf[i]; {
+		case b == '~' && i > 2:
+			key, ok := tildeKeys[string(buf[2:i])]
+			if !ok {
+				key = tcell.KeyNUL
+			}
+			return key, i + 1, true
+		case b < '0' || b > '9' && b != ';':
+			return 0, 0, false
+		}
+	}
+	return 0, 0, false
+}
+
+// State is the outcome of feeding input to an Editor.
+type State int
+
+const (
+	StateEditing   State = iota // More input needed
+	StateDone                   // Enter pressed
+	StateCancelled              // Escape pressed
+)
+
+// Editor reads a password from raw terminal input into an Input, and
+// writes masked echo to Echo.
+//
+// With a known Width, the prompt and its masks are kept on one line: a
+// password longer than the line shows its last keys after an ellipsis,
+// and Resize redraws the line for a new width.
+//
+// With a Policy, a strength meter follows the masks, redrawn on every key.
+type Editor struct {
+	Echo   io.Writer
+	Prompt string               // Written by Start and redrawn with the masks
+	Width  int                  // Terminal columns, 0 if unknown: masks are only appended
+	ASCII  bool                 // Echo special keys with ASCII glyphs, e.g. ^ v < >
+	Rhythm *lock.RhythmRecorder // Records keystroke times when not nil
+	Policy *lock.Policy         // Shows a strength meter against it when not nil
+
+	input   *Input
+	pending []byte // Start of a UTF-8 character split across reads
+	drawn   int    // Columns written on the prompt line
+}
+
+func (e *Editor) in() *Input {
+	if e.input == nil {
+		e.input = NewInput(e.Rhythm)
+	}
+	return e.input
+}
+
+// Password returns a copy of the accumulated password, to clear after use.
+func (e *Editor) Password() []byte {
+	return e.in().Bytes()
+}
+
+// Clear wipes the accumulated password.
+func (e *Editor) Clear() {
+	e.in().Clear()
+	lock.ClearBytes(e.pending)
+	e.pending = nil
+}
+
+// Destroy wipes the password for good, keeping the keystroke times.
+func (e *Editor) Destroy() {
+	lock.ClearBytes(e.pending)
+	e.pending = nil
+	e.in().Destroy()
+}
+
+// Start writes the prompt.
+func (e *Editor) Start() {
+	fmt.Fprint(e.Echo, e.Prompt)
+	e.drawn = uniseg.StringWidth(e.Prompt)
+}
+
+// Resize redraws the prompt line for a terminal now width columns wide.
+func (e *Editor) Resize(width int) {
+	if width <= 0 || width == e.Width {
+		return
+	}
+	// The terminal rewrapped the line drawn for the old width: go back to
+	// its first row before drawing over it.
+	if rows := (e.drawn - 1) / width; e.drawn > 0 && rows > 0 {
+		fmt.Fprintf(e.Echo, "\033[%dA", rows)
+	}
+	e.Width = width
+	e.redraw()
+}
+
+// maskColumns is the number of columns left for masks after the prompt
+// and the meter, keeping the last column free so the cursor never wraps.
+func (e *Editor) maskColumns() int {
+	reserved := 0
+	if e.Policy != nil {
+		reserved = meterColumns
+	}
+	return max(e.Width-uniseg.StringWidth(e.Prompt)-reserved-1, 0)
+}
+
+// redraw rewrites the whole prompt line.
+func (e *Editor) redraw() {
+	fmt.Fprint(e.Echo, "\r\033[J"+e.Prompt)
+	e.drawn = uniseg.StringWidth(e.Prompt)
+	masks := e.in().Buffer.Masks(e.ASCII)
+	first, clipped := MaskWindow(len(masks), e.maskColumns())
+	if clipped {
+		fmt.Fprintf(e.Echo, "%c", ellipsis(e.ASCII))
+		e.drawn++
+	}
+	for _, m := range masks[first:] {
+		e.echoMask(m)
+	}
+	e.drawMeter(len(masks))
+}
+
+// echoMask writes the mask of one key.
+func (e *Editor) echoMask(m lock.Mask) {
+	if m.Key {
+		fmt.Fprintf(e.Echo, "\033[33m%c\033[0m", m.Glyph) // Yellow glyph
+	} else {
+		fmt.Fprintf(e.Echo, "%c", m.Glyph)
+	}
+	e.drawn++
+}
+
+// fits reports whether n masks fit the line without clipping, so they can
+// be echoed one by one.
+func (e *Editor) fits(n int) bool {
+	_, clipped := MaskWindow(n, e.maskColumns())
+	return e.Width == 0 || !clipped
+}
+
+// handleKey applies a key to the input and updates the echo.
+func (e *Editor) handleKey(key tcell.Key, r rune) {
+	before := e.in().Len()
+	e.in().HandleKey(key, r, time.Now())
+	after := e.in().Len()
+
+	switch {
+	case after == before:
+	case e.Policy != nil || !e.fits(max(before, after)):
+		e.redraw()
+	case after > before:
+		masks := e.in().Buffer.Masks(e.ASCII)
+		e.echoMask(masks[len(masks)-1])
+	default:
+		fmt.Fprint(e.Echo, "\b \b")
+		e.drawn--
+	}
+}
+
+// Feed processes a chunk of raw terminal input.
+func (e *Editor) Feed(buf []byte) (State, error) {
+	if len(e.pending) > 0 {
+		joined := append(e.pending, buf...)
+		defer lock.ClearBytes(joined)
+		e.pending = nil
+		buf = joined
+	}
+
+	n := len(buf)
+	for i := 0; i < n; {
+		b := buf[i]
+
+		switch {
+		case b == '\r' || b == '\n': // Enter
+			fmt.Fprint(e.Echo, "\r\n")
+			return StateDone, nil
+
+		case b == byteEscape: // Escape sequence
+			// Special key: ESC [ A, ESC O P, ESC [ 15 ~...
+			if key, length, ok := parseKey(buf[i:]); ok {
+				e.handleKey(key, 0)
+				i += length
+				continue
+			}
+			// Plain Escape key - cancel
+			fmt.Fprint(e.Echo, "\r\n")
+			e.Clear()
+			return StateCancelled, nil
+
+		case b == byteCtrlC:
+			fmt.Fprint(e.Echo, "\r\n")
+			e.Clear()
+			return StateCancelled, ErrInterrupted
+
+		case b == byteBackspace || b == byteDelete:
+			e.handleKey(tcell.KeyBackspace2, 0)
+
+		case b >= bytePrintableStart && b < bytePrintableEnd: // Printable ASCII
+			e.handleKey(tcell.KeyRune, rune(b))
+
+		case b >= utf8.RuneSelf: // UTF-8 character
+			if !utf8.FullRune(buf[i:]) {
+				e.pending = append([]byte(nil), buf[i:]...)
+				return StateEditing, nil
+			}
+			r, size := utf8.DecodeRune(buf[i:])
+			if r != utf8.RuneError {
+				e.handleKey(tcell.KeyRune, r)
+			}
+			i += size
+			continue
+
+		default:
+			// Ignore other control characters
+		}
+
+		i++
+	}
+	return StateEditing, nil
+}
+
+// ---- Strength Meter
+// While a new password is typed, a meter after the masks shows the keys
+// still missing to the policy's minimum length, then the strength
+// estimate: four bars and a word, red for weak, yellow for fair, green
+// for strong. The cursor stays after the last mask.
+
+// meterColumns is the columns kept for the meter: two spaces, four bars,
+// a space and "very strong" or the key count.
+const meterColumns = 20
+
+// meterColors are the SGR colors of each strength.
+var meterColors = [...]int{31, 31, 33, 32, 32}
+
+// drawMeter writes the meter for keys typed keys and moves the cursor back
+// before it.
+func (e *Editor) drawMeter(keys int) {
+	if e.Policy == nil || keys == 0 {
+		return
+	}
+	strength := e.in().Buffer.Strength()
+	text := Meter(strength, keys, e.Policy.MinLength, e.ASCII)
+	fmt.Fprintf(e.Echo, "\033[%dm%s\033[0m\033[%dD", meterColors[strength], text, uniseg.StringWidth(text))
+}
+
+// Meter renders the strength meter of a password of keys keys with the
+// given strength.
+func Meter(strength lock.Strength, keys, minLength int, ascii bool) string {
+	full, empty := "▰", "▱"
+	if ascii {
+		full, empty = "#", "-"
+	}
+	bars := strings.Repeat(full, int(strength)) + strings.Repeat(empty, int(lock.StrengthVeryStrong-strength))
+	if keys < minLength {
+		return fmt.Sprintf("  %s %d/%d keys", bars, keys, minLength)
+	}
+	return "  " + bars + " " + strength.String()
+}
+
+// ---- Layout
+// The set-password prompt and the lock screen indicator show one mask per
+// key on a single line. When the keys outnumber the columns, both show the
+// last ones after an ellipsis, so the line never wraps and the newest key
+// stays visible.
+
+// MaskEllipsis marks masks clipped on the left; MaskEllipsisASCII is used
+// in ASCII mode.
+const (
+	MaskEllipsis      = '…'
+	MaskEllipsisASCII = '<'
+)
+
+func ellipsis(ascii bool) rune {
+	if ascii {
+		return MaskEllipsisASCII
+	}
+	return MaskEllipsis
+}
+
+// MaskWindow returns the index of the first of n masks shown in width
+// columns, and whether an ellipsis (one column) precedes them.
+func MaskWindow(n, width int) (first int, clipped bool) {
+	switch {
+	case n <= width:
+		return 0, false
+	case width <= 0:
+		return n, false
+	default:
+		return n - width + 1, true
+	}
+}
+
+// ReadPassword shows prompt and reads a password from the terminal on in,
+// echoing masks to out. Uses POSIX-secure terminal input via
+// golang.org/x/term. The prompt line follows terminal resizes.
+// Returns the password bytes or nil if cancelled (Escape or Ctrl+C).
+// Keystroke times are recorded to rhythm, which may be nil. With a policy,
+// a strength meter follows the masks.
+func ReadPassword(in *os.File, out io.Writer, prompt string, ascii bool, rhythm *lock.RhythmRecorder, policy *lock.Policy) ([]byte, error) {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("stdin is not a terminal")
+	}
+
+	// Enter raw mode (disables echo and line buffering)
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("entering raw mode: %w", err)
+	}
+
+	// Ensure terminal is restored on exit
+	defer term.Restore(fd, oldState)
+
+	editor := &Editor{Echo: out, Prompt: prompt, ASCII: ascii, Rhythm: rhythm, Policy: policy}
+	defer editor.Destroy()
+	if width, _, err := term.GetSize(fd); err == nil {
+		editor.Width = width
+	}
+	var mu sync.Mutex // Guards editor against resizes during Feed
+	editor.Start()
+
+	// Handle signals to restore terminal on interrupt, and redraw the
+	// prompt line on resize
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	winchChan := make(chan os.Signal, 1)
+	signal.Notify(winchChan, syscall.SIGWINCH)
+	defer signal.Stop(winchChan)
+
+	doneChan := make(chan struct{})
+	defer close(doneChan)
+
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				term.Restore(fd, oldState)
+				os.Exit(1)
+			case <-winchChan:
+				if width, _, err := term.GetSize(fd); err == nil {
+					mu.Lock()
+					editor.Resize(width)
+					mu.Unlock()
+				}
+			case <-doneChan:
+				return
+			}
+		}
+	}()
+
+	buf := make([]byte, 16)
+	defer lock.ClearBytes(buf)
+
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return editor.Password(), nil
+			}
+			editor.Clear()
+			return nil, fmt.Errorf("reading input: %w", err)
+		}
+
+		mu.Lock()
+		state, err := editor.Feed(buf[:n])
+		mu.Unlock()
+		switch {
+		case err != nil:
+			return nil, err
+		case state == StateDone:
+			return editor.Password(), nil
+		case state == StateCancelled:
+			return nil, nil
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package prompt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/lock"
+)
+
+func TestEditorFeed(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []string
+		wantState State
+		wantErr   error
+		wantPass  string
+		wantEcho  string
+		ascii     bool
+	}{
+		{
+			name:      "simple password",
+			input:     []string{"abc\r"},
+			wantState: StateDone,
+			wantPass:  "abc",
+			wantEcho:  "***\r\n",
+		},
+		{
+			name:      "split across reads",
+			input:     []string{"ab", "c", "\n"},
+			wantState: StateDone,
+			wantPass:  "abc",
+			wantEcho:  "***\r\n",
+		},
+		{
+			name:      "arrows",
+			input:     []string{"a\x1b[A\x1b[D\r"},
+			wantState: StateDone,
+			wantPass:  "a" + lock.ArrowUpMarker + lock.ArrowLeftMarker,
+			wantEcho:  "*\033[33m↑\033[0m\033[33m←\033[0m\r\n",
+		},
+		{
+			name:      "ascii arrows",
+			input:     []string{"\x1b[B\x1b[C\r"},
+			ascii:     true,
+			wantState: StateDone,
+			wantPass:  lock.ArrowDownMarker + lock.ArrowRightMarker,
+			wantEcho:  "\033[33mv\033[0m\033[33m>\033[0m\r\n",
+		},
+		{
+			name:      "function and navigation keys",
+			input:     []string{"\x1bOP\x1b[15~\x1b[24~\x1b[H\x1b[4~\x1b[5~\x1b[6~\r"},
+			wantState: StateDone,
+			wantPass: lock.KeyMarker(tcell.KeyF1) + lock.KeyMarker(tcell.KeyF5) + lock.KeyMarker(tcell.KeyF12) +
+				lock.KeyMarker(tcell.KeyHome) + lock.KeyMarker(tcell.KeyEnd) + lock.KeyMarker(tcell.KeyPgUp) + lock.KeyMarker(tcell.KeyPgDn),
+			wantEcho: "\033[33m①\033[0m\033[33m⑤\033[0m\033[33m⑫\033[0m\033[33m⇱\033[0m\033[33m⇲\033[0m\033[33m⇞\033[0m\033[33m⇟\033[0m\r\n",
+		},
+		{
+			name:      "ascii function keys",
+			input:     []string{"\x1b[21~\x1b[1~\r"},
+			ascii:     true,
+			wantState: StateDone,
+			wantPass:  lock.KeyMarker(tcell.KeyF10) + lock.KeyMarker(tcell.KeyHome),
+			wantEcho:  "\033[33m0\033[0m\033[33m[\033[0m\r\n",
+		},
+		{
+			name:      "backspace removes function key marker",
+			input:     []string{"a\x1b[17~\x7f\r"},
+			wantState: StateDone,
+			wantPass:  "a",
+			wantEcho:  "*\033[33m⑥\033[0m\b \b\r\n",
+		},
+		{
+			name:      "insert, delete and unknown tilde keys are ignored",
+			input:     []string{"a\x1b[2~\x1b[3~\x1b[3;5~\x1b[99~\x1b[24;2~b\r"},
+			wantState: StateDone,
+			wantPass:  "ab",
+			wantEcho:  "**\r\n",
+		},
+		{
+			name:      "unknown sequence cancels",
+			input:     []string{"a\x1b[Z"},
+			wantState: StateCancelled,
+			wantEcho:  "*\r\n",
+		},
+		{
+			name:      "backspace removes arrow marker",
+			input:     []string{"a\x1b[A\x7f\r"},
+			wantState: StateDone,
+			wantPass:  "a",
+			wantEcho:  "*\033[33m↑\033[0m\b \b\r\n",
+		},
+		{
+			name:      "backspace on empty is ignored",
+			input:     []string{"\x7f\x08x\r"},
+			wantState: StateDone,
+			wantPass:  "x",
+			wantEcho:  "*\r\n",
+		},
+		{
+			name:      "escape cancels",
+			input:     []string{"abc\x1b"},
+			wantState: StateCancelled,
+			wantEcho:  "***\r\n",
+		},
+		{
+			name:      "ctrl-c interrupts",
+			input:     []string{"ab\x03"},
+			wantState: StateCancelled,
+			wantErr:   ErrInterrupted,
+			wantEcho:  "**\r\n",
+		},
+		{
+			name:      "control characters ignored",
+			input:     []string{"a\x01\x02b\r"},
+			wantState: StateDone,
+			wantPass:  "ab",
+			wantEcho:  "**\r\n",
+		},
+		{
+			name:      "still editing",
+			input:     []string{"abc"},
+			wantState: StateEditing,
+			wantPass:  "abc",
+			wantEcho:  "***",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var echo bytes.Buffer
+			e := &Editor{Echo: &echo, ASCII: tt.ascii}
+
+			var state State
+			var err error
+			for _, in := range tt.input {
+				state, err = e.Feed([]byte(in))
+				if state != StateEditing {
+					break
+				}
+			}
+
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantState, state)
+			assert.Equal(t, tt.wantPass, string(e.Password()))
+			assert.Equal(t, tt.wantEcho, echo.String())
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   tcell.Key
+		length int
+		ok     bool
+	}{
+		{"\x1b[A", tcell.KeyUp, 3, true},
+		{"\x1bOP", tcell.KeyF1, 3, true},
+		{"\x1b[24~x", tcell.KeyF12, 5, true},
+		{"\x1b[2~", tcell.KeyInsert, 4, true},
+		{"\x1b[3~", tcell.KeyDelete, 4, true},
+		{"\x1b[5~", tcell.KeyPgUp, 4, true},
+		{"\x1b[6~", tcell.KeyPgDn, 4, true},
+		{"\x1b[99~", tcell.KeyNUL, 5, true},   // Unknown: ignored
+		{"\x1b[3;5~", tcell.KeyNUL, 6, true},  // Ctrl+Delete
+		{"\x1b[24;2~", tcell.KeyNUL, 7, true}, // Shift+F12
+		{"\x1b[~", 0, 0, false},
+		{"\x1b[Z", 0, 0, false},
+		{"\x1b[2", 0, 0, false},       // Incomplete
+		{"\x1b[123456~", 0, 0, false}, // Too long
+		{"\x1bO2~", 0, 0, false},
+		{"\x1b", 0, 0, false},
+	}
+	for _, tt := range tests {
+		key, length, ok := parseKey([]byte(tt.in))
+		assert.Equal(t, tt.ok, ok, "%q", tt.in)
+		assert.Equal(t, tt.want, key, "%q", tt.in)
+		assert.Equal(t, tt.length, length, "%q", tt.in)
+	}
+}
+
+func TestEditorRecordsRhythm(t *testing.T) {
+	var echo bytes.Buffer
+	e := &Editor{Echo: &echo, Rhythm: &lock.RhythmRecorder{}}
+
+	for _, in := range []string{"a", "b", "\x1b[A", "c", "\x7f"} {
+		_, err := e.Feed([]byte(in))
+		require.NoError(t, err)
+	}
+	assert.Len(t, e.Rhythm.Rhythm(), 2, "three keys kept after backspace")
+
+	e.Clear()
+	assert.Empty(t, e.Rhythm.Rhythm())
+}
+
+func TestMaskWindow(t *testing.T) {
+	tests := []struct {
+		n, width    int
+		wantFirst   int
+		wantClipped bool
+	}{
+		{0, 10, 0, false},
+		{10, 10, 0, false},
+		{11, 10, 2, true}, // Ellipsis and the last 9
+		{3, 1, 3, true},   // Ellipsis only
+		{3, 0, 3, false},  // No room at all
+	}
+	for _, tt := range tests {
+		first, clipped := MaskWindow(tt.n, tt.width)
+		assert.Equal(t, tt.wantFirst, first, "n=%d width=%d", tt.n, tt.width)
+		assert.Equal(t, tt.wantClipped, clipped, "n=%d width=%d", tt.n, tt.width)
+	}
+}
+
+func TestEditorClipsToWidth(t *testing.T) {
+	var echo bytes.Buffer
+	e := &Editor{Echo: &echo, Prompt: "pw: ", Width: 10} // Room for 5 masks
+	e.Start()
+
+	_, err := e.Feed([]byte("abcde"))
+	require.NoError(t, err)
+	assert.Equal(t, "pw: *****", echo.String(), "fits: appended")
+
+	echo.Reset()
+	_, err = e.Feed([]byte("f"))
+	require.NoError(t, err)
+	assert.Equal(t, "\r\033[Jpw: …****", echo.String(), "clipped: redrawn")
+
+	echo.Reset()
+	_, err = e.Feed([]byte("\x7f"))
+	require.NoError(t, err)
+	assert.Equal(t, "\r\033[Jpw: *****", echo.String(), "fits again: redrawn")
+
+	echo.Reset()
+	_, err = e.Feed([]byte("\x7f\r"))
+	require.NoError(t, err)
+	assert.Equal(t, "\b \b\r\n", echo.String())
+	assert.Equal(t, "abcd", string(e.Password()))
+}
+
+func TestEditorResize(t *testing.T) {
+	var echo bytes.Buffer
+	e := &Editor{Echo: &echo, Prompt: "pw: ", Width: 80, ASCII: true}
+	e.Start()
+	_, err := e.Feed([]byte("abcdefgh"))
+	require.NoError(t, err)
+
+	// The 12 columns drawn now span 3 rows of 5: go up 2 and redraw.
+	echo.Reset()
+	e.Resize(5)
+	assert.Equal(t, "\033[2A\r\033[Jpw: ", echo.String(), "no room left for masks")
+
+	echo.Reset()
+	e.Resize(8)
+	assert.Equal(t, "\r\033[Jpw: <**", echo.String())
+
+	echo.Reset()
+	e.Resize(8)
+	assert.Empty(t, echo.String(), "same width")
+	assert.Equal(t, "abcdefgh", string(e.Password()))
+}
+
+func TestEditorUTF8(t *testing.T) {
+	var echo bytes.Buffer
+	e := &Editor{Echo: &echo}
+
+	// é split across two reads, then erased as one key
+	for _, in := range []string{"a\xc3", "\xa9b", "\x7f\x7f", "ü\r"} {
+		_, err := e.Feed([]byte(in))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "aü", string(e.Password()))
+	assert.Equal(t, "***\b \b\b \b*\r\n", echo.String())
+}
+
+func TestMeter(t *testing.T) {
+	assert.Equal(t, "  ▱▱▱▱ 3/8 keys", Meter(lock.StrengthVeryWeak, 3, 8, false))
+	assert.Equal(t, "  ▰▰▱▱ fair", Meter(lock.StrengthFair, 8, 8, false))
+	assert.Equal(t, "  #### very strong", Meter(lock.StrengthVeryStrong, 12, 8, true))
+}
+
+func TestEditorStrengthMeter(t *testing.T) {
+	var echo bytes.Buffer
+	e := &Editor{Echo: &echo, Prompt: "pw: ", Width: 80, Policy: &lock.Policy{MinLength: 2}}
+	e.Start()
+
+	echo.Reset()
+	_, err := e.Feed([]byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, "\r\033[Jpw: *\033[31m  ▱▱▱▱ 1/2 keys\033[0m\033[15D", echo.String(),
+		"redrawn with the meter, the cursor back after the masks")
+
+	echo.Reset()
+	_, err = e.Feed([]byte("b"))
+	require.NoError(t, err)
+	assert.Equal(t, "\r\033[Jpw: **\033[31m  ▱▱▱▱ very weak\033[0m\033[16D", echo.String())
+
+	// The meter keeps its columns when clipping.
+	e.Width = 4 + meterColumns + 4
+	assert.Equal(t, 3, e.maskColumns())
+}
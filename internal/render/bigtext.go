@@ -0,0 +1,55 @@
+package render
+
+import "strings"
+
+// ---- Big Text
+// A small figlet-style font for the clock overlay: digits, colon and
+// space, 5 pixels high. Each pixel is two cells wide so the glyphs keep
+// their shape in terminal cells, which are about twice as high as wide.
+
+// BigTextHeight is the number of rows of big text.
+const BigTextHeight = 5
+
+// bigFont holds the glyphs, row by row, '#' for lit pixels.
+var bigFont = map[rune][BigTextHeight]string{
+	'0': {"###", "# #", "# #", "# #", "###"},
+	'1': {" # ", "## ", " # ", " # ", "###"},
+	'2': {"###", "  #", "###", "#  ", "###"},
+	'3': {"###", "  #", " ##", "  #", "###"},
+	'4': {"# #", "# #", "###", "  #", "  #"},
+	'5': {"###", "#  ", "###", "  #", "###"},
+	'6': {"###", "#  ", "###", "# #", "###"},
+	'7': {"###", "  #", "  #", " # ", " # "},
+	'8': {"###", "# #", "###", "# #", "###"},
+	'9': {"###", "# #", "###", "  #", "###"},
+	':': {" ", "#", " ", "#", " "},
+	' ': {" ", " ", " ", " ", " "},
+}
+
+// BigText renders s in the big font: BigTextHeight rows of equal width,
+// '#' for lit cells and ' ' for the others, glyphs one pixel apart.
+// Runes without a glyph are skipped.
+func BigText(s string) []string {
+	var rows [BigTextHeight]strings.Builder
+	first := true
+	for _, r := range s {
+		glyph, ok := bigFont[r]
+		if !ok {
+			continue
+		}
+		for i, line := range glyph {
+			if !first {
+				rows[i].WriteString("  ")
+			}
+			for _, px := range line {
+				rows[i].WriteString(strings.Repeat(string(px), 2))
+			}
+		}
+		first = false
+	}
+	out := make([]string, BigTextHeight)
+	for i := range rows {
+		out[i] = rows[i].String()
+	}
+	return out
+}
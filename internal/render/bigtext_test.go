@@ -0,0 +1,24 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBigText(t *testing.T) {
+	assert.Equal(t, []string{
+		"  ##        ######",
+		"####    ##      ##",
+		"  ##            ##",
+		"  ##    ##    ##  ",
+		"######        ##  ",
+	}, BigText("1:7"))
+
+	rows := BigText("12:34:56")
+	for _, row := range rows {
+		assert.Len(t, row, 6*6+2*2+7*2, "rows have equal width")
+	}
+	assert.Equal(t, BigText("12"), BigText("1x2"), "unknown runes are skipped")
+	assert.Equal(t, []string{"", "", "", "", ""}, BigText(""))
+}
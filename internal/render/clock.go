@@ -0,0 +1,77 @@
+package render
+
+import "time"
+
+// ---- Frame Pacing
+// Frames are paced against deadlines: a frame that ran late is followed by
+// the next one right away, so the animation keeps its speed when a frame
+// takes longer than usual. After a stall, the process stopped (SIGSTOP, a
+// suspended laptop) or Show blocked on a terminal whose output is paused
+// (Ctrl-S), the missed frames are skipped instead of simulated back to
+// back in a burst.
+
+// StallThreshold is how late a frame must be to count as a stall.
+const StallThreshold = 500 * time.Millisecond
+
+// Stall describes frames missed while the screensaver could not run.
+type Stall struct {
+	At       time.Time     // When the frames resumed
+	Duration time.Duration // How late the frame was
+	Skipped  int           // Frames that were not simulated
+}
+
+// FrameClock paces frames and measures how long they take.
+type FrameClock struct {
+	// Now and Sleep default to time.Now and time.Sleep.
+	Now   func() time.Time
+	Sleep func(time.Duration)
+
+	// Work is the time the last frame took, from the end of the previous
+	// Wait to the start of this one.
+	Work time.Duration
+
+	next  time.Time // Deadline of the next frame
+	start time.Time // Start of the current frame
+}
+
+// Wait waits until the next frame is due, interval after the previous
+// one. It reports a stall, skipping the missed frames, when the frame is
+// more than StallThreshold late.
+func (c *FrameClock) Wait(interval time.Duration) (Stall, bool) {
+	now := c.now()
+	if !c.start.IsZero() {
+		c.Work = now.Sub(c.start)
+	}
+	if c.next.IsZero() {
+		c.next = now
+	}
+	c.next = c.next.Add(interval)
+
+	var stall Stall
+	stalled := false
+	switch late := now.Sub(c.next); {
+	case late < 0:
+		c.sleep(-late)
+	case late > StallThreshold:
+		stall = Stall{At: now, Duration: late, Skipped: int(late / max(interval, time.Millisecond))}
+		stalled = true
+		c.next = now
+	}
+	c.start = c.now()
+	return stall, stalled
+}
+
+func (c *FrameClock) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+func (c *FrameClock) sleep(d time.Duration) {
+	if c.Sleep != nil {
+		c.Sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameClock(t *testing.T) {
+	const interval = 30 * time.Millisecond
+	now := time.Unix(1700000000, 0)
+	var slept []time.Duration
+	c := &FrameClock{
+		Now: func() time.Time { return now },
+		Sleep: func(d time.Duration) {
+			slept = append(slept, d)
+			now = now.Add(d)
+		},
+	}
+	frame := func(work time.Duration) (Stall, bool) {
+		now = now.Add(work)
+		return c.Wait(interval)
+	}
+
+	// The first frame has no start to measure from; then render time is
+	// taken off the sleep
+	_, stalled := frame(10 * time.Millisecond)
+	assert.False(t, stalled)
+	_, stalled = frame(10 * time.Millisecond)
+	assert.False(t, stalled)
+	assert.Equal(t, []time.Duration{interval, 20 * time.Millisecond}, slept)
+	assert.Equal(t, 10*time.Millisecond, c.Work)
+
+	// A slow frame is caught up by the next one
+	slept = nil
+	frame(50 * time.Millisecond)
+	frame(5 * time.Millisecond)
+	assert.Equal(t, []time.Duration{5 * time.Millisecond}, slept)
+
+	// A stall skips the missed frames instead of catching up
+	slept = nil
+	stall, stalled := frame(3 * time.Second)
+	assert.True(t, stalled)
+	assert.Equal(t, now, stall.At)
+	assert.Equal(t, 3*time.Second-interval, stall.Duration)
+	assert.Equal(t, 99, stall.Skipped)
+	assert.Empty(t, slept)
+
+	_, stalled = frame(10 * time.Millisecond)
+	assert.False(t, stalled)
+	assert.Equal(t, []time.Duration{20 * time.Millisecond}, slept, "back to the normal pace")
+}
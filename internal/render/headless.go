@@ -0,0 +1,44 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Headless Screen
+// Fixed-size in-memory screens let the screensaver run without a terminal,
+// for smoke tests in CI.
+
+// DefaultHeadlessWidth and DefaultHeadlessHeight size headless screens
+// when no size is given.
+const (
+	DefaultHeadlessWidth  = 80
+	DefaultHeadlessHeight = 24
+)
+
+// ParseSize parses a screen size such as "80x24".
+func ParseSize(s string) (width, height int, err error) {
+	ws, hs, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid size %q (want WIDTHxHEIGHT, e.g. 80x24)", s)
+	}
+	width, errW := strconv.Atoi(ws)
+	height, errH := strconv.Atoi(hs)
+	if errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid size %q (want WIDTHxHEIGHT, e.g. 80x24)", s)
+	}
+	return width, height, nil
+}
+
+// NewHeadless returns an initialized in-memory screen of the given size.
+func NewHeadless(width, height int) (tcell.SimulationScreen, error) {
+	sim := tcell.NewSimulationScreen("UTF-8")
+	if err := sim.Init(); err != nil {
+		return nil, fmt.Errorf("initializing headless screen: %w", err)
+	}
+	sim.SetSize(width, height)
+	return sim, nil
+}
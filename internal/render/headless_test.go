@@ -0,0 +1,44 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		w, h int
+		ok   bool
+	}{
+		{in: "80x24", w: 80, h: 24, ok: true},
+		{in: " 200X50 ", w: 200, h: 50, ok: true},
+		{in: "80"},
+		{in: "80x"},
+		{in: "0x24"},
+		{in: "80x-1"},
+		{in: "axb"},
+	}
+	for _, tt := range tests {
+		w, h, err := ParseSize(tt.in)
+		if !tt.ok {
+			assert.Error(t, err, tt.in)
+			continue
+		}
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.w, w)
+		assert.Equal(t, tt.h, h)
+	}
+}
+
+func TestNewHeadless(t *testing.T) {
+	sim, err := NewHeadless(40, 10)
+	require.NoError(t, err)
+	defer sim.Fini()
+
+	w, h := sim.Size()
+	assert.Equal(t, 40, w)
+	assert.Equal(t, 10, h)
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Bandwidth Meter
+
+// approxBytesPerCell is a rough estimate of what a changed cell costs on
+// the wire: cursor positioning, an SGR color sequence and the glyph.
+const approxBytesPerCell = 16
+
+type cell struct {
+	r     rune
+	style tcell.Style
+}
+
+// MeteredScreen wraps a tcell.Screen and counts how many cells actually
+// change between two shown frames, to quantify terminal bandwidth.
+type MeteredScreen struct {
+	tcell.Screen
+
+	width, height int
+	shown, next   []cell
+
+	// LastChanged is the number of cells changed by the last Show.
+	LastChanged int
+	// AvgChanged is a moving average of changed cells per shown frame.
+	AvgChanged float64
+}
+
+// NewMeteredScreen wraps screen.
+func NewMeteredScreen(screen tcell.Screen) *MeteredScreen {
+	return &MeteredScreen{Screen: screen}
+}
+
+// SetContent records the cell and forwards it to the screen.
+func (m *MeteredScreen) SetContent(x, y int, primary rune, combining []rune, style tcell.Style) {
+	m.ensureSize()
+	if x >= 0 && x < m.width && y >= 0 && y < m.height {
+		m.next[y*m.width+x] = cell{r: primary, style: style}
+	}
+	m.Screen.SetContent(x, y, primary, combining, style)
+}
+
+// Show counts changed cells and forwards to the screen.
+func (m *MeteredScreen) Show() {
+	m.ensureSize()
+	changed := 0
+	for i := range m.next {
+		if m.next[i] != m.shown[i] {
+			changed++
+		}
+	}
+	copy(m.shown, m.next)
+
+	m.LastChanged = changed
+	m.AvgChanged = m.AvgChanged*0.9 + float64(changed)*0.1
+	m.Screen.Show()
+}
+
+// BytesPerFrame estimates the bytes sent per shown frame.
+func (m *MeteredScreen) BytesPerFrame() int {
+	return int(m.AvgChanged) * approxBytesPerCell
+}
+
+func (m *MeteredScreen) ensureSize() {
+	w, h := m.Screen.Size()
+	if w == m.width && h == m.height {
+		return
+	}
+	m.width, m.height = w, h
+	m.shown = make([]cell, w*h)
+	m.next = make([]cell, w*h)
+}
@@ -0,0 +1,42 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeteredScreen(t *testing.T) {
+	sim := tcell.NewSimulationScreen("UTF-8")
+	require.NoError(t, sim.Init())
+	defer sim.Fini()
+	sim.SetSize(10, 5)
+
+	m := NewMeteredScreen(sim)
+	red := tcell.StyleDefault.Foreground(tcell.ColorRed)
+
+	m.SetContent(0, 0, 'a', nil, red)
+	m.SetContent(1, 0, 'b', nil, red)
+	m.Show()
+	assert.Equal(t, 2, m.LastChanged)
+
+	// Same content again: nothing changes
+	m.SetContent(0, 0, 'a', nil, red)
+	m.SetContent(1, 0, 'b', nil, red)
+	m.Show()
+	assert.Equal(t, 0, m.LastChanged)
+
+	// Style change counts
+	m.SetContent(0, 0, 'a', nil, tcell.StyleDefault)
+	m.SetContent(99, 99, 'x', nil, red) // out of bounds is ignored
+	m.Show()
+	assert.Equal(t, 1, m.LastChanged)
+
+	// Cells reach the wrapped screen
+	r, _, style, _ := sim.GetContent(1, 0)
+	assert.Equal(t, 'b', r)
+	assert.Equal(t, red, style)
+	assert.Greater(t, m.AvgChanged, 0.0)
+}
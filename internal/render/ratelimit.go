@@ -0,0 +1,82 @@
+package render
+
+import "time"
+
+// ---- Output Rate Limiting
+// The simulation always runs at full frame rate; the rate limiter decides
+// which frames are actually transmitted to the terminal. Over slow links
+// (high-latency SSH) writing every frame floods the connection.
+
+const (
+	// MaxEvery is the largest transmit interval the automatic mode uses.
+	MaxEvery = 8
+
+	// slowShow is the average Show duration above which output is reduced.
+	slowShow = 12 * time.Millisecond
+	// fastShow is the average Show duration below which output is raised.
+	fastShow = 3 * time.Millisecond
+
+	// adjustWindow is the number of transmitted frames between adjustments.
+	adjustWindow = 30
+)
+
+// RateLimiter transmits one frame out of Every. With Auto set, Every is
+// adapted from the time the terminal takes to accept a frame: tcell's Show
+// writes synchronously, so it blocks when the link cannot keep up.
+type RateLimiter struct {
+	Every int  // Transmit one frame out of Every (<= 1 transmits all)
+	Auto  bool // Adapt Every from observed Show durations
+
+	frame    int
+	skipped  int
+	total    time.Duration
+	observed int
+}
+
+// ShouldTransmit advances to the next frame and reports whether it must be
+// shown.
+func (r *RateLimiter) ShouldTransmit() bool {
+	r.frame++
+	if r.Every <= 1 || r.frame >= r.Every {
+		r.frame = 0
+		return true
+	}
+	r.skipped++
+	return false
+}
+
+// Skipped returns the number of frames skipped since the last transmitted
+// frame was observed.
+func (r *RateLimiter) Skipped() int {
+	return r.skipped
+}
+
+// Discard forgets the last transmitted frame without observing it: a Show
+// blocked by a paused terminal says nothing about the link.
+func (r *RateLimiter) Discard() {
+	r.skipped = 0
+}
+
+// Observe records how long the last transmitted frame took to show.
+func (r *RateLimiter) Observe(d time.Duration) {
+	r.skipped = 0
+	if !r.Auto {
+		return
+	}
+
+	r.total += d
+	r.observed++
+	if r.observed < adjustWindow {
+		return
+	}
+
+	avg := r.total / time.Duration(r.observed)
+	r.total, r.observed = 0, 0
+
+	switch {
+	case avg > slowShow && r.Every < MaxEvery:
+		r.Every = max(r.Every, 1) + 1
+	case avg < fastShow && r.Every > 1:
+		r.Every--
+	}
+}
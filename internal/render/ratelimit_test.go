@@ -0,0 +1,65 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterEvery(t *testing.T) {
+	r := &RateLimiter{Every: 3}
+
+	var got []bool
+	for i := 0; i < 7; i++ {
+		got = append(got, r.ShouldTransmit())
+	}
+	assert.Equal(t, []bool{false, false, true, false, false, true, false}, got)
+	assert.Equal(t, 5, r.Skipped())
+
+	r.Observe(time.Millisecond)
+	assert.Zero(t, r.Skipped())
+}
+
+func TestRateLimiterAllFrames(t *testing.T) {
+	for _, every := range []int{0, 1} {
+		r := &RateLimiter{Every: every}
+		for i := 0; i < 5; i++ {
+			assert.True(t, r.ShouldTransmit())
+		}
+	}
+}
+
+func TestRateLimiterAuto(t *testing.T) {
+	r := &RateLimiter{Every: 1, Auto: true}
+
+	// Slow link: interval grows up to MaxEvery
+	for i := 0; i < adjustWindow*(MaxEvery+2); i++ {
+		r.Observe(50 * time.Millisecond)
+	}
+	assert.Equal(t, MaxEvery, r.Every)
+
+	// Fast link: back to every frame
+	for i := 0; i < adjustWindow*(MaxEvery+2); i++ {
+		r.Observe(time.Millisecond)
+	}
+	assert.Equal(t, 1, r.Every)
+}
+
+func TestRateLimiterManualIgnoresTiming(t *testing.T) {
+	r := &RateLimiter{Every: 2}
+	for i := 0; i < adjustWindow*2; i++ {
+		r.Observe(time.Second)
+	}
+	assert.Equal(t, 2, r.Every)
+}
+
+func TestRateLimiterDiscard(t *testing.T) {
+	r := &RateLimiter{Every: 1, Auto: true}
+	r.ShouldTransmit()
+	for i := 0; i < adjustWindow; i++ {
+		r.Discard() // A paused terminal, not a slow link
+	}
+	assert.Equal(t, 1, r.Every)
+	assert.Zero(t, r.Skipped())
+}
@@ -0,0 +1,389 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"golang.org/x/term"
+)
+
+// ---- Backends
+// The screensaver draws on a tcell.Screen. The tcell backend drives the
+// terminal through terminfo; the VT backend writes the common VT100/xterm
+// escape sequences directly, for terminals terminfo gets wrong, and starts
+// faster as it reads no terminal database. Without a terminal, it writes
+// the frames to stdout: a recording that cat plays back.
+
+// Backend names a screen implementation.
+type Backend string
+
+const (
+	BackendTCell Backend = "tcell" // terminfo, through tcell (default)
+	BackendVT    Backend = "vt"    // Direct escape sequences
+)
+
+// Backends lists the accepted backend names.
+var Backends = []Backend{BackendTCell, BackendVT}
+
+// ParseBackend parses a --backend value, "" for tcell.
+func ParseBackend(s string) (Backend, error) {
+	switch b := Backend(strings.ToLower(strings.TrimSpace(s))); b {
+	case "", BackendTCell:
+		return BackendTCell, nil
+	case BackendVT:
+		return b, nil
+	}
+	return "", fmt.Errorf("invalid backend %q (want tcell or vt)", s)
+}
+
+// ---- VT Screen
+
+// vtCell is a cell as last written to the terminal.
+type vtCell struct {
+	text  string // Primary rune and combining runes
+	style tcell.Style
+}
+
+// VTScreen is a tcell.Screen writing escape sequences itself. Cells and
+// events go through an in-memory tcell screen; Show writes the cells that
+// changed since the previous frame. Mouse input is not supported.
+type VTScreen struct {
+	tcell.SimulationScreen
+
+	out    io.Writer
+	tty    *os.File    // nil when writing a recording
+	state  *term.State // Terminal mode to restore
+	winch  chan os.Signal
+	colors int
+
+	prev    []vtCell
+	cursorX int // Cursor shown at Show, -1 when hidden
+	cursorY int
+	fini    sync.Once
+}
+
+// NewVT takes over the controlling terminal: raw mode, alternate screen.
+func NewVT() (*VTScreen, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening terminal: %w", err)
+	}
+	width, height, err := term.GetSize(int(tty.Fd()))
+	if err != nil {
+		tty.Close()
+		return nil, fmt.Errorf("reading terminal size: %w", err)
+	}
+	v, err := newVT(tty, width, height, vtColors())
+	if err != nil {
+		tty.Close()
+		return nil, err
+	}
+	if v.state, err = term.MakeRaw(int(tty.Fd())); err != nil {
+		tty.Close()
+		return nil, fmt.Errorf("entering raw mode: %w", err)
+	}
+	v.tty = tty
+	io.WriteString(tty, "\x1b[?1049h\x1b[?25l\x1b[2J")
+
+	v.winch = make(chan os.Signal, 1)
+	signal.Notify(v.winch, syscall.SIGWINCH)
+	go v.watchSize()
+	go v.readInput()
+	return v, nil
+}
+
+// NewVTWriter returns a VT screen of a fixed size writing to out, with no
+// input, e.g. to record frames.
+func NewVTWriter(out io.Writer, width, height int) (*VTScreen, error) {
+	return newVT(out, width, height, 1<<24)
+}
+
+func newVT(out io.Writer, width, height, colors int) (*VTScreen, error) {
+	sim, err := NewHeadless(width, height)
+	if err != nil {
+		return nil, err
+	}
+	return &VTScreen{SimulationScreen: sim, out: out, colors: colors, cursorX: -1}, nil
+}
+
+// vtColors guesses the colors of the terminal from its environment, as no
+// terminal database is read.
+func vtColors() int {
+	switch ct := os.Getenv("COLORTERM"); {
+	case ct == "truecolor" || ct == "24bit":
+		return 1 << 24
+	case os.Getenv("TERM") == "linux":
+		return 8
+	}
+	return 256
+}
+
+func (v *VTScreen) Colors() int { return v.colors }
+
+func (v *VTScreen) EnableFocus() {
+	if v.tty != nil {
+		io.WriteString(v.out, "\x1b[?1004h")
+	}
+}
+
+func (v *VTScreen) DisableFocus() {
+	if v.tty != nil {
+		io.WriteString(v.out, "\x1b[?1004l")
+	}
+}
+
+func (v *VTScreen) ShowCursor(x, y int) { v.cursorX, v.cursorY = x, y }
+func (v *VTScreen) HideCursor()         { v.cursorX = -1 }
+
+// Sync redraws every cell on the next Show.
+func (v *VTScreen) Sync() {
+	v.prev = nil
+	v.Show()
+}
+
+// Show writes the cells that changed since the last frame.
+func (v *VTScreen) Show() {
+	width, height := v.Size()
+	var buf bytes.Buffer
+	if len(v.prev) != width*height {
+		v.prev = make([]vtCell, width*height)
+		buf.WriteString("\x1b[0m\x1b[2J")
+		for i := range v.prev {
+			v.prev[i].text = " "
+		}
+	}
+	buf.WriteString("\x1b[?25l")
+
+	style := tcell.Style{}
+	styled := false
+	atX, atY := -1, -1
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			primary, combining, st, w := v.GetContent(x, y)
+			if primary == 0 {
+				primary = ' '
+			}
+			c := vtCell{text: string(append([]rune{primary}, combining...)), style: st}
+			if v.prev[y*width+x] == c {
+				x += max(w, 1) - 1
+				continue
+			}
+			v.prev[y*width+x] = c
+			if x != atX || y != atY {
+				fmt.Fprintf(&buf, "\x1b[%d;%dH", y+1, x+1)
+			}
+			if !styled || st != style {
+				buf.WriteString(SGR(st))
+				style, styled = st, true
+			}
+			buf.WriteString(c.text)
+			atX, atY = x+max(w, 1), y
+			x += max(w, 1) - 1
+		}
+	}
+	buf.WriteString("\x1b[0m")
+	if v.cursorX >= 0 {
+		fmt.Fprintf(&buf, "\x1b[%d;%dH\x1b[?25h", v.cursorY+1, v.cursorX+1)
+	}
+	v.out.Write(buf.Bytes())
+}
+
+// Fini gives the terminal back. It is safe to call more than once.
+func (v *VTScreen) Fini() {
+	v.fini.Do(func() {
+		if v.tty != nil {
+			signal.Stop(v.winch)
+			close(v.winch)
+			io.WriteString(v.tty, "\x1b[0m\x1b[?1004l\x1b[?25h\x1b[?1049l")
+			term.Restore(int(v.tty.Fd()), v.state)
+			v.tty.SetReadDeadline(time.Now()) // Ends readInput
+		}
+		v.SimulationScreen.Fini()
+	})
+}
+
+// watchSize follows the terminal size.
+func (v *VTScreen) watchSize() {
+	for range v.winch {
+		width, height, err := term.GetSize(int(v.tty.Fd()))
+		if err != nil {
+			continue
+		}
+		v.SetSize(width, height)
+		v.PostEvent(tcell.NewEventResize(width, height))
+	}
+}
+
+// readInput turns what the terminal sends into key and focus events.
+func (v *VTScreen) readInput() {
+	buf := make([]byte, 256)
+	var pending []byte
+	for {
+		n, err := v.tty.Read(buf)
+		if err != nil {
+			return
+		}
+		var events []tcell.Event
+		events, pending = ParseVTInput(append(pending, buf[:n]...))
+		for _, ev := range events {
+			v.PostEvent(ev)
+		}
+	}
+}
+
+// ---- Escape Sequences
+
+// SGR returns the escape sequence selecting style, from a reset.
+func SGR(style tcell.Style) string {
+	fg, bg, attr := style.Decompose()
+	var b strings.Builder
+	b.WriteString("\x1b[0")
+	for _, a := range []struct {
+		mask tcell.AttrMask
+		code string
+	}{
+		{tcell.AttrBold, ";1"}, {tcell.AttrDim, ";2"}, {tcell.AttrItalic, ";3"},
+		{tcell.AttrBlink, ";5"}, {tcell.AttrReverse, ";7"}, {tcell.AttrStrikeThrough, ";9"},
+	} {
+		if attr&a.mask != 0 {
+			b.WriteString(a.code)
+		}
+	}
+	if style.GetUnderlineStyle() != tcell.UnderlineStyleNone {
+		b.WriteString(";4")
+	}
+	b.WriteString(sgrColor(fg, 30, 90, 38))
+	b.WriteString(sgrColor(bg, 40, 100, 48))
+	b.WriteString("m")
+	return b.String()
+}
+
+// sgrColor returns the SGR parameters of a color: base+i for the first 8
+// palette colors, bright+i for the next 8, extended (38 or 48) for the
+// others.
+func sgrColor(c tcell.Color, base, bright, extended int) string {
+	switch {
+	case !c.Valid():
+		return ""
+	case c.IsRGB():
+		r, g, b := c.RGB()
+		return fmt.Sprintf(";%d;2;%d;%d;%d", extended, r, g, b)
+	}
+	i := int(c &^ tcell.ColorValid)
+	switch {
+	case i < 8:
+		return ";" + strconv.Itoa(base+i)
+	case i < 16:
+		return ";" + strconv.Itoa(bright+i-8)
+	case i < 256:
+		return fmt.Sprintf(";%d;5;%d", extended, i)
+	}
+	return "" // Special colors, e.g. reset
+}
+
+// vtKeys maps the escape sequences of special keys, after ESC: xterm's,
+// and the rxvt forms of Home, End and F1-F4, like the set-password
+// prompt.
+var vtKeys = map[string]tcell.Key{
+	"[A": tcell.KeyUp, "[B": tcell.KeyDown, "[C": tcell.KeyRight, "[D": tcell.KeyLeft,
+	"OA": tcell.KeyUp, "OB": tcell.KeyDown, "OC": tcell.KeyRight, "OD": tcell.KeyLeft,
+	"[H": tcell.KeyHome, "[F": tcell.KeyEnd, "OH": tcell.KeyHome, "OF": tcell.KeyEnd,
+	"[1~": tcell.KeyHome, "[4~": tcell.KeyEnd, "[7~": tcell.KeyHome, "[8~": tcell.KeyEnd,
+	"[2~": tcell.KeyInsert, "[3~": tcell.KeyDelete,
+	"[5~": tcell.KeyPgUp, "[6~": tcell.KeyPgDn, "[Z": tcell.KeyBacktab,
+	"OP": tcell.KeyF1, "OQ": tcell.KeyF2, "OR": tcell.KeyF3, "OS": tcell.KeyF4,
+	"[11~": tcell.KeyF1, "[12~": tcell.KeyF2, "[13~": tcell.KeyF3, "[14~": tcell.KeyF4,
+	"[15~": tcell.KeyF5, "[17~": tcell.KeyF6, "[18~": tcell.KeyF7, "[19~": tcell.KeyF8,
+	"[20~": tcell.KeyF9, "[21~": tcell.KeyF10, "[23~": tcell.KeyF11, "[24~": tcell.KeyF12,
+}
+
+// ParseVTInput decodes the keys and focus changes in input. It returns
+// the incomplete sequence left at the end, to be completed by the next
+// read. An ESC alone at the end is the Escape key: terminals send whole
+// sequences in one write.
+func ParseVTInput(input []byte) (events []tcell.Event, rest []byte) {
+	for len(input) > 0 {
+		b := input[0]
+		switch {
+		case b == 0x1b && len(input) == 1:
+			events = append(events, tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+			input = input[1:]
+
+		case b == 0x1b:
+			ev, n := parseEscape(input[1:])
+			if n < 0 {
+				return events, input // Incomplete
+			}
+			events = append(events, ev)
+			input = input[1+n:]
+
+		case b < 0x20 || b == 0x7f:
+			// The keys tcell reads from the same bytes
+			var ev *tcell.EventKey
+			switch b {
+			case '\t':
+				ev = tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone)
+			case '\b', 0x7f:
+				ev = tcell.NewEventKey(tcell.KeyBackspace, 0, tcell.ModNone)
+			case '\r':
+				ev = tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+			default:
+				ev = tcell.NewEventKey(tcell.KeyCtrlSpace+tcell.Key(b), 0, tcell.ModCtrl)
+			}
+			events = append(events, ev)
+			input = input[1:]
+
+		default:
+			if !utf8.FullRune(input) {
+				return events, input
+			}
+			r, n := utf8.DecodeRune(input)
+			events = append(events, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+			input = input[n:]
+		}
+	}
+	return events, nil
+}
+
+// parseEscape decodes the sequence following ESC and returns its length,
+// -1 when incomplete. Unknown sequences are read as Escape followed by
+// nothing, dropping them.
+func parseEscape(seq []byte) (tcell.Event, int) {
+	if seq[0] != '[' && seq[0] != 'O' {
+		// Alt+key
+		r, n := utf8.DecodeRune(seq)
+		return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModAlt), n
+	}
+	// CSI and SS3 sequences end with a byte within 0x40..0x7e.
+	end := -1
+	for i := 1; i < len(seq); i++ {
+		if seq[i] >= 0x40 && seq[i] <= 0x7e {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, -1
+	}
+	code := string(seq[:end+1])
+	switch code {
+	case "[I":
+		return tcell.NewEventFocus(true), len(code)
+	case "[O":
+		return tcell.NewEventFocus(false), len(code)
+	}
+	if key, ok := vtKeys[code]; ok {
+		return tcell.NewEventKey(key, 0, tcell.ModNone), len(code)
+	}
+	return tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), len(code)
+}
@@ -0,0 +1,124 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/lock"
+)
+
+func TestParseBackend(t *testing.T) {
+	b, err := ParseBackend("")
+	require.NoError(t, err)
+	assert.Equal(t, BackendTCell, b)
+	b, err = ParseBackend(" VT ")
+	require.NoError(t, err)
+	assert.Equal(t, BackendVT, b)
+	_, err = ParseBackend("kitty")
+	assert.ErrorContains(t, err, "invalid backend")
+}
+
+func TestSGR(t *testing.T) {
+	assert.Equal(t, "\x1b[0m", SGR(tcell.StyleDefault))
+	assert.Equal(t, "\x1b[0;1;31;40m", SGR(tcell.StyleDefault.Bold(true).Foreground(tcell.ColorMaroon).Background(tcell.ColorBlack)))
+	assert.Equal(t, "\x1b[0;38;2;255;128;0;100m", SGR(tcell.StyleDefault.Foreground(tcell.NewRGBColor(255, 128, 0)).Background(tcell.PaletteColor(8))))
+	assert.Equal(t, "\x1b[0;2;38;5;202m", SGR(tcell.StyleDefault.Dim(true).Foreground(tcell.PaletteColor(202))))
+}
+
+func TestVTShow(t *testing.T) {
+	var out bytes.Buffer
+	v, err := NewVTWriter(&out, 4, 2)
+	require.NoError(t, err)
+	defer v.Fini()
+
+	red := tcell.StyleDefault.Foreground(tcell.NewRGBColor(255, 0, 0))
+	v.SetContent(0, 0, 'a', nil, red)
+	v.SetContent(1, 0, 'b', nil, red)
+	v.SetContent(3, 1, 'c', nil, tcell.StyleDefault)
+	v.Show()
+	assert.Equal(t, "\x1b[0m\x1b[2J\x1b[?25l"+
+		"\x1b[1;1H\x1b[0;38;2;255;0;0mab"+
+		"\x1b[2;4H\x1b[0mc\x1b[0m", out.String(), "first frame: the cells set")
+
+	out.Reset()
+	v.SetContent(1, 0, 'x', nil, red)
+	v.Show()
+	assert.Equal(t, "\x1b[?25l\x1b[1;2H\x1b[0;38;2;255;0;0mx\x1b[0m", out.String(), "only the changed cell")
+
+	out.Reset()
+	v.Sync()
+	assert.Contains(t, out.String(), "\x1b[2J", "Sync redraws everything")
+}
+
+func keys(events []tcell.Event) []string {
+	var names []string
+	for _, ev := range events {
+		switch ev := ev.(type) {
+		case *tcell.EventKey:
+			names = append(names, ev.Name())
+		case *tcell.EventFocus:
+			if ev.Focused {
+				names = append(names, "FocusIn")
+			} else {
+				names = append(names, "FocusOut")
+			}
+		}
+	}
+	return names
+}
+
+func TestParseVTInput(t *testing.T) {
+	events, rest := ParseVTInput([]byte("a\x1b[A\x1b[5~\r\x7f\x03\té\x1b[I\x1bx"))
+	assert.Empty(t, rest)
+	assert.Equal(t, []string{
+		"Rune[a]", "Up", "PgUp", "Enter", "Backspace", "Ctrl+C", "Tab", "Rune[é]", "FocusIn", "Alt+Rune[x]",
+	}, keys(events))
+
+	events, rest = ParseVTInput([]byte("\x1b"))
+	assert.Equal(t, []string{"Esc"}, keys(events), "a lone ESC is the Escape key")
+	assert.Empty(t, rest)
+
+	events, rest = ParseVTInput([]byte("b\x1b[1"))
+	assert.Equal(t, []string{"Rune[b]"}, keys(events))
+	assert.Equal(t, []byte("\x1b[1"), rest, "incomplete sequences wait for the next read")
+	events, _ = ParseVTInput(append(rest, '~'))
+	assert.Equal(t, []string{"Home"}, keys(events))
+
+	events, rest = ParseVTInput([]byte{0xc3})
+	assert.Empty(t, events)
+	assert.Equal(t, []byte{0xc3}, rest, "split UTF-8")
+}
+
+// TestParseVTInputPasswordKeys checks that every special key of a lock
+// password can be typed through the vt backend.
+func TestParseVTInputPasswordKeys(t *testing.T) {
+	sequences := map[tcell.Key]string{
+		tcell.KeyUp: "\x1b[A", tcell.KeyDown: "\x1b[B", tcell.KeyRight: "\x1b[C", tcell.KeyLeft: "\x1b[D",
+		tcell.KeyF1: "\x1bOP", tcell.KeyF2: "\x1bOQ", tcell.KeyF3: "\x1bOR", tcell.KeyF4: "\x1bOS",
+		tcell.KeyF5: "\x1b[15~", tcell.KeyF6: "\x1b[17~", tcell.KeyF7: "\x1b[18~", tcell.KeyF8: "\x1b[19~",
+		tcell.KeyF9: "\x1b[20~", tcell.KeyF10: "\x1b[21~", tcell.KeyF11: "\x1b[23~", tcell.KeyF12: "\x1b[24~",
+		tcell.KeyHome: "\x1b[H", tcell.KeyEnd: "\x1b[F", tcell.KeyPgUp: "\x1b[5~", tcell.KeyPgDn: "\x1b[6~",
+	}
+	for key, name := range tcell.KeyNames {
+		if lock.IsMarkerKey(key) {
+			assert.Contains(t, sequences, key, "no sequence for %s", name)
+		}
+	}
+	for key, seq := range sequences {
+		events, rest := ParseVTInput([]byte(seq))
+		assert.Empty(t, rest)
+		assert.Equal(t, []string{tcell.KeyNames[key]}, keys(events), "%q", seq)
+	}
+
+	// rxvt
+	for seq, name := range map[string]string{
+		"\x1b[7~": "Home", "\x1b[8~": "End", "\x1b[11~": "F1", "\x1b[12~": "F2", "\x1b[13~": "F3", "\x1b[14~": "F4",
+	} {
+		events, _ := ParseVTInput([]byte(seq))
+		assert.Equal(t, []string{name}, keys(events), "%q", seq)
+	}
+}
@@ -0,0 +1,194 @@
+// Package shader post-processes the fire: small per-cell stages that run
+// over the grid of heat values and colors before it is drawn, for effects
+// such as CRT scanlines or a vignette. Everything runs on the CPU, one
+// closure call per cell and stage, so chains are kept short.
+//
+// Effects are named, with an optional strength within 0..1, and chained in
+// order, e.g. in a theme file:
+//
+//	effects = ["curvature", "scanlines:0.4", "vignette"]
+package shader
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yule-log/internal/palette"
+)
+
+// ---- Grid
+
+// Cell is one cell of the grid: its heat value and color.
+type Cell struct {
+	Value int
+	Color palette.RGB
+}
+
+// Grid is a frame of the fire, row major.
+type Grid struct {
+	Width, Height int
+	Frame         int // Frame number, for animated effects
+	Cells         []Cell
+}
+
+// At returns the cell at x, y: a blank (cold, black) cell outside the
+// grid.
+func (g *Grid) At(x, y int) Cell {
+	if x < 0 || y < 0 || x >= g.Width || y >= g.Height {
+		return Cell{}
+	}
+	return g.Cells[y*g.Width+x]
+}
+
+// Shader computes the cell at x, y of the next grid from src. It may read
+// any cell of src, e.g. to displace the picture.
+type Shader func(src *Grid, x, y int) Cell
+
+// ---- Pipeline
+
+// Pipeline runs shaders in order, each over the output of the previous.
+// The zero Pipeline leaves grids unchanged.
+type Pipeline struct {
+	Stages []Shader
+	buf    []Cell // Output of the running stage, swapped with the grid's
+}
+
+// Apply runs the stages over g. The cells of g are replaced.
+func (p *Pipeline) Apply(g *Grid) {
+	for _, stage := range p.Stages {
+		if cap(p.buf) < len(g.Cells) {
+			p.buf = make([]Cell, len(g.Cells))
+		}
+		out := p.buf[:len(g.Cells)]
+		for y := 0; y < g.Height; y++ {
+			for x := 0; x < g.Width; x++ {
+				out[y*g.Width+x] = stage(g, x, y)
+			}
+		}
+		p.buf, g.Cells = g.Cells, out
+	}
+}
+
+// ---- Named Effects
+
+// effect builds a shader of the given strength, within 0..1.
+type effect struct {
+	build    func(strength float64) Shader
+	strength float64 // Default
+}
+
+var effects = map[string]effect{
+	"scanlines": {Scanlines, 0.35},
+	"vignette":  {Vignette, 0.6},
+	"flicker":   {Flicker, 0.5},
+	"curvature": {Curvature, 0.5},
+}
+
+// Names returns the effect names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(effects))
+	for name := range effects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse builds the pipeline of an effect chain: effect names, each
+// optionally followed by :strength.
+func Parse(chain []string) (Pipeline, error) {
+	var p Pipeline
+	for _, spec := range chain {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(spec), ":")
+		e, ok := effects[name]
+		if !ok {
+			return Pipeline{}, fmt.Errorf("unknown effect %q (want %s)", name, strings.Join(Names(), ", "))
+		}
+		strength := e.strength
+		if hasValue {
+			var err error
+			strength, err = strconv.ParseFloat(value, 64)
+			if err != nil || strength < 0 || strength > 1 {
+				return Pipeline{}, fmt.Errorf("effect %s: strength must be within 0..1, got %q", name, value)
+			}
+		}
+		p.Stages = append(p.Stages, e.build(strength))
+	}
+	return p, nil
+}
+
+// ---- Effects
+
+// Scanlines darkens every other row, like the gaps between the lines of a
+// CRT.
+func Scanlines(strength float64) Shader {
+	dim := palette.Dim(strength)
+	return func(src *Grid, x, y int) Cell {
+		c := src.At(x, y)
+		if y%2 == 1 {
+			c.Color = dim(c.Color)
+		}
+		return c
+	}
+}
+
+// Vignette darkens the grid toward its corners.
+func Vignette(strength float64) Shader {
+	return func(src *Grid, x, y int) Cell {
+		c := src.At(x, y)
+		nx, ny := centered(src, x, y)
+		c.Color = palette.Dim(strength * (nx*nx + ny*ny) / 2)(c.Color)
+		return c
+	}
+}
+
+// Flicker makes the brightness waver from frame to frame and, on some
+// rows, splits the red and blue channels apart by one cell, like a badly
+// converged CRT.
+func Flicker(strength float64) Shader {
+	return func(src *Grid, x, y int) Cell {
+		c := src.At(x, y)
+		if noise(src.Frame, y) < strength*0.3 {
+			c.Color.R = src.At(x-1, y).Color.R
+			c.Color.B = src.At(x+1, y).Color.B
+		}
+		c.Color = palette.Dim(strength * 0.15 * noise(src.Frame, -1))(c.Color)
+		return c
+	}
+}
+
+// Curvature approximates the bulge of a CRT screen: the picture is
+// magnified toward the center and the corners fall off the glass.
+func Curvature(strength float64) Shader {
+	return func(src *Grid, x, y int) Cell {
+		nx, ny := centered(src, x, y)
+		k := 1 + strength*0.25*(nx*nx+ny*ny)
+		sx := math.Round((nx*k + 1) / 2 * float64(src.Width-1))
+		sy := math.Round((ny*k + 1) / 2 * float64(src.Height-1))
+		return src.At(int(sx), int(sy))
+	}
+}
+
+// centered maps x, y to -1..1 across the grid, 0 at the center.
+func centered(g *Grid, x, y int) (float64, float64) {
+	return span(x, g.Width), span(y, g.Height)
+}
+
+func span(v, n int) float64 {
+	if n <= 1 {
+		return 0
+	}
+	return 2*float64(v)/float64(n-1) - 1
+}
+
+// noise returns a value within 0..1 that only depends on frame and row.
+func noise(frame, row int) float64 {
+	h := uint32(frame)*2654435761 ^ uint32(row)*40503
+	h ^= h >> 15
+	h *= 2246822519
+	h ^= h >> 13
+	return float64(h) / math.MaxUint32
+}
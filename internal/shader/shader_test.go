@@ -0,0 +1,126 @@
+package shader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/palette"
+)
+
+var white = palette.RGB{R: 200, G: 200, B: 200}
+
+// uniform returns a grid of white cells.
+func uniform(width, height int) *Grid {
+	g := &Grid{Width: width, Height: height, Cells: make([]Cell, width*height)}
+	for i := range g.Cells {
+		g.Cells[i] = Cell{Value: 5, Color: white}
+	}
+	return g
+}
+
+func TestAt(t *testing.T) {
+	g := uniform(3, 2)
+	assert.Equal(t, Cell{Value: 5, Color: white}, g.At(2, 1))
+	assert.Equal(t, Cell{}, g.At(3, 0))
+	assert.Equal(t, Cell{}, g.At(0, -1))
+}
+
+func TestPipeline(t *testing.T) {
+	g := uniform(4, 3)
+	var zero Pipeline
+	zero.Apply(g)
+	assert.Equal(t, uniform(4, 3), g, "no stages")
+
+	// Stages run in order, each over the output of the previous.
+	shift := func(src *Grid, x, y int) Cell { return src.At(x-1, y) }
+	mark := func(src *Grid, x, y int) Cell {
+		c := src.At(x, y)
+		c.Value++
+		return c
+	}
+	p := Pipeline{Stages: []Shader{shift, mark}}
+	p.Apply(g)
+	assert.Equal(t, 1, g.At(0, 0).Value, "shifted in blank, then marked")
+	assert.Equal(t, 6, g.At(1, 0).Value)
+
+	p.Apply(g)
+	assert.Equal(t, 1, g.At(0, 2).Value)
+	assert.Equal(t, 2, g.At(1, 2).Value)
+	assert.Equal(t, 7, g.At(2, 2).Value)
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]string{"curvature", " scanlines:0.4", "vignette:1"})
+	require.NoError(t, err)
+	assert.Len(t, p.Stages, 3)
+
+	p, err = Parse(nil)
+	require.NoError(t, err)
+	assert.Empty(t, p.Stages)
+
+	_, err = Parse([]string{"bloom"})
+	assert.ErrorContains(t, err, "unknown effect")
+	_, err = Parse([]string{"vignette:2"})
+	assert.ErrorContains(t, err, "within 0..1")
+	_, err = Parse([]string{"vignette:strong"})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"curvature", "flicker", "scanlines", "vignette"}, Names())
+}
+
+func TestScanlines(t *testing.T) {
+	g := uniform(2, 4)
+	p := Pipeline{Stages: []Shader{Scanlines(0.5)}}
+	p.Apply(g)
+	assert.Equal(t, white, g.At(0, 0).Color)
+	assert.Equal(t, palette.RGB{R: 100, G: 100, B: 100}, g.At(0, 1).Color)
+	assert.Equal(t, white, g.At(1, 2).Color)
+	assert.Equal(t, 5, g.At(0, 1).Value, "values untouched")
+}
+
+func TestVignette(t *testing.T) {
+	g := uniform(9, 9)
+	p := Pipeline{Stages: []Shader{Vignette(1)}}
+	p.Apply(g)
+	assert.Equal(t, white, g.At(4, 4).Color, "center untouched")
+	assert.Equal(t, palette.RGB{}, g.At(0, 0).Color, "corners black at full strength")
+	assert.Less(t, g.At(0, 4).Color.R, white.R)
+	assert.Greater(t, g.At(0, 4).Color.R, g.At(0, 0).Color.R)
+}
+
+func TestCurvature(t *testing.T) {
+	g := uniform(21, 11)
+	g.Cells[5*21+10].Value = 9 // Center
+	p := Pipeline{Stages: []Shader{Curvature(1)}}
+	p.Apply(g)
+	assert.Equal(t, 9, g.At(10, 5).Value, "center stays in place")
+	assert.Equal(t, Cell{}, g.At(0, 0), "corners fall off")
+	assert.Equal(t, 5, g.At(10, 1).Value)
+}
+
+func TestFlicker(t *testing.T) {
+	g := uniform(8, 8)
+	g.Frame = 3
+	p := Pipeline{Stages: []Shader{Flicker(0)}}
+	p.Apply(g)
+	assert.Equal(t, uniform(8, 8).Cells, g.Cells, "strength 0 leaves the grid unchanged")
+
+	// The same frame flickers the same way.
+	a, b := uniform(8, 8), uniform(8, 8)
+	a.Frame, b.Frame = 7, 7
+	p = Pipeline{Stages: []Shader{Flicker(1)}}
+	p.Apply(a)
+	p.Apply(b)
+	assert.Equal(t, a.Cells, b.Cells)
+}
+
+func TestNoise(t *testing.T) {
+	for frame := range 100 {
+		v := noise(frame, frame%7)
+		assert.GreaterOrEqual(t, v, 0.0)
+		assert.LessOrEqual(t, v, 1.0)
+	}
+	assert.NotEqual(t, noise(1, 0), noise(2, 0))
+}
@@ -0,0 +1,100 @@
+// Package soak watches a long run for leaks: the heap and goroutine
+// counts are sampled periodically and compared with a baseline taken once
+// the run has warmed up (caches filled, tickers loaded).
+package soak
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// ErrLeak is returned by Check once the run grew beyond its limits.
+var ErrLeak = errors.New("leak detected")
+
+// Sample is the memory of the process at one point of the run.
+type Sample struct {
+	At         time.Time
+	HeapAlloc  uint64 // Live heap bytes, after a collection
+	Goroutines int
+}
+
+// Read collects garbage, then samples the process, so that the heap only
+// counts live objects.
+func Read() Sample {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return Sample{At: time.Now(), HeapAlloc: m.HeapAlloc, Goroutines: runtime.NumGoroutine()}
+}
+
+// Limits bounds the growth over the baseline.
+type Limits struct {
+	HeapGrowth      uint64 // Bytes
+	GoroutineGrowth int
+	Strikes         int // Consecutive samples over a limit before failing, 1 when unset
+}
+
+// Monitor compares samples with the baseline: the first sample taken
+// Warmup after the first one.
+type Monitor struct {
+	Limits Limits
+	Warmup time.Duration
+
+	start    time.Time
+	baseline *Sample
+	strikes  int
+}
+
+// Baseline returns the baseline sample, false while warming up.
+func (m *Monitor) Baseline() (Sample, bool) {
+	if m.baseline == nil {
+		return Sample{}, false
+	}
+	return *m.baseline, true
+}
+
+// Check records s and returns an error wrapping ErrLeak once Strikes
+// samples in a row grew beyond the limits. A single spike, e.g. a ticker
+// refresh caught halfway, is forgiven.
+func (m *Monitor) Check(s Sample) error {
+	if m.start.IsZero() {
+		m.start = s.At
+	}
+	if m.baseline == nil {
+		if s.At.Sub(m.start) >= m.Warmup {
+			m.baseline = &s
+		}
+		return nil
+	}
+
+	err := m.overLimits(s)
+	if err == nil {
+		m.strikes = 0
+		return nil
+	}
+	m.strikes++
+	if m.strikes < max(m.Limits.Strikes, 1) {
+		return nil
+	}
+	return fmt.Errorf("%w after %s: %w", ErrLeak, s.At.Sub(m.start).Round(time.Second), err)
+}
+
+func (m *Monitor) overLimits(s Sample) error {
+	base := m.baseline
+	if s.HeapAlloc > base.HeapAlloc && s.HeapAlloc-base.HeapAlloc > m.Limits.HeapGrowth {
+		return fmt.Errorf("heap grew from %s to %s (limit +%s)",
+			FormatBytes(base.HeapAlloc), FormatBytes(s.HeapAlloc), FormatBytes(m.Limits.HeapGrowth))
+	}
+	if s.Goroutines-base.Goroutines > m.Limits.GoroutineGrowth {
+		return fmt.Errorf("goroutines grew from %d to %d (limit +%d)",
+			base.Goroutines, s.Goroutines, m.Limits.GoroutineGrowth)
+	}
+	return nil
+}
+
+// FormatBytes formats n in MiB, e.g. "12.3 MiB".
+func FormatBytes(n uint64) string {
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
@@ -0,0 +1,61 @@
+package soak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var t0 = time.Date(2025, 12, 24, 22, 0, 0, 0, time.UTC)
+
+func sample(minutes int, heapMiB uint64, goroutines int) Sample {
+	return Sample{At: t0.Add(time.Duration(minutes) * time.Minute), HeapAlloc: heapMiB << 20, Goroutines: goroutines}
+}
+
+func TestMonitorWarmup(t *testing.T) {
+	m := Monitor{Limits: Limits{HeapGrowth: 8 << 20, GoroutineGrowth: 2}, Warmup: 10 * time.Minute}
+	require.NoError(t, m.Check(sample(0, 1, 5)))
+	require.NoError(t, m.Check(sample(5, 30, 20)), "growth while warming up is fine")
+	_, ok := m.Baseline()
+	assert.False(t, ok)
+
+	require.NoError(t, m.Check(sample(10, 30, 20)))
+	base, ok := m.Baseline()
+	require.True(t, ok)
+	assert.Equal(t, 20, base.Goroutines)
+
+	require.NoError(t, m.Check(sample(20, 36, 22)), "within limits")
+	require.NoError(t, m.Check(sample(30, 20, 10)), "shrinking is fine")
+}
+
+func TestMonitorLeak(t *testing.T) {
+	m := Monitor{Limits: Limits{HeapGrowth: 8 << 20, GoroutineGrowth: 2}}
+	require.NoError(t, m.Check(sample(0, 10, 5)))
+
+	err := m.Check(sample(60, 19, 5))
+	require.ErrorIs(t, err, ErrLeak)
+	assert.ErrorContains(t, err, "heap grew from 10.0 MiB to 19.0 MiB")
+
+	err = m.Check(sample(120, 10, 8))
+	require.ErrorIs(t, err, ErrLeak)
+	assert.ErrorContains(t, err, "goroutines grew from 5 to 8")
+}
+
+func TestMonitorStrikes(t *testing.T) {
+	m := Monitor{Limits: Limits{GoroutineGrowth: 1, Strikes: 3}}
+	require.NoError(t, m.Check(sample(0, 1, 5)))
+
+	require.NoError(t, m.Check(sample(1, 1, 9)))
+	require.NoError(t, m.Check(sample(2, 1, 5)), "a spike is forgiven")
+	require.NoError(t, m.Check(sample(3, 1, 9)))
+	require.NoError(t, m.Check(sample(4, 1, 9)))
+	assert.ErrorIs(t, m.Check(sample(5, 1, 9)), ErrLeak)
+}
+
+func TestRead(t *testing.T) {
+	s := Read()
+	assert.NotZero(t, s.HeapAlloc)
+	assert.GreaterOrEqual(t, s.Goroutines, 1)
+}
@@ -0,0 +1,125 @@
+package stats
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"yule-log/internal/fsutil"
+)
+
+// ---- Idle History
+// The idle watcher appends one heartbeat sample per interval to a plain
+// text file: "<unix time> <max idle seconds> <triggers>" per line.
+
+// Retention is how long samples are kept.
+const Retention = 24 * time.Hour
+
+// Sample is one heartbeat of the idle watcher.
+type Sample struct {
+	Time     time.Time
+	Idle     int // Maximum client idle time seen during the interval, in seconds
+	Triggers int // Number of idle triggers during the interval
+}
+
+// Append adds a sample to the history file.
+func Append(path string, s Sample) error {
+	line := fmt.Sprintf("%d %d %d\n", s.Time.Unix(), s.Idle, s.Triggers)
+	if err := fsutil.Append(path, []byte(line), 0600); err != nil {
+		return fmt.Errorf("idle stats: %w", err)
+	}
+	return nil
+}
+
+// Load returns the samples recorded at or after since, oldest first.
+// Malformed lines are skipped. A missing file yields no samples.
+func Load(path string, since time.Time) ([]Sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening idle stats: %w", err)
+	}
+	defer f.Close()
+
+	var samples []Sample
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		s, ok := parseSample(scanner.Text())
+		if !ok || s.Time.Before(since) {
+			continue
+		}
+		samples = append(samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading idle stats: %w", err)
+	}
+	return samples, nil
+}
+
+// Prune rewrites the history file keeping only samples newer than since.
+// It holds the file lock, so samples appended concurrently are not lost.
+func Prune(path string, since time.Time) error {
+	err := fsutil.Update(path, 0600, func(data []byte) ([]byte, error) {
+		var b strings.Builder
+		for _, line := range strings.Split(string(data), "\n") {
+			s, ok := parseSample(line)
+			if !ok || s.Time.Before(since) {
+				continue
+			}
+			fmt.Fprintf(&b, "%d %d %d\n", s.Time.Unix(), s.Idle, s.Triggers)
+		}
+		return []byte(b.String()), nil
+	})
+	if err != nil {
+		return fmt.Errorf("idle stats: %w", err)
+	}
+	return nil
+}
+
+func parseSample(line string) (Sample, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return Sample{}, false
+	}
+	var n [3]int64
+	for i, f := range fields {
+		v, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return Sample{}, false
+		}
+		n[i] = v
+	}
+	return Sample{Time: time.Unix(n[0], 0), Idle: int(n[1]), Triggers: int(n[2])}, true
+}
+
+// Buckets groups samples into n equal time buckets between start and end.
+// For each bucket it returns the maximum idle time and the trigger count.
+// Buckets without samples report -1 idle so gaps can be told apart from
+// activity.
+func Buckets(samples []Sample, start, end time.Time, n int) (idle, triggers []int) {
+	idle = make([]int, n)
+	triggers = make([]int, n)
+	for i := range idle {
+		idle[i] = -1
+	}
+
+	span := end.Sub(start)
+	if n <= 0 || span <= 0 {
+		return idle, triggers
+	}
+
+	for _, s := range samples {
+		if s.Time.Before(start) || !s.Time.Before(end) {
+			continue
+		}
+		i := int(int64(s.Time.Sub(start)) * int64(n) / int64(span))
+		idle[i] = max(idle[i], s.Idle)
+		triggers[i] += s.Triggers
+	}
+	return idle, triggers
+}
@@ -0,0 +1,64 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendLoadPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idle.stats")
+	now := time.Unix(1_700_000_000, 0)
+
+	require.NoError(t, Append(path, Sample{Time: now.Add(-48 * time.Hour), Idle: 10}))
+	require.NoError(t, Append(path, Sample{Time: now.Add(-time.Hour), Idle: 300, Triggers: 1}))
+	require.NoError(t, Append(path, Sample{Time: now, Idle: 5}))
+
+	// Garbage lines are ignored
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, _ = f.WriteString("not a sample\n")
+	require.NoError(t, f.Close())
+
+	samples, err := Load(path, now.Add(-Retention))
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, 300, samples[0].Idle)
+	assert.Equal(t, 1, samples[0].Triggers)
+
+	require.NoError(t, Prune(path, now.Add(-Retention)))
+	all, err := Load(path, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestLoadMissing(t *testing.T) {
+	samples, err := Load(filepath.Join(t.TempDir(), "missing"), time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+}
+
+func TestBuckets(t *testing.T) {
+	start := time.Unix(0, 0)
+	end := start.Add(4 * time.Hour)
+	samples := []Sample{
+		{Time: start.Add(10 * time.Minute), Idle: 30},
+		{Time: start.Add(20 * time.Minute), Idle: 90, Triggers: 1},
+		{Time: start.Add(3*time.Hour + 5*time.Minute), Idle: 10},
+		{Time: end, Idle: 999}, // out of range
+	}
+
+	idle, triggers := Buckets(samples, start, end, 4)
+	assert.Equal(t, []int{90, -1, -1, 10}, idle)
+	assert.Equal(t, []int{1, 0, 0, 0}, triggers)
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▄█ ", Sparkline([]int{0, 50, 100, -1}, 0, false))
+	assert.Equal(t, "_:# ", Sparkline([]int{0, 50, 100, -1}, 100, true))
+	assert.Equal(t, "▁▁", Sparkline([]int{0, 0}, 0, false))
+}
@@ -0,0 +1,38 @@
+package stats
+
+// ---- Sparklines
+
+var (
+	sparkRunes      = []rune("▁▂▃▄▅▆▇█")
+	sparkRunesASCII = []rune("_.-:=+*#")
+)
+
+// Sparkline renders values as a one-line bar chart scaled to maxValue
+// (or to the largest value when maxValue <= 0). Negative values are gaps
+// and render as spaces.
+func Sparkline(values []int, maxValue int, ascii bool) string {
+	ramp := sparkRunes
+	if ascii {
+		ramp = sparkRunesASCII
+	}
+
+	if maxValue <= 0 {
+		for _, v := range values {
+			maxValue = max(maxValue, v)
+		}
+	}
+
+	out := make([]rune, len(values))
+	for i, v := range values {
+		switch {
+		case v < 0:
+			out[i] = ' '
+		case maxValue <= 0:
+			out[i] = ramp[0]
+		default:
+			level := min(v, maxValue) * (len(ramp) - 1) / maxValue
+			out[i] = ramp[level]
+		}
+	}
+	return string(out)
+}
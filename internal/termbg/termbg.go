@@ -0,0 +1,174 @@
+// Package termbg detects whether the terminal has a light background, so
+// themes drawn for dark terminals can be swapped for inverted variants.
+package termbg
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+
+	"yule-log/internal/palette"
+)
+
+// Mode selects the background: detected, or forced dark or light.
+type Mode string
+
+const (
+	ModeAuto  Mode = "auto"
+	ModeDark  Mode = "dark"
+	ModeLight Mode = "light"
+)
+
+// ParseMode validates a --background value.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "", ModeAuto:
+		return ModeAuto, nil
+	case ModeDark, ModeLight:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown background %q (want auto, dark or light)", s)
+}
+
+// QueryTimeout bounds how long Light waits for the terminal to answer.
+const QueryTimeout = 150 * time.Millisecond
+
+// Light reports whether the background is light. In auto mode, COLORFGBG
+// is checked first, then the terminal is asked with an OSC 11 query.
+// Anything failing means dark, the default the themes are drawn for.
+//
+// The query reads from the terminal, so it must run before the screen is
+// initialized.
+func Light(mode Mode) bool {
+	switch mode {
+	case ModeLight:
+		return true
+	case ModeDark:
+		return false
+	}
+	if light, ok := ParseColorFGBG(os.Getenv("COLORFGBG")); ok {
+		return light
+	}
+	bg, err := Query(QueryTimeout)
+	if err != nil {
+		return false
+	}
+	return IsLight(bg)
+}
+
+// IsLight reports whether c is a light background color.
+func IsLight(c palette.RGB) bool {
+	return c.Luma() > 128
+}
+
+// ParseColorFGBG reads the background from a COLORFGBG value such as
+// "15;0" (set by rxvt, Konsole...). ANSI colors 7 and 9-15 are light.
+func ParseColorFGBG(v string) (light, ok bool) {
+	if v == "" {
+		return false, false
+	}
+	fields := strings.Split(v, ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || bg < 0 || bg > 15 {
+		return false, false
+	}
+	return bg == 7 || bg >= 9, true
+}
+
+// ---- OSC 11 Query
+
+const (
+	osc11Query = "\x1b]11;?\x1b\\"
+	// da1Query (primary device attributes) is answered by every terminal,
+	// so a terminal ignoring OSC 11 doesn't cost the whole timeout.
+	da1Query = "\x1b[c"
+)
+
+// Query asks the controlling terminal for its background color.
+func Query(timeout time.Duration) (palette.RGB, error) {
+	// O_NONBLOCK puts the file in the runtime poller, without which read
+	// deadlines are silently ignored.
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return palette.RGB{}, err
+	}
+	defer tty.Close()
+
+	// Without a deadline a silent terminal would block forever and the
+	// pending read would steal input from the screen.
+	if err := tty.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return palette.RGB{}, fmt.Errorf("terminal doesn't support read deadlines: %w", err)
+	}
+
+	state, err := term.MakeRaw(int(tty.Fd()))
+	if err != nil {
+		return palette.RGB{}, err
+	}
+	defer func() { _ = term.Restore(int(tty.Fd()), state) }()
+
+	if _, err := tty.WriteString(osc11Query + da1Query); err != nil {
+		return palette.RGB{}, err
+	}
+
+	var resp []byte
+	buf := make([]byte, 64)
+	for len(resp) < 1024 {
+		n, err := tty.Read(buf)
+		resp = append(resp, buf[:n]...)
+		if err != nil {
+			break
+		}
+		if hasDA1Response(string(resp)) {
+			break
+		}
+	}
+
+	c, ok := ParseOSC11(string(resp))
+	if !ok {
+		return palette.RGB{}, fmt.Errorf("no background color reported")
+	}
+	return c, nil
+}
+
+// hasDA1Response reports whether s contains the answer to da1Query
+// (ESC [ ? ... c), which terminals send after the OSC 11 answer.
+func hasDA1Response(s string) bool {
+	i := strings.Index(s, "\x1b[?")
+	return i >= 0 && strings.IndexByte(s[i:], 'c') >= 0
+}
+
+// ParseOSC11 extracts the color from an OSC 11 answer such as
+// "ESC ] 11 ; rgb:ffff/ffff/dddd BEL". Components have 1 to 4 hex digits.
+func ParseOSC11(s string) (palette.RGB, bool) {
+	i := strings.Index(s, "]11;rgb:")
+	if i < 0 {
+		return palette.RGB{}, false
+	}
+	s = s[i+len("]11;rgb:"):]
+	if end := strings.IndexAny(s, "\x07\x1b"); end >= 0 {
+		s = s[:end]
+	}
+
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 {
+		return palette.RGB{}, false
+	}
+	var ch [3]uint8
+	for i, p := range parts {
+		if len(p) < 1 || len(p) > 4 {
+			return palette.RGB{}, false
+		}
+		v, err := strconv.ParseUint(p, 16, 16)
+		if err != nil {
+			return palette.RGB{}, false
+		}
+		maxV := uint64(1)<<(4*len(p)) - 1
+		ch[i] = uint8(v * 255 / maxV)
+	}
+	return palette.RGB{R: ch[0], G: ch[1], B: ch[2]}, true
+}
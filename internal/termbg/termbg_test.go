@@ -0,0 +1,75 @@
+package termbg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/palette"
+)
+
+func TestParseOSC11(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want palette.RGB
+		ok   bool
+	}{
+		{name: "16 bit BEL", in: "\x1b]11;rgb:ffff/ffff/ffff\x07", want: palette.RGB{R: 255, G: 255, B: 255}, ok: true},
+		{name: "16 bit ST", in: "\x1b]11;rgb:1e1e/1e1e/2e2e\x1b\\", want: palette.RGB{R: 30, G: 30, B: 46}, ok: true},
+		{name: "8 bit", in: "\x1b]11;rgb:fd/f6/e3\x07", want: palette.RGB{R: 253, G: 246, B: 227}, ok: true},
+		{name: "4 bit", in: "\x1b]11;rgb:f/0/8\x07", want: palette.RGB{R: 255, G: 0, B: 136}, ok: true},
+		{name: "followed by DA1", in: "\x1b]11;rgb:0000/0000/0000\x1b\\\x1b[?62;22c", want: palette.RGB{}, ok: true},
+		{name: "DA1 only", in: "\x1b[?62;22c"},
+		{name: "two components", in: "\x1b]11;rgb:ffff/ffff\x07"},
+		{name: "not hex", in: "\x1b]11;rgb:zz/00/00\x07"},
+		{name: "too long", in: "\x1b]11;rgb:fffff/0/0\x07"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseOSC11(tt.in)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseColorFGBG(t *testing.T) {
+	tests := []struct {
+		in    string
+		light bool
+		ok    bool
+	}{
+		{in: "15;0", light: false, ok: true},
+		{in: "0;15", light: true, ok: true},
+		{in: "0;default;7", light: true, ok: true},
+		{in: "7;8", light: false, ok: true},
+		{in: ""},
+		{in: "15;default"},
+		{in: "0;42"},
+	}
+	for _, tt := range tests {
+		light, ok := ParseColorFGBG(tt.in)
+		assert.Equal(t, tt.ok, ok, tt.in)
+		assert.Equal(t, tt.light, light, tt.in)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]Mode{"": ModeAuto, "auto": ModeAuto, "Dark": ModeDark, "light": ModeLight} {
+		got, err := ParseMode(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseMode("sepia")
+	assert.Error(t, err)
+
+	assert.True(t, Light(ModeLight))
+	assert.False(t, Light(ModeDark))
+}
+
+func TestIsLight(t *testing.T) {
+	assert.True(t, IsLight(palette.RGB{R: 253, G: 246, B: 227}))
+	assert.False(t, IsLight(palette.RGB{R: 30, G: 30, B: 46}))
+}
@@ -0,0 +1,275 @@
+// Package themes loads screensaver themes: the glyph ramp and the heat to
+// color stops. Built-in themes are embedded TOML files; users add their own
+// as <config dir>/themes/<name>.toml, which also overrides a built-in of the
+// same name:
+//
+//	chars = " .:^*xsS#$"   # 10 glyphs, cold to hot
+//	text = "#ffffff"       # ticker text
+//	stops = [
+//	  { at = 0, color = "#800000" },
+//	  { at = 5, color = "#ff6400" },
+//	]
+//	light_stops = [...]    # light terminal backgrounds (optional)
+//	bands = true           # keep solid color bands on truecolor terminals
+//	model = "doom"         # heat propagation: average, doom or buoyant
+//	stops_256 = [{ at = 0, color = 88 }, ...] # xterm palette (optional)
+//	stops_16 = [{ at = 0, color = 1 }, ...]   # ANSI colors 0-15 (optional)
+//	effects = ["scanlines", "vignette:0.5"]   # post-processing, see shader (optional)
+//
+// The indexed stops replace the RGB ones on terminals with 256 or 16
+// colors, which would otherwise round every color to the nearest one of
+// their palette.
+package themes
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/rivo/uniseg"
+
+	"yule-log/internal/fire"
+	"yule-log/internal/palette"
+	"yule-log/internal/shader"
+)
+
+// RampLength is the number of glyphs in a theme, one per heat level.
+const RampLength = 10
+
+// Built-in theme names.
+const (
+	Fire     = "fire"
+	Contribs = "contribs"
+)
+
+//go:embed builtin/*.toml
+var builtin embed.FS
+
+// ErrUnknown is returned by Load for a theme that doesn't exist.
+var ErrUnknown = errors.New("unknown theme")
+
+// Theme is a decoded theme.
+type Theme struct {
+	Name       string
+	Chars      []rune              // RampLength glyphs, cold to hot
+	Stops      []palette.Stop      // Heat to color bands
+	LightStops []palette.Stop      // Light background variant, nil to reuse Stops
+	Text       palette.RGB         // Ticker text
+	Bands      bool                // Never interpolate between stops
+	Model      string              // Heat propagation model, see fire.NewModel
+	Stops256   []palette.IndexStop // 256-color terminals, nil to round Stops
+	Stops16    []palette.IndexStop // 16-color terminals, nil to round Stops
+	Effects    []string            // Effect chain, see shader.Parse
+}
+
+// file is the TOML layout of a theme file.
+type file struct {
+	Chars      string      `toml:"chars"`
+	Text       string      `toml:"text"`
+	Stops      []stop      `toml:"stops"`
+	LightStops []stop      `toml:"light_stops"`
+	Bands      bool        `toml:"bands"`
+	Model      string      `toml:"model"`
+	Stops256   []indexStop `toml:"stops_256"`
+	Stops16    []indexStop `toml:"stops_16"`
+	Effects    []string    `toml:"effects"`
+}
+
+type stop struct {
+	At    *int   `toml:"at"`
+	Color string `toml:"color"`
+}
+
+type indexStop struct {
+	At    *int `toml:"at"`
+	Color *int `toml:"color"`
+}
+
+// namePattern restricts theme names to safe file names.
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidName reports whether name can name a theme file.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// UserNames returns the names of the theme files in dir, sorted.
+func UserNames(dir string) []string {
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".toml")
+		if ok && !e.IsDir() && namePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Load returns the theme called name, looking in dir (the user themes
+// directory, may be empty) before the built-in themes.
+func Load(dir, name string) (Theme, error) {
+	if !namePattern.MatchString(name) {
+		return Theme{}, fmt.Errorf("invalid theme name %q (lowercase letters, digits, - and _)", name)
+	}
+
+	if dir != "" {
+		path := filepath.Join(dir, name+".toml")
+		data, err := os.ReadFile(path)
+		if err == nil {
+			t, err := Parse(name, data)
+			if err != nil {
+				return Theme{}, fmt.Errorf("%s: %w", path, err)
+			}
+			return t, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return Theme{}, fmt.Errorf("reading theme: %w", err)
+		}
+	}
+
+	data, err := builtin.ReadFile("builtin/" + name + ".toml")
+	if err != nil {
+		return Theme{}, fmt.Errorf("%w %q (available: %s)", ErrUnknown, name, strings.Join(Names(dir), ", "))
+	}
+	return Parse(name, data)
+}
+
+// MustBuiltin returns a built-in theme, panicking if it is invalid.
+func MustBuiltin(name string) Theme {
+	t, err := Load("", name)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Names returns the built-in and user theme names, sorted.
+func Names(dir string) []string {
+	seen := make(map[string]bool)
+	entries, _ := builtin.ReadDir("builtin")
+	if dir != "" {
+		user, _ := os.ReadDir(dir)
+		entries = append(entries, user...)
+	}
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".toml")
+		if !ok || e.IsDir() || !namePattern.MatchString(name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse decodes and validates a theme file.
+func Parse(name string, data []byte) (Theme, error) {
+	var f file
+	if err := toml.Unmarshal(data, &f); err != nil {
+		return Theme{}, fmt.Errorf("parsing: %w", err)
+	}
+
+	t := Theme{Name: name, Chars: []rune(f.Chars), Bands: f.Bands, Model: f.Model, Effects: f.Effects}
+	if len(t.Chars) != RampLength {
+		return Theme{}, fmt.Errorf("chars must have %d glyphs, cold to hot, got %d", RampLength, len(t.Chars))
+	}
+	for _, c := range t.Chars {
+		if uniseg.StringWidth(string(c)) != 1 {
+			return Theme{}, fmt.Errorf("chars: glyph %q is not one column wide", c)
+		}
+	}
+
+	if _, err := fire.NewModel(f.Model); err != nil {
+		return Theme{}, fmt.Errorf("model: %w", err)
+	}
+	if _, err := shader.Parse(f.Effects); err != nil {
+		return Theme{}, fmt.Errorf("effects: %w", err)
+	}
+
+	var err error
+	if t.Text, err = ParseColor(f.Text); err != nil {
+		return Theme{}, fmt.Errorf("text: %w", err)
+	}
+	if t.Stops, err = parseStops(f.Stops); err != nil {
+		return Theme{}, fmt.Errorf("stops: %w", err)
+	}
+	if len(t.Stops) == 0 {
+		return Theme{}, errors.New("stops: at least one color stop is required")
+	}
+	if t.LightStops, err = parseStops(f.LightStops); err != nil {
+		return Theme{}, fmt.Errorf("light_stops: %w", err)
+	}
+	if t.Stops256, err = parseIndexStops(f.Stops256, 255); err != nil {
+		return Theme{}, fmt.Errorf("stops_256: %w", err)
+	}
+	if t.Stops16, err = parseIndexStops(f.Stops16, 15); err != nil {
+		return Theme{}, fmt.Errorf("stops_16: %w", err)
+	}
+	return t, nil
+}
+
+func parseStops(stops []stop) ([]palette.Stop, error) {
+	var out []palette.Stop
+	for i, s := range stops {
+		if s.At == nil {
+			return nil, fmt.Errorf("stop %d: missing at", i+1)
+		}
+		if *s.At < 0 {
+			return nil, fmt.Errorf("stop %d: at must not be negative, got %d", i+1, *s.At)
+		}
+		if i > 0 && *s.At <= out[i-1].At {
+			return nil, fmt.Errorf("stop %d: at must increase, got %d after %d", i+1, *s.At, out[i-1].At)
+		}
+		c, err := ParseColor(s.Color)
+		if err != nil {
+			return nil, fmt.Errorf("stop %d: %w", i+1, err)
+		}
+		out = append(out, palette.Stop{At: *s.At, Color: c})
+	}
+	return out, nil
+}
+
+// parseIndexStops validates palette index stops, up to maxIndex.
+func parseIndexStops(stops []indexStop, maxIndex int) ([]palette.IndexStop, error) {
+	var out []palette.IndexStop
+	for i, s := range stops {
+		switch {
+		case s.At == nil:
+			return nil, fmt.Errorf("stop %d: missing at", i+1)
+		case s.Color == nil:
+			return nil, fmt.Errorf("stop %d: missing color", i+1)
+		case *s.At < 0:
+			return nil, fmt.Errorf("stop %d: at must not be negative, got %d", i+1, *s.At)
+		case i > 0 && *s.At <= out[i-1].At:
+			return nil, fmt.Errorf("stop %d: at must increase, got %d after %d", i+1, *s.At, out[i-1].At)
+		case *s.Color < 0 || *s.Color > maxIndex:
+			return nil, fmt.Errorf("stop %d: color must be a palette index within 0..%d, got %d", i+1, maxIndex, *s.Color)
+		}
+		out = append(out, palette.IndexStop{At: *s.At, Index: *s.Color})
+	}
+	return out, nil
+}
+
+// ParseColor parses a "#rrggbb" color.
+func ParseColor(s string) (palette.RGB, error) {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || len(hex) != 6 {
+		return palette.RGB{}, fmt.Errorf("invalid color %q, want #rrggbb", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return palette.RGB{}, fmt.Errorf("invalid color %q, want #rrggbb", s)
+	}
+	return palette.RGB{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
@@ -0,0 +1,149 @@
+package themes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/palette"
+)
+
+const validTheme = `
+chars = " .-=+*%@#&"
+text = "#c0c0c0"
+stops = [
+  { at = 0, color = "#000020" },
+  { at = 8, color = "#4060ff" },
+]
+effects = ["scanlines:0.5"]
+`
+
+func TestBuiltins(t *testing.T) {
+	for _, name := range []string{Fire, Contribs} {
+		th, err := Load("", name)
+		require.NoError(t, err, name)
+		assert.Len(t, th.Chars, RampLength)
+		assert.NotEmpty(t, th.LightStops)
+	}
+
+	fire := MustBuiltin(Fire)
+	assert.Equal(t, []rune(" .:^*xsS#$"), fire.Chars)
+	assert.Equal(t, palette.Stop{At: 0, Color: palette.RGB{R: 128}}, fire.Stops[0])
+	assert.Equal(t, palette.RGB{R: 255, G: 255, B: 255}, fire.Text)
+	assert.False(t, fire.Bands)
+	assert.True(t, MustBuiltin(Contribs).Bands)
+	assert.Equal(t, palette.IndexStop{At: 0, Index: 88}, fire.Stops256[0])
+	assert.Equal(t, palette.IndexStop{At: 16, Index: 15}, fire.Stops16[4])
+	assert.Len(t, MustBuiltin(Contribs).Stops16, 5)
+}
+
+func TestLoadUser(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ocean.toml"), []byte(validTheme), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fire.toml"), []byte(validTheme), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.toml"), []byte("chars = 1"), 0600))
+
+	ocean, err := Load(dir, "ocean")
+	require.NoError(t, err)
+	assert.Equal(t, "ocean", ocean.Name)
+	assert.Equal(t, palette.RGB{R: 0xc0, G: 0xc0, B: 0xc0}, ocean.Text)
+	assert.Nil(t, ocean.LightStops)
+	assert.Equal(t, []string{"scanlines:0.5"}, ocean.Effects)
+
+	fire, err := Load(dir, "fire")
+	require.NoError(t, err)
+	assert.Equal(t, ocean.Chars, fire.Chars, "user themes override built-ins")
+
+	_, err = Load(dir, "broken")
+	assert.ErrorContains(t, err, "broken.toml")
+
+	_, err = Load(dir, "missing")
+	assert.True(t, errors.Is(err, ErrUnknown))
+	assert.ErrorContains(t, err, "broken, contribs, fire, ocean")
+
+	_, err = Load(dir, "../etc/passwd")
+	assert.ErrorContains(t, err, "invalid theme name")
+}
+
+func TestUserNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"ocean.toml", "fire.toml", "Bad Name.toml", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(validTheme), 0600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "dir.toml"), 0700))
+
+	assert.Equal(t, []string{"fire", "ocean"}, UserNames(dir))
+	assert.Empty(t, UserNames(filepath.Join(dir, "missing")))
+	assert.True(t, ValidName("ocean"))
+	assert.False(t, ValidName("../ocean"))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "bad toml", data: "chars = ", want: "parsing"},
+		{name: "short ramp", data: `chars = "abc"`, want: "10 glyphs"},
+		{name: "wide glyph", data: `chars = " .:^*xsS#火"`, want: "one column"},
+		{name: "bad text", data: `chars = " .:^*xsS#$"` + "\ntext = \"white\"", want: "text"},
+		{name: "no stops", data: `chars = " .:^*xsS#$"` + "\ntext = \"#ffffff\"", want: "at least one"},
+		{
+			name: "missing at",
+			data: `chars = " .:^*xsS#$"` + "\ntext = \"#ffffff\"\nstops = [{ color = \"#ff0000\" }]",
+			want: "missing at",
+		},
+		{
+			name: "unsorted",
+			data: `chars = " .:^*xsS#$"` + "\ntext = \"#ffffff\"\nstops = [{ at = 5, color = \"#ff0000\" }, { at = 2, color = \"#00ff00\" }]",
+			want: "must increase",
+		},
+		{
+			name: "bad light color",
+			data: `chars = " .:^*xsS#$"` + "\ntext = \"#ffffff\"\nstops = [{ at = 0, color = \"#ff0000\" }]\nlight_stops = [{ at = 0, color = \"#ff00\" }]",
+			want: "light_stops",
+		},
+		{
+			name: "unknown model",
+			data: `chars = " .:^*xsS#$"` + "\ntext = \"#ffffff\"\nmodel = \"lava\"\nstops = [{ at = 0, color = \"#ff0000\" }]",
+			want: "model: unknown fire model",
+		},
+		{
+			name: "unknown effect",
+			data: `chars = " .:^*xsS#$"` + "\ntext = \"#ffffff\"\neffects = [\"bloom\"]\nstops = [{ at = 0, color = \"#ff0000\" }]",
+			want: "effects: unknown effect",
+		},
+		{
+			name: "16 color index out of range",
+			data: `chars = " .:^*xsS#$"` + "\ntext = \"#ffffff\"\nstops = [{ at = 0, color = \"#ff0000\" }]\nstops_16 = [{ at = 0, color = 16 }]",
+			want: "stops_16: stop 1: color must be a palette index within 0..15",
+		},
+		{
+			name: "256 color missing index",
+			data: `chars = " .:^*xsS#$"` + "\ntext = \"#ffffff\"\nstops = [{ at = 0, color = \"#ff0000\" }]\nstops_256 = [{ at = 0 }]",
+			want: "stops_256: stop 1: missing color",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("test", []byte(tt.data))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	c, err := ParseColor("#ff8000")
+	require.NoError(t, err)
+	assert.Equal(t, palette.RGB{R: 255, G: 128}, c)
+
+	for _, bad := range []string{"", "ff8000", "#ff80", "#gg0000"} {
+		_, err := ParseColor(bad)
+		assert.Error(t, err, bad)
+	}
+}
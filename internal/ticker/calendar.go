@@ -0,0 +1,42 @@
+package ticker
+
+import (
+	"time"
+
+	"yule-log/internal/calendar"
+)
+
+// ---- Calendar Ticker
+// Upcoming events: "Standup in 12m" above "09:30-09:45". Labels count down,
+// so the items are built again every minute.
+
+const (
+	// CalendarRefresh is the age after which events are fetched again.
+	CalendarRefresh = 15 * time.Minute
+	// CalendarWindow is how far ahead events are shown.
+	CalendarWindow = 12 * time.Hour
+	// maxCalendarEvents is the number of events shown, soonest first.
+	maxCalendarEvents = 5
+)
+
+// CalendarItems returns one ticker item per upcoming event.
+func CalendarItems(events []calendar.Event, now time.Time, ascii bool) []Item {
+	dash := "–"
+	if ascii {
+		dash = "-"
+	}
+	upcoming := calendar.Upcoming(events, now, CalendarWindow)
+	if len(upcoming) > maxCalendarEvents {
+		upcoming = upcoming[:maxCalendarEvents]
+	}
+	items := make([]Item, 0, len(upcoming))
+	for _, ev := range upcoming {
+		meta := ev.Start.Local().Format("15:04")
+		if !ev.End.IsZero() {
+			meta += dash + ev.End.Local().Format("15:04")
+		}
+		ev.Summary = Sanitize(ev.Summary)
+		items = append(items, Item{Subject: calendar.Label(ev, now), Meta: meta, Time: ev.Start})
+	}
+	return items
+}
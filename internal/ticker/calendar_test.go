@@ -0,0 +1,25 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"yule-log/internal/calendar"
+)
+
+func TestCalendarItems(t *testing.T) {
+	now := time.Date(2024, 3, 4, 9, 18, 0, 0, time.Local)
+	events := []calendar.Event{
+		{Summary: "Review", Start: now.Add(3 * time.Hour)},
+		{Summary: "Stand\x1b[1mup", Start: now.Add(12 * time.Minute), End: now.Add(27 * time.Minute)},
+		{Summary: "Tomorrow", Start: now.Add(20 * time.Hour)},
+	}
+
+	assert.Equal(t, []Item{
+		{Subject: "Standup in 12m", Meta: "09:30–09:45", Time: now.Add(12 * time.Minute)},
+		{Subject: "Review in 3h00m", Meta: "12:18", Time: now.Add(3 * time.Hour)},
+	}, CalendarItems(events, now, false))
+	assert.Equal(t, "09:30-09:45", CalendarItems(events, now, true)[0].Meta)
+}
@@ -0,0 +1,48 @@
+package ticker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ---- Command Ticker
+// The output of any shell command, one item per line: "subject" or
+// "subject<TAB>meta", e.g. the build status, the on-call rotation or
+// "kubectl get pods" trimmed with awk.
+
+const (
+	// CommandRefresh is the age after which the command runs again.
+	CommandRefresh = time.Minute
+	// maxCommandItems is the number of output lines shown, first first.
+	maxCommandItems = 20
+)
+
+// RunCommand runs a shell command and returns its output as items, see
+// CommandItems.
+func RunCommand(ctx context.Context, command string) ([]Item, error) {
+	out, err := exec.CommandContext(ctx, "sh", "-c", command).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ticker command: %w", err)
+	}
+	return CommandItems(string(out)), nil
+}
+
+// CommandItems returns one item per non-blank line of out. A tab splits
+// the subject from the meta row.
+func CommandItems(out string) []Item {
+	var items []Item
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() && len(items) < maxCommandItems {
+		subject, meta, _ := strings.Cut(sc.Text(), "\t")
+		subject = Sanitize(subject)
+		if subject == "" {
+			continue
+		}
+		items = append(items, Item{Subject: subject, Meta: Sanitize(meta)})
+	}
+	return items
+}
@@ -0,0 +1,27 @@
+package ticker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandItems(t *testing.T) {
+	items := CommandItems("build: passing\tci, 2m ago\n\n   \ndeploy \x1b[31mfailed\n")
+	assert.Equal(t, []Item{
+		{Subject: "build: passing", Meta: "ci, 2m ago"},
+		{Subject: "deploy failed"},
+	}, items)
+	assert.Empty(t, CommandItems(""))
+}
+
+func TestRunCommand(t *testing.T) {
+	items, err := RunCommand(context.Background(), "printf 'one\\ttwo\\n'")
+	require.NoError(t, err)
+	assert.Equal(t, []Item{{Subject: "one", Meta: "two"}}, items)
+
+	_, err = RunCommand(context.Background(), "exit 3")
+	assert.Error(t, err)
+}
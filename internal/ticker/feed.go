@@ -0,0 +1,136 @@
+package ticker
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ---- Feed Ticker
+// The latest entries of RSS 2.0 and Atom feeds: "Go 1.23 is released"
+// above "The Go Blog, 2h ago". Entries of several feeds take turns.
+
+const (
+	// FeedRefresh is the age after which feeds are fetched again.
+	FeedRefresh = 15 * time.Minute
+	// maxFeedItems is the number of entries shown, over all feeds.
+	maxFeedItems = 10
+	// maxFeedSize bounds the document read from a feed.
+	maxFeedSize = 4 << 20
+)
+
+// Feed is the title and entries of a feed, newest first as published.
+type Feed struct {
+	Title   string
+	Entries []FeedEntry
+}
+
+// FeedEntry is an entry of a feed.
+type FeedEntry struct {
+	Title     string
+	Published time.Time // Zero if the feed has no usable date
+}
+
+// feedDocument decodes both formats: RSS 2.0 nests the entries in a
+// channel, Atom keeps them at the root.
+type feedDocument struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title   string `xml:"title"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+	Title   string `xml:"title"`
+	Entries []struct {
+		Title     string `xml:"title"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// feedDateLayouts are the date formats of RSS (RFC 822, with or without
+// a numeric zone) and Atom (RFC 3339).
+var feedDateLayouts = []string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", time.RFC3339}
+
+// ParseFeed reads an RSS 2.0 or Atom document.
+func ParseFeed(r io.Reader) (Feed, error) {
+	var doc feedDocument
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := dec.Decode(&doc); err != nil {
+		return Feed{}, fmt.Errorf("parsing feed: %w", err)
+	}
+
+	var feed Feed
+	if len(doc.Entries) > 0 {
+		feed.Title = doc.Title
+		for _, e := range doc.Entries {
+			date := e.Published
+			if date == "" {
+				date = e.Updated
+			}
+			feed.Entries = append(feed.Entries, FeedEntry{Title: e.Title, Published: parseFeedDate(date)})
+		}
+		return feed, nil
+	}
+	feed.Title = doc.Channel.Title
+	for _, it := range doc.Channel.Items {
+		feed.Entries = append(feed.Entries, FeedEntry{Title: it.Title, Published: parseFeedDate(it.PubDate)})
+	}
+	return feed, nil
+}
+
+func parseFeedDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// FetchFeed downloads and parses the feed at url.
+func FetchFeed(ctx context.Context, url string) (Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Feed{}, fmt.Errorf("fetching feed: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Feed{}, fmt.Errorf("fetching feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Feed{}, fmt.Errorf("fetching feed: %s", resp.Status)
+	}
+	return ParseFeed(io.LimitReader(resp.Body, maxFeedSize))
+}
+
+// FeedItems returns the entries of the feeds, taking turns between feeds.
+func FeedItems(feeds []Feed, now time.Time) []Item {
+	lists := make([][]Item, 0, len(feeds))
+	for _, feed := range feeds {
+		title := Sanitize(feed.Title)
+		var list []Item
+		for _, e := range feed.Entries {
+			subject := Sanitize(e.Title)
+			if subject == "" {
+				continue
+			}
+			meta := title
+			if !e.Published.IsZero() {
+				meta = strings.TrimPrefix(meta+", "+since(now.Sub(e.Published))+" ago", ", ")
+			}
+			list = append(list, Item{Subject: subject, Meta: meta, Time: e.Published})
+		}
+		lists = append(lists, list)
+	}
+	return Interleave(lists, maxFeedItems)
+}
@@ -0,0 +1,81 @@
+package ticker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel>
+<title>Release notes</title>
+<item><title>v1.2.0 is out</title><link>https://example.com/v1.2.0</link><pubDate>Sun, 10 Mar 2024 10:00:00 +0000</pubDate></item>
+<item><title>No date</title></item>
+</channel></rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>The Go Blog</title>
+<entry><title>Go 1.22 is released</title><link href="https://go.dev/blog/go1.22"/><updated>2024-03-07T12:00:00Z</updated></entry>
+<entry><title>Routing enhancements</title><published>2024-03-09T12:00:00Z</published><updated>2024-03-10T00:00:00Z</updated></entry>
+</feed>`
+
+func TestParseFeed(t *testing.T) {
+	rss, err := ParseFeed(strings.NewReader(rssFeed))
+	require.NoError(t, err)
+	assert.Equal(t, Feed{Title: "Release notes", Entries: []FeedEntry{
+		{Title: "v1.2.0 is out", Published: time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC)},
+		{Title: "No date"},
+	}}, rss)
+
+	atom, err := ParseFeed(strings.NewReader(atomFeed))
+	require.NoError(t, err)
+	assert.Equal(t, "The Go Blog", atom.Title)
+	require.Len(t, atom.Entries, 2)
+	assert.Equal(t, time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC), atom.Entries[0].Published)
+	assert.Equal(t, time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC), atom.Entries[1].Published, "published before updated")
+
+	_, err = ParseFeed(strings.NewReader("not xml"))
+	assert.Error(t, err)
+}
+
+func TestFeedItems(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	rss, _ := ParseFeed(strings.NewReader(rssFeed))
+	atom, _ := ParseFeed(strings.NewReader(atomFeed))
+
+	items := FeedItems([]Feed{rss, atom}, now)
+	assert.Equal(t, []Item{
+		{Subject: "v1.2.0 is out", Meta: "Release notes, 2h ago", Time: now.Add(-2 * time.Hour)},
+		{Subject: "Go 1.22 is released", Meta: "The Go Blog, 3d ago", Time: now.Add(-3 * 24 * time.Hour)},
+		{Subject: "No date", Meta: "Release notes"},
+		{Subject: "Routing enhancements", Meta: "The Go Blog, 1d ago", Time: now.Add(-24 * time.Hour)},
+	}, items)
+
+	untitled := FeedItems([]Feed{{Entries: []FeedEntry{{Title: "Hi", Published: now}}}}, now)
+	assert.Equal(t, "0m ago", untitled[0].Meta)
+}
+
+func TestFetchFeed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(rssFeed))
+	}))
+	defer srv.Close()
+
+	feed, err := FetchFeed(context.Background(), srv.URL+"/feed.xml")
+	require.NoError(t, err)
+	assert.Equal(t, "Release notes", feed.Title)
+
+	_, err = FetchFeed(context.Background(), srv.URL+"/missing.xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,32 @@
+package ticker
+
+import (
+	"fmt"
+	"time"
+
+	"yule-log/internal/forge"
+)
+
+// ---- Forge Ticker
+// Pull requests awaiting review and issues assigned to the user, from the
+// configured forge: "Review: Fix resize crash" above "gfanton/yule#12, 3d old".
+
+// ForgeRefresh is the age after which forge items are fetched again.
+const ForgeRefresh = 5 * time.Minute
+
+// ForgeItems returns one ticker item per pull request or issue, in order.
+func ForgeItems(items []forge.Item, now time.Time) []Item {
+	out := make([]Item, 0, len(items))
+	for _, it := range items {
+		label := "Issue"
+		if it.Kind == forge.KindPullRequest {
+			label = "Review"
+		}
+		out = append(out, Item{
+			Subject: label + ": " + Sanitize(it.Title),
+			Meta:    fmt.Sprintf("%s#%d, %s old", Sanitize(it.Repo), it.Number, since(now.Sub(it.Created))),
+			Time:    it.Created,
+		})
+	}
+	return out
+}
@@ -0,0 +1,24 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"yule-log/internal/forge"
+)
+
+func TestForgeItems(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	items := ForgeItems([]forge.Item{
+		{Kind: forge.KindPullRequest, Repo: "gfanton/yule", Number: 12, Title: "Fix\tresize crash", Created: now.Add(-3 * 24 * time.Hour)},
+		{Kind: forge.KindIssue, Repo: "gfanton/yule", Number: 7, Title: "Snow \x1b[31mmelts", Created: now.Add(-2 * time.Hour)},
+	}, now)
+
+	assert.Equal(t, []Item{
+		{Subject: "Review: Fix resize crash", Meta: "gfanton/yule#12, 3d old", Time: now.Add(-3 * 24 * time.Hour)},
+		{Subject: "Issue: Snow melts", Meta: "gfanton/yule#7, 2h old", Time: now.Add(-2 * time.Hour)},
+	}, items)
+	assert.Empty(t, ForgeItems(nil, now))
+}
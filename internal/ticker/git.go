@@ -0,0 +1,391 @@
+package ticker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+
+	"yule-log/internal/gitdata"
+)
+
+// ---- Git Ticker
+
+// filterScanFactor is how many more commits are read from git log when
+// filters are set, so that filtered-out commits don't empty the ticker.
+const filterScanFactor = 10
+
+// DefaultMaxWidth is the default maximum width of a ticker segment.
+const DefaultMaxWidth = 80
+
+// segmentGap is the number of blank cells between two ticker segments.
+const segmentGap = 4
+
+// fieldSep separates git log fields. Subjects may contain tabs, never NUL.
+const fieldSep = "\x00"
+
+// Continuation fills the cell after a double-width character in Msg and
+// Meta, so that rune offsets are also cell offsets. Renderers skip it.
+const Continuation rune = 0
+
+// Ellipsis marks truncated segments; EllipsisASCII is used in ASCII mode.
+const (
+	Ellipsis      = "…"
+	EllipsisASCII = "..."
+)
+
+// Item sources, listed in the [ticker] sources setting.
+const (
+	SourceCommits  = "commits"  // Recent commits, the default
+	SourceTodos    = "todos"    // TODO/FIXME counts, see ScanTodos
+	SourceForge    = "forge"    // Pull requests and issues, see ForgeItems
+	SourceCalendar = "calendar" // Upcoming events, see CalendarItems
+	SourceCommand  = "command"  // Output lines of a command, see CommandItems
+	SourceFeed     = "feed"     // RSS and Atom entries, see FeedItems
+)
+
+// Sources lists the valid item sources.
+var Sources = []string{SourceCommits, SourceTodos, SourceForge, SourceCalendar, SourceCommand, SourceFeed}
+
+// ValidSource reports whether name is a known item source.
+func ValidSource(name string) bool {
+	for _, source := range Sources {
+		if source == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Options controls which commits end up in the ticker.
+type Options struct {
+	MaxCommits int      // Maximum number of commits shown
+	MaxWidth   int      // Maximum segment width in cells (0 = DefaultMaxWidth)
+	ASCII      bool     // Use an ASCII ellipsis
+	Include    []string // Subject regexps, at least one must match (if any)
+	Exclude    []string // Subject regexps, none may match
+
+	// Work tree status appended to the meta rows, see ReadStatus
+	Branch bool
+	Tag    bool
+	Dirty  bool
+}
+
+// Item is one entry of the ticker.
+type Item struct {
+	Hash    string // Full commit hash
+	Subject string // Full subject, not truncated to the segment width
+	Author  string
+	Meta    string
+	Time    time.Time // Author date
+	Dir     string    // Repository of the commit, with several, see RepoItems
+}
+
+// Text is a rendered ticker: two rows of equal length scrolled together,
+// plus the rune offset at which each item starts so that renderers can
+// react to item boundaries. Rows hold one rune per terminal cell, see
+// Continuation.
+type Text struct {
+	Msg    string
+	Meta   string
+	Items  []Item
+	Starts []int // Starts[i] is the rune offset of Items[i] in Msg and Meta
+}
+
+// Len returns the length of the rows in runes.
+func (t Text) Len() int {
+	return utf8.RuneCountInString(t.Msg)
+}
+
+// ItemStartingAt returns the index of the item starting at rune offset
+// pos, if any.
+func (t Text) ItemStartingAt(pos int) (int, bool) {
+	for i, start := range t.Starts {
+		if start == pos {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ItemAt returns the index of the item covering rune offset pos, gap
+// included.
+func (t Text) ItemAt(pos int) (int, bool) {
+	for i := len(t.Starts) - 1; i >= 0; i-- {
+		if t.Starts[i] <= pos {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// BuildGit runs git log in gitDir (or YULE_LOG_GIT_DIR, or the current
+// directory) and returns the message and meta rows of the ticker.
+func BuildGit(gitDir string, opts Options) (Text, bool) {
+	items, err := GitItems(gitDir, opts)
+	if err != nil {
+		return Text{}, false
+	}
+	return Layout(items, opts)
+}
+
+// GitItems runs git log in gitDir (or YULE_LOG_GIT_DIR, or the current
+// directory) and returns the commits to show. On a repository too slow to
+// answer within the gitdata limits, the commits read in time are returned.
+func GitItems(gitDir string, opts Options) ([]Item, error) {
+	scan := opts.MaxCommits
+	if len(opts.Include) > 0 || len(opts.Exclude) > 0 {
+		scan *= filterScanFactor
+	}
+
+	repo := gitdata.Repo{Dir: gitDir}
+	if gitDir == "" {
+		repo.Dir = os.Getenv("YULE_LOG_GIT_DIR")
+	}
+
+	res, err := repo.Run(context.Background(), "log", "-n", strconv.Itoa(scan), "--pretty=format:%H%x00%an%x00%ar%x00%at%x00%s")
+	if err != nil {
+		return nil, err
+	}
+	items := parseGitItems(res.Out, opts)
+	if opts.wantStatus() {
+		suffix := ReadStatus(repo.Dir, opts).Suffix(opts.ASCII)
+		for i := range items {
+			items[i].Meta += suffix
+		}
+	}
+	return items, nil
+}
+
+// RepoItems returns the commits of several repositories, interleaved, each
+// subject prefixed with the name of its repository. Repositories git
+// can't read are skipped. opts.MaxCommits bounds the total.
+func RepoItems(dirs []string, opts Options) []Item {
+	lists := make([][]Item, 0, len(dirs))
+	for _, dir := range dirs {
+		items, err := GitItems(dir, opts)
+		if err != nil {
+			continue
+		}
+		lists = append(lists, LabelRepo(items, dir))
+	}
+	return Interleave(lists, opts.MaxCommits)
+}
+
+// LabelRepo prefixes the subject of every item with the name of the
+// repository at dir, and records dir.
+func LabelRepo(items []Item, dir string) []Item {
+	name := RepoName(dir)
+	labeled := make([]Item, len(items))
+	for i, item := range items {
+		item.Subject = name + ": " + item.Subject
+		item.Dir = dir
+		labeled[i] = item
+	}
+	return labeled
+}
+
+// RepoName returns the name of the repository at dir, its last path
+// element.
+func RepoName(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Base(dir)
+}
+
+// Interleave takes one item of every list in turn, keeping the order of
+// each, until limit items (0 for all).
+func Interleave(lists [][]Item, limit int) []Item {
+	var items []Item
+	for i := 0; ; i++ {
+		added := false
+		for _, list := range lists {
+			if i >= len(list) {
+				continue
+			}
+			if limit > 0 && len(items) >= limit {
+				return items
+			}
+			items = append(items, list[i])
+			added = true
+		}
+		if !added {
+			return items
+		}
+	}
+}
+
+// ParseGitLog converts NUL-separated git log output (hash, author, relative
+// time, unix time, subject) into padded message and meta rows of equal length.
+// Tabs and other whitespace in fields collapse to single spaces.
+// Invalid filter expressions are ignored.
+func ParseGitLog(logOutput string, opts Options) (Text, bool) {
+	return Layout(parseGitItems(logOutput, opts), opts)
+}
+
+func parseGitItems(logOutput string, opts Options) []Item {
+	include := compileAll(opts.Include)
+	exclude := compileAll(opts.Exclude)
+
+	var items []Item
+	for _, line := range strings.Split(strings.TrimSpace(logOutput), "\n") {
+		if opts.MaxCommits > 0 && len(items) >= opts.MaxCommits {
+			break
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, fieldSep, 5)
+		if len(parts) != 5 {
+			continue
+		}
+
+		author, relTime, subject := Sanitize(parts[1]), Sanitize(parts[2]), Sanitize(parts[4])
+		if subject == "" || !keep(subject, include, exclude) {
+			continue
+		}
+		var at time.Time
+		if unix, err := strconv.ParseInt(parts[3], 10, 64); err == nil {
+			at = time.Unix(unix, 0)
+		}
+		items = append(items, Item{Hash: Sanitize(parts[0]), Subject: subject, Author: author, Meta: "by " + author + " " + relTime, Time: at})
+	}
+	return items
+}
+
+// Layout lays items out as the two ticker rows: subjects on the message
+// row, Meta below. Both are truncated to opts.MaxWidth; the returned items
+// hold the truncated Meta.
+func Layout(items []Item, opts Options) (Text, bool) {
+	maxWidth := opts.MaxWidth
+	if maxWidth <= 0 {
+		maxWidth = DefaultMaxWidth
+	}
+
+	ell := Ellipsis
+	if opts.ASCII {
+		ell = EllipsisASCII
+	}
+
+	var msgSegs, metaSegs []string
+	var text Text
+	offset := 0
+
+	for _, item := range items {
+		item.Meta = truncate(item.Meta, maxWidth, ell)
+		subjectCells := toCells(truncate(item.Subject, maxWidth, ell))
+		metaCells := toCells(item.Meta)
+
+		// Both rows share the segment width so they stay aligned; the gap
+		// is fixed so a short subject never scrolls through long blanks
+		// beyond what its meta line needs.
+		width := max(utf8.RuneCountInString(subjectCells), utf8.RuneCountInString(metaCells)) + segmentGap
+		msgSegs = append(msgSegs, padRight(subjectCells, width))
+		metaSegs = append(metaSegs, padRight(metaCells, width))
+		text.Items = append(text.Items, item)
+		text.Starts = append(text.Starts, offset)
+		offset += width
+	}
+
+	if len(msgSegs) == 0 {
+		return Text{}, false
+	}
+	text.Msg = strings.Join(msgSegs, "")
+	text.Meta = strings.Join(metaSegs, "")
+	return text, true
+}
+
+func keep(subject string, include, exclude []*regexp.Regexp) bool {
+	for _, re := range exclude {
+		if re.MatchString(subject) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, re := range include {
+		if re.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileAll(exprs []string) []*regexp.Regexp {
+	var res []*regexp.Regexp
+	for _, expr := range exprs {
+		if re, err := regexp.Compile(expr); err == nil {
+			res = append(res, re)
+		}
+	}
+	return res
+}
+
+// truncate shortens s to at most n cells, marking the cut with ellipsis.
+// Trailing spaces before the ellipsis are dropped.
+func truncate(s string, n int, ellipsis string) string {
+	if uniseg.StringWidth(s) <= n {
+		return s
+	}
+	ellWidth := uniseg.StringWidth(ellipsis)
+	if n <= ellWidth {
+		return prefixCells(s, n)
+	}
+	cut := strings.TrimRight(prefixCells(s, n-ellWidth), " ")
+	return cut + ellipsis
+}
+
+// prefixCells returns the longest prefix of s at most n cells wide.
+func prefixCells(s string, n int) string {
+	width := 0
+	for i, r := range s {
+		width += runeWidth(r)
+		if width > n {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// toCells lays s out one rune per cell: double-width runes are followed by
+// Continuation and zero-width runes (combining marks...) are dropped.
+func toCells(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch runeWidth(r) {
+		case 0:
+			continue
+		case 2:
+			b.WriteRune(r)
+			b.WriteRune(Continuation)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// runeWidth returns the number of cells r occupies.
+func runeWidth(r rune) int {
+	return uniseg.StringWidth(string(r))
+}
+
+func padRight(s string, n int) string {
+	rs := []rune(s)
+	if len(rs) >= n {
+		return s
+	}
+	return s + strings.Repeat(" ", n-len(rs))
+}
@@ -0,0 +1,185 @@
+package ticker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleLog = "a1\x00alice\x001 hour ago\x001700000000\x00feat: add snow\n" +
+	"b2\x00bob\x002 hours ago\x001700000000\x00chore: bump deps\n" +
+	"c3\x00carol\x003 hours ago\x001700000000\x00fix: resize crash\n" +
+	"d4\x00dave\x004 hours ago\x001700000000\x00Merge branch 'main'\n"
+
+func TestParseGitLogOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "no options",
+			want: []string{"feat: add snow", "chore: bump deps", "fix: resize crash", "Merge branch"},
+		},
+		{
+			name:    "max commits",
+			opts:    Options{MaxCommits: 2},
+			want:    []string{"feat: add snow", "chore: bump deps"},
+			notWant: []string{"fix: resize crash"},
+		},
+		{
+			name:    "exclude",
+			opts:    Options{Exclude: []string{"^chore", "^Merge"}},
+			want:    []string{"feat: add snow", "fix: resize crash"},
+			notWant: []string{"chore", "Merge"},
+		},
+		{
+			name:    "include",
+			opts:    Options{Include: []string{"^fix"}},
+			want:    []string{"fix: resize crash"},
+			notWant: []string{"feat", "chore"},
+		},
+		{
+			name:    "limit applies after filtering",
+			opts:    Options{MaxCommits: 1, Exclude: []string{"^feat"}},
+			want:    []string{"chore: bump deps"},
+			notWant: []string{"feat", "fix"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, ok := ParseGitLog(sampleLog, tt.opts)
+			assert.True(t, ok)
+			msg := text.Msg
+			for _, w := range tt.want {
+				assert.Contains(t, msg, w)
+			}
+			for _, nw := range tt.notWant {
+				assert.NotContains(t, msg, nw)
+			}
+		})
+	}
+}
+
+func TestParseGitLogEverythingFiltered(t *testing.T) {
+	_, ok := ParseGitLog(sampleLog, Options{Include: []string{"^docs"}})
+	assert.False(t, ok)
+
+	_, ok = ParseGitLog(strings.Repeat("\n", 3), Options{})
+	assert.False(t, ok)
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"this is too long", 10, "this is t…"},
+		{"this is a test", 9, "this is…"},
+		{"héllo wörld", 6, "héllo…"},
+		{"abc", 1, "a"},
+		{"日本語のコミット", 7, "日本語…"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, truncate(tt.in, tt.n, Ellipsis), tt.in)
+	}
+}
+
+func TestParseGitLogMaxWidth(t *testing.T) {
+	long := "feat(scope): " + strings.Repeat("very long subject ", 20)
+	text, ok := ParseGitLog("a1\x00alice\x001 hour ago\x001700000000\x00"+long, Options{MaxWidth: 40})
+	assert.True(t, ok)
+	msg, meta := text.Msg, text.Meta
+	assert.Equal(t, 40+segmentGap, len([]rune(msg)))
+	assert.Equal(t, len([]rune(msg)), len([]rune(meta)))
+	assert.Contains(t, msg, Ellipsis)
+	assert.Len(t, []rune(text.Items[0].Subject), MaxTextLen, "items keep the full (sanitized) subject")
+}
+
+func TestTruncateASCII(t *testing.T) {
+	assert.Equal(t, "this...", truncate("this is too long", 7, EllipsisASCII))
+}
+
+func TestParseGitLogItemStarts(t *testing.T) {
+	text, ok := ParseGitLog(sampleLog, Options{MaxCommits: 3})
+	assert.True(t, ok)
+	assert.Len(t, text.Items, 3)
+	assert.Equal(t, []int{0, 23, 45}, text.Starts)
+
+	runes := []rune(text.Msg)
+	for i, start := range text.Starts {
+		assert.True(t, strings.HasPrefix(string(runes[start:]), text.Items[i].Subject))
+
+		idx, found := text.ItemStartingAt(start)
+		assert.True(t, found)
+		assert.Equal(t, i, idx)
+	}
+
+	_, found := text.ItemStartingAt(1)
+	assert.False(t, found)
+
+	for pos, want := range map[int]int{0: 0, 22: 0, 23: 1, 44: 1, 45: 2, len(runes) - 1: 2} {
+		idx, found := text.ItemAt(pos)
+		assert.True(t, found)
+		assert.Equal(t, want, idx, "pos %d", pos)
+	}
+	assert.Equal(t, "b2", text.Items[1].Hash)
+	assert.Equal(t, int64(1700000000), text.Items[1].Time.Unix())
+	assert.Equal(t, len(runes), text.Len())
+}
+
+func TestParseGitLogTabsInFields(t *testing.T) {
+	log := "a1\x00alice\x001 hour ago\x001700000000\x00fix:\tsplit\t\tfields\n" +
+		"b2\x00bob\x002 hours ago\x001700000000\x00\tleading tab"
+	text, ok := ParseGitLog(log, Options{})
+	assert.True(t, ok)
+	assert.Len(t, text.Items, 2)
+	assert.Equal(t, "fix: split fields", text.Items[0].Subject)
+	assert.Equal(t, "by alice 1 hour ago", text.Items[0].Meta)
+	assert.Equal(t, "leading tab", text.Items[1].Subject)
+	assert.NotContains(t, text.Msg, "\t")
+}
+
+func TestParseGitLogWideCharacters(t *testing.T) {
+	text, ok := ParseGitLog("a1\x00太郎\x001 hour ago\x001700000000\x00修正: 日本語", Options{})
+	assert.True(t, ok)
+
+	// Every double-width rune is followed by a Continuation cell, so both
+	// rows have the same width in cells and runes.
+	msg, meta := []rune(text.Msg), []rune(text.Meta)
+	assert.Equal(t, len(msg), len(meta))
+	assert.Equal(t, []rune{'修', Continuation, '正', Continuation, ':'}, msg[:5])
+	assert.Equal(t, []rune{'b', 'y', ' ', '太', Continuation, '郎', Continuation, ' '}, meta[:8])
+	assert.Equal(t, "修正: 日本語", text.Items[0].Subject)
+}
+
+func TestInterleave(t *testing.T) {
+	item := func(s string) Item { return Item{Subject: s} }
+	a := []Item{item("a1"), item("a2"), item("a3")}
+	b := []Item{item("b1")}
+	c := []Item{item("c1"), item("c2")}
+
+	subjects := func(items []Item) []string {
+		var s []string
+		for _, item := range items {
+			s = append(s, item.Subject)
+		}
+		return s
+	}
+	assert.Equal(t, []string{"a1", "b1", "c1", "a2", "c2", "a3"}, subjects(Interleave([][]Item{a, b, c}, 0)))
+	assert.Equal(t, []string{"a1", "b1", "c1", "a2"}, subjects(Interleave([][]Item{a, b, c}, 4)))
+	assert.Empty(t, Interleave(nil, 10))
+}
+
+func TestLabelRepo(t *testing.T) {
+	items := []Item{{Hash: "a1", Subject: "feat: add snow"}}
+	labeled := LabelRepo(items, "/src/yule-log/")
+	assert.Equal(t, []Item{{Hash: "a1", Subject: "yule-log: feat: add snow", Dir: "/src/yule-log/"}}, labeled)
+	assert.Equal(t, "feat: add snow", items[0].Subject, "items are copied")
+}
@@ -0,0 +1,115 @@
+package ticker
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxTextLen is the maximum number of runes kept from a single piece of
+// externally sourced ticker text (commit subject, author, feed title...).
+const MaxTextLen = 256
+
+// Sanitize makes externally sourced text safe to draw into the cell grid.
+// It removes terminal escape sequences (CSI, OSC, DCS...), strips C0/C1
+// control characters and bidi overrides, replaces invalid UTF-8, collapses
+// whitespace runs into a single space and truncates to MaxTextLen runes.
+func Sanitize(s string) string {
+	s = strings.ToValidUTF8(s, string(utf8.RuneError))
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	runes := []rune(s)
+	count := 0
+	pendingSpace := false
+
+	for i := 0; i < len(runes) && count < MaxTextLen; i++ {
+		r := runes[i]
+
+		switch {
+		case r == 0x1b: // ESC: skip the whole sequence
+			i = skipEscape(runes, i)
+			continue
+		case r == 0x9b: // C1 CSI
+			i = skipCSI(runes, i+1)
+			continue
+		case r == 0x9d || r == 0x90 || r == 0x9e || r == 0x9f: // C1 OSC, DCS, PM, APC
+			i = skipString(runes, i+1)
+			continue
+		case unicode.IsSpace(r):
+			pendingSpace = b.Len() > 0
+			continue
+		case isControl(r):
+			continue
+		}
+
+		if pendingSpace {
+			if count+1 >= MaxTextLen {
+				break
+			}
+			b.WriteByte(' ')
+			count++
+			pendingSpace = false
+		}
+		b.WriteRune(r)
+		count++
+	}
+
+	return b.String()
+}
+
+// isControl reports whether r is a C0/C1 control, DEL or a bidi formatting
+// character that could reorder the surrounding cells.
+func isControl(r rune) bool {
+	switch {
+	case r < 0x20, r == 0x7f, r >= 0x80 && r <= 0x9f:
+		return true
+	case r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069:
+		return true
+	}
+	return false
+}
+
+// skipEscape returns the index of the last rune of the escape sequence
+// starting at runes[i] (which must be ESC).
+func skipEscape(runes []rune, i int) int {
+	if i+1 >= len(runes) {
+		return i
+	}
+	switch runes[i+1] {
+	case '[':
+		return skipCSI(runes, i+2)
+	case ']', 'P', '^', '_', 'X':
+		return skipString(runes, i+2)
+	default:
+		// Two-character sequence (ESC c, ESC 7, ...)
+		return i + 1
+	}
+}
+
+// skipCSI skips parameter and intermediate bytes up to and including the
+// final byte (0x40-0x7e) of a control sequence.
+func skipCSI(runes []rune, i int) int {
+	for ; i < len(runes); i++ {
+		if runes[i] >= 0x40 && runes[i] <= 0x7e {
+			return i
+		}
+	}
+	return len(runes) - 1
+}
+
+// skipString skips a control string terminated by BEL, ST (ESC \) or C1 ST.
+func skipString(runes []rune, i int) int {
+	for ; i < len(runes); i++ {
+		switch runes[i] {
+		case 0x07, 0x9c:
+			return i
+		case 0x1b:
+			if i+1 < len(runes) && runes[i+1] == '\\' {
+				return i + 1
+			}
+		}
+	}
+	return len(runes) - 1
+}
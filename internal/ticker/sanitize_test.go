@@ -0,0 +1,142 @@
+package ticker
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "plain text",
+			in:   "fix: handle resize",
+			want: "fix: handle resize",
+		},
+		{
+			name: "SGR color sequence",
+			in:   "\x1b[31mred\x1b[0m subject",
+			want: "red subject",
+		},
+		{
+			name: "cursor movement and clear screen",
+			in:   "a\x1b[2J\x1b[H\x1b[10;20fb",
+			want: "ab",
+		},
+		{
+			name: "OSC title change terminated by BEL",
+			in:   "x\x1b]0;pwned\x07y",
+			want: "xy",
+		},
+		{
+			name: "OSC 52 clipboard write terminated by ST",
+			in:   "x\x1b]52;c;ZWNobyBoaQ==\x1b\\y",
+			want: "xy",
+		},
+		{
+			name: "DCS string",
+			in:   "a\x1bPq#0;2;0;0;0\x1b\\b",
+			want: "ab",
+		},
+		{
+			name: "C1 CSI",
+			in:   "a\u009b31mb",
+			want: "ab",
+		},
+		{
+			name: "C1 OSC",
+			in:   "a\u009d0;title\u009cb",
+			want: "ab",
+		},
+		{
+			name: "two-character escape",
+			in:   "a\x1bcb",
+			want: "ab",
+		},
+		{
+			name: "trailing lone escape",
+			in:   "abc\x1b",
+			want: "abc",
+		},
+		{
+			name: "unterminated CSI",
+			in:   "abc\x1b[1;2",
+			want: "abc",
+		},
+		{
+			name: "C0 controls",
+			in:   "a\x00b\x07c\x08d\x7fe",
+			want: "abcde",
+		},
+		{
+			name: "whitespace normalized",
+			in:   "  tabs\tand\r\nnewlines   collapse  ",
+			want: "tabs and newlines collapse",
+		},
+		{
+			name: "bidi override",
+			in:   "safe‮exe.txt",
+			want: "safeexe.txt",
+		},
+		{
+			name: "invalid utf8",
+			in:   "a\xffb",
+			want: "a�b",
+		},
+		{
+			name: "unicode preserved",
+			in:   "feat: 🔥 flammes",
+			want: "feat: 🔥 flammes",
+		},
+		{
+			name: "only controls",
+			in:   "\x1b[31m\x00\x1b[0m",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sanitize(tt.in))
+		})
+	}
+}
+
+func TestSanitizeLength(t *testing.T) {
+	got := Sanitize(strings.Repeat("é", MaxTextLen*2))
+	assert.Equal(t, MaxTextLen, utf8.RuneCountInString(got))
+
+	got = Sanitize(strings.Repeat("a ", MaxTextLen))
+	assert.LessOrEqual(t, utf8.RuneCountInString(got), MaxTextLen)
+	assert.False(t, strings.HasSuffix(got, " "))
+}
+
+func TestParseGitLogMaliciousSubjects(t *testing.T) {
+	log := strings.Join([]string{
+		"abc1234\x00mallory\x002 hours ago\x001700000000\x00\x1b]0;owned\x07\x1b[2Jinnocent fix",
+		"def5678\x00\x1b[31meve\x1b[0m\x003 days ago\x001700000000\x00line one\rline two",
+		"0123456\x00bob\x001 week ago\x001700000000\x00\x1b[0m\x7f",
+	}, "\n")
+
+	text, ok := ParseGitLog(log, Options{})
+	require.True(t, ok)
+	msg, meta := text.Msg, text.Meta
+
+	for _, s := range []string{msg, meta} {
+		for _, r := range s {
+			assert.False(t, isControl(r), "unexpected control %U", r)
+			assert.NotEqual(t, rune(0x1b), r)
+		}
+	}
+	assert.Contains(t, msg, "innocent fix")
+	assert.Contains(t, msg, "line one line two")
+	assert.Contains(t, meta, "by eve 3 days ago")
+	assert.Equal(t, utf8.RuneCountInString(msg), utf8.RuneCountInString(meta))
+}
@@ -0,0 +1,67 @@
+package ticker
+
+import (
+	"context"
+	"strings"
+
+	"yule-log/internal/gitdata"
+)
+
+// ---- Repository Status
+// The meta row of the commits can end with the state of the work tree:
+// the current branch, the nearest tag and whether there are uncommitted
+// changes, e.g. "by alice 1 hour ago · main · v1.2.0 · dirty".
+
+// Status is the state of a work tree.
+type Status struct {
+	Branch string // Empty on a detached HEAD
+	Tag    string // Nearest tag reachable from HEAD, if any
+	Dirty  bool   // Uncommitted changes to tracked files
+}
+
+// ReadStatus reads the parts of the status of the work tree at dir that
+// opts asks for. Parts git can't tell, or not in time (git status on a
+// huge work tree), are left empty.
+func ReadStatus(dir string, opts Options) Status {
+	var st Status
+	if opts.Branch {
+		st.Branch = gitOutput(dir, "symbolic-ref", "--short", "-q", "HEAD")
+	}
+	if opts.Tag {
+		st.Tag = gitOutput(dir, "describe", "--tags", "--abbrev=0")
+	}
+	if opts.Dirty {
+		st.Dirty = gitOutput(dir, "status", "--porcelain", "--untracked-files=no") != ""
+	}
+	return st
+}
+
+// gitOutput runs git in dir and returns its trimmed output, empty on
+// failure.
+func gitOutput(dir string, args ...string) string {
+	return Sanitize(gitdata.Repo{Dir: dir}.Output(context.Background(), args...))
+}
+
+// Suffix returns the status to append to a meta row, starting with a
+// separator, or "" when there is nothing to show.
+func (st Status) Suffix(ascii bool) string {
+	sep := " · "
+	if ascii {
+		sep = " - "
+	}
+	var b strings.Builder
+	for _, part := range []string{st.Branch, st.Tag} {
+		if part != "" {
+			b.WriteString(sep + part)
+		}
+	}
+	if st.Dirty {
+		b.WriteString(sep + "dirty")
+	}
+	return b.String()
+}
+
+// wantStatus reports whether opts asks for any part of the status.
+func (o Options) wantStatus() bool {
+	return o.Branch || o.Tag || o.Dirty
+}
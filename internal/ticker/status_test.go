@@ -0,0 +1,49 @@
+package ticker
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusSuffix(t *testing.T) {
+	assert.Equal(t, "", Status{}.Suffix(false))
+	assert.Equal(t, " · main · v1.2.0 · dirty", Status{Branch: "main", Tag: "v1.2.0", Dirty: true}.Suffix(false))
+	assert.Equal(t, " - v1.2.0", Status{Tag: "v1.2.0"}.Suffix(true))
+}
+
+func TestReadStatus(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=alice", "-c", "user.email=a@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q", "-b", "trunk")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "log.txt"), []byte("one\n"), 0600))
+	git("add", "log.txt")
+	git("commit", "-q", "-m", "feat: add snow")
+	git("tag", "v0.1.0")
+	git("commit", "-q", "--allow-empty", "-m", "fix: resize crash")
+
+	all := Options{MaxCommits: 5, Branch: true, Tag: true, Dirty: true}
+	assert.Equal(t, Status{Branch: "trunk", Tag: "v0.1.0"}, ReadStatus(root, all))
+	assert.Equal(t, Status{Tag: "v0.1.0"}, ReadStatus(root, Options{Tag: true}))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "log.txt"), []byte("two\n"), 0600))
+	items, err := GitItems(root, all)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Regexp(t, `^by alice .* ago · trunk · v0\.1\.0 · dirty$`, items[0].Meta)
+
+	items, err = GitItems(root, Options{MaxCommits: 5})
+	require.NoError(t, err)
+	assert.NotContains(t, items[0].Meta, "trunk")
+}
@@ -0,0 +1,218 @@
+package ticker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"yule-log/internal/fsutil"
+	"yule-log/internal/gitdata"
+)
+
+// ---- TODO Ticker
+// Counts TODO and FIXME lines per top-level directory of a repository and
+// scrolls them with their trend: "TODOs: api 42 (+3 this week)". Scans go
+// through git grep, so ignored files are skipped. Every scan is recorded
+// in a cache file holding a few weeks of snapshots per repository, which
+// the trend is computed from.
+
+const (
+	// TodoStale is the age after which cached counts are scanned again.
+	TodoStale = 15 * time.Minute
+	// todoScanTimeout bounds one scan, partial counts are kept beyond.
+	todoScanTimeout = 30 * time.Second
+	// todoRetention is how long snapshots are kept.
+	todoRetention = 30 * 24 * time.Hour
+	// todoSnapshotEvery is the minimum time between two kept snapshots;
+	// scans in between replace the latest one.
+	todoSnapshotEvery = time.Hour
+	// todoTrendWindow is how far back the trend looks.
+	todoTrendWindow = 7 * 24 * time.Hour
+	// todoRootDir names files at the root of the repository.
+	todoRootDir = "."
+)
+
+// TodoCounts holds TODO/FIXME line counts by top-level directory.
+type TodoCounts map[string]int
+
+// TodoSnapshot is the result of one scan.
+type TodoSnapshot struct {
+	Time   time.Time  `json:"time"`
+	Counts TodoCounts `json:"counts"`
+}
+
+// TodoCache holds snapshots by repository root, oldest first.
+type TodoCache map[string][]TodoSnapshot
+
+// ScanTodos counts TODO and FIXME lines in the tracked and untracked (but
+// not ignored) text files of the repository at root. A scan cut short by
+// the gitdata limits returns the files counted so far and partial true.
+func ScanTodos(root string) (counts TodoCounts, partial bool, err error) {
+	repo := gitdata.Repo{Dir: root, Limits: gitdata.Limits{Timeout: todoScanTimeout}}
+	res, err := repo.Run(context.Background(), "grep", "--untracked", "-I", "-c", "-w", "-E", "TODO|FIXME")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return TodoCounts{}, false, nil // No match
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("scanning TODOs: %w", err)
+	}
+	return parseTodoCounts(res.Out), res.Partial, nil
+}
+
+// parseTodoCounts sums git grep -c output ("path:count" lines) by
+// top-level directory.
+func parseTodoCounts(out string) TodoCounts {
+	counts := TodoCounts{}
+	for _, line := range strings.Split(out, "\n") {
+		i := strings.LastIndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		n, err := strconv.Atoi(line[i+1:])
+		if err != nil {
+			continue
+		}
+		dir, _, nested := strings.Cut(line[:i], "/")
+		if !nested {
+			dir = todoRootDir
+		}
+		counts[dir] += n
+	}
+	return counts
+}
+
+// LoadTodoCache reads the cache file. A missing or corrupt file is an
+// empty cache: it only holds what can be scanned again.
+func LoadTodoCache(path string) TodoCache {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TodoCache{}
+	}
+	var cache TodoCache
+	if json.Unmarshal(data, &cache) != nil || cache == nil {
+		return TodoCache{}
+	}
+	return cache
+}
+
+// Record adds a snapshot for root, replacing the latest one when it is
+// recent, and drops expired snapshots.
+func (c TodoCache) Record(root string, snap TodoSnapshot) {
+	snaps := c[root]
+	// The latest snapshot is kept once it is an hour past the one before.
+	if n := len(snaps); n > 1 && snap.Time.Sub(snaps[n-2].Time) < todoSnapshotEvery {
+		snaps = snaps[:n-1]
+	}
+	snaps = append(snaps, snap)
+
+	cutoff := snap.Time.Add(-todoRetention)
+	kept := snaps[:0]
+	for _, s := range snaps {
+		if !s.Time.Before(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	c[root] = kept
+}
+
+// RecordTodos adds a snapshot to the cache file, merging with writes from
+// other screensavers.
+func RecordTodos(path, root string, snap TodoSnapshot) error {
+	return fsutil.Update(path, 0600, func(data []byte) ([]byte, error) {
+		var cache TodoCache
+		if json.Unmarshal(data, &cache) != nil || cache == nil {
+			cache = TodoCache{}
+		}
+		cache.Record(root, snap)
+		return json.Marshal(cache)
+	})
+}
+
+// TodoItems returns one ticker item per directory of the latest snapshot,
+// most TODOs first, with the change over the last week. At most limit
+// directories are listed (0 = all).
+func TodoItems(snaps []TodoSnapshot, now time.Time, limit int, ascii bool) []Item {
+	if len(snaps) == 0 {
+		return nil
+	}
+	latest := snaps[len(snaps)-1]
+	base, label, ok := todoBaseline(snaps, now)
+
+	dirs := make([]string, 0, len(latest.Counts))
+	for dir, n := range latest.Counts {
+		if n > 0 {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if latest.Counts[dirs[i]] != latest.Counts[dirs[j]] {
+			return latest.Counts[dirs[i]] > latest.Counts[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+	if limit > 0 && len(dirs) > limit {
+		dirs = dirs[:limit]
+	}
+
+	meta := "scanned " + since(now.Sub(latest.Time)) + " ago"
+	items := make([]Item, 0, len(dirs))
+	for _, dir := range dirs {
+		n := latest.Counts[dir]
+		subject := fmt.Sprintf("TODOs: %s %d", Sanitize(dir), n)
+		if ok {
+			if delta := n - base.Counts[dir]; delta != 0 {
+				subject += " (" + signed(delta, ascii) + label + ")"
+			}
+		}
+		items = append(items, Item{Subject: subject, Meta: meta, Time: latest.Time})
+	}
+	return items
+}
+
+// todoBaseline returns the snapshot to compare the latest one with: the
+// newest one at least a week old, else the oldest one there is.
+func todoBaseline(snaps []TodoSnapshot, now time.Time) (TodoSnapshot, string, bool) {
+	if len(snaps) < 2 {
+		return TodoSnapshot{}, "", false
+	}
+	weekAgo := now.Add(-todoTrendWindow)
+	for i := len(snaps) - 2; i >= 0; i-- {
+		if !snaps[i].Time.After(weekAgo) {
+			return snaps[i], " this week", true
+		}
+	}
+	return snaps[0], " since " + snaps[0].Time.Format("Jan 2"), true
+}
+
+// signed formats a change with its sign. The minus sign is typographic
+// unless ascii is set.
+func signed(n int, ascii bool) string {
+	switch {
+	case n > 0:
+		return "+" + strconv.Itoa(n)
+	case ascii:
+		return strconv.Itoa(n)
+	default:
+		return "−" + strconv.Itoa(-n)
+	}
+}
+
+// since formats a duration coarsely: 3m, 2h, 5d.
+func since(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return strconv.Itoa(max(int(d/time.Minute), 0)) + "m"
+	case d < 24*time.Hour:
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	default:
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	}
+}
@@ -0,0 +1,131 @@
+package ticker
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTodoCounts(t *testing.T) {
+	out := "api/handler.go:3\napi/v2/routes.go:2\nui/app.ts:1\nmain.go:4\nweird:name.go:1\n\n"
+	assert.Equal(t, TodoCounts{"api": 5, "ui": 1, ".": 5}, parseTodoCounts(out))
+}
+
+func TestScanTodos(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	require.NoError(t, exec.Command("git", "-C", root, "init", "-q").Run())
+
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	counts, partial, err := ScanTodos(root)
+	require.NoError(t, err)
+	assert.False(t, partial)
+	assert.Empty(t, counts)
+
+	write("api/a.go", "// TODO: one\n// FIXME: two\n// TODOS is not a marker\n")
+	write("main.go", "// TODO\n")
+	write("build/out.go", "// TODO: generated\n")
+	write(".gitignore", "build/\n")
+
+	counts, partial, err = ScanTodos(root)
+	require.NoError(t, err)
+	assert.False(t, partial)
+	assert.Equal(t, TodoCounts{"api": 2, ".": 1}, counts)
+}
+
+func TestTodoCacheRecord(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	snap := func(d time.Duration, n int) TodoSnapshot {
+		return TodoSnapshot{Time: start.Add(d), Counts: TodoCounts{"api": n}}
+	}
+
+	cache := TodoCache{}
+	cache.Record("/repo", snap(0, 1))
+	cache.Record("/repo", snap(15*time.Minute, 2))
+	cache.Record("/repo", snap(30*time.Minute, 3))
+	assert.Equal(t, []TodoSnapshot{snap(0, 1), snap(30*time.Minute, 3)}, cache["/repo"],
+		"scans within the hour replace the latest snapshot")
+
+	cache.Record("/repo", snap(75*time.Minute, 4))
+	cache.Record("/repo", snap(80*time.Minute, 5))
+	assert.Equal(t, []TodoSnapshot{snap(0, 1), snap(30*time.Minute, 3), snap(80*time.Minute, 5)}, cache["/repo"])
+
+	cache.Record("/repo", snap(todoRetention+80*time.Minute, 6))
+	assert.Equal(t, []TodoSnapshot{snap(80*time.Minute, 5), snap(todoRetention+80*time.Minute, 6)}, cache["/repo"],
+		"expired snapshots are dropped")
+}
+
+func TestRecordTodos(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	assert.Empty(t, LoadTodoCache(path))
+
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, RecordTodos(path, "/a", TodoSnapshot{Time: now, Counts: TodoCounts{"api": 1}}))
+	require.NoError(t, RecordTodos(path, "/b", TodoSnapshot{Time: now, Counts: TodoCounts{"ui": 2}}))
+
+	cache := LoadTodoCache(path)
+	require.Len(t, cache["/a"], 1)
+	assert.Equal(t, 2, cache["/b"][0].Counts["ui"])
+	assert.True(t, now.Equal(cache["/a"][0].Time))
+
+	require.NoError(t, os.WriteFile(path, []byte("{corrupt"), 0600))
+	assert.Empty(t, LoadTodoCache(path))
+	require.NoError(t, RecordTodos(path, "/a", TodoSnapshot{Time: now, Counts: TodoCounts{}}))
+}
+
+func TestTodoItems(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	latest := TodoSnapshot{Time: now.Add(-5 * time.Minute), Counts: TodoCounts{"api": 42, "ui": 17, "docs": 1, "old": 0}}
+
+	subjects := func(items []Item) []string {
+		var s []string
+		for _, item := range items {
+			s = append(s, item.Subject)
+		}
+		return s
+	}
+
+	t.Run("no snapshot", func(t *testing.T) {
+		assert.Empty(t, TodoItems(nil, now, 0, false))
+	})
+
+	t.Run("single snapshot", func(t *testing.T) {
+		items := TodoItems([]TodoSnapshot{latest}, now, 0, false)
+		assert.Equal(t, []string{"TODOs: api 42", "TODOs: ui 17", "TODOs: docs 1"}, subjects(items))
+		assert.Equal(t, "scanned 5m ago", items[0].Meta)
+		assert.Empty(t, items[0].Hash)
+	})
+
+	t.Run("weekly trend", func(t *testing.T) {
+		snaps := []TodoSnapshot{
+			{Time: now.Add(-9 * 24 * time.Hour), Counts: TodoCounts{"api": 10}},
+			{Time: now.Add(-8 * 24 * time.Hour), Counts: TodoCounts{"api": 39, "ui": 19, "docs": 1}},
+			{Time: now.Add(-2 * 24 * time.Hour), Counts: TodoCounts{"api": 50}},
+			latest,
+		}
+		assert.Equal(t, []string{"TODOs: api 42 (+3 this week)", "TODOs: ui 17 (−2 this week)", "TODOs: docs 1"},
+			subjects(TodoItems(snaps, now, 0, false)))
+		assert.Equal(t, []string{"TODOs: api 42 (+3 this week)", "TODOs: ui 17 (-2 this week)"},
+			subjects(TodoItems(snaps, now, 2, true)))
+	})
+
+	t.Run("young history", func(t *testing.T) {
+		snaps := []TodoSnapshot{
+			{Time: time.Date(2024, 3, 8, 9, 0, 0, 0, time.UTC), Counts: TodoCounts{"api": 40}},
+			latest,
+		}
+		assert.Equal(t, "TODOs: api 42 (+2 since Mar 8)", TodoItems(snaps, now, 1, false)[0].Subject)
+	})
+}
@@ -0,0 +1,136 @@
+// Package tmuxcmd builds tmux command lines that run yule-log, quoting
+// paths and flags for the shell and for the tmux command parser.
+package tmuxcmd
+
+import (
+	"strings"
+)
+
+// ---- Quoting
+// A command run from a tmux binding is parsed twice: by tmux, when the
+// binding fires, then by the shell display-popup starts it with.
+
+// ShellQuote quotes s for POSIX shells. Words made of safe characters
+// only are left as is.
+func ShellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("/._-+=:,%@", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ShellJoin quotes args and joins them into a shell command line.
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Quote quotes s as one argument for the tmux command parser. Single
+// quoted text is kept as is; single quotes themselves are escaped outside
+// the quotes.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ---- Popups
+// display-popup hands its command to the shell as a single string, so
+// every argument is quoted: a pane path with spaces, quotes or $(...) in
+// it stays one argument and is never run.
+
+// PopupArgs returns the tmux arguments opening command in a full-screen
+// popup over client, "" for the current one.
+func PopupArgs(client string, command []string) []string {
+	args := []string{"display-popup"}
+	if client != "" {
+		args = append(args, "-c", client)
+	}
+	return append(args, "-E", "-w", "100%", "-h", "100%", ShellJoin(command))
+}
+
+// ---- Lock Binding
+// `yule-log lock bind` installs a key binding that asks for confirmation,
+// then opens the lock screen in a full-screen popup. The binding carries
+// a note so that `lock unbind` only removes its own.
+
+// Note marks the bindings installed by yule-log.
+const Note = "yule-log lock"
+
+// Binding is a key binding opening the lock screen after a confirmation.
+type Binding struct {
+	Table   string   // Key table, e.g. prefix or root
+	Key     string   // tmux key name, e.g. C-l
+	Prompt  string   // confirm-before prompt
+	Command []string // Lock command line: the yule-log path and its flags
+}
+
+// PopupCommand returns the tmux command opening the lock screen.
+func (b Binding) PopupCommand() string {
+	return "display-popup -E -w 100% -h 100% " + Quote(ShellJoin(b.Command))
+}
+
+// BindArgs returns the tmux arguments installing the binding.
+func (b Binding) BindArgs() []string {
+	return []string{"bind-key", "-N", Note, "-T", b.Table, b.Key, "confirm-before", "-p", b.Prompt, b.PopupCommand()}
+}
+
+// UnbindArgs returns the tmux arguments removing the binding.
+func (b Binding) UnbindArgs() []string {
+	return []string{"unbind-key", "-T", b.Table, b.Key}
+}
+
+// Installed reports whether the output of `tmux list-keys -N -T <table>`
+// lists key with the yule-log note.
+func Installed(listKeys, key string) bool {
+	for _, line := range strings.Split(listKeys, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == key && strings.Join(fields[1:], " ") == Note {
+			return true
+		}
+	}
+	return false
+}
+
+// ---- Client Popups
+// lock --all-clients opens the lock screen over every other attached
+// client. display-popup only returns once its popup closes, so each popup
+// is opened by a tmux client of its own, left running. That client first
+// signals a wait-for channel: once the channel is signaled, it is
+// connected to the server and needs the socket no more.
+
+// ClientPopupArgs returns the tmux arguments signaling channel, then
+// opening command in a full-screen popup over client.
+func ClientPopupArgs(client, channel string, command []string) []string {
+	return append([]string{"wait-for", "-S", channel, ";"}, PopupArgs(client, command)...)
+}
+
+// WaitArgs returns the tmux arguments waiting for channel to be signaled.
+// A channel signaled before is not waited for.
+func WaitArgs(channel string) []string {
+	return []string{"wait-for", channel}
+}
+
+// ---- Client Focus
+// tmux only tracks whether a client's terminal has focus, and sets the
+// "focused" client flag, with the focus-events option on. It ships off.
+
+// ClientFocused reports whether a client is focused, from its
+// #{client_flags} and the value of the focus-events option. Without
+// focus-events, or when the flags can't be read, the client counts as
+// focused, so that the idle watcher never silently stops.
+func ClientFocused(flags, focusEvents string) bool {
+	flags = strings.TrimSpace(flags)
+	if strings.TrimSpace(focusEvents) != "on" || flags == "" {
+		return true
+	}
+	for _, flag := range strings.Split(flags, ",") {
+		if flag == "focused" {
+			return true
+		}
+	}
+	return false
+}
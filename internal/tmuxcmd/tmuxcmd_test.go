@@ -0,0 +1,144 @@
+package tmuxcmd
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var hostile = []string{
+	"/opt/yule log/bin/yule-log",
+	"it's",
+	`"; rm -rf ~; echo "`,
+	"$(touch /tmp/pwned)",
+	"`id`",
+	"a\\b",
+	"#{pane_id}",
+	"tab\there",
+	"",
+}
+
+func TestShellJoin(t *testing.T) {
+	assert.Equal(t, "/usr/bin/yule-log lock --max-lock 8h", ShellJoin([]string{"/usr/bin/yule-log", "lock", "--max-lock", "8h"}))
+	assert.Equal(t, `'it'\''s' ''`, ShellJoin([]string{"it's", ""}))
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	// The shell must hand every argument back unchanged.
+	script := ShellJoin(append([]string{"printf", `%s\0`}, hostile...))
+	out, err := exec.Command("sh", "-c", script).Output()
+	require.NoError(t, err)
+	assert.Equal(t, hostile, strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00"))
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, `'a b'`, Quote("a b"))
+	assert.Equal(t, `'it'\''s'`, Quote("it's"))
+}
+
+func TestBinding(t *testing.T) {
+	b := Binding{Table: "prefix", Key: "C-l", Prompt: "Lock? (y/n)", Command: []string{"/opt/yule log/yule-log", "lock", "--reveal"}}
+	assert.Equal(t, []string{
+		"bind-key", "-N", Note, "-T", "prefix", "C-l", "confirm-before", "-p", "Lock? (y/n)",
+		`display-popup -E -w 100% -h 100% ''\''/opt/yule log/yule-log'\'' lock --reveal'`,
+	}, b.BindArgs())
+	assert.Equal(t, []string{"unbind-key", "-T", "prefix", "C-l"}, b.UnbindArgs())
+}
+
+func TestInstalled(t *testing.T) {
+	out := "C-b       Send the prefix key\nC-l       yule-log lock\nL         Lock the session\n"
+	assert.True(t, Installed(out, "C-l"))
+	assert.False(t, Installed(out, "L"))
+	assert.False(t, Installed(out, "C-b"))
+}
+
+func TestPopupArgs(t *testing.T) {
+	assert.Equal(t, []string{"display-popup", "-E", "-w", "100%", "-h", "100%", "/usr/bin/yule-log run"},
+		PopupArgs("", []string{"/usr/bin/yule-log", "run"}))
+	assert.Equal(t, []string{"display-popup", "-c", "/dev/pts/3", "-E", "-w", "100%", "-h", "100%", "/usr/bin/yule-log run"},
+		PopupArgs("/dev/pts/3", []string{"/usr/bin/yule-log", "run"}))
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	// Hostile pane paths reach yule-log as the single --dir argument.
+	dirs := []string{"/home/me/trailing ", "/tmp/a\nb", "/tmp/x'; touch /tmp/pwned; '"}
+	for _, dir := range append(dirs, hostile[:len(hostile)-1]...) {
+		args := PopupArgs("", []string{"printf", `%s\0`, "run", "--dir", dir})
+		out, err := exec.Command("sh", "-c", args[len(args)-1]).Output()
+		require.NoError(t, err, dir)
+		assert.Equal(t, []string{"run", "--dir", dir}, strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00"), dir)
+	}
+}
+
+func TestClientPopupArgs(t *testing.T) {
+	args := ClientPopupArgs("/dev/pts/3", "yule-log-42-0", []string{"/opt/yule log/yule-log", "lock", "--follow", "--theme=it's"})
+	assert.Equal(t, []string{
+		"wait-for", "-S", "yule-log-42-0", ";",
+		"display-popup", "-c", "/dev/pts/3", "-E", "-w", "100%", "-h", "100%",
+		`'/opt/yule log/yule-log' lock --follow '--theme=it'\''s'`,
+	}, args)
+	assert.Equal(t, []string{"wait-for", "yule-log-42-0"}, WaitArgs("yule-log-42-0"))
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		out          string
+		major, minor int
+		popups       bool
+	}{
+		{"tmux 3.3a\n", 3, 3, true},
+		{"tmux 3.2", 3, 2, true},
+		{"tmux 3.0", 3, 0, false},
+		{"tmux 2.9a", 2, 9, false},
+		{"tmux next-3.5", 3, 5, true},
+		{"tmux openbsd-7.4", 7, 4, true},
+	}
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.out)
+		require.NoError(t, err, tt.out)
+		assert.Equal(t, tt.major, v.Major, tt.out)
+		assert.Equal(t, tt.minor, v.Minor, tt.out)
+		assert.Equal(t, tt.popups, v.AtLeast(PopupVersion), tt.out)
+	}
+
+	v, err := ParseVersion("tmux master")
+	require.NoError(t, err)
+	assert.True(t, v.AtLeast(PopupVersion))
+
+	_, err = ParseVersion("tmux")
+	assert.Error(t, err)
+}
+
+func TestVersionError(t *testing.T) {
+	found, err := ParseVersion("tmux 3.0")
+	require.NoError(t, err)
+	err = &VersionError{Feature: "popups", Need: PopupVersion, Found: found}
+	assert.Equal(t, "tmux ≥3.2 required for popups, found 3.0", err.Error())
+}
+
+func TestClientFocused(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       string
+		focusEvents string
+		want        bool
+	}{
+		{"focused", "attached,focused,UTF-8", "on", true},
+		{"unfocused", "attached,UTF-8", "on", false},
+		{"focus-events off", "attached,UTF-8", "off", true},
+		{"focus-events unreadable", "attached,UTF-8", "", true},
+		{"flags unreadable", "", "on", true},
+		{"trailing newlines", "attached,focused\n", "on\n", true},
+		{"flag prefix only", "attached,focusedx", "on", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClientFocused(tt.flags, tt.focusEvents))
+		})
+	}
+}
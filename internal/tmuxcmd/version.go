@@ -0,0 +1,90 @@
+package tmuxcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ---- Version
+// Features yule-log relies on appeared in given tmux releases. Require
+// reports a missing one up front, naming the feature and both versions,
+// rather than leaving tmux to fail on an unknown command.
+
+// ErrNotFound is returned when tmux isn't installed.
+var ErrNotFound = errors.New("tmux not found")
+
+// Version is a tmux release, such as 3.3a (Major 3, Minor 3).
+type Version struct {
+	Major, Minor int
+	Raw          string // As printed by tmux -V, e.g. "3.3a" or "next-3.5"
+}
+
+// PopupVersion is the first release with display-popup.
+var PopupVersion = Version{Major: 3, Minor: 2, Raw: "3.2"}
+
+func (v Version) String() string { return v.Raw }
+
+// AtLeast reports whether v is need or newer.
+func (v Version) AtLeast(need Version) bool {
+	return v.Major > need.Major || v.Major == need.Major && v.Minor >= need.Minor
+}
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// ParseVersion parses the output of tmux -V. Development builds ("tmux
+// master") have no number and are taken as newer than any release.
+func ParseVersion(out string) (Version, error) {
+	raw := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(out), "tmux "))
+	if raw == "master" {
+		return Version{Major: 1 << 30, Raw: raw}, nil
+	}
+	m := versionPattern.FindStringSubmatch(raw)
+	if m == nil {
+		return Version{}, fmt.Errorf("unrecognized tmux version %q", raw)
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return Version{Major: major, Minor: minor, Raw: raw}, nil
+}
+
+// CurrentVersion returns the version of the tmux on PATH.
+func CurrentVersion(ctx context.Context) (Version, error) {
+	out, err := exec.CommandContext(ctx, "tmux", "-V").Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return Version{}, ErrNotFound
+	}
+	if err != nil {
+		return Version{}, fmt.Errorf("running tmux -V: %w", err)
+	}
+	return ParseVersion(string(out))
+}
+
+// VersionError reports a tmux too old for a feature.
+type VersionError struct {
+	Feature string
+	Need    Version
+	Found   Version
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("tmux ≥%s required for %s, found %s", e.Need, e.Feature, e.Found)
+}
+
+// Require returns a *VersionError when the tmux on PATH is older than
+// need, which feature needs. A version that can't be read passes: tmux
+// reports its own errors.
+func Require(ctx context.Context, need Version, feature string) error {
+	v, err := CurrentVersion(ctx)
+	if errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err != nil || v.AtLeast(need) {
+		return nil
+	}
+	return &VersionError{Feature: feature, Need: need, Found: v}
+}
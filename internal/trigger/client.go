@@ -0,0 +1,64 @@
+package trigger
+
+import (
+	"slices"
+	"strings"
+)
+
+// ---- Client Capabilities
+// Clients attached to one tmux server can sit in very different terminals:
+// a truecolor emulator, an SSH session through a 256-color terminal, the
+// Linux console. Inside a popup the screensaver only sees tmux, so the
+// watcher reads what tmux knows of the client's terminal and passes
+// settings suited to it.
+
+// ClientFormat is the tmux format describing a client, see ParseClient.
+const ClientFormat = "#{client_name}\t#{client_termname}\t#{client_termfeatures}\t#{client_utf8}"
+
+// Client is a tmux client and the terminal it runs in.
+type Client struct {
+	Name     string
+	Term     string   // TERM of the client's terminal
+	Features []string // tmux terminal features, e.g. RGB, 256 (tmux 3.2+)
+	UTF8     bool
+}
+
+// Capabilities are the screensaver settings suited to a client's terminal.
+type Capabilities struct {
+	Colors string // Color depth, "" when unknown
+	ASCII  bool   // Glyphs beyond ASCII can't be drawn
+}
+
+// ParseClient reads a line printed with ClientFormat.
+func ParseClient(line string) (Client, bool) {
+	fields := strings.Split(strings.TrimRight(line, "\r\n"), "\t")
+	if len(fields) != 4 || fields[0] == "" {
+		return Client{}, false
+	}
+	c := Client{Name: fields[0], Term: fields[1], UTF8: fields[3] == "1"}
+	for _, f := range strings.Split(fields[2], ",") {
+		if f != "" {
+			c.Features = append(c.Features, f)
+		}
+	}
+	return c, true
+}
+
+// Capabilities returns the settings suited to the client's terminal. The
+// Linux console has neither the glyphs nor more than 16 colors, whatever
+// tmux believes.
+func (c Client) Capabilities() Capabilities {
+	if c.Term == "linux" {
+		return Capabilities{Colors: "16", ASCII: true}
+	}
+	caps := Capabilities{ASCII: !c.UTF8}
+	switch {
+	case slices.Contains(c.Features, "RGB"):
+		caps.Colors = "truecolor"
+	case slices.Contains(c.Features, "256") || strings.Contains(c.Term, "256color"):
+		caps.Colors = "256"
+	case c.Term != "":
+		caps.Colors = "16"
+	}
+	return caps
+}
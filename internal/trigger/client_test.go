@@ -0,0 +1,39 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClient(t *testing.T) {
+	c, ok := ParseClient("/dev/pts/3\txterm-256color\t256,RGB,title\t1\n")
+	require.True(t, ok)
+	assert.Equal(t, Client{Name: "/dev/pts/3", Term: "xterm-256color", Features: []string{"256", "RGB", "title"}, UTF8: true}, c)
+
+	c, ok = ParseClient("/dev/tty1\tlinux\t\t0")
+	require.True(t, ok)
+	assert.Empty(t, c.Features)
+
+	for _, bad := range []string{"", "\txterm\t\t1", "/dev/pts/3 xterm"} {
+		_, ok := ParseClient(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	for _, tc := range []struct {
+		client Client
+		want   Capabilities
+	}{
+		{Client{Term: "xterm-256color", Features: []string{"256", "RGB"}, UTF8: true}, Capabilities{Colors: "truecolor"}},
+		{Client{Term: "screen-256color", UTF8: true}, Capabilities{Colors: "256"}},
+		{Client{Term: "xterm", Features: []string{"256"}}, Capabilities{Colors: "256", ASCII: true}},
+		{Client{Term: "vt220", UTF8: true}, Capabilities{Colors: "16"}},
+		{Client{Term: "linux", Features: []string{"RGB"}, UTF8: true}, Capabilities{Colors: "16", ASCII: true}},
+		{Client{UTF8: true}, Capabilities{}},
+	} {
+		assert.Equal(t, tc.want, tc.client.Capabilities(), tc.client.Term)
+	}
+}
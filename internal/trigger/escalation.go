@@ -0,0 +1,76 @@
+// Package trigger decides what the idle watcher shows when the client goes
+// idle, escalating through a sequence of styles on repeated triggers.
+package trigger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Style is what a trigger shows.
+type Style string
+
+const (
+	StyleScreensaver Style = "screensaver"
+	StyleContribs    Style = "contribs" // Screensaver in contribution graph mode
+	StyleLock        Style = "lock"
+)
+
+// ParseSequence parses a comma-separated list of styles, e.g.
+// "screensaver,contribs,lock".
+func ParseSequence(s string) ([]Style, error) {
+	var seq []Style
+	for _, name := range strings.Split(s, ",") {
+		style := Style(strings.ToLower(strings.TrimSpace(name)))
+		switch style {
+		case StyleScreensaver, StyleContribs, StyleLock:
+			seq = append(seq, style)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown trigger style %q (want screensaver, contribs or lock)", name)
+		}
+	}
+	if len(seq) == 0 {
+		return nil, fmt.Errorf("empty trigger sequence")
+	}
+	return seq, nil
+}
+
+// DefaultWindow is how long a trigger counts toward escalation.
+const DefaultWindow = time.Hour
+
+// Escalation walks a style sequence on consecutive triggers: the n-th
+// trigger within Window shows the n-th style, and the last style repeats.
+// Triggers older than Window decay, stepping the sequence back down.
+type Escalation struct {
+	Sequence []Style
+	Window   time.Duration
+
+	recent []time.Time // Trigger times within Window, oldest first
+}
+
+// Next records a trigger at now and returns the style to show.
+func (e *Escalation) Next(now time.Time) Style {
+	e.decay(now)
+	e.recent = append(e.recent, now)
+	return e.Sequence[min(len(e.recent), len(e.Sequence))-1]
+}
+
+// Count returns the number of triggers within Window before now.
+func (e *Escalation) Count(now time.Time) int {
+	e.decay(now)
+	return len(e.recent)
+}
+
+func (e *Escalation) decay(now time.Time) {
+	window := e.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	i := 0
+	for i < len(e.recent) && now.Sub(e.recent[i]) >= window {
+		i++
+	}
+	e.recent = e.recent[i:]
+}
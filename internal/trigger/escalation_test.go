@@ -0,0 +1,50 @@
+package trigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSequence(t *testing.T) {
+	seq, err := ParseSequence("screensaver, Contribs,lock")
+	require.NoError(t, err)
+	assert.Equal(t, []Style{StyleScreensaver, StyleContribs, StyleLock}, seq)
+
+	for _, bad := range []string{"", " , ", "screensaver,matrix"} {
+		_, err := ParseSequence(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestEscalation(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	e := &Escalation{Sequence: []Style{StyleScreensaver, StyleContribs, StyleLock}}
+
+	assert.Equal(t, StyleScreensaver, e.Next(at(0)))
+	assert.Equal(t, StyleContribs, e.Next(at(20)))
+	assert.Equal(t, StyleLock, e.Next(at(40)))
+	assert.Equal(t, StyleLock, e.Next(at(50)), "the last style repeats")
+	assert.Equal(t, 4, e.Count(at(55)))
+
+	// The first two triggers decay: two remain within the hour.
+	assert.Equal(t, 2, e.Count(at(85)))
+	assert.Equal(t, StyleLock, e.Next(at(85)))
+
+	// A quiet hour resets the sequence.
+	assert.Equal(t, 0, e.Count(at(200)))
+	assert.Equal(t, StyleScreensaver, e.Next(at(200)))
+}
+
+func TestEscalationWindow(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	e := &Escalation{Sequence: []Style{StyleScreensaver, StyleLock}, Window: 10 * time.Minute}
+
+	assert.Equal(t, StyleScreensaver, e.Next(start))
+	assert.Equal(t, StyleScreensaver, e.Next(start.Add(10*time.Minute)), "window is exclusive")
+	assert.Equal(t, StyleLock, e.Next(start.Add(15*time.Minute)))
+}
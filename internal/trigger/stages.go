@@ -0,0 +1,74 @@
+package trigger
+
+import "time"
+
+// ---- Stages
+// Within one idle period the watcher goes through stages: it shows the
+// screensaver after Timeout, then, with a lock timeout, replaces it with
+// the lock screen after LockTimeout. Activity ends the period.
+
+// Stage is where the watcher is in the current idle period.
+type Stage int
+
+const (
+	StageActive      Stage = iota // Waiting for Timeout
+	StageScreensaver              // Screensaver shown, waiting for LockTimeout or activity
+	StageLocked                   // Lock screen shown, waiting for activity
+)
+
+// Action is what the watcher does after a poll.
+type Action int
+
+const (
+	ActionNone    Action = iota
+	ActionTrigger        // Idle for Timeout: show the screensaver
+	ActionLock           // Idle for LockTimeout: show the lock screen
+	ActionWake           // Activity resumed after a trigger
+)
+
+// Stages tracks the stage of the current idle period.
+type Stages struct {
+	Timeout     time.Duration
+	LockTimeout time.Duration // 0: no second stage
+
+	stage Stage
+}
+
+// Stage returns the current stage.
+func (s *Stages) Stage() Stage {
+	return s.stage
+}
+
+// Observe records the idle time read by a poll and returns what to do.
+// An idle time past both timeouts at once (the machine slept) goes
+// straight to the lock.
+func (s *Stages) Observe(idle time.Duration) Action {
+	action, stage := s.next(idle)
+	s.stage = stage
+	return action
+}
+
+// Peek returns what Observe would, without changing the stage.
+func (s *Stages) Peek(idle time.Duration) Action {
+	action, _ := s.next(idle)
+	return action
+}
+
+func (s *Stages) next(idle time.Duration) (Action, Stage) {
+	lockDue := s.LockTimeout > 0 && idle >= s.LockTimeout
+	switch {
+	case s.stage != StageActive && idle < s.Timeout:
+		return ActionWake, StageActive
+	case s.stage != StageLocked && lockDue:
+		return ActionLock, StageLocked
+	case s.stage == StageActive && idle >= s.Timeout:
+		return ActionTrigger, StageScreensaver
+	}
+	return ActionNone, s.stage
+}
+
+// Locked records that the screen shown on trigger was already the lock
+// screen (--lock, or a sequence reaching lock), skipping the second stage.
+func (s *Stages) Locked() {
+	s.stage = StageLocked
+}
@@ -0,0 +1,45 @@
+package trigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStages(t *testing.T) {
+	s := Stages{Timeout: 5 * time.Minute, LockTimeout: 15 * time.Minute}
+	steps := []struct {
+		idle  time.Duration
+		want  Action
+		stage Stage
+	}{
+		{time.Minute, ActionNone, StageActive},
+		{5 * time.Minute, ActionTrigger, StageScreensaver},
+		{10 * time.Minute, ActionNone, StageScreensaver},
+		{15 * time.Minute, ActionLock, StageLocked},
+		{20 * time.Minute, ActionNone, StageLocked},
+		{10 * time.Second, ActionWake, StageActive},
+		{6 * time.Minute, ActionTrigger, StageScreensaver},
+		{time.Second, ActionWake, StageActive},
+		{time.Hour, ActionLock, StageLocked}, // Woke up from sleep
+	}
+	for i, step := range steps {
+		assert.Equal(t, step.want, s.Peek(step.idle), "step %d", i)
+		assert.Equal(t, step.want, s.Observe(step.idle), "step %d", i)
+		assert.Equal(t, step.stage, s.Stage(), "step %d", i)
+	}
+}
+
+func TestStagesSingle(t *testing.T) {
+	s := Stages{Timeout: time.Minute}
+	assert.Equal(t, ActionTrigger, s.Observe(time.Minute))
+	assert.Equal(t, ActionNone, s.Observe(time.Hour), "no second stage")
+	assert.Equal(t, ActionWake, s.Observe(0))
+
+	// A trigger showing the lock screen skips the second stage
+	s = Stages{Timeout: time.Minute, LockTimeout: time.Hour}
+	assert.Equal(t, ActionTrigger, s.Observe(time.Minute))
+	s.Locked()
+	assert.Equal(t, ActionNone, s.Observe(2*time.Hour))
+}
@@ -0,0 +1,82 @@
+// Package weather fetches the one-line weather summary shown above the
+// ticker, from wttr.in or a user command.
+package weather
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// Refresh is the age after which the weather is fetched again. wttr.in
+	// updates about every half hour.
+	Refresh = 30 * time.Minute
+
+	// DefaultURL is the wttr.in service.
+	DefaultURL = "https://wttr.in"
+
+	// maxLineSize bounds the answer read from wttr.in.
+	maxLineSize = 1024
+)
+
+// wttr.in one-line formats: with a weather emoji, or a text condition for
+// ASCII terminals, e.g. "Paris: Partly cloudy +12°C".
+const (
+	formatEmoji = "%l: %c %t"
+	formatText  = "%l: %C %t"
+)
+
+// Fetch returns the weather at location from the wttr.in service at base,
+// located from the IP address when location is empty.
+func Fetch(ctx context.Context, base, location string, ascii bool) (string, error) {
+	format := formatEmoji
+	if ascii {
+		format = formatText
+	}
+	u := strings.TrimSuffix(base, "/") + "/" + url.PathEscape(location) + "?format=" + url.QueryEscape(format)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("fetching weather: %w", err)
+	}
+	// wttr.in answers curl-like clients with text, others with HTML.
+	req.Header.Set("User-Agent", "curl/8 (yule-log)")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching weather: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching weather: %s", resp.Status)
+	}
+	return firstLine(io.LimitReader(resp.Body, maxLineSize))
+}
+
+// RunCommand runs a shell command and returns the first line it prints.
+func RunCommand(ctx context.Context, command string) (string, error) {
+	out, err := exec.CommandContext(ctx, "sh", "-c", command).Output()
+	if err != nil {
+		return "", fmt.Errorf("weather command: %w", err)
+	}
+	return firstLine(strings.NewReader(string(out)))
+}
+
+// firstLine returns the first non-blank line of r.
+func firstLine(r io.Reader) (string, error) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			return line, nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", fmt.Errorf("reading weather: %w", err)
+	}
+	return "", fmt.Errorf("reading weather: empty answer")
+}
@@ -0,0 +1,49 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetch(t *testing.T) {
+	var gotPath, gotFormat string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotFormat = r.URL.Path, r.URL.Query().Get("format")
+		if r.URL.Path == "/Nowhere" {
+			http.Error(w, "unknown location", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("\nSaint-Malo: Light rain +9°C\n"))
+	}))
+	defer srv.Close()
+
+	line, err := Fetch(context.Background(), srv.URL+"/", "Saint-Malo", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Saint-Malo: Light rain +9°C", line)
+	assert.Equal(t, "/Saint-Malo", gotPath)
+	assert.Equal(t, formatEmoji, gotFormat)
+
+	_, err = Fetch(context.Background(), srv.URL, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, "/", gotPath, "located from the IP address")
+	assert.Equal(t, formatText, gotFormat)
+
+	_, err = Fetch(context.Background(), srv.URL, "Nowhere", false)
+	assert.Error(t, err)
+}
+
+func TestRunCommand(t *testing.T) {
+	line, err := RunCommand(context.Background(), "printf '\\n  sunny 21C \\nmore\\n'")
+	require.NoError(t, err)
+	assert.Equal(t, "sunny 21C", line)
+
+	_, err = RunCommand(context.Background(), "true")
+	assert.Error(t, err, "no output")
+	_, err = RunCommand(context.Background(), "exit 1")
+	assert.Error(t, err)
+}
@@ -5,10 +5,29 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"sync"
 )
 
 const appName = "tmux-yule-log"
 
+// created holds the directories already created by this process, so that
+// path lookups on the startup path cost no system call after the first.
+// The runtime directory isn't cached: it lives in a temporary directory
+// that may be cleaned while a long-running idle watcher needs it.
+var created sync.Map
+
+// ensureDir creates dir (with 0700 permissions) once per process.
+func ensureDir(dir string) error {
+	if _, ok := created.Load(dir); ok {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	created.Store(dir, true)
+	return nil
+}
+
 // ConfigDir returns the configuration directory for tmux-yule-log.
 // On Linux: $XDG_CONFIG_HOME/tmux-yule-log or ~/.config/tmux-yule-log
 // On macOS: ~/Library/Application Support/tmux-yule-log (fallback to XDG if set)
@@ -34,7 +53,51 @@ func ConfigDir() (string, error) {
 	}
 
 	dir := filepath.Join(base, appName)
-	if err := os.MkdirAll(dir, 0700); err != nil {
+	if err := ensureDir(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// StateDir returns the state directory for tmux-yule-log.
+// $XDG_STATE_HOME/tmux-yule-log or ~/.local/state/tmux-yule-log.
+// State dir is for history that should survive reboots but isn't config.
+//
+// Note: This function creates the directory (with 0700 permissions) if it doesn't exist.
+func StateDir() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+
+	dir := filepath.Join(base, appName)
+	if err := ensureDir(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// CacheDir returns the cache directory for tmux-yule-log.
+// $XDG_CACHE_HOME/tmux-yule-log or ~/.cache/tmux-yule-log.
+// Cache dir is for data that can be computed again if lost.
+//
+// Note: This function creates the directory (with 0700 permissions) if it doesn't exist.
+func CacheDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".cache")
+	}
+
+	dir := filepath.Join(base, appName)
+	if err := ensureDir(dir); err != nil {
 		return "", err
 	}
 	return dir, nil
@@ -72,6 +135,98 @@ func PasswordFile() (string, error) {
 	return filepath.Join(dir, "passwd"), nil
 }
 
+// ConfigFile returns the path to the global configuration file.
+func ConfigFile() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// HeatFile returns the path to the heat paths drawn in playground mode.
+func HeatFile() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "heat.toml"), nil
+}
+
+// ThemesDir returns the directory holding user theme files.
+func ThemesDir() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "themes"), nil
+}
+
+// IdleStatsFile returns the path to the idle watcher history file.
+func IdleStatsFile() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "idle.stats"), nil
+}
+
+// LogFile returns the path to the log of unusual events, such as frame
+// stalls.
+func LogFile() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "yule-log.log"), nil
+}
+
+// UnlockLogFile returns the path to the audit log of unlock attempts.
+func UnlockLogFile() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "unlock.log"), nil
+}
+
+// CrashFile returns the path to the report of the last crash: the panic
+// and its stack.
+func CrashFile() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "crash.log"), nil
+}
+
+// TodoCacheFile returns the path to the TODO ticker snapshots.
+func TodoCacheFile() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "todos.json"), nil
+}
+
+// ForgeCacheFile returns the path to the forge ticker cache.
+func ForgeCacheFile() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "forge.json"), nil
+}
+
+// ContribsCacheFile returns the path to the contribution calendar cache.
+func ContribsCacheFile() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "contribs.json"), nil
+}
+
 // LockStateFile returns the path to the lock state file.
 func LockStateFile() (string, error) {
 	dir, err := RuntimeDir()
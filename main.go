@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"crypto/subtle"
 	"errors"
@@ -12,17 +13,42 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
-	"strconv"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/peterbourgon/ff/v3"
 	"github.com/peterbourgon/ff/v3/ffcli"
 	"golang.org/x/term"
 
+	"yule-log/hooks"
+	"yule-log/internal/anim"
+	"yule-log/internal/announce"
+	"yule-log/internal/bundle"
+	"yule-log/internal/config"
+	"yule-log/internal/errfmt"
 	"yule-log/internal/fire"
+	"yule-log/internal/idlectl"
+	"yule-log/internal/idlesrc"
+	"yule-log/internal/instance"
+	"yule-log/internal/keyring"
 	"yule-log/internal/lock"
+	"yule-log/internal/palette"
+	"yule-log/internal/prompt"
+	"yule-log/internal/render"
+	"yule-log/internal/shader"
+	"yule-log/internal/stats"
+	"yule-log/internal/termbg"
+	"yule-log/internal/themes"
+	"yule-log/internal/ticker"
+	"yule-log/internal/tmuxcmd"
+	"yule-log/internal/trigger"
+	"yule-log/internal/xdg"
 )
 
 // ---- Constants
@@ -32,32 +58,20 @@ const (
 	frameDelay         = 30 * time.Millisecond
 	defaultIdleTimeout = 300
 	pollInterval       = 5
+	popupCloseDelay    = 500 * time.Millisecond // For a dismissed popup to close
 
 	// Fire simulation
-	maxTickerCommits  = 20
-	defaultHeatPower  = 75
-	heatSourceDivisor = 6
-	minHeat           = 10
-	maxHeat           = 85
-	minSources        = 1
-
-	// Heat value thresholds for color selection
-	heatThresholdHigh   = 15
-	heatThresholdMedium = 9
-	heatThresholdLow    = 4
-	heatThresholdMin    = 1
+	maxTickerCommits   = 20
+	defaultHeatPower   = 75
+	heatSourceDivisor  = 6
+	minHeat            = 10
+	maxHeat            = 85
+	minSources         = 1
+	defaultFlameHeight = "100%" // Flames reach the whole screen
 
 	// Color shift thresholds
 	colorShiftBaseHeat = 18
 	colorShiftMaxHeat  = 38
-
-	// Terminal input byte values
-	byteEscape         = 0x1b
-	byteCtrlC          = 0x03
-	byteBackspace      = 0x7f
-	byteDelete         = 0x08
-	bytePrintableStart = 0x20
-	bytePrintableEnd   = 0x7f
 )
 
 // Mode represents the screensaver operating mode.
@@ -72,35 +86,331 @@ const (
 // ---- Visual Themes
 
 type theme struct {
-	chars []rune
+	chars      []rune
+	stops      []palette.Stop      // Heat to color bands
+	lightStops []palette.Stop      // Light background variant, nil to reuse stops
+	text       tcell.Color         // Ticker text
+	light      bool                // Drawn for light terminal backgrounds
+	bands      bool                // Never drawn as a gradient
+	stops256   []palette.IndexStop // 256-color terminals, nil to round stops
+	stops16    []palette.IndexStop // 16-color terminals, nil to round stops
+	model      string              // Heat propagation model name
+	effects    []string            // Effect chain, see effects.go
 }
 
-var (
-	fireTheme = theme{
-		chars: []rune{' ', '.', ':', '^', '*', 'x', 's', 'S', '#', '$'},
+// newTheme converts a theme file to its drawing form.
+func newTheme(t themes.Theme) theme {
+	return theme{
+		chars:      t.Chars,
+		stops:      t.Stops,
+		lightStops: t.LightStops,
+		bands:      t.Bands,
+		stops256:   t.Stops256,
+		stops16:    t.Stops16,
+		model:      t.Model,
+		effects:    t.Effects,
+		text:       tcell.NewRGBColor(int32(t.Text.R), int32(t.Text.G), int32(t.Text.B)),
 	}
+}
 
-	contribTheme = theme{
-		chars: []rune{' ', '⬝', '⬝', '⯀', '⯀', '◼', '◼', '■', '■', '■'},
+// fireTheme returns the built-in fire, also the ASCII fallback ramp. It is
+// parsed on first use, not by every command at startup.
+var fireTheme = sync.OnceValue(func() theme { return newTheme(themes.MustBuiltin(themes.Fire)) })
+
+// loadTheme loads a theme by name from the user themes directory or the
+// built-in themes.
+func loadTheme(name string) (theme, error) {
+	dir, _ := xdg.ThemesDir() // Built-in themes only when unavailable
+	t, err := themes.Load(dir, name)
+	if err != nil {
+		return theme{}, fmt.Errorf("loading theme: %w", err)
 	}
-)
+	return newTheme(t), nil
+}
+
+// asciiGlyphs maps the non-ASCII glyphs used by themes to ASCII-safe
+// equivalents of similar visual weight.
+var asciiGlyphs = map[rune]rune{
+	'⬝': '.',
+	'⯀': 'o',
+	'◼': 'O',
+	'■': '#',
+}
+
+// ascii returns a copy of the theme whose ramp only uses ASCII characters.
+// Glyphs without a known equivalent fall back to the fire ramp at the same
+// heat level.
+func (t theme) ascii() theme {
+	chars := make([]rune, len(t.chars))
+	for i, c := range t.chars {
+		switch {
+		case c < utf8.RuneSelf:
+			chars[i] = c
+		case asciiGlyphs[c] != 0:
+			chars[i] = asciiGlyphs[c]
+		default:
+			ramp := fireTheme().chars
+			chars[i] = ramp[clamp(i, 0, len(ramp)-1)]
+		}
+	}
+	t.chars = chars
+	return t
+}
+
+// indexStops returns the indexed stops for a terminal color depth, nil
+// when the RGB stops are drawn.
+func (t theme) indexStops(depth palette.Depth) []palette.IndexStop {
+	switch {
+	case depth <= palette.Depth16:
+		return t.stops16
+	case depth <= palette.Depth256:
+		return t.stops256
+	default:
+		return nil
+	}
+}
+
+// inverted returns the light background variant of the theme. Themes
+// without light stops keep their colors, only the ticker text turns dark.
+// Indexed stops are drawn for dark backgrounds: light ones round the RGB
+// colors instead.
+func (t theme) inverted() theme {
+	if t.lightStops != nil {
+		t.stops = t.lightStops
+	}
+	t.stops256, t.stops16 = nil, nil
+	t.text = tcell.ColorBlack
+	t.light = true
+	return t
+}
+
+// unicodeUnsupported reports whether the environment is unlikely to render
+// non-ASCII glyphs: a non-UTF-8 locale or the Linux console.
+func unicodeUnsupported() bool {
+	if os.Getenv("TERM") == "linux" {
+		return true
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return !strings.Contains(v, "utf-8") && !strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
 
 // ---- Screensaver Configuration & State
 
 type screensaverConfig struct {
-	mode      Mode
-	contribs  bool
-	gitDir    string
-	noTicker  bool
-	cooldown  fire.CooldownSpeed
-	intensity int
+	mode       Mode
+	contribs   bool
+	theme      string   // Theme name, overrides contribs
+	gitDirs    []string // Ticker repositories, the first one holds the config
+	noTicker   bool
+	cooldown   fire.CooldownSpeed
+	intensity  int
+	maxCommits int // Overrides config files when > 0
+	ascii      bool
+	announcer  announce.Announcer
+	autoLocked bool   // Lock was engaged by the idle watcher
+	phrase     string // Session phrase of the lock, see lock.NewPhrase
+
+	// What the lock password is checked against (passwords, loaded from
+	// auth as the lock starts), and whether the duress password ends the
+	// lock (the client that locked; on the others it fails like a wrong
+	// password). With duressExec, the duress password runs it and the lock
+	// screen stays up.
+	auth       lock.Auth
+	passwords  lock.Verifier
+	duress     bool
+	duressExec string
+
+	// Work tree status left out of the ticker meta row
+	noTickerBranch, noTickerTag, noTickerDirty bool
+
+	// Shell command of the command ticker source, overrides [ticker] command
+	tickerCmd string
+
+	// Burn down to embers over this long (0 = steady), see ember.go
+	burnDown time.Duration
+
+	// Experimental typing rhythm factor: the profile unlocking also
+	// requires (nil = off), and how far the rhythm may drift from it
+	rhythm          lock.Rhythm
+	rhythmTolerance float64
+
+	// Soft lock: pane mirrored on the lock screen, see softlock.go
+	softLockPane string
+
+	// Overlay shown from the start ("" or "sessions", see sessions.go)
+	overlay string
+
+	// Background animation ("fire", an anim name, or "cycle")
+	animation     string
+	cycleInterval time.Duration
+
+	// Output rate limiting
+	transmitEvery int  // Show one frame out of N
+	autoRate      bool // Adapt transmitEvery from terminal write latency
+	blend         bool // Average fire heat over skipped frames
+
+	// Bandwidth reduction
+	reducedPalette bool // Skip heat-based color shifts so cells change less
+	tickerStep     int  // Columns the ticker jumps at a time (1 = smooth)
+	bandwidthMeter bool // Show changed cells per frame overlay
+
+	// Random events
+	events           []fire.EventKind
+	eventMinInterval time.Duration
+	eventMaxInterval time.Duration
+
+	// Burn the captured pane content away on start
+	ignite bool
+	// Reveal the pane content through the dying fire on unlock
+	reveal bool
+
+	// Terminal background: auto-detected (termbg.ModeAuto) or forced.
+	// light is resolved from it before the screen starts.
+	background termbg.Mode
+	light      bool
+
+	// Shift the palette warmer in the evening, cooler in the morning
+	daylight bool
+
+	// Big clock over the fire, see clock.go
+	clock bool
+
+	// Weather line above the ticker, see weather.go
+	weather bool
+
+	// Bells and activity of other windows in a corner, see alerts.go
+	alerts bool
+
+	// Interpolate the palette between theme stops (auto: on truecolor
+	// terminals)
+	gradient palette.Gradient
+	// Terminal color depth, detected by tcell unless forced
+	colors palette.Depth
+
+	// Share of the screen height flames reach (0 or 1 = all of it)
+	flameHeight float64
+
+	// Drop to the ember state after this long without input (0 = never)
+	emberAfter time.Duration
+
+	// Color levels applied to the palette (0 = unchanged)
+	brightness float64
+	contrast   float64
+	gamma      float64
+
+	// Mouse support: clicking the ticker copies or opens a commit
+	mouse           bool
+	tickerClickExec string // Shell command run with YULE_LOG_COMMIT on click
+
+	// Lock mode: give up after this long, see expireLock (0 = never)
+	maxLock time.Duration
+
+	// Lock mode over every client (lock --all-clients): the lock engaged
+	// at this time, whose end closes the screen (zero when not shared)
+	lockedAt time.Time
+
+	// Other modes: exit after this long, through the reveal transition
+	// when possible (0 = never)
+	duration time.Duration
+
+	// Test mode: exit after this many frames (0 = run until dismissed).
+	// Headless runs draw on an in-memory screen, without frame delays.
+	frames                        int
+	headless                      bool
+	headlessWidth, headlessHeight int
+
+	// Soak test (yule-log soak): headless, but paced and listening like a
+	// real screensaver. Closing stop ends the run.
+	soak bool
+	stop <-chan struct{}
+
+	// Screen implementation, see render.Backend
+	backend render.Backend
+
+	// Startup phases, printed on exit with --trace-startup (nil = off)
+	trace *startupTrace
+}
+
+// applyTestMode sets up a run of a fixed number of frames. It is headless
+// when a size is given or stdout is not a terminal.
+func (c *screensaverConfig) applyTestMode(frames int, size string) error {
+	switch {
+	case frames < 0:
+		return fmt.Errorf("--frames must be >= 0, got %d", frames)
+	case frames == 0 && size != "":
+		return fmt.Errorf("--size needs --frames")
+	case frames == 0:
+		return nil
+	}
+	c.frames = frames
+
+	if size == "" && term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil
+	}
+	c.headless = true
+	c.headlessWidth, c.headlessHeight = render.DefaultHeadlessWidth, render.DefaultHeadlessHeight
+	if size != "" {
+		var err error
+		c.headlessWidth, c.headlessHeight, err = render.ParseSize(size)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applySSHFriendly tunes the configuration for remote terminals: fewer
+// transmitted frames, no heat-based color shifts (fewer SGR changes) and a
+// ticker that jumps instead of scrolling every column.
+func (c *screensaverConfig) applySSHFriendly() {
+	c.transmitEvery = max(c.transmitEvery, 2)
+	c.autoRate = true
+	c.reducedPalette = true
+	c.tickerStep = 8
+}
+
+// levels returns the brightness, contrast and gamma, 1 when unset.
+func (c screensaverConfig) levels() (brightness, contrast, gamma float64) {
+	orOne := func(v float64) float64 {
+		if v == 0 {
+			return 1
+		}
+		return v
+	}
+	return orOne(c.brightness), orOne(c.contrast), orOne(c.gamma)
+}
+
+// themeName returns the selected theme: --theme, or the one matching
+// --contribs.
+func (c screensaverConfig) themeName() string {
+	switch {
+	case c.theme != "":
+		return c.theme
+	case c.contribs:
+		return themes.Contribs
+	default:
+		return themes.Fire
+	}
 }
 
-func (c screensaverConfig) theme() theme {
-	if c.contribs {
-		return contribTheme
+// resolveTheme loads the selected theme and adapts it to the terminal.
+func (c screensaverConfig) resolveTheme() (theme, error) {
+	t, err := loadTheme(c.themeName())
+	if err != nil {
+		return theme{}, err
+	}
+	if c.light {
+		t = t.inverted()
 	}
-	return fireTheme
+	if c.ascii {
+		return t.ascii(), nil
+	}
+	return t, nil
 }
 
 type screensaver struct {
@@ -112,32 +422,162 @@ type screensaver struct {
 	width, height int
 
 	// Fire state
-	buffer      []int
+	sim         *fire.Simulation
+	model       fire.Model // Heat propagation, see fireModel
 	heatPower   int
+	burnDown    fire.BurnDown // Base heat envelope, with cfg.burnDown
 	heatSources int
 
+	// Heat controls, offsets from the base heat and source count, see heat.go
+	heatAdjust       int
+	heatSourceAdjust int
+
 	// Ticker state
-	msgText, metaText string
-	haveTicker        bool
-	tickerOffset      int
-	frame             int
+	tickerText    ticker.Text
+	haveTicker    bool
+	tickerOffset  int
+	frame         int
+	tickerOpts    ticker.Options
+	tickerSources []string
+	tickerItems   map[string][]ticker.Item // By source
+	background    []*backgroundSource
+	calendar      *calendarState // nil unless the calendar source is on
+
+	// Background animation
+	anim            anim.Animation
+	animationName   string
+	animationFrames int
+	cycle           []string // Animation rotation in cycle mode
+
+	// Bandwidth meter (nil unless enabled)
+	meter *render.MeteredScreen
+
+	// Output rate limiting
+	rate         render.RateLimiter
+	clock        render.FrameClock
+	transmitting bool  // Current frame will be shown
+	blendAcc     []int // Heat accumulated over skipped frames
+
+	// Random events
+	rng         *rand.Rand
+	scheduler   *fire.EventScheduler
+	particles   fire.Particles
+	wind        fire.Wind
+	flareFrames int
+
+	// Pane content burning away on start (nil when done or disabled)
+	ignition *fire.Ignition
+	// Pane content emerging after unlock (nil until unlocked)
+	reveal *fire.Reveal
+
+	// Extensions and first-party plugins (announcements, notifications)
+	hooks hooks.Multi
 
 	// Interactive state (nil in normal mode)
 	visualState *fire.VisualState
-	inputBuffer *lock.SecureBuffer
+	input       *prompt.Input          // Password typed in lock mode, with its rhythm
+	panicKeys   *lock.SequenceDetector // Panic keys of the lock screen, nil if unset
+
+	// Pane kept visible by a soft lock (nil otherwise)
+	paneView *paneView
+
+	// tmux session list, see sessions.go
+	sessions *sessionOverlay
+
+	// Drawn heat sources and the cells they cover, see heatpath.go
+	heatPath   fire.Path
+	heatCells  []int
+	pathEditor *pathEditor // Heat path being drawn in playground mode
+
+	// Mean contribution level of each week, oldest first, see contribs.go
+	contribWeeks []float64
+
+	// Terminal focus (reported by terminals supporting focus events)
+	unfocused bool
+
+	// Low power state after cfg.emberAfter without input, see ember.go
+	lastInput time.Time
+	ember     bool
 
-	// Input timeout (frames since last input, for clearing password)
-	framesSinceInput int
+	// Short message shown above the ticker (frames remaining)
+	notice       string
+	noticeFrames int
+
+	// Ticker item under the mouse pointer (scrolling pauses meanwhile)
+	hoverItem ticker.Item
+	hovering  bool
+
+	// Last key typed on the lock screen, for clearing a half-typed password
+	lastKey time.Time
+
+	// When the screen started, for cfg.maxLock and cfg.duration
+	startedAt time.Time
+
+	// Last look at the lock state, for cfg.lockedAt
+	lockCheckedAt time.Time
+
+	// Screen flash before a calendar event (frames remaining)
+	alertFrames int
+
+	// Weather line, see weather.go
+	weather string
+
+	// Flagged windows, with cfg.alerts (see alerts.go)
+	alerts *alertIndicator
+
+	// Command palette, see commands.go
+	commands      *commandPalette // nil when closed
+	fps           int             // Frame rate set with the fps command (0: default)
+	lockRequested bool            // The lock command ended the screensaver
+	duressEntered bool            // The duress password ended the lock screen
 
 	// Wrong password animation (frames remaining, fades from 1.0 to 0.0)
 	wrongPasswordFrames int
+	// Consecutive wrong passwords, reported to hooks
+	failedAttempts int
+
+	// Heat to color lookup tables, see initPalette
+	basePalette palette.Palette // Theme stops only
+	heatPalette palette.Palette // With the heat color shift
+	palette     palette.Palette // Drawn this frame
+	// Theme palette colors drawn instead on terminals without truecolor
+	// (nil when drawing RGB colors)
+	indexed *palette.Indexed
+
+	// User color levels, adjustable live in playground mode
+	brightness, contrast, gamma float64
+	levels                      palette.Effect
+
+	// Time of day temperature shift (-1 cool .. 1 warm), see daylight.go
+	temperature float64
+
+	// Merged configuration files (global and repository)
+	conf config.Config
+
+	// Fire post-processing, see effects.go
+	effects shader.Pipeline
+	grid    shader.Grid
 
 	// Event channel
 	events   chan tcell.Event
 	pollDone chan struct{}
+	instance *instance.Instance // Socket of yule-log dismiss, nil if none
 }
 
-func newScreensaver(cfg screensaverConfig) (*screensaver, error) {
+// newScreensaver is the only place a tcell screen is created: subcommands
+// that print or prompt (status, set-password...) must never switch the
+// terminal to the alternate screen.
+// newScreen returns the terminal screen, or an in-memory one in headless
+// test mode. The headless VT backend writes its frames to stdout.
+func newScreen(cfg screensaverConfig) (tcell.Screen, error) {
+	switch {
+	case cfg.headless && cfg.backend == render.BackendVT:
+		return render.NewVTWriter(os.Stdout, cfg.headlessWidth, cfg.headlessHeight)
+	case cfg.headless:
+		return render.NewHeadless(cfg.headlessWidth, cfg.headlessHeight)
+	case cfg.backend == render.BackendVT:
+		return render.NewVT()
+	}
 	screen, err := tcell.NewScreen()
 	if err != nil {
 		return nil, fmt.Errorf("creating screen: %w", err)
@@ -145,35 +585,89 @@ func newScreensaver(cfg screensaverConfig) (*screensaver, error) {
 	if err := screen.Init(); err != nil {
 		return nil, fmt.Errorf("initializing screen: %w", err)
 	}
+	return screen, nil
+}
+
+func newScreensaver(cfg screensaverConfig) (*screensaver, error) {
+	// A broken theme file is reported before the terminal is taken over.
+	t, err := cfg.resolveTheme()
+	if err != nil {
+		return nil, err
+	}
+	cfg.trace.mark("theme")
+	screen, err := newScreen(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.trace.mark("screen")
 
 	s := &screensaver{
 		cfg:       cfg,
 		screen:    screen,
-		theme:     cfg.theme(),
+		theme:     t,
 		heatPower: defaultHeatPower,
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		rate:      render.RateLimiter{Every: cfg.transmitEvery, Auto: cfg.autoRate},
 		events:    make(chan tcell.Event, 10),
 		pollDone:  make(chan struct{}),
 	}
+	s.hooks = pluginHooks(cfg.announcer)
+	s.hooks.OnStart(hooks.Start{Mode: cfg.mode.hookMode()})
+	if cfg.bandwidthMeter {
+		s.meter = render.NewMeteredScreen(screen)
+		s.screen = s.meter
+	}
 
 	s.visualState = fire.NewVisualStateWithPreset(cfg.cooldown)
 	if cfg.intensity > 0 {
 		s.visualState.SetBaseHeat(cfg.intensity)
 	}
+	if cfg.burnDown > 0 {
+		s.burnDown = fire.NewBurnDown(cfg.burnDown, s.visualState.BaseHeat, emberHeatPower)
+		s.visualState.SetBaseHeat(s.burnDown.Peak)
+	}
 	s.heatPower = s.visualState.EffectiveHeatPower()
 
 	if cfg.mode == ModeLock {
-		s.inputBuffer = lock.NewSecureBuffer()
+		s.input = prompt.NewInput(&lock.RhythmRecorder{})
+		s.panicKeys = loadPanicKeys()
+		s.hooks.OnLock(hooks.Lock{Auto: cfg.autoLocked})
+		if cfg.phrase != "" {
+			s.notice = "session phrase: " + cfg.phrase
+			s.noticeFrames = framesFor(phraseNoticeDuration)
+		}
 	}
 
+	cfg.trace.mark("hooks")
+
+	s.brightness, s.contrast, s.gamma = cfg.levels()
+	s.loadConfig()
+	cfg.trace.mark("config")
+	s.initHeatPath()
+	s.model = s.fireModel()
+	s.initDaylight()
+	s.initPalette()
+	s.initEffects()
 	s.resize()
+	cfg.trace.mark("fire")
 	s.loadTicker()
+	cfg.trace.mark("ticker")
+	s.initEvents()
+	s.initAnimation()
+	s.initIgnition()
+	s.initPaneView()
+	s.initSessions()
+	s.initContribs()
+	s.initWeather()
+	s.initAlerts()
+	cfg.trace.mark("extras")
 
 	return s, nil
 }
 
 func (s *screensaver) close() {
-	if s.inputBuffer != nil {
-		s.inputBuffer.Destroy()
+	if s.input != nil {
+		s.input.Destroy()
 	}
 	s.screen.Fini()
 
@@ -189,17 +683,212 @@ func (s *screensaver) resize() {
 	if s.width <= 0 || s.height <= 0 {
 		return
 	}
-	size := s.width * s.height
-	// Extra space (width+1) for fire propagation lookups: i+1, i+width, i+width+1
-	s.buffer = make([]int, size+s.width+1)
-	s.heatSources = s.width / heatSourceDivisor
+	s.sim = fire.NewSimulation(s.width, s.height, s.model, s.rng)
+	if s.cfg.flameHeight > 0 {
+		s.sim.SetFlameHeight(s.cfg.flameHeight)
+	}
+	s.heatSources = s.adjustedHeatSources(s.heatSourceAdjust)
+	s.heatCells = s.heatPath.Cells(s.width, s.height)
+	s.ignition = nil // Captured pane content no longer matches the screen
+	if s.anim != nil {
+		s.anim.Resize(s.width, s.height-s.tickerRows())
+	}
+}
+
+// tickerDir returns the directory of the ticker repository.
+func (s *screensaver) tickerDir() string {
+	if len(s.cfg.gitDirs) > 0 {
+		return s.cfg.gitDirs[0]
+	}
+	return os.Getenv("YULE_LOG_GIT_DIR")
+}
+
+// loadConfig reads the configuration files of the ticker repository.
+func (s *screensaver) loadConfig() {
+	// Broken config layers are skipped: a bad repo config must not keep
+	// the screensaver from starting.
+	s.conf, _ = config.Load(s.tickerDir())
 }
 
 func (s *screensaver) loadTicker() {
 	if s.cfg.noTicker {
 		return
 	}
-	s.msgText, s.metaText, s.haveTicker = buildGitTickerText(maxTickerCommits, s.cfg.gitDir)
+
+	conf := s.conf
+	if conf.Ticker.Disabled != nil && *conf.Ticker.Disabled {
+		return
+	}
+
+	opts := ticker.Options{
+		MaxCommits: maxTickerCommits,
+		ASCII:      s.cfg.ascii,
+		Include:    conf.Ticker.Include,
+		Exclude:    conf.Ticker.Exclude,
+	}
+	if conf.Ticker.MaxCommits != nil {
+		opts.MaxCommits = *conf.Ticker.MaxCommits
+	}
+	if conf.Ticker.MaxWidth != nil {
+		opts.MaxWidth = *conf.Ticker.MaxWidth
+	}
+	if s.cfg.maxCommits > 0 {
+		opts.MaxCommits = s.cfg.maxCommits
+	}
+	opts.Branch, opts.Tag, opts.Dirty = !s.cfg.noTickerBranch, !s.cfg.noTickerTag, !s.cfg.noTickerDirty
+
+	s.tickerOpts = opts
+	s.tickerSources = conf.Ticker.Sources
+	if len(s.tickerSources) == 0 {
+		s.tickerSources = []string{ticker.SourceCommits}
+	}
+	if s.tickerCommand() != "" && !slices.Contains(s.tickerSources, ticker.SourceCommand) {
+		s.tickerSources = append(slices.Clip(s.tickerSources), ticker.SourceCommand)
+	}
+	s.tickerItems = map[string][]ticker.Item{}
+	for _, source := range s.tickerSources {
+		if provide, ok := tickerProviders[source]; ok {
+			s.tickerItems[source] = provide(s)
+		}
+	}
+	s.layoutTicker()
+}
+
+// tickerRepos returns the repositories of the commits source: every --dir
+// (or the ticker repository), then those of the [ticker] repos config
+// entry, each once.
+func (s *screensaver) tickerRepos() []string {
+	dirs := append([]string(nil), s.cfg.gitDirs...)
+	if len(dirs) == 0 && len(s.conf.Ticker.Repos) > 0 {
+		dirs = append(dirs, cmp.Or(s.tickerDir(), "."))
+	}
+	for _, dir := range s.conf.Ticker.Repos {
+		if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(home, rest)
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+	seen := make(map[string]bool)
+	return slices.DeleteFunc(dirs, func(dir string) bool {
+		abs, _ := filepath.Abs(dir)
+		dup := seen[abs]
+		seen[abs] = true
+		return dup
+	})
+}
+
+// commitItems returns the recent commits of the ticker repository, or of
+// every repository interleaved when there are several.
+func (s *screensaver) commitItems(opts ticker.Options) []ticker.Item {
+	dirs := s.tickerRepos()
+	if len(dirs) > 1 {
+		return ticker.RepoItems(dirs, opts)
+	}
+	var dir string
+	if len(dirs) == 1 {
+		dir = dirs[0]
+	}
+	items, _ := ticker.GitItems(dir, opts)
+	return items
+}
+
+// layoutTicker lays the items of every source out, in source order. The
+// animation is resized if the ticker appears or disappears.
+func (s *screensaver) layoutTicker() {
+	rows := s.tickerRows()
+
+	var items []ticker.Item
+	for _, source := range s.tickerSources {
+		items = append(items, s.tickerItems[source]...)
+	}
+	s.tickerText, s.haveTicker = ticker.Layout(items, s.tickerOpts)
+	if n := len([]rune(s.tickerText.Msg)); n > 0 {
+		s.tickerOffset %= n
+	}
+
+	if s.anim != nil && s.tickerRows() != rows {
+		s.anim.Resize(s.width, s.height-s.tickerRows())
+	}
+	s.feedTicker()
+}
+
+// feedTicker hands the ticker items to animations using them all.
+func (s *screensaver) feedTicker() {
+	if feed, ok := s.anim.(anim.TickerFeed); ok {
+		feed.SetTickerItems(s.tickerText.Items)
+	}
+}
+
+// ---- Hooks
+
+// pluginHooks returns the registered hooks followed by the first-party
+// announcement and notification plugin, the unlock audit log and the
+// shell hooks of the configuration file.
+func pluginHooks(announcer announce.Announcer) hooks.Multi {
+	h := hooks.Registered()
+	if announcer != nil {
+		h = append(h, announce.Hooks{Announcer: announcer})
+	}
+	h = append(h, auditHooks{})
+	if shell := loadShellHooks(); shell != nil {
+		h = append(h, shell)
+	}
+	return h
+}
+
+// auditHooks records unlock attempts to the audit log read by lock
+// attempts. Failing to write it doesn't stop the lock screen.
+type auditHooks struct{ hooks.Base }
+
+func (auditHooks) OnUnlock(u hooks.Unlock) {
+	path, err := xdg.UnlockLogFile()
+	if err != nil {
+		return
+	}
+	result := lock.ResultFailed
+	switch {
+	case u.OK:
+		result = lock.ResultOK
+	case u.Expired:
+		result = lock.ResultExpired
+	}
+	_ = lock.RecordAttempt(path, lock.Attempt{Time: time.Now(), Result: result})
+}
+
+// OnPanic marks the panic keys in the audit log.
+func (auditHooks) OnPanic(hooks.Panic) {
+	if path, err := xdg.UnlockLogFile(); err == nil {
+		_ = lock.RecordAttempt(path, lock.Attempt{Time: time.Now(), Result: lock.ResultPanic})
+	}
+}
+
+// hookMode names the screensaver mode for hooks.
+func (m Mode) hookMode() hooks.Mode {
+	switch m {
+	case ModePlayground:
+		return hooks.ModePlayground
+	case ModeLock:
+		return hooks.ModeLock
+	default:
+		return hooks.ModeNormal
+	}
+}
+
+// hookKey describes a key press for hooks, leaving out the keys typed in
+// lock mode: they are the password.
+func (s *screensaver) hookKey(ev *tcell.EventKey) hooks.Key {
+	name := tcell.KeyNames[ev.Key()]
+	if s.cfg.mode == ModeLock {
+		return hooks.LockKey(name)
+	}
+	k := hooks.Key{Mode: s.cfg.mode.hookMode(), Name: name}
+	if ev.Key() == tcell.KeyRune {
+		k.Name = "Rune"
+		k.Rune = ev.Rune()
+	}
+	return k
 }
 
 // ---- Event Handling
@@ -215,6 +904,9 @@ const (
 func (s *screensaver) handleEvent(ev tcell.Event) action {
 	switch ev := ev.(type) {
 	case *tcell.EventResize:
+		if s.reveal != nil {
+			return actionExit // Captured pane content no longer fits
+		}
 		s.resize()
 		if s.width <= 0 || s.height <= 0 {
 			return actionExit
@@ -222,18 +914,37 @@ func (s *screensaver) handleEvent(ev tcell.Event) action {
 		return actionResize
 
 	case *tcell.EventKey:
+		s.wake()
+		s.hooks.OnKey(s.hookKey(ev))
 		return s.handleKey(ev)
+
+	case *tcell.EventMouse:
+		s.wake()
+		return s.handleMouse(ev)
+
+	case *tcell.EventFocus:
+		s.unfocused = !ev.Focused
+		if ev.Focused {
+			s.wake()
+		}
 	}
 	return actionNone
 }
 
 func (s *screensaver) handleKey(ev *tcell.EventKey) action {
+	if s.reveal != nil {
+		return actionExit // Any key skips the unlock transition
+	}
+
 	// Feed fire in interactive modes
 	if s.visualState != nil {
 		s.visualState.OnKeyPress()
-		s.heatPower = s.visualState.EffectiveHeatPower()
+		s.heatPower = s.visualState.EffectiveHeatPower() + s.heatAdjust
 	}
 
+	if s.commands != nil {
+		return s.handleKeyPalette(ev)
+	}
 	switch s.cfg.mode {
 	case ModeLock:
 		return s.handleKeyLock(ev)
@@ -250,14 +961,42 @@ func (s *screensaver) handleKeyNormal(ev *tcell.EventKey) action {
 		return actionExit
 	case tcell.KeyUp, tcell.KeyDown:
 		return actionNone // Fire burst handled by visualState.OnKeyPress()
+	case tcell.KeyTab:
+		s.toggleSessions()
+		return actionNone
+	case tcell.KeyRune:
+		if ev.Rune() == ':' {
+			s.openPalette()
+			return actionNone
+		}
+		return actionExit
 	default:
 		return actionExit
 	}
 }
 
 func (s *screensaver) handleKeyPlayground(ev *tcell.EventKey) action {
-	if ev.Key() == tcell.KeyEscape {
+	if s.pathEditor != nil {
+		s.handleKeyPathEditor(ev)
+		return actionNone
+	}
+	switch ev.Key() {
+	case tcell.KeyEscape:
 		return actionExit
+	case tcell.KeyTab:
+		s.toggleSessions()
+	case tcell.KeyRune:
+		if ev.Rune() == 'p' {
+			s.startPathEditor()
+			break
+		}
+		if ev.Rune() == ':' {
+			s.openPalette()
+			break
+		}
+		s.adjustLevels(ev.Rune())
+	default:
+		s.handleKeyHeat(ev)
 	}
 	return actionNone
 }
@@ -265,40 +1004,103 @@ func (s *screensaver) handleKeyPlayground(ev *tcell.EventKey) action {
 // wrongPasswordDuration is frames for wrong password red animation (~2 sec).
 const wrongPasswordDuration = 67 // ~2 sec at 30ms/frame
 
-// inputTimeoutFrames is how long to wait before clearing password input.
-const inputTimeoutFrames = 200 // ~6 seconds at 30ms/frame
+// passwordInputTimeout is how long a half-typed password is kept without
+// a key press before it is cleared. Wall clock time, as the frame rate
+// varies (ember state, unfocused terminal).
+const passwordInputTimeout = 60 * time.Second
 
 func (s *screensaver) handleKeyLock(ev *tcell.EventKey) action {
 	// Reset input timeout on any keypress
-	s.framesSinceInput = 0
+	s.lastKey = time.Now()
+	if s.panicKeys != nil {
+		det := s.panicKeys.Feed(ev.Key(), ev.When())
+		for _, held := range det.Released {
+			s.input.HandleKey(held.Key, 0, held.At)
+		}
+		if det.Fired {
+			s.hooks.OnPanic(hooks.Panic{Keys: s.panicKeys.Keys.String()}) // No visible change
+		}
+		if det.Held {
+			return actionNone // Kept out of the password
+		}
+	}
+	if s.handleKeyPaneView(ev) {
+		return actionNone
+	}
 	switch ev.Key() {
 	case tcell.KeyEnter:
-		if s.tryUnlock() {
+		match, err := s.checkPassword()
+		if err != nil {
+			// Not the user's fault: no failure counted
+			s.setNotice("Can't check the password: " + err.Error())
+			return actionNone
+		}
+		if match == lock.MatchDuress {
+			s.clearInput()
+			// No hooks: nothing may tell it apart
+			if s.cfg.duressExec != "" {
+				go func() { _ = lock.RunDuressExec(s.cfg.duressExec) }()
+				s.wrongPasswordFrames = wrongPasswordDuration // Still locked
+				return actionNone
+			}
+			s.duressEntered = true
+			return actionExit
+		}
+		if match == lock.MatchPassword {
+			s.failedAttempts = 0
+			s.hooks.OnUnlock(hooks.Unlock{OK: true})
+			s.clearInput()
+			if s.startReveal() {
+				return actionNone
+			}
 			return actionExit // Just exit, no flash
 		}
 		// Wrong password - red spike animation
+		s.failedAttempts++
+		s.hooks.OnUnlock(hooks.Unlock{Failures: s.failedAttempts})
 		s.wrongPasswordFrames = wrongPasswordDuration
-		s.inputBuffer.Clear()
-	case tcell.KeyBackspace, tcell.KeyBackspace2:
-		s.inputBuffer.Backspace()
-	case tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight:
-		s.inputBuffer.AppendString(lock.ArrowKeyMarker(ev.Key()))
-	case tcell.KeyRune:
-		s.inputBuffer.AppendRune(ev.Rune())
+		s.clearInput()
+	case tcell.KeyTab:
+		s.toggleSessions()
+	default:
+		// Characters, backspace, arrow, function and navigation keys
+		s.input.HandleKey(ev.Key(), ev.Rune(), ev.When())
 	}
 	return actionNone
 }
 
-func (s *screensaver) tryUnlock() bool {
-	password := s.inputBuffer.Bytes()
+// clearInput wipes the typed password and its keystroke times, with any
+// panic keys held back.
+func (s *screensaver) clearInput() {
+	s.input.Clear()
+	if s.panicKeys != nil {
+		s.panicKeys.Reset()
+	}
+}
+
+// checkPassword checks the typed password and, with a rhythm profile, how
+// the lock password was typed. Both failures look the same on screen; an
+// error is the backend's.
+func (s *screensaver) checkPassword() (lock.Match, error) {
+	password := s.input.Bytes()
 	defer lock.ClearBytes(password)
 
-	valid, err := lock.CheckPassword(password)
-	if err != nil || !valid {
-		s.inputBuffer.Clear()
-		return false
+	match, err := s.cfg.passwords.Verify(password)
+	if err != nil {
+		s.clearInput()
+		return lock.MatchNone, err
 	}
-	return true
+	switch {
+	case match == lock.MatchPassword && s.cfg.rhythm != nil &&
+		!lock.RhythmMatches(s.cfg.rhythm, s.input.Rhythm.Rhythm(), s.cfg.rhythmTolerance):
+		match = lock.MatchNone
+	case match == lock.MatchDuress && !s.cfg.duress:
+		match = lock.MatchNone
+	}
+	if match == lock.MatchNone {
+		s.clearInput()
+	}
+	return match, nil
 }
 
 // ---- Rendering
@@ -311,22 +1113,110 @@ func (s *screensaver) run() error {
 		return nil
 	}
 
+	s.screen.EnableFocus()
+	if s.cfg.mouse {
+		s.screen.EnableMouse(tcell.MouseMotionEvents)
+	}
 	go s.pollEvents()
+	if !s.cfg.headless || s.cfg.soak {
+		if dir, err := xdg.RuntimeDir(); err == nil {
+			s.instance, _ = instance.Listen(dir, s.cfg.mode == ModeLock)
+		}
+		defer s.instance.Close()
+	}
 
+	s.startedAt = time.Now()
+	s.lastInput = time.Now()
 	for {
 		if done := s.processEvents(); done {
+			if s.lockRequested {
+				return errLockRequested
+			}
+			if s.duressEntered {
+				return errDuress
+			}
 			return nil
 		}
+		if s.lockExpired() {
+			return errLockExpired
+		}
+		if s.lockEnded() {
+			return errLockEnded
+		}
+		if s.durationOver() && !s.revealPane() {
+			return nil // Nothing to reveal
+		}
 		s.updateVisualState()
 		s.renderFrame()
-		time.Sleep(frameDelay)
+		if s.frame == 0 {
+			s.cfg.trace.mark("first frame")
+		}
+		s.hooks.OnFrame(hooks.Frame{Number: s.frame, Width: s.width, Height: s.height, HeatPower: s.heatPower})
+		if s.reveal != nil && s.reveal.Done() {
+			return nil // Unlock transition finished
+		}
+		if s.cfg.frames > 0 && s.frame+1 >= s.cfg.frames {
+			return nil // Test mode done
+		}
+		if !s.cfg.headless || s.cfg.soak {
+			s.waitFrame()
+		}
 		s.frame++
 	}
 }
 
+// unfocusedSlowdown divides the frame rate while the terminal is unfocused.
+const unfocusedSlowdown = 4
+
+// errLockExpired ends the lock screen once it has been up longer than
+// cfg.maxLock, see expireLock.
+var errLockExpired = errors.New("lock expired")
+
+// lockExpired reports whether the lock has been up longer than cfg.maxLock.
+// A running unlock reveal is never interrupted.
+func (s *screensaver) lockExpired() bool {
+	return s.cfg.mode == ModeLock && s.cfg.maxLock > 0 && s.reveal == nil &&
+		time.Since(s.startedAt) >= s.cfg.maxLock
+}
+
+// errDuress ends the lock screen on the duress password, see
+// duressAction.
+var errDuress = errors.New("duress password")
+
+// errLockEnded closes a lock screen shared by every client once the lock
+// was lifted from another one.
+var errLockEnded = errors.New("lock ended")
+
+// lockCheckInterval is how often a shared lock screen reads the lock state.
+const lockCheckInterval = time.Second
+
+// lockEnded reports whether the lock engaged at cfg.lockedAt is gone:
+// unlocked from another client, or replaced by a newer lock. A running
+// unlock reveal is never interrupted.
+func (s *screensaver) lockEnded() bool {
+	if s.cfg.mode != ModeLock || s.cfg.lockedAt.IsZero() || s.reveal != nil ||
+		time.Since(s.lockCheckedAt) < lockCheckInterval {
+		return false
+	}
+	s.lockCheckedAt = time.Now()
+	state, err := lock.LoadState()
+	if errors.Is(err, lock.ErrNotLocked) {
+		return true
+	}
+	return err == nil && !state.LockedAt.Equal(s.cfg.lockedAt)
+}
+
+// durationOver reports whether the screensaver has run for cfg.duration.
+// It stays false once the closing reveal runs.
+func (s *screensaver) durationOver() bool {
+	return s.cfg.mode != ModeLock && s.cfg.duration > 0 && s.reveal == nil &&
+		time.Since(s.startedAt) >= s.cfg.duration
+}
+
 // pollEvents reads events until the screen is finalized.
 // When screen.Fini() is called (in close()), PollEvent returns nil, ending this goroutine.
 func (s *screensaver) pollEvents() {
+	defer guard()
 	defer close(s.pollDone)
 	for {
 		ev := s.screen.PollEvent()
@@ -344,6 +1234,10 @@ func (s *screensaver) processEvents() bool {
 			if s.handleEvent(ev) == actionExit {
 				return true
 			}
+		case <-s.instance.Dismissed():
+			return true
+		case <-s.cfg.stop:
+			return true
 		default:
 			return false
 		}
@@ -351,44 +1245,69 @@ func (s *screensaver) processEvents() bool {
 }
 
 func (s *screensaver) updateVisualState() {
+	s.updateEvents()
+	s.updateAnimation()
+	s.updateDaylight()
+	s.updateEmber()
+	s.updatePaneView()
+	s.updateBackgroundSources()
+	s.updateCalendar()
+	s.updateAlerts()
+
 	if s.visualState == nil {
 		return
 	}
 
+	s.updateBurnDown()
 	s.visualState.OnFrame()
-	s.heatPower = s.visualState.EffectiveHeatPower()
+	s.heatPower = s.visualState.EffectiveHeatPower() + s.heatAdjust + s.flareBonus()
+	if listener, ok := s.anim.(anim.IntensityListener); ok {
+		listener.SetIntensity(s.visualState.IntensityRatio())
+	}
+	if s.ember {
+		s.heatPower = min(s.heatPower, emberHeatPower)
+	}
 
 	// Decrement wrong password animation
 	if s.wrongPasswordFrames > 0 {
 		s.wrongPasswordFrames--
 	}
+	s.updatePalette()
 
-	// Track input timeout and clear password after timeout
-	if s.cfg.mode == ModeLock && s.inputBuffer != nil && s.inputBuffer.Len() > 0 {
-		s.framesSinceInput++
-		if s.framesSinceInput >= inputTimeoutFrames {
-			s.inputBuffer.Clear()
-			s.framesSinceInput = 0
-		}
+	// Clear a password left half-typed, and its indicator with it
+	if s.cfg.mode == ModeLock && s.input != nil && s.input.Len() > 0 &&
+		time.Since(s.lastKey) >= passwordInputTimeout {
+		s.clearInput()
 	}
 }
 
 func (s *screensaver) renderFrame() {
-	s.generateHeat()
-	s.renderFire()
+	s.transmitting = s.rate.ShouldTransmit()
+	s.anim.Step()
+	s.anim.Draw(s.screen)
+	s.renderClock()
+	s.renderPaneView()
+	s.renderSessions()
+	s.renderPathEditor()
 	s.renderPasswordIndicator()
 	s.renderTicker()
-	s.screen.Show()
+	s.renderWeather()
+	s.renderAlerts()
+	s.renderNotice()
+	s.renderPalette()
+	s.renderBandwidthMeter()
+	s.renderAlert()
+	s.present()
 }
 
-// renderPasswordIndicator displays asterisks for password input in lock mode.
-func (s *screensaver) renderPasswordIndicator() {
-	if s.cfg.mode != ModeLock || s.inputBuffer == nil {
-		return
-	}
+// phraseNoticeDuration is how long the session phrase is shown when the
+// lock starts. It shows again next to the password while typing.
+const phraseNoticeDuration = 5 * time.Second
 
-	count := s.inputBuffer.VisualLen()
-	if count == 0 {
+// renderPasswordIndicator displays asterisks for password input in lock
+// mode, followed by the session phrase.
+func (s *screensaver) renderPasswordIndicator() {
+	if s.cfg.mode != ModeLock || s.input == nil || s.input.Len() == 0 {
 		return
 	}
 
@@ -400,52 +1319,68 @@ func (s *screensaver) renderPasswordIndicator() {
 	s.screen.SetContent(col, 0, ' ', nil, tcell.StyleDefault)
 	col++
 
-	for i := 0; i < count && col < s.width; i++ {
-		s.screen.SetContent(col, 0, '*', nil, dimStyle)
+	col += s.input.Draw(s.screen, col, 0, s.width-col, s.cfg.ascii, dimStyle, dimStyle.Foreground(tcell.ColorYellow))
+
+	if s.cfg.phrase == "" {
+		return
+	}
+	col += 3
+	for _, r := range "[" + s.cfg.phrase + "]" {
+		if col >= s.width {
+			break
+		}
+		s.screen.SetContent(col, 0, r, nil, dimStyle)
 		col++
 	}
 }
 
 func (s *screensaver) generateHeat() {
+	if s.feedHeatPath() {
+		return
+	}
 	bottomRow := s.width * (s.height - 1)
 	for i := 0; i < s.heatSources; i++ {
-		idx := rand.Intn(s.width) + bottomRow
-		if idx >= 0 && idx < len(s.buffer) {
-			s.buffer[idx] = s.heatPower
+		x := rand.Intn(s.width)
+		if idx := bottomRow + x; idx >= 0 && idx < len(s.sim.Heat) {
+			s.sim.Heat[idx] = s.contribHeat(x)
 		}
 	}
 }
 
+// tickerRows returns the number of bottom rows used by the ticker.
+func (s *screensaver) tickerRows() int {
+	if s.haveTicker {
+		return 2
+	}
+	return 0
+}
+
 func (s *screensaver) renderFire() {
 	size := s.width * s.height
-	tickerRows := 0
-	if s.haveTicker {
-		tickerRows = 2
+	tickerRows := s.tickerRows()
+	shading := s.shading()
+	if shading {
+		s.resetGrid(s.height - tickerRows)
 	}
 
 	for i := 0; i < size; i++ {
-		s.buffer[i] = (s.buffer[i] + s.buffer[i+1] + s.buffer[i+s.width] + s.buffer[i+s.width+1]) / 4
-
 		row, col := i/s.width, i%s.width
 		if row >= s.height || col >= s.width || row >= s.height-tickerRows {
 			continue
 		}
 
-		v := s.buffer[i]
+		v := s.blendHeat(i, s.sim.Heat[i])
+		if shading {
+			s.grid.Cells[i] = shader.Cell{Value: v, Color: s.palette.At(v)}
+			continue
+		}
 		style := s.styleForValue(v)
 		char := s.theme.chars[clamp(v, 0, 9)]
 		s.screen.SetContent(col, row, char, nil, style)
 	}
-}
-
-// Base RGB colors for fire (matching the theme visually).
-// Using consistent RGB values ensures smooth transitions.
-var fireBaseColors = []struct{ r, g, b uint8 }{
-	{128, 0, 0},    // Maroon (dark, low heat)
-	{200, 50, 0},   // Dark red-orange
-	{255, 100, 0},  // Orange
-	{255, 160, 0},  // Bright orange
-	{255, 200, 50}, // Yellow-orange (high heat)
+	if shading {
+		s.renderEffects()
+	}
 }
 
 func (s *screensaver) styleForValue(v int) tcell.Style {
@@ -455,77 +1390,242 @@ func (s *screensaver) styleForValue(v int) tcell.Style {
 
 // rgbStyle returns RGB-based style with color derived from cell heat.
 // Both height and color use the same source (cell heat v) so they correlate.
+// Indexed colors give way to the wrong password red shift.
 func (s *screensaver) rgbStyle(v int) tcell.Style {
-	// Select base color from heat value
-	var r, g, b uint8
-	switch {
-	case v > heatThresholdHigh:
-		r, g, b = fireBaseColors[4].r, fireBaseColors[4].g, fireBaseColors[4].b
-	case v > heatThresholdMedium:
-		r, g, b = fireBaseColors[3].r, fireBaseColors[3].g, fireBaseColors[3].b
-	case v > heatThresholdLow:
-		r, g, b = fireBaseColors[2].r, fireBaseColors[2].g, fireBaseColors[2].b
-	case v > heatThresholdMin:
-		r, g, b = fireBaseColors[1].r, fireBaseColors[1].g, fireBaseColors[1].b
-	default:
-		r, g, b = fireBaseColors[0].r, fireBaseColors[0].g, fireBaseColors[0].b
+	if s.indexed != nil && s.wrongPasswordFrames <= 0 {
+		return tcell.StyleDefault.Foreground(tcell.PaletteColor(s.indexed.At(v)))
 	}
+	c := s.palette.At(v)
+	return tcell.StyleDefault.Foreground(tcell.NewRGBColor(int32(c.R), int32(c.G), int32(c.B)))
+}
 
-	// Wrong password animation: red shift (takes priority, uses timer)
-	if s.wrongPasswordFrames > 0 {
-		redIntensity := float64(s.wrongPasswordFrames) / float64(wrongPasswordDuration)
-		r, g, b = fire.ApplyRedShift(r, g, b, redIntensity)
-		return tcell.StyleDefault.Foreground(tcell.NewRGBColor(int32(r), int32(g), int32(b)))
+// initPalette builds the heat to color tables of the theme: a smooth
+// gradient on truecolor terminals, solid bands otherwise.
+// The user's brightness/contrast/gamma levels apply last.
+//
+// Terminals with 256 or 16 colors draw the theme's indexed stops when it
+// has some for them. The color shifts and levels only apply to RGB colors.
+func (s *screensaver) initPalette() {
+	depth := s.cfg.colors.Resolve(s.screen.Colors())
+	s.indexed = nil
+	if stops := s.theme.indexStops(depth); stops != nil {
+		indexed := palette.NewIndexed(stops, maxHeat)
+		s.indexed = &indexed
 	}
 
-	// Color shift based on cell heat (same source as height).
-	// After heat diffusion, values are lower than heatPower.
-	if v > colorShiftBaseHeat {
-		intensity := float64(v-colorShiftBaseHeat) / float64(colorShiftMaxHeat-colorShiftBaseHeat)
-		if intensity > 1 {
-			intensity = 1
-		}
-		r, g, b = fire.ApplyIntensityShift(r, g, b, intensity)
+	s.levels = palette.Levels(s.brightness, s.contrast, s.gamma)
+	if s.cfg.gradient.Smooth(int(depth)) && !s.theme.bands {
+		s.basePalette = palette.NewGradient(s.theme.stops, maxHeat)
+	} else {
+		s.basePalette = palette.New(s.theme.stops, maxHeat)
 	}
+	if s.temperature != 0 {
+		s.basePalette = s.basePalette.Map(palette.Temperature(s.temperature))
+	}
+	s.heatPalette = s.basePalette
+	switch {
+	case s.cfg.reducedPalette:
+	case s.theme.light:
+		s.heatPalette = s.basePalette.MapValues(lightHeatShift)
+	default:
+		s.heatPalette = s.basePalette.MapValues(heatShift)
+	}
+	s.heatPalette = s.heatPalette.Map(s.levels)
+	s.updatePalette()
+}
+
+// heatShift shifts the color of cells hotter than colorShiftBaseHeat.
+// After heat diffusion, values are lower than heatPower.
+func heatShift(v int) palette.Effect {
+	if v <= colorShiftBaseHeat {
+		return palette.Identity
+	}
+	return palette.IntensityShift(float64(v-colorShiftBaseHeat) / float64(colorShiftMaxHeat-colorShiftBaseHeat))
+}
 
-	return tcell.StyleDefault.Foreground(tcell.NewRGBColor(int32(r), int32(g), int32(b)))
+// lightHeatShift darkens the hottest cells on light backgrounds, where
+// shifting toward white would make them vanish.
+func lightHeatShift(v int) palette.Effect {
+	if v <= colorShiftBaseHeat {
+		return palette.Identity
+	}
+	return palette.Dim(0.5 * float64(v-colorShiftBaseHeat) / float64(colorShiftMaxHeat-colorShiftBaseHeat))
+}
+
+// updatePalette applies the wrong password red shift, which takes priority
+// over the heat color shift and fades out with its timer.
+func (s *screensaver) updatePalette() {
+	if s.wrongPasswordFrames <= 0 {
+		s.palette = s.heatPalette
+		return
+	}
+	redIntensity := float64(s.wrongPasswordFrames) / float64(wrongPasswordDuration)
+	s.palette = s.basePalette.Map(palette.Chain(palette.RedShift(redIntensity), s.levels))
+}
+
+// levelStep is how much a playground key changes a level.
+const levelStep = 0.1
+
+// adjustLevels handles the playground level keys: b/B brightness, c/C
+// contrast, g/G gamma, 0 to reset.
+func (s *screensaver) adjustLevels(r rune) bool {
+	switch r {
+	case 'b':
+		s.brightness = max(s.brightness-levelStep, 0)
+	case 'B':
+		s.brightness += levelStep
+	case 'c':
+		s.contrast = max(s.contrast-levelStep, 0)
+	case 'C':
+		s.contrast += levelStep
+	case 'g':
+		s.gamma = max(s.gamma-levelStep, levelStep)
+	case 'G':
+		s.gamma += levelStep
+	case '0':
+		s.brightness, s.contrast, s.gamma = 1, 1, 1
+	default:
+		return false
+	}
+	s.initPalette()
+	s.setNotice(fmt.Sprintf("brightness %.1f  contrast %.1f  gamma %.1f", s.brightness, s.contrast, s.gamma))
+	return true
 }
 
 func (s *screensaver) renderTicker() {
-	if !s.haveTicker || s.height < 2 || len(s.msgText) == 0 {
+	if !s.haveTicker || s.reveal != nil || s.height < 2 || len(s.tickerText.Msg) == 0 {
 		return
 	}
 
-	msgRunes := []rune(s.msgText)
-	metaRunes := []rune(s.metaText)
+	msgRunes := []rune(s.tickerText.Msg)
+	metaRunes := []rune(s.tickerText.Meta)
 	msgRow := s.height - 2
 	metaRow := s.height - 1
-	style := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	style := tcell.StyleDefault.Foreground(s.theme.text)
 
 	for x := 0; x < s.width; x++ {
 		mi := (s.tickerOffset + x) % len(msgRunes)
 		mj := (s.tickerOffset + x) % len(metaRunes)
-		s.screen.SetContent(x, msgRow, msgRunes[mi], nil, style)
-		s.screen.SetContent(x, metaRow, metaRunes[mj], nil, style)
+		s.setTickerCell(x, msgRow, msgRunes, mi, style)
+		s.setTickerCell(x, metaRow, metaRunes, mj, s.metaStyle(mj, style))
+	}
+
+	step := max(s.cfg.tickerStep, 1)
+	if !s.hovering && !s.ember && s.frame%(4*step) == 0 {
+		for i := 0; i < step; i++ {
+			s.tickerOffset = (s.tickerOffset + 1) % len(msgRunes)
+			s.notifyTickerItem(len(msgRunes))
+		}
+	}
+}
+
+// metaStyle returns the style of the meta row at rune offset pos: commits
+// take the color of their author, the same as their fireworks rockets, so
+// who committed what stands out as the ticker scrolls by.
+func (s *screensaver) metaStyle(pos int, style tcell.Style) tcell.Style {
+	i, ok := s.tickerText.ItemAt(pos)
+	if !ok || s.tickerText.Items[i].Hash == "" {
+		return style
+	}
+	color := anim.AuthorColor(s.tickerText.Items[i].Author)
+	if s.theme.light {
+		// The bright author colors fade into light backgrounds
+		r, g, b := color.RGB()
+		color = tcell.NewRGBColor(r/2, g/2, b/2)
+	}
+	return style.Foreground(color)
+}
+
+// setTickerCell draws runes[i] at (x, y). Double-width runes cover the
+// next cell, which holds a ticker.Continuation; halves cut by the screen
+// edges are drawn as blanks.
+func (s *screensaver) setTickerCell(x, y int, runes []rune, i int, style tcell.Style) {
+	r := runes[i]
+	switch {
+	case r == ticker.Continuation && x > 0:
+		return
+	case r == ticker.Continuation:
+		r = ' '
+	case x == s.width-1 && runes[(i+1)%len(runes)] == ticker.Continuation:
+		r = ' '
 	}
+	s.screen.SetContent(x, y, r, nil, style)
+}
+
+// notifyTickerItem tells the animation when an item scrolls in at the
+// right edge of the screen.
+func (s *screensaver) notifyTickerItem(length int) {
+	edge := (s.tickerOffset + s.width - 1) % length
+	i, ok := s.tickerText.ItemStartingAt(edge)
+	if !ok {
+		return
+	}
+	item := s.tickerText.Items[i]
+	if listener, ok := s.anim.(anim.TickerListener); ok {
+		listener.OnTickerItem(item)
+	}
+	if item.Hash != "" { // Commits only, not items of other sources
+		s.hooks.OnTickerItem(hooks.TickerItem{Hash: item.Hash, Subject: item.Subject, Author: item.Author, Time: item.Time})
+	}
+}
 
-	if s.frame%4 == 0 {
-		s.tickerOffset = (s.tickerOffset + 1) % len(msgRunes)
+// renderBandwidthMeter draws changed cells per frame in the top-right
+// corner.
+func (s *screensaver) renderBandwidthMeter() {
+	if s.meter == nil {
+		return
+	}
+	fps := float64(time.Second/s.baseFrameDelay()) / float64(max(s.rate.Every, 1))
+	text := fmt.Sprintf(" %d cells/frame (avg %.0f) ~%.1f KB/s ",
+		s.meter.LastChanged, s.meter.AvgChanged, float64(s.meter.BytesPerFrame())*fps/1024)
+	style := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
+	x := max(s.width-len(text), 0)
+	for i, r := range text {
+		if x+i < s.width {
+			s.screen.SetContent(x+i, 0, r, nil, style)
+		}
 	}
 }
 
 // ---- Command Execution
 
-func execScreensaver(cfg screensaverConfig) error {
-	if cfg.mode == ModeLock && !lock.PasswordExists() {
-		return fmt.Errorf("no password configured. Run 'yule-log lock set-password' first")
+// prepareScreensaver checks cfg and sets up the screensaver, ready to run.
+func prepareScreensaver(cfg screensaverConfig) (*screensaver, error) {
+	if cfg.mode == ModeLock {
+		if !cfg.auth.Configured() {
+			return nil, lock.ErrNoPassword
+		}
+		// run --lock comes without the passwords loaded by lockScreenConfig
+		if cfg.passwords == nil {
+			passwords, err := cfg.auth.Load()
+			if err != nil {
+				return nil, fmt.Errorf("loading password: %w", err)
+			}
+			cfg.passwords = passwords
+		}
 	}
 
-	s, err := newScreensaver(cfg)
+	cfg.trace.mark("flags")
+	// Ask the terminal before tcell takes it over.
+	if cfg.headless {
+		cfg.light = cfg.background == termbg.ModeLight
+	} else {
+		cfg.light = termbg.Light(cfg.background)
+	}
+	cfg.trace.mark("background")
+
+	return newScreensaver(cfg)
+}
+
+func execScreensaver(cfg screensaverConfig) error {
+	s, err := prepareScreensaver(cfg)
 	if err != nil {
 		return err
 	}
+	// Printed once close restored the terminal
+	defer cfg.trace.write(os.Stderr)
 	defer s.close()
+	defer atCrash(s.screen.Fini)()
 
 	return s.run()
 }
@@ -534,9 +1634,41 @@ type idleConfig struct {
 	Timeout       int
 	Once          bool
 	Contribs      bool
+	Theme         string // Theme name passed to the screensaver
+	Animation     string // Animation passed to the screensaver
+	Overlay       string // Overlay passed to the screensaver
 	NoTicker      bool
 	Lock          bool
 	SocketProtect bool
+	Auth          lock.Auth // Password backend of the lock screen
+	DryRun        bool
+	ASCII         bool
+	Notify        string
+	Ignite        bool            // Burn the pane content away when the screensaver opens
+	Reveal        bool            // Reveal the pane content through the fire on unlock
+	Mouse         bool            // Enable ticker clicks in the screensaver
+	Background    string          // Terminal background passed to the screensaver
+	Gradient      string          // Palette gradient passed to the screensaver
+	Colors        string          // Color depth passed to the screensaver
+	Backend       string          // Screen backend passed to the screensaver
+	FlameHeight   string          // Flame height limit passed to the screensaver
+	Daylight      bool            // Shift the palette with the time of day
+	Clock         bool            // Show the big clock in the screensaver
+	Alerts        bool            // Show bells and activity of other windows
+	EmberAfter    time.Duration   // Screensaver ember state delay
+	MaxLock       time.Duration   // Passed to the lock screen (with Lock)
+	SkipUnfocused bool            // Don't trigger while the client's terminal is unfocused
+	ClientCaps    bool            // Adapt colors and glyphs to the client's terminal
+	Exec          string          // Shell command run on idle instead of the popup
+	ExecWake      string          // Shell command run when activity resumes
+	Sequence      []trigger.Style // Styles shown on consecutive triggers within an hour
+
+	// Where idleness is read: tmux, system or both, see idlesrc
+	Source string
+
+	// Replace the screensaver with the lock screen after this many idle
+	// seconds (0 = never), see trigger.Stages
+	LockTimeout int
 }
 
 func execIdle(cfg idleConfig) error {
@@ -545,13 +1677,110 @@ func execIdle(cfg idleConfig) error {
 		return fmt.Errorf("finding executable path: %w", err)
 	}
 
+	source, err := idlesrc.New(cfg.Source)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Lock && !cfg.Auth.Configured() {
+		return fmt.Errorf("--lock: %w", lock.ErrNoPassword)
+	}
+	if cfg.LockTimeout > 0 {
+		if cfg.LockTimeout <= cfg.Timeout {
+			return fmt.Errorf("--lock-timeout (%ds) must be longer than --timeout (%ds)", cfg.LockTimeout, cfg.Timeout)
+		}
+		if !cfg.Auth.Configured() {
+			return fmt.Errorf("--lock-timeout: %w", lock.ErrNoPassword)
+		}
+	}
+	if slices.Contains(cfg.Sequence, trigger.StyleLock) && !cfg.Auth.Configured() {
+		return fmt.Errorf("trigger sequence includes lock: %w", lock.ErrNoPassword)
+	}
+	if !cfg.DryRun && cfg.Exec == "" {
+		if err := tmuxcmd.Require(context.Background(), tmuxcmd.PopupVersion, "the screensaver popup"); err != nil {
+			return err
+		}
+	}
+
+	popup := triggerConfig{
+		Contribs:      cfg.Contribs,
+		Theme:         cfg.Theme,
+		Animation:     cfg.Animation,
+		Overlay:       cfg.Overlay,
+		NoTicker:      cfg.NoTicker,
+		Lock:          cfg.Lock,
+		SocketProtect: cfg.SocketProtect,
+		Auth:          cfg.Auth,
+		DryRun:        cfg.DryRun,
+		ASCII:         cfg.ASCII,
+		Notify:        cfg.Notify,
+		Ignite:        cfg.Ignite,
+		Reveal:        cfg.Reveal,
+		Mouse:         cfg.Mouse,
+		Background:    cfg.Background,
+		Gradient:      cfg.Gradient,
+		Colors:        cfg.Colors,
+		Backend:       cfg.Backend,
+		FlameHeight:   cfg.FlameHeight,
+		Daylight:      cfg.Daylight,
+		Clock:         cfg.Clock,
+		Alerts:        cfg.Alerts,
+		EmberAfter:    cfg.EmberAfter,
+		MaxLock:       cfg.MaxLock,
+	}
+
+	// nextPopup picks the popup for a trigger, escalating through the
+	// sequence when one is configured.
+	escalation := &trigger.Escalation{Sequence: cfg.Sequence}
+	nextPopup := func() triggerConfig {
+		if len(cfg.Sequence) == 0 {
+			return popup
+		}
+		style := escalation.Next(time.Now())
+		if cfg.DryRun {
+			fmt.Printf("dry-run: trigger %d within the hour, showing %s\n", escalation.Count(time.Now()), style)
+		}
+		tc := popup
+		tc.Lock = style == trigger.StyleLock
+		tc.Contribs = cfg.Contribs || style == trigger.StyleContribs
+		if style == trigger.StyleContribs {
+			tc.Theme = ""
+		}
+		return tc
+	}
+
+	onIdle := func(ctx context.Context, tc triggerConfig) {
+		if cfg.Exec != "" {
+			runHook(ctx, cfg.Exec, cfg.DryRun)
+			return
+		}
+		if cfg.ClientCaps {
+			tc = negotiateClient(ctx, tc)
+		}
+		triggerScreensaver(ctx, exePath, tc)
+	}
+
+	// onLockTimeout replaces the screensaver, still up after the lock
+	// timeout, with the lock screen.
+	onLockTimeout := func(ctx context.Context) {
+		tc := popup
+		tc.Lock = true
+		if cfg.DryRun {
+			fmt.Println("dry-run: would dismiss the screensaver for the lock screen")
+		} else if dir, err := xdg.RuntimeDir(); err == nil {
+			if res, _ := instance.Dismiss(dir); res.Dismissed > 0 {
+				// Give the popup time to close: tmux shows one at a time
+				time.Sleep(popupCloseDelay)
+			}
+		}
+		if cfg.ClientCaps {
+			tc = negotiateClient(ctx, tc)
+		}
+		triggerScreensaver(ctx, exePath, tc)
+	}
+
 	if cfg.Once {
-		triggerScreensaver(context.Background(), exePath, triggerConfig{
-			Contribs:      cfg.Contribs,
-			NoTicker:      cfg.NoTicker,
-			Lock:          cfg.Lock,
-			SocketProtect: cfg.SocketProtect,
-		})
+		onIdle(context.Background(), nextPopup())
 		return nil
 	}
 
@@ -562,60 +1791,316 @@ func execIdle(cfg idleConfig) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	fmt.Printf("Yule log idle watcher started (timeout: %ds, poll: %ds)\n", cfg.Timeout, pollInterval)
+	fmt.Printf("Yule log idle watcher started (timeout: %ds, poll: %ds, source: %s)\n", cfg.Timeout, pollInterval, cmp.Or(cfg.Source, idlesrc.NameTmux))
+	if cfg.DryRun {
+		fmt.Println("dry-run: no popup will be opened")
+	}
 
-	ticker := time.NewTicker(time.Duration(pollInterval) * time.Second)
-	defer ticker.Stop()
+	pollTicker := time.NewTicker(time.Duration(pollInterval) * time.Second)
+	defer pollTicker.Stop()
 
-	waitingForActivity := false
+	// Shells report activity tmux misses on the control socket, see
+	// yule-log hook. The watcher works without it.
+	var control *idlectl.Listener
+	if path, err := idleSocketPath(ctx); err == nil {
+		control, err = idlectl.Listen(path)
+		if err != nil && cfg.DryRun {
+			fmt.Printf("dry-run: %v\n", err)
+		}
+		defer control.Close()
+	}
+
+	stages := &trigger.Stages{
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+		LockTimeout: time.Duration(cfg.LockTimeout) * time.Second,
+	}
+
+	var heartbeat *idleHeartbeat
+	watcherHooks := hooks.Registered()
+	if shell := loadShellHooks(); shell != nil && !cfg.DryRun {
+		watcherHooks = append(watcherHooks, shell)
+	}
+	if !cfg.DryRun {
+		heartbeat = newIdleHeartbeat()
+		defer heartbeat.flush()
+		if heartbeat != nil {
+			watcherHooks = append(watcherHooks, heartbeat)
+		}
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			fmt.Println("Yule log idle watcher stopped")
 			return nil
-		case <-ticker.C:
-			idleSeconds, err := getClientIdleTime(ctx)
+		case <-pollTicker.C:
+			idle, err := source.Idle(ctx)
 			if err != nil {
+				if cfg.DryRun {
+					fmt.Printf("dry-run: reading idle time failed: %v\n", err)
+				}
 				continue
 			}
+			idleSeconds := int(idle.Seconds())
+			if last := control.LastActivity(); !last.IsZero() {
+				idleSeconds = min(idleSeconds, int(time.Since(last).Seconds()))
+			}
+			heartbeat.observe(idleSeconds)
+
+			action := stages.Peek(time.Duration(idleSeconds) * time.Second)
+			if cfg.DryRun && stages.Stage() == trigger.StageActive {
+				fmt.Printf("dry-run: idle %ds, would trigger in %ds\n", idleSeconds, max(cfg.Timeout-idleSeconds, 0))
+			}
 
-			if waitingForActivity {
-				if idleSeconds < cfg.Timeout {
-					waitingForActivity = false
+			if (action == trigger.ActionTrigger || action == trigger.ActionLock) && cfg.SkipUnfocused && !clientFocused(ctx) {
+				if cfg.DryRun {
+					fmt.Println("dry-run: client terminal unfocused, not triggering")
 				}
 				continue
 			}
 
-			if idleSeconds >= cfg.Timeout {
-				triggerScreensaver(ctx, exePath, triggerConfig{
-					Contribs:      cfg.Contribs,
-					NoTicker:      cfg.NoTicker,
-					Lock:          cfg.Lock,
-					SocketProtect: cfg.SocketProtect,
-				})
-				waitingForActivity = true
+			switch stages.Observe(time.Duration(idleSeconds) * time.Second) {
+			case trigger.ActionWake:
+				if cfg.DryRun {
+					fmt.Printf("dry-run: activity detected (idle %ds), re-arming\n", idleSeconds)
+				}
+				if cfg.ExecWake != "" {
+					runHook(ctx, cfg.ExecWake, cfg.DryRun)
+				}
+			case trigger.ActionTrigger:
+				tc := nextPopup()
+				watcherHooks.OnTrigger(hooks.Trigger{Idle: time.Duration(idleSeconds) * time.Second, Lock: tc.Lock})
+				onIdle(ctx, tc)
+				if tc.Lock && cfg.Exec == "" {
+					stages.Locked()
+				}
+			case trigger.ActionLock:
+				if cfg.DryRun {
+					fmt.Printf("dry-run: idle %ds, lock timeout reached\n", idleSeconds)
+				}
+				watcherHooks.OnTrigger(hooks.Trigger{Idle: time.Duration(idleSeconds) * time.Second, Lock: true})
+				onLockTimeout(ctx)
 			}
 		}
 	}
 }
 
+// statsInterval is how often the idle watcher records a history sample.
+const statsInterval = time.Minute
+
+// idleHeartbeat aggregates idle watcher polls into one history sample per
+// statsInterval. It counts triggers as a watcher hook. A nil heartbeat
+// records nothing.
+type idleHeartbeat struct {
+	hooks.Base
+	path   string
+	sample stats.Sample
+}
+
+func newIdleHeartbeat() *idleHeartbeat {
+	path, err := xdg.IdleStatsFile()
+	if err != nil {
+		return nil
+	}
+	_ = stats.Prune(path, time.Now().Add(-stats.Retention))
+	return &idleHeartbeat{path: path, sample: stats.Sample{Time: time.Now()}}
+}
+
+// observe records a poll and flushes the sample once the interval elapsed.
+func (h *idleHeartbeat) observe(idleSeconds int) {
+	if h == nil {
+		return
+	}
+	h.sample.Idle = max(h.sample.Idle, idleSeconds)
+	if time.Since(h.sample.Time) >= statsInterval {
+		h.flush()
+	}
+}
+
+// OnTrigger counts the trigger in the current sample.
+func (h *idleHeartbeat) OnTrigger(hooks.Trigger) {
+	h.sample.Triggers++
+}
+
+// flush writes the pending sample (best effort) and starts a new one.
+func (h *idleHeartbeat) flush() {
+	if h == nil {
+		return
+	}
+	h.sample.Time = time.Now()
+	_ = stats.Append(h.path, h.sample)
+	h.sample = stats.Sample{Time: time.Now()}
+}
+
+// historyColumns is the number of sparkline columns in idle status.
+const historyColumns = 48
+
+func execIdleStatus(ascii bool) error {
+	path, err := xdg.IdleStatsFile()
+	if err != nil {
+		return fmt.Errorf("getting idle stats path: %w", err)
+	}
+
+	now := time.Now()
+	start := now.Add(-stats.Retention)
+	samples, err := stats.Load(path, start)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		fmt.Println("No idle history recorded in the last 24h (is the idle watcher running?)")
+		return nil
+	}
+
+	idle, triggers := stats.Buckets(samples, start, now, historyColumns)
+
+	longest, total := 0, 0
+	for i := range idle {
+		longest = max(longest, idle[i])
+		total += triggers[i]
+	}
+
+	marks := make([]rune, len(triggers))
+	for i, n := range triggers {
+		switch {
+		case n == 0:
+			marks[i] = ' '
+		case n < 10:
+			marks[i] = rune('0' + n)
+		default:
+			marks[i] = '+'
+		}
+	}
+
+	last := samples[0].Time
+	for _, sample := range samples {
+		if sample.Time.After(last) {
+			last = sample.Time
+		}
+	}
+	bucket := stats.Retention / historyColumns
+
+	fmt.Printf("Idle history (last 24h, %s per column)\n", bucket)
+	fmt.Printf("  idle     |%s| max %s\n", stats.Sparkline(idle, 0, ascii), time.Duration(longest)*time.Second)
+	fmt.Printf("  triggers |%s| total %d\n", string(marks), total)
+	fmt.Printf("           -24h%snow\n", strings.Repeat(" ", historyColumns-5))
+	fmt.Printf("Last heartbeat: %s ago\n", now.Sub(last).Round(time.Second))
+	return nil
+}
+
 type lockConfig struct {
 	SocketProtect bool
+	Auth          lock.Auth // Password file or macOS login password
 	Contribs      bool
+	Theme         string
 	NoTicker      bool
 	Cooldown      fire.CooldownSpeed
+	DryRun        bool
+	ASCII         bool
+	Announcer     announce.Announcer
+	Auto          bool
+	Events        []fire.EventKind
+	Animation     string
+	Overlay       string // Needs SocketProtect off, like SoftLockPane
+	Reveal        bool
+	Background    termbg.Mode
+	Gradient      palette.Gradient
+	Colors        palette.Depth
+	FlameHeight   float64
+	Daylight      bool
+	Clock         bool
+	Alerts        bool // Needs SocketProtect off, like Overlay
+	EmberAfter    time.Duration
+	Brightness    float64
+	Contrast      float64
+	Gamma         float64
+	MaxLock       time.Duration // Detach all clients after this long (0 = never)
+	MaxLockExec   string        // Shell command run when MaxLock expires
+	Backend       render.Backend
+
+	Rhythm          bool    // Also require the recorded typing rhythm (experimental)
+	RhythmTolerance float64 // See lock.RhythmDistance
+
+	SoftLockPane string // Pane kept visible on the lock screen, see softlock.go
+
+	Yes bool // Lock without asking about other attached clients
+
+	// Lock screens over the other attached clients too: AllClients opens
+	// them, with ClientArgs (the lock flags), as followers of this lock
+	AllClients bool
+	ClientArgs []string
+	Follow     bool
 }
 
 func execLock(cfg lockConfig) error {
-	if !lock.PasswordExists() {
-		return fmt.Errorf("no password configured. Run 'yule-log lock set-password' first")
+	if !cfg.Auth.Configured() {
+		return lock.ErrNoPassword
 	}
 
 	if os.Getenv("TMUX") == "" {
 		return fmt.Errorf("not running inside tmux")
 	}
 
+	var rhythm lock.Rhythm
+	if cfg.Rhythm && cfg.Auth == lock.AuthSystem {
+		return fmt.Errorf("--experimental-rhythm needs --auth %s: the rhythm is kept in the password file", lock.AuthFile)
+	}
+	if cfg.Rhythm {
+		var err error
+		if rhythm, err = lock.LoadRhythm(); err != nil {
+			return fmt.Errorf("loading typing rhythm: %w", err)
+		}
+		if rhythm == nil {
+			return fmt.Errorf("no typing rhythm recorded. Run 'yule-log lock set-password --experimental-rhythm' first")
+		}
+	}
+
+	if cfg.Overlay == overlaySessions && cfg.SocketProtect {
+		return fmt.Errorf("--overlay %s needs --socket-protect=false", overlaySessions)
+	}
+	if cfg.Alerts && cfg.SocketProtect {
+		return fmt.Errorf("--alerts needs --socket-protect=false")
+	}
+
+	if cfg.SoftLockPane != "" {
+		// The pane is mirrored through tmux, which can't be reached once
+		// the socket is restricted.
+		if cfg.SocketProtect {
+			return fmt.Errorf("--soft-lock-pane needs --socket-protect=false")
+		}
+		pane, err := resolvePane(context.Background(), cfg.SoftLockPane)
+		if err != nil {
+			return err
+		}
+		cfg.SoftLockPane = pane
+	}
+
+	if cfg.Follow {
+		return followLock(cfg, rhythm)
+	}
+	if cfg.AllClients && !cfg.DryRun {
+		if err := tmuxcmd.Require(context.Background(), tmuxcmd.PopupVersion, "--all-clients"); err != nil {
+			return err
+		}
+	}
+
+	// Nobody is there to answer for the idle watcher, and --all-clients
+	// covers the other clients anyway.
+	if !cfg.Yes && !cfg.Auto && !cfg.AllClients {
+		proceed, err := confirmOtherClients(cfg.DryRun)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			fmt.Println("Lock cancelled.")
+			return nil
+		}
+	}
+
+	if cfg.DryRun {
+		return dryRunLock(cfg)
+	}
+
 	var socketPath string
 	var originalPerm os.FileMode
 
@@ -625,78 +2110,697 @@ func execLock(cfg lockConfig) error {
 		if err != nil {
 			return fmt.Errorf("getting tmux socket: %w", err)
 		}
+		originalPerm, err = lock.OriginalSocketPerm(socketPath)
+		if err != nil {
+			return fmt.Errorf("reading socket permissions: %w", err)
+		}
+	}
+
+	// Read once: a keychain out of reach later must not lock the user out.
+	passwords, err := cfg.Auth.Load()
+	if err != nil {
+		return fmt.Errorf("loading password: %w", err)
+	}
+
+	phrase, err := lock.Lock(socketPath, originalPerm, cfg.Auth)
+	if err != nil {
+		return fmt.Errorf("creating lock state: %w", err)
+	}
+	defer lock.Unlock()
+	defer atCrash(func() { _ = lock.Unlock() })()
+
+	// Killing the lock (or closing its popup) ends the screen, so that the
+	// socket is restored and the lock lifted on the way out.
+	signals := stopOnSignals(syscall.SIGTERM, syscall.SIGHUP)
+	defer signals.Stop()
+
+	screen := lockScreenConfig(cfg, phrase, rhythm, passwords)
+	screen.stop = signals.stop
+
+	// The other lock screens are opened through tmux, before the socket
+	// is out of reach, and find the lock state written above.
+	if cfg.AllClients {
+		state, err := lock.LoadState()
+		if err != nil {
+			return fmt.Errorf("reading lock state: %w", err)
+		}
+		screen.lockedAt = state.LockedAt
+		if err := lockOtherClients(cfg.ClientArgs); err != nil {
+			return err
+		}
+	}
 
+	if cfg.SocketProtect {
 		originalPerm, err = lock.RestrictSocket(socketPath)
 		if err != nil {
 			return fmt.Errorf("restricting socket: %w", err)
 		}
 		defer lock.RestoreSocket(socketPath, originalPerm)
+		defer atCrash(func() { _ = lock.RestoreSocket(socketPath, originalPerm) })()
 	}
 
-	if err := lock.Lock(socketPath, originalPerm); err != nil {
-		return fmt.Errorf("creating lock state: %w", err)
+	for {
+		err = execScreensaver(screen)
+		if sig := signals.Received(); sig != nil {
+			return fmt.Errorf("lock ended by %s", sig)
+		}
+		if errors.Is(err, errLockEnded) {
+			return nil // Unlocked from another client
+		}
+		if !errors.Is(err, errDuress) {
+			break
+		}
+		// The lock screen comes back if the server survives.
+		if lock.KillServer(socketPath, originalPerm, killServer) == nil {
+			return nil
+		}
+	}
+	if !errors.Is(err, errLockExpired) {
+		return err
+	}
+
+	// tmux commands can't reach a protected socket: restore it first.
+	if cfg.SocketProtect {
+		if err := lock.RestoreSocket(socketPath, originalPerm); err != nil {
+			return fmt.Errorf("restoring socket: %w", err)
+		}
+	}
+	if err := lock.Unlock(); err != nil {
+		return err
+	}
+	return expireLock(cfg)
+}
+
+// duressExec returns the [password] duress_exec command of the global
+// configuration file, "" to kill the tmux server.
+func duressExec() string {
+	conf, err := passwordConfig()
+	if err != nil || conf.DuressExec == nil {
+		return ""
+	}
+	return *conf.DuressExec
+}
+
+// killServer kills the tmux server, the default action of the duress
+// password.
+func killServer() error {
+	cmd := exec.Command("tmux", "kill-server")
+	cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
+	return cmd.Run()
+}
+
+// lockScreenConfig returns the lock screen settings of cfg.
+func lockScreenConfig(cfg lockConfig, phrase string, rhythm lock.Rhythm, passwords lock.Verifier) screensaverConfig {
+	return screensaverConfig{
+		mode:       ModeLock,
+		phrase:     phrase,
+		auth:       cfg.Auth,
+		passwords:  passwords,
+		duress:     !cfg.Follow,
+		duressExec: duressExec(),
+
+		rhythm:          rhythm,
+		rhythmTolerance: cfg.RhythmTolerance,
+		softLockPane:    cfg.SoftLockPane,
+
+		contribs:   cfg.Contribs,
+		theme:      cfg.Theme,
+		noTicker:   cfg.NoTicker,
+		cooldown:   cfg.Cooldown,
+		ascii:      cfg.ASCII,
+		announcer:  cfg.Announcer,
+		autoLocked: cfg.Auto,
+
+		animation: cfg.Animation,
+		overlay:   cfg.Overlay,
+		reveal:    cfg.Reveal,
+
+		background:  cfg.Background,
+		gradient:    cfg.Gradient,
+		colors:      cfg.Colors,
+		flameHeight: cfg.FlameHeight,
+		daylight:    cfg.Daylight,
+		clock:       cfg.Clock,
+		alerts:      cfg.Alerts,
+		brightness:  cfg.Brightness,
+		contrast:    cfg.Contrast,
+		gamma:       cfg.Gamma,
+
+		maxLock:    cfg.MaxLock,
+		emberAfter: cfg.EmberAfter,
+
+		events:           cfg.Events,
+		eventMinInterval: defaultEventMinInterval,
+		eventMaxInterval: defaultEventMaxInterval,
+
+		backend: cfg.Backend,
+	}
+}
+
+// ---- Every Client
+// lock --all-clients opens a lock screen over each other attached client,
+// in a full-screen popup running lock --follow. Followers share the lock
+// of the client that engaged it: whichever unlocks removes the lock state,
+// and every other screen closes when it sees the state gone. Only the
+// first client restricts and restores the socket, or gives up on
+// --max-lock.
+
+// popupReadyTimeout bounds the wait for a follower popup to reach tmux.
+const popupReadyTimeout = 2 * time.Second
+
+// lockOtherClients opens a follower lock screen over every other attached
+// client, and waits until the tmux clients opening them are connected:
+// the popups stay up once the socket is restricted, which only keeps new
+// connections out.
+func lockOtherClients(lockArgs []string) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("getting executable path: %w", err)
+	}
+	command := append([]string{exePath, "lock", "--follow"}, lockArgs...)
+	for i, c := range otherClients(context.Background()) {
+		channel := fmt.Sprintf("yule-log-%d-%d", os.Getpid(), i)
+		popup := exec.Command("tmux", tmuxcmd.ClientPopupArgs(c.Name, channel, command)...)
+		if err := popup.Start(); err != nil {
+			return fmt.Errorf("opening lock screen on %s: %w", c.Name, err)
+		}
+		go popup.Wait() // Returns when the popup closes
+
+		ctx, cancel := context.WithTimeout(context.Background(), popupReadyTimeout)
+		err := exec.CommandContext(ctx, "tmux", tmuxcmd.WaitArgs(channel)...).Run()
+		cancel()
+		if err != nil {
+			return fmt.Errorf("opening lock screen on %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// followLock shows the lock screen of a follower, for the lock engaged by
+// another client. Unlocking here lifts the lock everywhere.
+func followLock(cfg lockConfig, rhythm lock.Rhythm) error {
+	state, err := lock.LoadState()
+	if err != nil {
+		return fmt.Errorf("no lock to follow: %w", err)
+	}
+	phrase, err := lock.Phrase()
+	if err != nil {
+		return fmt.Errorf("reading session phrase: %w", err)
+	}
+	passwords, err := cfg.Auth.Load()
+	if err != nil {
+		return fmt.Errorf("loading password: %w", err)
 	}
-	defer lock.Unlock()
 
-	return execScreensaver(screensaverConfig{
-		mode:     ModeLock,
-		contribs: cfg.Contribs,
-		noTicker: cfg.NoTicker,
-		cooldown: cfg.Cooldown,
+	screen := lockScreenConfig(cfg, phrase, rhythm, passwords)
+	screen.lockedAt = state.LockedAt
+	screen.maxLock = 0 // Left to the first client
+	err = execScreensaver(screen)
+	switch {
+	case errors.Is(err, errLockEnded):
+		return nil
+	case err != nil:
+		return err
+	}
+	return lock.Unlock()
+}
+
+// followerFlags returns the lock flags set in fs, for the lock screens of
+// the other clients. The flags about engaging the lock are left out.
+func followerFlags(fs *flag.FlagSet) []string {
+	var args []string
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "all-clients", "follow", "yes", "dry-run", "max-lock", "max-lock-exec":
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
 	})
+	return args
 }
 
-func execSetPassword() error {
-	reader := bufio.NewReader(os.Stdin)
+// otherClients returns the tmux clients attached besides the one the lock
+// screen opens over. Listing failures yield none: they must not stop the
+// lock.
+func otherClients(ctx context.Context) []lock.Client {
+	self, err := exec.CommandContext(ctx, "tmux", "display-message", "-p", "#{client_name}").Output()
+	if err != nil {
+		return nil
+	}
+	out, err := exec.CommandContext(ctx, "tmux", "list-clients", "-F", lock.ClientFormat).Output()
+	if err != nil {
+		return nil
+	}
+	clients := lock.ParseClients(string(out), strings.TrimSpace(string(self)))
+	for i := range clients {
+		clients[i].Remote = lock.RemoteAddr(clients[i].PID)
+	}
+	return clients
+}
 
-	if lock.PasswordExists() {
-		fmt.Print("A password is already set. Replace it? [y/N]: ")
-		response, err := reader.ReadString('\n')
-		if err != nil && !errors.Is(err, io.EOF) {
-			return fmt.Errorf("reading confirmation: %w", err)
+// confirmOtherClients warns that other attached clients keep working
+// behind the lock screen, and asks whether to lock anyway, detach them
+// first or cancel. It reports whether to go on locking.
+func confirmOtherClients(dryRun bool) (bool, error) {
+	ctx := context.Background()
+	clients := otherClients(ctx)
+	if len(clients) == 0 {
+		return true, nil
+	}
+
+	noun := "client"
+	if len(clients) > 1 {
+		noun = "clients"
+	}
+	fmt.Printf("%d other %s attached:\n", len(clients), noun)
+	for _, c := range clients {
+		fmt.Printf("  %s\n", c)
+	}
+	fmt.Println("The lock screen only covers this one: they keep access to the session.")
+	if dryRun {
+		fmt.Println("dry-run: would ask to lock anyway, detach them first or cancel")
+		return true, nil
+	}
+
+	key, err := prompt.Choose(os.Stdin, os.Stdout, "[l]ock anyway, [d]etach them and lock, [c]ancel? ", "ldcyn")
+	if err != nil {
+		return false, fmt.Errorf("reading answer: %w", err)
+	}
+	switch key {
+	case 'l', 'y':
+		return true, nil
+	case 'd':
+		for _, c := range clients {
+			if err := exec.CommandContext(ctx, "tmux", "detach-client", "-t", c.Name).Run(); err != nil {
+				return false, fmt.Errorf("detaching %s: %w", c.Name, err)
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// expireLock gives up on a lock nobody came back to: it runs the
+// --max-lock-exec hook (e.g. to kill the ssh-agent), then detaches every
+// client. Getting back in then takes a shell as the user, which the lock
+// can't stop anyway.
+func expireLock(cfg lockConfig) error {
+	pluginHooks(cfg.Announcer).OnUnlock(hooks.Unlock{Expired: true})
+
+	if cfg.MaxLockExec != "" {
+		runHook(context.Background(), cfg.MaxLockExec, false)
+	}
+
+	out, err := exec.Command("tmux", "list-clients", "-F", "#{client_name}").Output()
+	if err != nil {
+		return fmt.Errorf("listing tmux clients: %w", err)
+	}
+	clients := strings.Fields(string(out))
+	if len(clients) == 0 {
+		return nil
+	}
+	// One tmux invocation for all clients: detaching the one showing this
+	// lock closes the popup and may kill us before a later command runs.
+	if err := exec.Command("tmux", detachArgs(clients)...).Run(); err != nil {
+		return fmt.Errorf("detaching clients: %w", err)
+	}
+	return nil
+}
+
+// detachArgs builds a tmux command line detaching every client.
+func detachArgs(clients []string) []string {
+	var args []string
+	for i, client := range clients {
+		if i > 0 {
+			args = append(args, ";")
+		}
+		args = append(args, "detach-client", "-t", client)
+	}
+	return args
+}
+
+// dryRunLock reports what execLock would do without touching the socket,
+// the lock state file or the terminal.
+func dryRunLock(cfg lockConfig) error {
+	if cfg.SocketProtect {
+		socketPath, err := lock.GetTmuxSocketPath()
+		if err != nil {
+			return fmt.Errorf("getting tmux socket: %w", err)
+		}
+		current, err := lock.GetSocketPermissions(socketPath)
+		if err != nil {
+			return fmt.Errorf("reading socket permissions: %w", err)
+		}
+		perm, err := lock.OriginalSocketPerm(socketPath)
+		if err != nil {
+			return fmt.Errorf("reading socket permissions: %w", err)
+		}
+		fmt.Printf("dry-run: would chmod %s from %04o to 0000 (restored to %04o on unlock)\n", socketPath, current, perm)
+	} else {
+		fmt.Println("dry-run: socket protection disabled, socket permissions unchanged")
+	}
+
+	if path, err := xdg.LockStateFile(); err == nil {
+		fmt.Printf("dry-run: would write lock state to %s\n", path)
+	}
+
+	fmt.Printf("dry-run: would show lock screen (contribs=%t, theme=%q, ticker=%t, cooldown=%s)\n",
+		cfg.Contribs, cfg.Theme, !cfg.NoTicker, cfg.Cooldown)
+	if cfg.AllClients {
+		for _, c := range otherClients(context.Background()) {
+			fmt.Printf("dry-run: would also show it over %s\n", c)
+		}
+	}
+	if cfg.SoftLockPane != "" {
+		fmt.Printf("dry-run: pane %s would stay visible (soft lock)\n", cfg.SoftLockPane)
+	}
+	if cfg.Rhythm {
+		fmt.Printf("dry-run: unlocking would also require the typing rhythm (tolerance %.2f)\n", cfg.RhythmTolerance)
+	}
+	if cfg.MaxLock > 0 {
+		fmt.Printf("dry-run: would detach all clients after %s locked\n", cfg.MaxLock)
+		if cfg.MaxLockExec != "" {
+			fmt.Printf("dry-run: then run: sh -c %q\n", cfg.MaxLockExec)
 		}
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+	}
+	return nil
+}
+
+type setPasswordConfig struct {
+	ASCII       bool
+	Stdin       bool   // Read the password from the first line of stdin
+	FromFile    string // Read the password from the first line of this file
+	Force       bool   // Replace an existing password without asking
+	Rhythm      bool   // Record the typing rhythm (experimental)
+	Duress      bool   // Set the duress password instead
+	ClearDuress bool   // Remove the duress password
+}
+
+func execSetPassword(cfg setPasswordConfig) error {
+	switch {
+	case cfg.Duress && cfg.ClearDuress:
+		return fmt.Errorf("--duress and --clear-duress are mutually exclusive")
+	case cfg.ClearDuress:
+		return execClearDuressPassword()
+	case cfg.Duress:
+		return execSetDuressPassword(cfg)
+	}
+	if cfg.Stdin || cfg.FromFile != "" {
+		if cfg.Rhythm {
+			return fmt.Errorf("--experimental-rhythm needs the password typed interactively")
+		}
+		return execSetPasswordNonInteractive(cfg)
+	}
+
+	ascii := cfg.ASCII
+
+	if lock.PasswordExists() {
+		replace, err := confirm("A password is already set. Replace it? [y/N]: ")
+		if err != nil || !replace {
 			fmt.Println("Password not changed.")
-			return nil
+			return err
 		}
 	}
 
+	policy, err := passwordPolicy()
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("Set your lock password.")
 	fmt.Println("You can use regular characters and arrow keys (shown as arrows).")
-	fmt.Print("Enter password: ")
+	if cfg.Rhythm {
+		fmt.Println("Your typing rhythm is recorded too: type both times at your natural pace.")
+	}
+
+	var passwordTimes, confirmTimes *lock.RhythmRecorder
+	if cfg.Rhythm {
+		passwordTimes, confirmTimes = &lock.RhythmRecorder{}, &lock.RhythmRecorder{}
+	}
+
+	password, err := readNewPassword(ascii, policy, passwordTimes, confirmTimes)
+	if err != nil {
+		return err
+	}
+	defer lock.ClearBytes(password)
+
+	var rhythm lock.Rhythm
+	if cfg.Rhythm {
+		first, second := passwordTimes.Rhythm(), confirmTimes.Rhythm()
+		if !lock.RhythmMatches(first, second, lock.DefaultRhythmTolerance) {
+			return fmt.Errorf("the two entries were typed with different rhythms, try again")
+		}
+		if rhythm, err = lock.AverageRhythm(first, second); err != nil {
+			return err
+		}
+	}
 
-	password, err := readPasswordWithArrows()
+	store, err := savePassword(password, rhythm)
 	if err != nil {
-		return fmt.Errorf("reading password: %w", err)
+		return err
+	}
+
+	fmt.Println("\nPassword set successfully" + storedIn(store) + ".")
+	return nil
+}
+
+// confirm asks a yes/no question on the terminal, no by default.
+func confirm(question string) (bool, error) {
+	fmt.Print(question)
+	response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("reading confirmation: %w", err)
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
+// readNewPassword prompts for a new password, with a strength meter
+// against policy, and its confirmation. Keystroke times go to the
+// recorders, which may be nil.
+func readNewPassword(ascii bool, policy lock.Policy, passwordTimes, confirmTimes *lock.RhythmRecorder) ([]byte, error) {
+	password, err := prompt.ReadPassword(os.Stdin, os.Stdout, "Enter password: ", ascii, passwordTimes, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("reading password: %w", err)
 	}
 	if len(password) == 0 {
-		return fmt.Errorf("password cannot be empty")
+		return nil, fmt.Errorf("password cannot be empty")
+	}
+	if err := policy.Check(password); err != nil {
+		lock.ClearBytes(password)
+		return nil, err
 	}
-	defer lock.ClearBytes(password)
 
-	fmt.Print("\nConfirm password: ")
-	confirm, err := readPasswordWithArrows()
+	fmt.Println()
+	confirm, err := prompt.ReadPassword(os.Stdin, os.Stdout, "Confirm password: ", ascii, confirmTimes, nil)
 	if err != nil {
-		return fmt.Errorf("reading confirmation: %w", err)
+		lock.ClearBytes(password)
+		return nil, fmt.Errorf("reading confirmation: %w", err)
 	}
 	defer lock.ClearBytes(confirm)
 
 	if subtle.ConstantTimeCompare(password, confirm) != 1 {
-		return fmt.Errorf("passwords do not match")
+		lock.ClearBytes(password)
+		return nil, fmt.Errorf("passwords do not match")
+	}
+	return password, nil
+}
+
+// passwordConfig returns the [password] table of the global
+// configuration file.
+func passwordConfig() (config.Password, error) {
+	path, err := xdg.ConfigFile()
+	if err != nil {
+		return config.Password{}, nil
 	}
+	conf, err := config.LoadFile(path)
+	if err != nil {
+		return config.Password{}, err
+	}
+	return conf.Password, nil
+}
+
+// passwordPolicy returns the policy new passwords must meet, from the
+// global configuration file.
+func passwordPolicy() (lock.Policy, error) {
+	conf, err := passwordConfig()
+	if err != nil {
+		return lock.Policy{}, err
+	}
+	policy := lock.DefaultPolicy
+	if conf.MinLength != nil {
+		policy.MinLength = *conf.MinLength
+	}
+	if conf.MinStrength != nil {
+		if policy.MinStrength, err = lock.ParseStrength(*conf.MinStrength); err != nil {
+			return lock.Policy{}, err
+		}
+	}
+	if conf.RequireKeys != nil {
+		policy.RequireKeys = *conf.RequireKeys
+	}
+	return policy, nil
+}
 
-	if err := lock.SavePassword(password); err != nil {
-		return fmt.Errorf("saving password: %w", err)
+// savePassword stores the password where the global configuration file
+// says ([password] storage), warning when the keychain is not available.
+func savePassword(password []byte, rhythm lock.Rhythm) (lock.Store, error) {
+	conf, err := passwordConfig()
+	if err != nil {
+		return "", err
+	}
+	want := lock.StoreFile
+	if conf.Storage != nil {
+		if want, err = lock.ParseStore(*conf.Storage); err != nil {
+			return "", err
+		}
 	}
 
-	fmt.Println("\nPassword set successfully.")
+	store, err := lock.SavePassword(password, rhythm, want)
+	if err != nil {
+		return "", fmt.Errorf("saving password: %w", err)
+	}
+	if store != want {
+		fmt.Fprintf(os.Stderr, "warning: %v; the password is stored in the password file instead.\n", keyring.Available())
+	}
+	return store, nil
+}
+
+// storedIn describes a password store other than the file, for messages.
+func storedIn(store lock.Store) string {
+	if store == lock.StoreKeychain {
+		return " (in the keychain)"
+	}
+	return ""
+}
+
+// execSetPasswordNonInteractive provisions the password from stdin or a
+// file, for scripts and dotfile installers. It goes through the same
+// hashing path as the interactive prompt.
+func execSetPasswordNonInteractive(cfg setPasswordConfig) error {
+	if lock.PasswordExists() && !cfg.Force {
+		return fmt.Errorf("a password is already set; use --force to replace it")
+	}
+
+	password, err := readPasswordNonInteractive(cfg, "lock password")
+	if err != nil {
+		return err
+	}
+	defer lock.ClearBytes(password)
+
+	policy, err := passwordPolicy()
+	if err != nil {
+		return err
+	}
+	if err := policy.Check(password); err != nil {
+		return err
+	}
+
+	store, err := savePassword(password, nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "Password set successfully"+storedIn(store)+".")
+	return nil
+}
+
+// readPasswordNonInteractive reads the first line of stdin or of the
+// --from-file file, warning about the risks of provisioning what.
+func readPasswordNonInteractive(cfg setPasswordConfig, what string) ([]byte, error) {
+	if cfg.Stdin && cfg.FromFile != "" {
+		return nil, fmt.Errorf("--stdin and --from-file are mutually exclusive")
+	}
+
+	fmt.Fprintf(os.Stderr, "warning: setting the %s non-interactively.\n", what)
+	fmt.Fprintln(os.Stderr, "warning: make sure it does not end up in shell history, logs or world-readable files.")
+
+	var src io.Reader = os.Stdin
+	if cfg.FromFile != "" {
+		f, err := os.Open(cfg.FromFile)
+		if err != nil {
+			return nil, fmt.Errorf("opening password file: %w", err)
+		}
+		defer f.Close()
+
+		if info, err := f.Stat(); err == nil && info.Mode().Perm()&0077 != 0 {
+			fmt.Fprintf(os.Stderr, "warning: %s is accessible by other users (mode %04o)\n", cfg.FromFile, info.Mode().Perm())
+		}
+		src = f
+	}
+
+	password, err := prompt.ReadPasswordLine(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(password) == 0 {
+		return nil, fmt.Errorf("password cannot be empty")
+	}
+	return password, nil
+}
+
+// ---- Duress Password
+
+// execSetDuressPassword sets the duress password, typed or provisioned
+// like the lock password, which must be set first.
+func execSetDuressPassword(cfg setPasswordConfig) error {
+	if cfg.Rhythm {
+		return fmt.Errorf("--experimental-rhythm can't be combined with --duress")
+	}
+	if !lock.PasswordExists() {
+		return lock.ErrNoPassword
+	}
+	policy, err := passwordPolicy()
+	if err != nil {
+		return err
+	}
+
+	var password []byte
+	if cfg.Stdin || cfg.FromFile != "" {
+		if lock.HasDuressPassword() && !cfg.Force {
+			return fmt.Errorf("duress password: %w", lock.ErrPasswordExists)
+		}
+		if password, err = readPasswordNonInteractive(cfg, "duress password"); err != nil {
+			return err
+		}
+	} else {
+		if lock.HasDuressPassword() {
+			replace, err := confirm("A duress password is already set. Replace it? [y/N]: ")
+			if err != nil || !replace {
+				fmt.Println("Duress password not changed.")
+				return err
+			}
+		}
+		fmt.Println("Set your duress password.")
+		fmt.Println("Typed on the lock screen, it kills the tmux server instead of unlocking.")
+		if password, err = readNewPassword(cfg.ASCII, policy, nil, nil); err != nil {
+			return err
+		}
+	}
+	defer lock.ClearBytes(password)
+
+	if err := policy.Check(password); err != nil {
+		return err
+	}
+	if err := lock.SetDuressPassword(password); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Duress password set.")
+	return nil
+}
+
+// execClearDuressPassword removes the duress password.
+func execClearDuressPassword() error {
+	if err := lock.RemoveDuressPassword(); err != nil {
+		return err
+	}
+	fmt.Println("Duress password removed.")
 	return nil
 }
 
 func execLockStatus() error {
 	if lock.PasswordExists() {
-		fmt.Println("Password: configured")
+		fmt.Println("Password: configured" + storedIn(lock.PasswordStore()))
 	} else {
 		fmt.Println("Password: not configured")
 	}
@@ -714,6 +2818,169 @@ func execLockStatus() error {
 	return nil
 }
 
+// execLockRecover lifts a lock left behind by a lock process that was
+// killed, restoring the socket it restricted. It is quiet about nothing
+// to do, for the plugin's tmux hook.
+func execLockRecover(force bool) error {
+	state, err := lock.Recover(force)
+	switch {
+	case errors.Is(err, lock.ErrNotLocked):
+		fmt.Println("Not locked.")
+		return nil
+	case errors.Is(err, lock.ErrLockHeld):
+		fmt.Printf("Lock held by running process %d, nothing to recover (--force lifts it anyway).\n", state.PID)
+		return nil
+	case err != nil:
+		return fmt.Errorf("recovering lock: %w", err)
+	}
+	if state.SocketPath != "" {
+		fmt.Printf("Restored %s to %04o.\n", state.SocketPath, state.SocketPerm)
+	}
+	fmt.Printf("Removed the lock engaged at %s.\n", state.LockedAt.Local().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// attemptsLimit is the number of entries shown by lock attempts.
+const attemptsLimit = 20
+
+// execLockAttempts prints the last n entries of the unlock audit log.
+func execLockAttempts(n int) error {
+	path, err := xdg.UnlockLogFile()
+	if err != nil {
+		return fmt.Errorf("getting unlock log path: %w", err)
+	}
+	attempts, err := lock.RecentAttempts(path, n)
+	if err != nil {
+		return err
+	}
+	if len(attempts) == 0 {
+		fmt.Println("No unlock attempts recorded.")
+		return nil
+	}
+
+	failed, panics := 0, 0
+	for _, a := range attempts {
+		fmt.Printf("%s  %s\n", a.Time.Local().Format("2006-01-02 15:04:05"), a.Result)
+		switch a.Result {
+		case lock.ResultFailed:
+			failed++
+		case lock.ResultPanic:
+			panics++
+		}
+	}
+	fmt.Printf("%d attempts, %d failed", len(attempts)-panics, failed)
+	if panics > 0 {
+		fmt.Printf(", %d panic marks", panics)
+	}
+	fmt.Println()
+	return nil
+}
+
+// ---- Lock Binding
+
+// defaultBindPrompt is the confirmation asked by lock bind bindings.
+const defaultBindPrompt = "Lock the session? (y/n)"
+
+type bindConfig struct {
+	Key       string
+	Table     string
+	Prompt    string
+	LockFlags []string // Passed to yule-log lock, e.g. --reveal
+	DryRun    bool
+}
+
+// execLockBind installs a tmux binding asking for confirmation, then
+// opening the lock screen in a popup.
+func execLockBind(cfg bindConfig) error {
+	if cfg.Key == "" {
+		return fmt.Errorf("--key must not be empty")
+	}
+	auth, err := lock.ParseAuth(flagsAuth(cfg.LockFlags))
+	if err != nil {
+		return err
+	}
+	if !cfg.DryRun && !auth.Configured() {
+		return lock.ErrNoPassword
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding executable path: %w", err)
+	}
+
+	b := tmuxcmd.Binding{
+		Table:   cfg.Table,
+		Key:     cfg.Key,
+		Prompt:  cfg.Prompt,
+		Command: append([]string{exePath, "lock"}, cfg.LockFlags...),
+	}
+	if cfg.DryRun {
+		fmt.Printf("dry-run: would run: tmux %s\n", tmuxcmd.ShellJoin(b.BindArgs()))
+		return nil
+	}
+	if out, err := exec.Command("tmux", b.BindArgs()...).CombinedOutput(); err != nil {
+		return fmt.Errorf("binding %s: %s", cfg.Key, cmp.Or(strings.TrimSpace(string(out)), err.Error()))
+	}
+	fmt.Printf("Bound %s (%s table) to the lock screen\n", cfg.Key, cfg.Table)
+	return nil
+}
+
+// execLockUnbind removes a binding installed by lock bind, leaving other
+// bindings of the key alone.
+func execLockUnbind(cfg bindConfig) error {
+	b := tmuxcmd.Binding{Table: cfg.Table, Key: cfg.Key}
+	out, err := exec.Command("tmux", "list-keys", "-N", "-T", cfg.Table).Output()
+	if err != nil {
+		return fmt.Errorf("listing %s key bindings: %w", cfg.Table, err)
+	}
+	if !tmuxcmd.Installed(string(out), cfg.Key) {
+		return fmt.Errorf("%s (%s table) is not bound by yule-log lock bind", cfg.Key, cfg.Table)
+	}
+	if cfg.DryRun {
+		fmt.Printf("dry-run: would run: tmux %s\n", tmuxcmd.ShellJoin(b.UnbindArgs()))
+		return nil
+	}
+	if out, err := exec.Command("tmux", b.UnbindArgs()...).CombinedOutput(); err != nil {
+		return fmt.Errorf("unbinding %s: %s", cfg.Key, cmp.Or(strings.TrimSpace(string(out)), err.Error()))
+	}
+	fmt.Printf("Unbound %s (%s table)\n", cfg.Key, cfg.Table)
+	return nil
+}
+
+// flagsAuth returns the --auth value set in lock flags, if any.
+func flagsAuth(args []string) string {
+	var auth string
+	for i, arg := range args {
+		name, value, ok := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != "auth" || !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if !ok && i+1 < len(args) {
+			value = args[i+1]
+		}
+		auth = value
+	}
+	return auth
+}
+
+// execDismiss asks every running screensaver to exit. Lock screens
+// refuse and keep running.
+func execDismiss() error {
+	dir, err := xdg.RuntimeDir()
+	if err != nil {
+		return fmt.Errorf("finding runtime directory: %w", err)
+	}
+	res, err := instance.Dismiss(dir)
+	switch {
+	case res.Dismissed == 0 && res.Locked == 0:
+		fmt.Println("No screensaver running")
+	case res.Locked == 0:
+		fmt.Printf("Dismissed %d screensaver(s)\n", res.Dismissed)
+	default:
+		fmt.Printf("Dismissed %d screensaver(s), %d lock screen(s) left running\n", res.Dismissed, res.Locked)
+	}
+	return err
+}
+
 // ---- Helpers
 
 func clamp(v, min, max int) int {
@@ -726,40 +2993,116 @@ func clamp(v, min, max int) int {
 	return v
 }
 
-func getClientIdleTime(ctx context.Context) (int, error) {
-	cmd := exec.CommandContext(ctx, "tmux", "display-message", "-p", "#{client_activity}")
-	out, err := cmd.Output()
+// tmuxServerPID returns the PID of the tmux server, which names the files
+// of its idle watcher.
+func tmuxServerPID(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "tmux", "display-message", "-p", "#{pid}").Output()
+	if err != nil {
+		return "", fmt.Errorf("get tmux server pid: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// idleSocketPath returns the control socket of the idle watcher of the
+// current tmux server.
+func idleSocketPath(ctx context.Context) (string, error) {
+	server, err := tmuxServerPID(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("get client activity: %w", err)
+		return "", err
 	}
-
-	activityStr := strings.TrimSpace(string(out))
-	if activityStr == "" {
-		return 0, fmt.Errorf("empty activity timestamp")
+	dir, err := xdg.RuntimeDir()
+	if err != nil {
+		return "", err
 	}
+	return idlectl.SocketPath(dir, server), nil
+}
 
-	activityTime, err := strconv.ParseInt(activityStr, 10, 64)
+// clientFocused reports whether the tmux client's terminal has focus, see
+// tmuxcmd.ClientFocused.
+func clientFocused(ctx context.Context) bool {
+	flags, err := exec.CommandContext(ctx, "tmux", "display-message", "-p", "#{client_flags}").Output()
 	if err != nil {
-		return 0, fmt.Errorf("parse activity timestamp: %w", err)
+		return true
 	}
-
-	return max(int(time.Now().Unix()-activityTime), 0), nil
+	focusEvents, err := exec.CommandContext(ctx, "tmux", "show-options", "-gv", "focus-events").Output()
+	if err != nil {
+		return true
+	}
+	return tmuxcmd.ClientFocused(string(flags), string(focusEvents))
 }
 
 type triggerConfig struct {
 	Contribs      bool
+	Theme         string
+	Animation     string
+	Overlay       string
 	NoTicker      bool
 	Lock          bool
 	SocketProtect bool
+	Auth          lock.Auth
+	DryRun        bool
+	ASCII         bool
+	Notify        string
+	Ignite        bool
+	Reveal        bool
+	Mouse         bool
+	Background    string
+	Gradient      string
+	Colors        string
+	Backend       string
+	FlameHeight   string
+	Daylight      bool
+	Clock         bool
+	Alerts        bool
+	EmberAfter    time.Duration
+	MaxLock       time.Duration
+	Client        string // Client the popup opens on, "" for the current one
 }
 
-func triggerScreensaver(ctx context.Context, exePath string, cfg triggerConfig) {
+// negotiateClient adapts the popup to the terminal of the current client:
+// the color depth when left to auto, and ASCII glyphs when it can't draw
+// others. Settings are kept when tmux can't tell.
+func negotiateClient(ctx context.Context, cfg triggerConfig) triggerConfig {
+	out, err := exec.CommandContext(ctx, "tmux", "display-message", "-p", trigger.ClientFormat).Output()
+	if err != nil {
+		return cfg
+	}
+	client, ok := trigger.ParseClient(string(out))
+	if !ok {
+		return cfg
+	}
+	caps := client.Capabilities()
+	cfg.Client = client.Name
+	if caps.Colors != "" && (cfg.Colors == "" || cfg.Colors == "auto") {
+		cfg.Colors = caps.Colors
+	}
+	cfg.ASCII = cfg.ASCII || caps.ASCII
+	if cfg.DryRun {
+		fmt.Printf("dry-run: client %s (%s): colors %s, ascii %t\n", client.Name, client.Term, cmp.Or(cfg.Colors, "auto"), cfg.ASCII)
+	}
+	return cfg
+}
+
+// popupCommand builds the yule-log command line run inside the tmux popup.
+func popupCommand(ctx context.Context, exePath string, cfg triggerConfig) []string {
 	var args []string
 	if cfg.Lock {
-		args = []string{exePath, "lock"}
+		args = []string{exePath, "lock", "--auto"}
 		if !cfg.SocketProtect {
 			args = append(args, "--socket-protect=false")
 		}
+		if cfg.Auth == lock.AuthSystem {
+			args = append(args, "--auth", string(cfg.Auth))
+		}
+		if cfg.Notify != "" && cfg.Notify != string(announce.NotifyOff) {
+			args = append(args, "--notify", cfg.Notify)
+		}
+		if cfg.Reveal {
+			args = append(args, "--reveal")
+		}
+		if cfg.MaxLock > 0 {
+			args = append(args, "--max-lock", cfg.MaxLock.String())
+		}
 	} else {
 		args = []string{exePath, "run"}
 	}
@@ -767,20 +3110,83 @@ func triggerScreensaver(ctx context.Context, exePath string, cfg triggerConfig)
 	if cfg.Contribs {
 		args = append(args, "--contribs")
 	}
+	if cfg.Theme != "" {
+		args = append(args, "--theme", cfg.Theme)
+	}
+	if cfg.Animation != "" {
+		args = append(args, "--animation", cfg.Animation)
+	}
 	if cfg.NoTicker {
 		args = append(args, "--no-ticker")
 	}
+	if cfg.ASCII {
+		args = append(args, "--ascii")
+	}
+	if cfg.Background != "" && cfg.Background != string(termbg.ModeAuto) {
+		args = append(args, "--background", cfg.Background)
+	}
+	if cfg.Gradient != "" && cfg.Gradient != string(palette.GradientAuto) {
+		args = append(args, "--gradient", cfg.Gradient)
+	}
+	if cfg.Colors != "" && cfg.Colors != "auto" {
+		args = append(args, "--colors", cfg.Colors)
+	}
+	if cfg.Backend != "" && cfg.Backend != string(render.BackendTCell) {
+		args = append(args, "--backend", cfg.Backend)
+	}
+	if cfg.FlameHeight != "" && cfg.FlameHeight != defaultFlameHeight {
+		args = append(args, "--flame-height", cfg.FlameHeight)
+	}
+	if cfg.Daylight {
+		args = append(args, "--daylight")
+	}
+	if cfg.Clock {
+		args = append(args, "--clock")
+	}
+	if cfg.Alerts && (!cfg.Lock || !cfg.SocketProtect) {
+		args = append(args, "--alerts") // tmux is out of reach of a protected lock
+	}
+	if cfg.EmberAfter != defaultEmberAfter {
+		args = append(args, "--ember-after", cfg.EmberAfter.String())
+	}
 
 	if !cfg.Lock {
+		if cfg.Ignite {
+			args = append(args, "--ignite")
+		}
+		if cfg.Mouse {
+			args = append(args, "--mouse")
+		}
+		if cfg.Overlay != "" {
+			args = append(args, "--overlay", cfg.Overlay)
+		}
 		panePathCmd := exec.CommandContext(ctx, "tmux", "display-message", "-p", "#{pane_current_path}")
 		if panePathOut, _ := panePathCmd.Output(); len(panePathOut) > 0 {
-			if panePath := strings.TrimSpace(string(panePathOut)); panePath != "" {
+			// Only the newline goes: the path may end with a space.
+			if panePath := strings.TrimSuffix(string(panePathOut), "\n"); panePath != "" {
 				args = append(args, "--dir", panePath)
 			}
 		}
 	}
 
-	cmd := exec.Command("tmux", "display-popup", "-E", "-w", "100%", "-h", "100%", strings.Join(args, " "))
+	return args
+}
+
+func triggerScreensaver(ctx context.Context, exePath string, cfg triggerConfig) {
+	if cfg.Lock && !cfg.Auth.Configured() {
+		// The password was removed since the watcher started: the lock
+		// screen would refuse to start and the popup close at once.
+		fmt.Fprintln(os.Stderr, "idle: no password configured, showing the screensaver instead of the lock screen")
+		cfg.Lock = false
+	}
+	tmuxArgs := tmuxcmd.PopupArgs(cfg.Client, popupCommand(ctx, exePath, cfg))
+
+	if cfg.DryRun {
+		fmt.Printf("dry-run: would run: tmux %s\n", tmuxcmd.ShellJoin(tmuxArgs))
+		return
+	}
+
+	cmd := exec.Command("tmux", tmuxArgs...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -793,209 +3199,206 @@ func triggerScreensaver(ctx context.Context, exePath string, cfg triggerConfig)
 	_ = cmd.Run()
 }
 
-// ---- Git Ticker
-
-func buildGitTickerText(maxCommits int, gitDir string) (string, string, bool) {
-	cmd := exec.Command("git", "log", "-n", strconv.Itoa(maxCommits), "--pretty=format:%h%x09%an%x09%ar%x09%s")
-
-	if gitDir != "" {
-		cmd.Dir = gitDir
-	} else if dir := os.Getenv("YULE_LOG_GIT_DIR"); dir != "" {
-		cmd.Dir = dir
+// runHook runs a user-provided shell command from the idle watcher or an
+// expired lock. Output goes to our stdout/stderr; failures are reported
+// but never stop the caller.
+func runHook(ctx context.Context, command string, dryRun bool) {
+	if dryRun {
+		fmt.Printf("dry-run: would run: sh -c %q\n", command)
+		return
 	}
 
-	out, err := cmd.Output()
-	if err != nil {
-		return "", "", false
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "hook %q failed: %v\n", command, err)
 	}
-	return parseGitLogToTicker(string(out))
 }
 
-func parseGitLogToTicker(logOutput string) (string, string, bool) {
-	lines := strings.Split(strings.TrimSpace(logOutput), "\n")
-	var msgSegs, metaSegs []string
+// ---- Configuration Bundle
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+// bundleFiles lists what a configuration bundle can carry and where each
+// file lives on this machine: the configuration, the user themes called
+// themeNames, and on request the password hash and idle history.
+func bundleFiles(password, history bool, themeNames []string) ([]bundle.File, error) {
+	configPath, err := xdg.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("getting config file path: %w", err)
+	}
+	files := []bundle.File{{
+		Name: "config.toml", Path: configPath, Mode: 0600,
+		Validate: func(data []byte) error { _, err := config.Parse(data); return err },
+	}}
 
-		parts := strings.SplitN(line, "\t", 4)
-		if len(parts) != 4 {
-			continue
+	if len(themeNames) > 0 {
+		dir, err := xdg.ThemesDir()
+		if err != nil {
+			return nil, fmt.Errorf("getting themes directory: %w", err)
 		}
+		for _, name := range themeNames {
+			files = append(files, bundle.File{
+				Name: bundleThemesDir + name + ".toml", Path: filepath.Join(dir, name+".toml"), Mode: 0600,
+				Validate: func(data []byte) error { _, err := themes.Parse(name, data); return err },
+			})
+		}
+	}
 
-		author, relTime, subject := parts[1], parts[2], parts[3]
-		meta := "by " + author + " " + relTime
-
-		width := max(len([]rune(subject)), len([]rune(meta))) + 4
-		msgSegs = append(msgSegs, padRight(subject, width))
-		metaSegs = append(metaSegs, padRight(meta, width))
+	if password {
+		path, err := xdg.PasswordFile()
+		if err != nil {
+			return nil, fmt.Errorf("getting password file path: %w", err)
+		}
+		files = append(files, bundle.File{
+			Name: "passwd", Path: path, Mode: 0600,
+			// The password file of a keychain only points to an item that
+			// doesn't exist on other machines.
+			Validate: func(data []byte) error {
+				file, _, err := lock.ParsePasswordFile(data)
+				if err == nil && file.Store == lock.StoreKeychain {
+					err = lock.ErrKeychainPassword
+				}
+				return err
+			},
+		})
 	}
 
-	if len(msgSegs) == 0 {
-		return "", "", false
+	if history {
+		path, err := xdg.IdleStatsFile()
+		if err != nil {
+			return nil, fmt.Errorf("getting idle stats path: %w", err)
+		}
+		files = append(files, bundle.File{Name: "idle.stats", Path: path, Mode: 0600})
 	}
-	return strings.Join(msgSegs, ""), strings.Join(metaSegs, ""), true
+	return files, nil
 }
 
-func padRight(s string, n int) string {
-	rs := []rune(s)
-	if len(rs) >= n {
-		return s
+// bundleThemesDir holds the user themes in a bundle.
+const bundleThemesDir = "themes/"
+
+// bundledThemes returns the names of the user themes among the entries of
+// a bundle. Entries with invalid names are left out, for Install to
+// reject.
+func bundledThemes(entries []string) []string {
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry, bundleThemesDir)
+		if !ok {
+			continue
+		}
+		if name, ok = strings.CutSuffix(name, ".toml"); ok && themes.ValidName(name) {
+			names = append(names, name)
+		}
 	}
-	return s + strings.Repeat(" ", n-len(rs))
+	return names
 }
 
-// ---- Password Input
+type configExportConfig struct {
+	Path     string // "-" for stdout
+	Password bool   // Include the password hash
+	Stats    bool   // Include the idle history
+}
 
-// readPasswordWithArrows reads a password from stdin with arrow key support.
-// Uses POSIX-secure terminal input via golang.org/x/term.
-// Returns the password bytes or nil if cancelled (Escape or Ctrl+C).
-func readPasswordWithArrows() ([]byte, error) {
-	fd := int(os.Stdin.Fd())
-	if !term.IsTerminal(fd) {
-		return nil, fmt.Errorf("stdin is not a terminal")
+func execConfigExport(cfg configExportConfig) error {
+	if cfg.Password && lock.PasswordStore() == lock.StoreKeychain {
+		return fmt.Errorf("exporting password: %w", lock.ErrKeychainPassword)
 	}
-
-	// Enter raw mode (disables echo and line buffering)
-	oldState, err := term.MakeRaw(fd)
+	var themeNames []string
+	if dir, err := xdg.ThemesDir(); err == nil {
+		themeNames = themes.UserNames(dir)
+	}
+	files, err := bundleFiles(cfg.Password, cfg.Stats, themeNames)
 	if err != nil {
-		return nil, fmt.Errorf("entering raw mode: %w", err)
+		return err
 	}
 
-	// Ensure terminal is restored on exit
-	defer term.Restore(fd, oldState)
-
-	// Handle signals to restore terminal on interrupt
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	defer signal.Stop(sigChan)
+	if cfg.Path == "-" {
+		_, err := bundle.Export(os.Stdout, files, time.Now())
+		return err
+	}
 
-	doneChan := make(chan struct{})
-	defer close(doneChan)
+	f, err := os.OpenFile(cfg.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("creating bundle: %w", err)
+	}
+	names, err := bundle.Export(f, files, time.Now())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(cfg.Path)
+		return err
+	}
 
-	go func() {
-		select {
-		case <-sigChan:
-			term.Restore(fd, oldState)
-			os.Exit(1)
-		case <-doneChan:
-		}
-	}()
+	if len(names) == 0 {
+		fmt.Printf("Exported an empty bundle to %s (nothing configured yet)\n", cfg.Path)
+		return nil
+	}
+	fmt.Printf("Exported %s to %s\n", strings.Join(names, ", "), cfg.Path)
+	return nil
+}
 
-	var password []byte
-	var displayLen int
-	buf := make([]byte, 16)
-	defer lock.ClearBytes(buf)
+type configImportConfig struct {
+	Path   string // "-" for stdin
+	Force  bool   // Replace existing files
+	DryRun bool   // Only list the bundle content
+}
 
-	for {
-		n, err := os.Stdin.Read(buf)
+func execConfigImport(cfg configImportConfig) error {
+	in := os.Stdin
+	if cfg.Path != "-" {
+		f, err := os.Open(cfg.Path)
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				return password, nil
-			}
-			lock.ClearBytes(password)
-			return nil, fmt.Errorf("reading input: %w", err)
+			return fmt.Errorf("opening bundle: %w", err)
 		}
+		defer f.Close()
+		in = f
+	}
 
-		for i := 0; i < n; {
-			b := buf[i]
-
-			switch {
-			case b == '\r' || b == '\n': // Enter
-				fmt.Print("\r\n")
-				return password, nil
-
-			case b == byteEscape: // Escape sequence
-				if i+2 < n && buf[i+1] == '[' {
-					// Arrow key: ESC [ A/B/C/D
-					switch buf[i+2] {
-					case 'A': // Up
-						password = append(password, lock.ArrowUpMarker...)
-						fmt.Print("\033[33m↑\033[0m") // Yellow arrow
-						displayLen++
-						i += 3
-						continue
-					case 'B': // Down
-						password = append(password, lock.ArrowDownMarker...)
-						fmt.Print("\033[33m↓\033[0m")
-						displayLen++
-						i += 3
-						continue
-					case 'C': // Right
-						password = append(password, lock.ArrowRightMarker...)
-						fmt.Print("\033[33m→\033[0m")
-						displayLen++
-						i += 3
-						continue
-					case 'D': // Left
-						password = append(password, lock.ArrowLeftMarker...)
-						fmt.Print("\033[33m←\033[0m")
-						displayLen++
-						i += 3
-						continue
-					}
-				}
-				// Plain Escape key - cancel
-				fmt.Print("\r\n")
-				lock.ClearBytes(password)
-				return nil, nil
-
-			case b == byteCtrlC:
-				fmt.Print("\r\n")
-				lock.ClearBytes(password)
-				return nil, fmt.Errorf("interrupted")
-
-			case b == byteBackspace || b == byteDelete:
-				if len(password) > 0 && displayLen > 0 {
-					password = handlePasswordBackspace(password)
-					displayLen--
-					// Erase last character from display
-					fmt.Print("\b \b")
-				}
-
-			case b >= bytePrintableStart && b < bytePrintableEnd: // Printable ASCII
-				password = append(password, b)
-				fmt.Print("*")
-				displayLen++
-
-			default:
-				// Ignore other control characters
-			}
+	b, err := bundle.Read(in)
+	if err != nil {
+		return err
+	}
+	// Accept every file a bundle can carry; the bundle decides.
+	files, err := bundleFiles(true, true, bundledThemes(b.Manifest.Files))
+	if err != nil {
+		return err
+	}
 
-			i++
+	if cfg.DryRun {
+		fmt.Printf("dry-run: bundle version %d from %s\n", b.Manifest.Version, b.Manifest.Created.Local().Format(time.DateTime))
+		for _, name := range b.Manifest.Files {
+			fmt.Printf("dry-run: would import %s\n", name)
 		}
+		return nil
 	}
-}
 
-// handlePasswordBackspace removes the last character/marker from password.
-func handlePasswordBackspace(password []byte) []byte {
-	if len(password) == 0 {
-		return password
+	installed, err := b.Install(files, cfg.Force)
+	if errors.Is(err, bundle.ErrExists) {
+		return fmt.Errorf("%w (use --force to replace)", err)
 	}
-
-	// Handle multi-byte arrow markers
-	if len(password) >= 2 {
-		last2 := string(password[len(password)-2:])
-		if last2 == lock.ArrowUpMarker || last2 == lock.ArrowDownMarker ||
-			last2 == lock.ArrowLeftMarker || last2 == lock.ArrowRightMarker {
-			return password[:len(password)-2]
-		}
+	if err != nil {
+		return err
+	}
+	if len(installed) == 0 {
+		fmt.Println("The bundle is empty, nothing imported.")
+		return nil
 	}
-	return password[:len(password)-1]
+	fmt.Printf("Imported %s\n", strings.Join(installed, ", "))
+	return nil
 }
 
 // ---- CLI Setup
 
 func main() {
+	defer guard()
 	if err := run(); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			os.Exit(0)
 		}
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		msg, code := errfmt.Format(err)
+		fmt.Fprintln(os.Stderr, msg)
+		os.Exit(code)
 	}
 }
 
@@ -1004,36 +3407,244 @@ func run() error {
 	return rootCmd.ParseAndRun(context.Background(), os.Args[1:])
 }
 
+// envVarPrefix makes every flag settable from the environment: --no-ticker
+// is YULE_LOG_NO_TICKER, --event-min-interval YULE_LOG_EVENT_MIN_INTERVAL.
+// Command line flags take precedence.
+const envVarPrefix = "YULE_LOG"
+
+var envOptions = []ff.Option{ff.WithEnvVarPrefix(envVarPrefix)}
+
+// configOptions adds the command's table of the global config file as the
+// lowest-precedence source of flag values, below the environment.
+func configOptions(table string) []ff.Option {
+	opts := append([]ff.Option{}, envOptions...)
+	path, err := xdg.ConfigFile()
+	if err != nil {
+		return opts
+	}
+	return append(opts,
+		ff.WithConfigFile(path),
+		ff.WithConfigFileParser(config.FlagParser(path, table)),
+		ff.WithAllowMissingConfigFile(true),
+	)
+}
+
+// dirList is a flag given several times, or as a comma-separated list
+// like the lists of the config file.
+type dirList []string
+
+func (d *dirList) String() string {
+	return strings.Join(*d, ",")
+}
+
+func (d *dirList) Set(value string) error {
+	for _, dir := range strings.Split(value, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			*d = append(*d, dir)
+		}
+	}
+	return nil
+}
+
 func buildCLI() *ffcli.Command {
 	// Run command
 	runFlagSet := flag.NewFlagSet("yule-log run", flag.ExitOnError)
 	runContribs := runFlagSet.Bool("contribs", false, "Use GitHub contribution graph-style visualization")
-	runGitDir := runFlagSet.String("dir", "", "Git directory for commit ticker (defaults to current dir or YULE_LOG_GIT_DIR)")
+	runTheme := runFlagSet.String("theme", "", "Theme name: fire, contribs or a file in the themes config directory (overrides --contribs)")
+	var runGitDirs dirList
+	runFlagSet.Var(&runGitDirs, "dir", "Git directory for commit ticker (defaults to current dir or YULE_LOG_GIT_DIR); repeat it to interleave several repositories")
 	runNoTicker := runFlagSet.Bool("no-ticker", false, "Disable git commit ticker (fire animation only)")
 	runPlayground := runFlagSet.Bool("playground", false, "Playground mode: only ESC exits, all keys affect fire")
 	runCooldown := runFlagSet.String("cooldown", string(fire.DefaultCooldown), "Fire cooldown speed: fast, medium, slow")
 	runLock := runFlagSet.Bool("lock", false, "Lock mode: require password to exit")
 	runIntensity := runFlagSet.Int("intensity", fire.BaseHeatPower, "Base fire intensity (default 75, lower = smaller flames)")
+	runBurnDown := runFlagSet.Duration("burn-down", 0, "Start roaring and burn down to embers over this long, e.g. 2h for an evening (0 = steady)")
+	runASCII := runFlagSet.Bool("ascii", false, "Only use ASCII glyphs (auto-enabled on non-UTF-8 locales)")
+	runAnnounce := runFlagSet.String("announce", "", "Announce state changes as text: off, stderr, osc (default from "+announce.EnvVar+")")
+	runAnimation := runFlagSet.String("animation", "", "Background animation: "+strings.Join(animationNames(), ", ")+" or cycle (default: the mode's [animation] config entry, or fire)")
+	runCycleInterval := runFlagSet.Duration("cycle-interval", defaultCycleInterval, "Time per animation in cycle mode")
+	runTransmitEvery := runFlagSet.Int("transmit-every", 1, "Only send one frame out of N to the terminal (for slow links)")
+	runAutoRate := runFlagSet.Bool("auto-rate", false, "Adapt --transmit-every to the terminal's write latency")
+	runBlend := runFlagSet.Bool("blend", false, "Blend skipped frames when --transmit-every > 1")
+	runSSHFriendly := runFlagSet.Bool("ssh-friendly", false, "Minimize terminal bandwidth: fewer frames, reduced palette, stepped ticker")
+	runBandwidthMeter := runFlagSet.Bool("bandwidth-meter", false, "Show an overlay with changed cells per frame")
+	runEvents := runFlagSet.String("events", "all", "Random events: comma-separated sparks, flare, wind, or all/none")
+	runEventMin := runFlagSet.Duration("event-min-interval", defaultEventMinInterval, "Minimum time between random events")
+	runEventMax := runFlagSet.Duration("event-max-interval", defaultEventMaxInterval, "Maximum time between random events")
+	runDaylight := runFlagSet.Bool("daylight", false, "Shift the palette warmer in the evening and cooler in the morning ([daylight] location in config)")
+	runClock := runFlagSet.Bool("clock", false, "Show the time in big digits over the fire")
+	runAlerts := runFlagSet.Bool("alerts", false, "Show tmux windows ringing their bell or flagged by monitor-activity in the top-right corner")
+	runWeather := runFlagSet.Bool("weather", false, "Show the weather above the ticker, from wttr.in or the [weather] config table")
+	runEmberAfter := runFlagSet.Duration("ember-after", defaultEmberAfter, "Drop to a low-CPU ember state after this long without input (0 = never)")
+	runIgnite := runFlagSet.Bool("ignite", false, "Burn the current pane content away before the fire takes over")
+	runReveal := runFlagSet.Bool("reveal", false, "With --lock, reveal the pane content through the dying fire on unlock")
+	runDuration := runFlagSet.Duration("duration", 0, "Exit after this long, revealing the pane through the dying fire, e.g. 10m for a break timer (not with --lock)")
+	runMouse := runFlagSet.Bool("mouse", false, "Enable the mouse: hovering the ticker pauses it, clicking a commit copies its hash to the tmux buffer")
+	runOverlay := runFlagSet.String("overlay", "", "Overlay shown from the start: sessions, the tmux sessions and windows with activity (Tab toggles it)")
+	runTickerClickExec := runFlagSet.String("ticker-click-exec", "", "With --mouse, shell command run on ticker clicks instead of copying ($"+commitEnvVar+" holds the hash)")
+	runBackground := runFlagSet.String("background", string(termbg.ModeAuto), "Terminal background: auto (OSC 11 query), dark or light")
+	runGradient := runFlagSet.String("gradient", string(palette.GradientAuto), "Smooth color gradient between theme stops: auto (truecolor terminals), on or off")
+	runFlameHeight := runFlagSet.String("flame-height", defaultFlameHeight, "How far up the screen flames reach, e.g. 60% to keep the top clear on tall terminals")
+	runColors := runFlagSet.String("colors", "auto", "Terminal colors: auto (detected), truecolor, 256 or 16, for the theme's fallback palettes")
+	runBackend := runFlagSet.String("backend", string(render.BackendTCell), "Screen backend: tcell (terminfo) or vt (direct escape sequences, no mouse); vt with --size writes the frames to stdout")
+	runBrightness := runFlagSet.Float64("brightness", 1, "Palette brightness multiplier")
+	runContrast := runFlagSet.Float64("contrast", 1, "Palette contrast multiplier around mid-gray")
+	runGamma := runFlagSet.Float64("gamma", 1, "Palette gamma (above 1 brightens mid-tones)")
+	runMaxCommits := runFlagSet.Int("max-commits", 0, "Number of commits in the ticker (overrides config files, 0 = from config)")
+	runTickerBranch := runFlagSet.Bool("ticker-branch", true, "Show the current branch in the ticker meta row")
+	runTickerTag := runFlagSet.Bool("ticker-tag", true, "Show the nearest tag in the ticker meta row")
+	runTickerDirty := runFlagSet.Bool("ticker-dirty", true, "Mark uncommitted changes in the ticker meta row")
+	runTickerCmd := runFlagSet.String("ticker-cmd", "", "Shell command whose output lines scroll in the ticker, after the other sources")
+	runFrames := runFlagSet.Int("frames", 0, "Render this many frames then exit 0, for smoke tests (headless when not on a terminal)")
+	runSize := runFlagSet.String("size", "", "With --frames, render headless at this size, e.g. 80x24")
+	runTraceStartup := runFlagSet.Bool("trace-startup", false, "Print how long each startup phase took, up to the first frame, on exit")
 
 	runCmd := &ffcli.Command{
 		Name:       "run",
 		ShortUsage: "yule-log run [flags]",
 		ShortHelp:  "Run the screensaver",
 		FlagSet:    runFlagSet,
+		Options:    configOptions("run"),
 		Exec: func(_ context.Context, _ []string) error {
-			mode := ModeNormal
+			announcer, err := announce.New(announce.ModeFromEnv(*runAnnounce))
+			if err != nil {
+				return err
+			}
+			events, err := fire.ParseEventKinds(*runEvents)
+			if err != nil {
+				return err
+			}
+			if err := validateAnimation(*runAnimation); err != nil {
+				return err
+			}
+			if err := validateOverlay(*runOverlay); err != nil {
+				return err
+			}
+			if err := palette.ValidateLevels(*runBrightness, *runContrast, *runGamma); err != nil {
+				return err
+			}
+			background, err := termbg.ParseMode(*runBackground)
+			if err != nil {
+				return err
+			}
+			gradient, err := palette.ParseGradient(*runGradient)
+			if err != nil {
+				return err
+			}
+			colors, err := palette.ParseDepth(*runColors)
+			if err != nil {
+				return err
+			}
+			flameHeight, err := fire.ParseFlameHeight(*runFlameHeight)
+			if err != nil {
+				return err
+			}
+			backend, err := render.ParseBackend(*runBackend)
+			if err != nil {
+				return err
+			}
+			cfg := screensaverConfig{
+				contribs:   *runContribs,
+				theme:      *runTheme,
+				gitDirs:    runGitDirs,
+				noTicker:   *runNoTicker,
+				cooldown:   fire.CooldownSpeed(*runCooldown),
+				intensity:  *runIntensity,
+				burnDown:   *runBurnDown,
+				maxCommits: *runMaxCommits,
+				ascii:      *runASCII || unicodeUnsupported(),
+				announcer:  announcer,
+
+				noTickerBranch: !*runTickerBranch,
+				noTickerTag:    !*runTickerTag,
+				noTickerDirty:  !*runTickerDirty,
+				tickerCmd:      *runTickerCmd,
+
+				animation:     *runAnimation,
+				cycleInterval: *runCycleInterval,
+				overlay:       *runOverlay,
+
+				transmitEvery:  *runTransmitEvery,
+				autoRate:       *runAutoRate,
+				blend:          *runBlend,
+				bandwidthMeter: *runBandwidthMeter,
+
+				events:           events,
+				eventMinInterval: *runEventMin,
+				eventMaxInterval: *runEventMax,
+
+				ignite: *runIgnite,
+				reveal: *runReveal,
+
+				emberAfter: *runEmberAfter,
+
+				mouse:           *runMouse,
+				tickerClickExec: *runTickerClickExec,
+
+				background:  background,
+				gradient:    gradient,
+				colors:      colors,
+				flameHeight: flameHeight,
+				daylight:    *runDaylight,
+				clock:       *runClock,
+				alerts:      *runAlerts,
+				weather:     *runWeather,
+				brightness:  *runBrightness,
+				contrast:    *runContrast,
+				gamma:       *runGamma,
+
+				backend: backend,
+			}
+			if *runSSHFriendly {
+				cfg.applySSHFriendly()
+			}
+			switch {
+			case *runBurnDown < 0:
+				return fmt.Errorf("--burn-down must not be negative, got %s", *runBurnDown)
+			case *runDuration < 0:
+				return fmt.Errorf("--duration must not be negative, got %s", *runDuration)
+			case *runDuration > 0 && *runLock:
+				return fmt.Errorf("--duration cannot end a lock screen, only the password does")
+			}
+			cfg.duration = *runDuration
 			if *runLock {
-				mode = ModeLock
+				cfg.mode = ModeLock
 			} else if *runPlayground {
-				mode = ModePlayground
+				cfg.mode = ModePlayground
 			}
-			return execScreensaver(screensaverConfig{
-				mode:      mode,
-				contribs:  *runContribs,
-				gitDir:    *runGitDir,
-				noTicker:  *runNoTicker,
-				cooldown:  fire.CooldownSpeed(*runCooldown),
-				intensity: *runIntensity,
+			if err := cfg.applyTestMode(*runFrames, *runSize); err != nil {
+				return err
+			}
+			if *runTraceStartup {
+				cfg.trace = newStartupTrace()
+			}
+			err = execScreensaver(cfg)
+			if !errors.Is(err, errLockRequested) {
+				return err
+			}
+			// The palette's lock command: the same fire, behind the password
+			return execLock(lockConfig{
+				SocketProtect: true,
+				Contribs:      cfg.contribs,
+				Theme:         cfg.theme,
+				NoTicker:      cfg.noTicker,
+				Cooldown:      cfg.cooldown,
+				ASCII:         cfg.ascii,
+				Announcer:     cfg.announcer,
+				Events:        cfg.events,
+				Animation:     cfg.animation,
+				Reveal:        cfg.reveal,
+				Background:    cfg.background,
+				Gradient:      cfg.gradient,
+				Colors:        cfg.colors,
+				FlameHeight:   cfg.flameHeight,
+				Daylight:      cfg.daylight,
+				Clock:         cfg.clock,
+				EmberAfter:    cfg.emberAfter,
+				Brightness:    cfg.brightness,
+				Contrast:      cfg.contrast,
+				Gamma:         cfg.gamma,
+				Backend:       cfg.backend,
 			})
 		},
 	}
@@ -1043,23 +3654,143 @@ func buildCLI() *ffcli.Command {
 	idleTimeout := idleFlagSet.Int("timeout", defaultIdleTimeout, "Idle timeout in seconds before triggering screensaver")
 	idleOnce := idleFlagSet.Bool("once", false, "Trigger screensaver immediately and exit")
 	idleContribs := idleFlagSet.Bool("contribs", false, "Use GitHub contribution graph-style visualization")
+	idleTheme := idleFlagSet.String("theme", "", "Screensaver theme name (overrides --contribs)")
+	idleAnimation := idleFlagSet.String("animation", "", "Screensaver background animation: "+strings.Join(animationNames(), ", ")+" or cycle (default: the mode's [animation] config entry, or fire)")
 	idleNoTicker := idleFlagSet.Bool("no-ticker", false, "Disable git commit ticker")
 	idleLock := idleFlagSet.Bool("lock", false, "Trigger lock screen instead of screensaver on idle")
 	idleSocketProtect := idleFlagSet.Bool("socket-protect", true, "Restrict tmux socket permissions during lock")
+	idleAuth := idleFlagSet.String("auth", string(lock.AuthFile), "Lock screen password: file (set with lock set-password) or system (macOS login password)")
+	idleASCII := idleFlagSet.Bool("ascii", false, "Only use ASCII glyphs in the screensaver")
+	idleNotify := idleFlagSet.String("notify", string(announce.NotifyOff), "Desktop notifications from the lock screen: off, desktop, osc777")
+	idleIgnite := idleFlagSet.Bool("ignite", false, "Burn the pane content away when the screensaver opens")
+	idleReveal := idleFlagSet.Bool("reveal", false, "Reveal the pane content through the dying fire on unlock (with --lock)")
+	idleBackground := idleFlagSet.String("background", string(termbg.ModeAuto), "Terminal background for the screensaver: auto, dark or light")
+	idleGradient := idleFlagSet.String("gradient", string(palette.GradientAuto), "Smooth color gradient in the screensaver: auto, on or off")
+	idleFlameHeight := idleFlagSet.String("flame-height", defaultFlameHeight, "How far up the screen the screensaver flames reach, e.g. 60%")
+	idleColors := idleFlagSet.String("colors", "auto", "Terminal colors for the screensaver: auto, truecolor, 256 or 16")
+	idleBackend := idleFlagSet.String("backend", string(render.BackendTCell), "Screen backend of the screensaver: tcell or vt (direct escape sequences)")
+	idleMaxLock := idleFlagSet.Duration("max-lock", 0, "Detach all clients when the lock screen stays up longer than this (with --lock, 0 = never)")
+	idleEmberAfter := idleFlagSet.Duration("ember-after", defaultEmberAfter, "Screensaver drops to a low-CPU ember state after this long without input (0 = never)")
+	idleDaylight := idleFlagSet.Bool("daylight", false, "Shift the palette warmer in the evening and cooler in the morning")
+	idleClock := idleFlagSet.Bool("clock", false, "Show the time in big digits over the screensaver fire")
+	idleAlerts := idleFlagSet.Bool("alerts", false, "Show tmux windows ringing their bell or flagged by monitor-activity in the screensaver")
+	idleOverlay := idleFlagSet.String("overlay", "", "Overlay shown in the screensaver from the start: sessions (Tab toggles it)")
+	idleMouse := idleFlagSet.Bool("mouse", false, "Enable ticker clicks in the screensaver")
+	idleSkipUnfocused := idleFlagSet.Bool("skip-unfocused", true, "Don't trigger while the client terminal is unfocused (needs tmux focus-events)")
+	idleClientCaps := idleFlagSet.Bool("client-caps", true, "Adapt the screensaver colors and glyphs to the client terminal reported by tmux (unless --colors or --ascii is set)")
+	idleExec := idleFlagSet.String("exec", "", "Shell command to run on idle instead of showing the screensaver")
+	idleExecWake := idleFlagSet.String("exec-wake", "", "Shell command to run when activity resumes after an idle trigger")
+	idleSequence := idleFlagSet.String("sequence", "", "Escalate on consecutive triggers within an hour, e.g. screensaver,contribs,lock")
+	idleLockTimeout := idleFlagSet.Int("lock-timeout", 0, "Replace the screensaver with the lock screen after this many idle seconds (0 = never, needs a password)")
+	idleSource := idleFlagSet.String("source", idlesrc.NameTmux, "Activity that counts: tmux (keys typed in tmux), system (keyboard and mouse anywhere, X11, Wayland or macOS) or both")
+	idleDryRun := idleFlagSet.Bool("dry-run", false, "Log when the screensaver would trigger and the tmux command, without running it")
+
+	idleStatusFlagSet := flag.NewFlagSet("yule-log idle status", flag.ExitOnError)
+	idleStatusASCII := idleStatusFlagSet.Bool("ascii", false, "Draw the sparkline with ASCII characters")
+
+	idleStatusCmd := &ffcli.Command{
+		Name:       "status",
+		ShortUsage: "yule-log idle status [flags]",
+		ShortHelp:  "Show idle durations and triggers over the last 24h",
+		FlagSet:    idleStatusFlagSet,
+		Options:    envOptions,
+		Exec: func(_ context.Context, _ []string) error {
+			return execIdleStatus(*idleStatusASCII || unicodeUnsupported())
+		},
+	}
+
+	idleNotifyCmd := &ffcli.Command{
+		Name:       "notify",
+		ShortUsage: "yule-log idle notify",
+		ShortHelp:  "Report shell activity to the idle watcher (see yule-log hook)",
+		Exec: func(ctx context.Context, _ []string) error {
+			path, err := idleSocketPath(ctx)
+			if err != nil {
+				return err
+			}
+			return idlectl.Notify(path)
+		},
+	}
 
 	idleCmd := &ffcli.Command{
-		Name:       "idle",
-		ShortUsage: "yule-log idle [flags]",
-		ShortHelp:  "Run idle watcher daemon",
-		FlagSet:    idleFlagSet,
+		Name:        "idle",
+		ShortUsage:  "yule-log idle [flags] [<subcommand>]",
+		ShortHelp:   "Run idle watcher daemon",
+		FlagSet:     idleFlagSet,
+		Options:     configOptions("idle"),
+		Subcommands: []*ffcli.Command{idleStatusCmd, idleNotifyCmd},
 		Exec: func(_ context.Context, _ []string) error {
+			if _, err := termbg.ParseMode(*idleBackground); err != nil {
+				return err
+			}
+			if _, err := palette.ParseGradient(*idleGradient); err != nil {
+				return err
+			}
+			if _, err := palette.ParseDepth(*idleColors); err != nil {
+				return err
+			}
+			if _, err := render.ParseBackend(*idleBackend); err != nil {
+				return err
+			}
+			if _, err := fire.ParseFlameHeight(*idleFlameHeight); err != nil {
+				return err
+			}
+			if *idleTheme != "" {
+				if _, err := loadTheme(*idleTheme); err != nil {
+					return err
+				}
+			}
+			if err := validateAnimation(*idleAnimation); err != nil {
+				return err
+			}
+			if err := validateOverlay(*idleOverlay); err != nil {
+				return err
+			}
+			auth, err := lock.ParseAuth(*idleAuth)
+			if err != nil {
+				return err
+			}
+			var sequence []trigger.Style
+			if *idleSequence != "" {
+				var err error
+				if sequence, err = trigger.ParseSequence(*idleSequence); err != nil {
+					return err
+				}
+			}
 			return execIdle(idleConfig{
 				Timeout:       *idleTimeout,
 				Once:          *idleOnce,
 				Contribs:      *idleContribs,
+				Theme:         *idleTheme,
+				Animation:     *idleAnimation,
+				Overlay:       *idleOverlay,
 				NoTicker:      *idleNoTicker,
 				Lock:          *idleLock,
 				SocketProtect: *idleSocketProtect,
+				Auth:          auth,
+				DryRun:        *idleDryRun,
+				ASCII:         *idleASCII,
+				Notify:        *idleNotify,
+				Ignite:        *idleIgnite,
+				Reveal:        *idleReveal,
+				Mouse:         *idleMouse,
+				Background:    *idleBackground,
+				Gradient:      *idleGradient,
+				Colors:        *idleColors,
+				Backend:       *idleBackend,
+				FlameHeight:   *idleFlameHeight,
+				Daylight:      *idleDaylight,
+				Clock:         *idleClock,
+				Alerts:        *idleAlerts,
+				EmberAfter:    *idleEmberAfter,
+				MaxLock:       *idleMaxLock,
+				SkipUnfocused: *idleSkipUnfocused,
+				ClientCaps:    *idleClientCaps,
+				Exec:          *idleExec,
+				ExecWake:      *idleExecWake,
+				Sequence:      sequence,
+				Source:        *idleSource,
+				LockTimeout:   *idleLockTimeout,
 			})
 		},
 	}
@@ -1067,15 +3798,68 @@ func buildCLI() *ffcli.Command {
 	// Lock command and subcommands
 	lockFlagSet := flag.NewFlagSet("yule-log lock", flag.ExitOnError)
 	lockSocketProtect := lockFlagSet.Bool("socket-protect", true, "Restrict tmux socket permissions during lock")
+	lockAuth := lockFlagSet.String("auth", string(lock.AuthFile), "Password to unlock with: file (set with lock set-password) or system (macOS login password)")
 	lockContribs := lockFlagSet.Bool("contribs", false, "Use GitHub contribution graph-style visualization")
+	lockTheme := lockFlagSet.String("theme", "", "Theme name: fire, contribs or a file in the themes config directory (overrides --contribs)")
 	lockNoTicker := lockFlagSet.Bool("no-ticker", false, "Disable git commit ticker")
 	lockCooldown := lockFlagSet.String("cooldown", string(fire.DefaultCooldown), "Fire cooldown speed: fast, medium, slow")
+	lockASCII := lockFlagSet.Bool("ascii", false, "Only use ASCII glyphs (auto-enabled on non-UTF-8 locales)")
+	lockAnnounce := lockFlagSet.String("announce", "", "Announce lock state changes as text: off, stderr, osc (default from "+announce.EnvVar+")")
+	lockNotify := lockFlagSet.String("notify", string(announce.NotifyOff), "Desktop notifications on auto-lock, failed attempts and unlock: off, desktop, osc777")
+	lockNotifyThreshold := lockFlagSet.Int("notify-threshold", 3, "Failed attempts before notifying")
+	lockAnimation := lockFlagSet.String("animation", "", "Background animation: "+strings.Join(animationNames(), ", ")+" or cycle (default: the mode's [animation] config entry, or fire)")
+	lockReveal := lockFlagSet.Bool("reveal", false, "Reveal the pane content through the dying fire on unlock")
+	lockBackground := lockFlagSet.String("background", string(termbg.ModeAuto), "Terminal background: auto (OSC 11 query), dark or light")
+	lockGradient := lockFlagSet.String("gradient", string(palette.GradientAuto), "Smooth color gradient between theme stops: auto (truecolor terminals), on or off")
+	lockFlameHeight := lockFlagSet.String("flame-height", defaultFlameHeight, "How far up the screen flames reach, e.g. 60% to keep the top clear on tall terminals")
+	lockColors := lockFlagSet.String("colors", "auto", "Terminal colors: auto (detected), truecolor, 256 or 16, for the theme's fallback palettes")
+	lockBackend := lockFlagSet.String("backend", string(render.BackendTCell), "Screen backend: tcell (terminfo) or vt (direct escape sequences, no mouse)")
+	lockEmberAfter := lockFlagSet.Duration("ember-after", defaultEmberAfter, "Drop to a low-CPU ember state after this long without input (0 = never)")
+	lockDaylight := lockFlagSet.Bool("daylight", false, "Shift the palette warmer in the evening and cooler in the morning")
+	lockClock := lockFlagSet.Bool("clock", false, "Show the time in big digits over the fire")
+	lockAlerts := lockFlagSet.Bool("alerts", false, "Show tmux windows ringing their bell or flagged by monitor-activity (needs --socket-protect=false)")
+	lockBrightness := lockFlagSet.Float64("brightness", 1, "Palette brightness multiplier")
+	lockContrast := lockFlagSet.Float64("contrast", 1, "Palette contrast multiplier around mid-gray")
+	lockGamma := lockFlagSet.Float64("gamma", 1, "Palette gamma (above 1 brightens mid-tones)")
+	lockMaxLock := lockFlagSet.Duration("max-lock", 0, "Detach all clients when still locked after this long, e.g. 8h (0 = never)")
+	lockMaxLockExec := lockFlagSet.String("max-lock-exec", "", "Shell command to run before detaching on --max-lock, e.g. to kill the ssh-agent")
+	lockEvents := lockFlagSet.String("events", "all", "Random events: comma-separated sparks, flare, wind, or all/none")
+	lockRhythm := lockFlagSet.Bool("experimental-rhythm", false, "EXPERIMENTAL: also require the typing rhythm recorded by set-password --experimental-rhythm")
+	lockRhythmTolerance := lockFlagSet.Float64("rhythm-tolerance", lock.DefaultRhythmTolerance, "With --experimental-rhythm, how far the rhythm may drift (0 = exact, 1 = anything)")
+	lockOverlay := lockFlagSet.String("overlay", "", "Overlay shown from the start: sessions (Tab toggles it, needs --socket-protect=false)")
+	lockSoftLockPane := lockFlagSet.String("soft-lock-pane", "", "Soft lock: keep this tmux pane (e.g. %3 or music:0.1) visible, scrollable with Ctrl+U/Ctrl+D (needs --socket-protect=false)")
+	lockAuto := lockFlagSet.Bool("auto", false, "Mark the lock as engaged by the idle watcher")
+	lockYes := lockFlagSet.Bool("yes", false, "Lock without asking when other clients are attached")
+	lockAllClients := lockFlagSet.Bool("all-clients", false, "Also open the lock screen over every other attached client")
+	lockFollow := lockFlagSet.Bool("follow", false, "Show the lock engaged by another client (opened by --all-clients)")
+	lockDryRun := lockFlagSet.Bool("dry-run", false, "Print the socket and state changes the lock would make, without locking")
+
+	setPasswordFlagSet := flag.NewFlagSet("yule-log lock set-password", flag.ExitOnError)
+	setPasswordASCII := setPasswordFlagSet.Bool("ascii", false, "Echo arrow keys as ^ v < > (auto-enabled on non-UTF-8 locales)")
+	setPasswordStdin := setPasswordFlagSet.Bool("stdin", false, "Read the password from the first line of stdin (non-interactive)")
+	setPasswordFromFile := setPasswordFlagSet.String("from-file", "", "Read the password from the first line of a file (non-interactive)")
+	setPasswordForce := setPasswordFlagSet.Bool("force", false, "Replace an existing password without confirmation (non-interactive only)")
+	setPasswordRhythm := setPasswordFlagSet.Bool("experimental-rhythm", false, "EXPERIMENTAL: also record the typing rhythm, for lock --experimental-rhythm")
+	setPasswordDuress := setPasswordFlagSet.Bool("duress", false, "Set the duress password: typed on the lock screen, it kills the tmux server instead of unlocking")
+	setPasswordClearDuress := setPasswordFlagSet.Bool("clear-duress", false, "Remove the duress password")
 
 	setPasswordCmd := &ffcli.Command{
 		Name:       "set-password",
-		ShortUsage: "yule-log lock set-password",
+		ShortUsage: "yule-log lock set-password [flags]",
 		ShortHelp:  "Set or update the lock password",
-		Exec:       func(_ context.Context, _ []string) error { return execSetPassword() },
+		FlagSet:    setPasswordFlagSet,
+		Options:    envOptions,
+		Exec: func(_ context.Context, _ []string) error {
+			return execSetPassword(setPasswordConfig{
+				ASCII:       *setPasswordASCII || unicodeUnsupported(),
+				Stdin:       *setPasswordStdin,
+				FromFile:    *setPasswordFromFile,
+				Force:       *setPasswordForce,
+				Rhythm:      *setPasswordRhythm,
+				Duress:      *setPasswordDuress,
+				ClearDuress: *setPasswordClearDuress,
+			})
+		},
 	}
 
 	lockStatusCmd := &ffcli.Command{
@@ -1085,29 +3869,314 @@ func buildCLI() *ffcli.Command {
 		Exec:       func(_ context.Context, _ []string) error { return execLockStatus() },
 	}
 
+	lockAttemptsFlagSet := flag.NewFlagSet("yule-log lock attempts", flag.ExitOnError)
+	lockAttemptsN := lockAttemptsFlagSet.Int("n", attemptsLimit, "Number of entries to show (0 for all)")
+
+	lockAttemptsCmd := &ffcli.Command{
+		Name:       "attempts",
+		ShortUsage: "yule-log lock attempts [-n count]",
+		ShortHelp:  "Show recent unlock attempts",
+		FlagSet:    lockAttemptsFlagSet,
+		Exec:       func(_ context.Context, _ []string) error { return execLockAttempts(*lockAttemptsN) },
+	}
+
+	lockRecoverFlagSet := flag.NewFlagSet("yule-log lock recover", flag.ExitOnError)
+	lockRecoverForce := lockRecoverFlagSet.Bool("force", false, "Lift the lock even if its process still runs")
+
+	lockRecoverCmd := &ffcli.Command{
+		Name:       "recover",
+		ShortUsage: "yule-log lock recover [--force]",
+		ShortHelp:  "Restore the socket of a lock whose process was killed",
+		FlagSet:    lockRecoverFlagSet,
+		Exec:       func(_ context.Context, _ []string) error { return execLockRecover(*lockRecoverForce) },
+	}
+
+	lockBindFlagSet := flag.NewFlagSet("yule-log lock bind", flag.ExitOnError)
+	lockBindKey := lockBindFlagSet.String("key", "C-l", "tmux key to bind, e.g. C-l or M-l")
+	lockBindTable := lockBindFlagSet.String("table", "prefix", "Key table: prefix (after the prefix key) or root (no prefix)")
+	lockBindPrompt := lockBindFlagSet.String("prompt", defaultBindPrompt, "Confirmation prompt shown before locking")
+	lockBindDryRun := lockBindFlagSet.Bool("dry-run", false, "Print the tmux command instead of running it")
+
+	lockBindCmd := &ffcli.Command{
+		Name:       "bind",
+		ShortUsage: "yule-log lock bind [flags] [-- lock flags...]",
+		ShortHelp:  "Bind a tmux key to the lock screen, after a confirmation",
+		FlagSet:    lockBindFlagSet,
+		Exec: func(_ context.Context, args []string) error {
+			return execLockBind(bindConfig{
+				Key:       *lockBindKey,
+				Table:     *lockBindTable,
+				Prompt:    *lockBindPrompt,
+				LockFlags: args,
+				DryRun:    *lockBindDryRun,
+			})
+		},
+	}
+
+	lockUnbindFlagSet := flag.NewFlagSet("yule-log lock unbind", flag.ExitOnError)
+	lockUnbindKey := lockUnbindFlagSet.String("key", "C-l", "tmux key bound by lock bind")
+	lockUnbindTable := lockUnbindFlagSet.String("table", "prefix", "Key table of the binding")
+	lockUnbindDryRun := lockUnbindFlagSet.Bool("dry-run", false, "Print the tmux command instead of running it")
+
+	lockUnbindCmd := &ffcli.Command{
+		Name:       "unbind",
+		ShortUsage: "yule-log lock unbind [flags]",
+		ShortHelp:  "Remove a binding installed by lock bind",
+		FlagSet:    lockUnbindFlagSet,
+		Exec: func(_ context.Context, _ []string) error {
+			return execLockUnbind(bindConfig{Key: *lockUnbindKey, Table: *lockUnbindTable, DryRun: *lockUnbindDryRun})
+		},
+	}
+
 	lockCmd := &ffcli.Command{
 		Name:        "lock",
 		ShortUsage:  "yule-log lock [flags]",
 		ShortHelp:   "Lock the tmux session",
 		FlagSet:     lockFlagSet,
-		Subcommands: []*ffcli.Command{setPasswordCmd, lockStatusCmd},
+		Options:     configOptions("lock"),
+		Subcommands: []*ffcli.Command{setPasswordCmd, lockStatusCmd, lockAttemptsCmd, lockRecoverCmd, lockBindCmd, lockUnbindCmd},
 		Exec: func(_ context.Context, _ []string) error {
+			announcer, err := announce.New(announce.ModeFromEnv(*lockAnnounce))
+			if err != nil {
+				return err
+			}
+			notifier, err := announce.NewNotifier(announce.NotifyMode(*lockNotify), *lockNotifyThreshold)
+			if err != nil {
+				return err
+			}
+			events, err := fire.ParseEventKinds(*lockEvents)
+			if err != nil {
+				return err
+			}
+			if err := validateAnimation(*lockAnimation); err != nil {
+				return err
+			}
+			if err := validateOverlay(*lockOverlay); err != nil {
+				return err
+			}
+			if err := palette.ValidateLevels(*lockBrightness, *lockContrast, *lockGamma); err != nil {
+				return err
+			}
+			if *lockTheme != "" {
+				if _, err := loadTheme(*lockTheme); err != nil {
+					return err
+				}
+			}
+			auth, err := lock.ParseAuth(*lockAuth)
+			if err != nil {
+				return err
+			}
+			if *lockRhythmTolerance < 0 || *lockRhythmTolerance > 1 {
+				return fmt.Errorf("--rhythm-tolerance must be within 0..1, got %g", *lockRhythmTolerance)
+			}
+			background, err := termbg.ParseMode(*lockBackground)
+			if err != nil {
+				return err
+			}
+			gradient, err := palette.ParseGradient(*lockGradient)
+			if err != nil {
+				return err
+			}
+			colors, err := palette.ParseDepth(*lockColors)
+			if err != nil {
+				return err
+			}
+			flameHeight, err := fire.ParseFlameHeight(*lockFlameHeight)
+			if err != nil {
+				return err
+			}
+			backend, err := render.ParseBackend(*lockBackend)
+			if err != nil {
+				return err
+			}
 			return execLock(lockConfig{
 				SocketProtect: *lockSocketProtect,
+				Auth:          auth,
 				Contribs:      *lockContribs,
+				Theme:         *lockTheme,
 				NoTicker:      *lockNoTicker,
 				Cooldown:      fire.CooldownSpeed(*lockCooldown),
+				DryRun:        *lockDryRun,
+				ASCII:         *lockASCII || unicodeUnsupported(),
+				Announcer:     announce.Multi{announcer, notifier},
+				Auto:          *lockAuto,
+				Events:        events,
+				Animation:     *lockAnimation,
+				Overlay:       *lockOverlay,
+				Reveal:        *lockReveal,
+				Background:    background,
+				Gradient:      gradient,
+				Colors:        colors,
+				FlameHeight:   flameHeight,
+				Daylight:      *lockDaylight,
+				Clock:         *lockClock,
+				Alerts:        *lockAlerts,
+				EmberAfter:    *lockEmberAfter,
+				Brightness:    *lockBrightness,
+				Contrast:      *lockContrast,
+				Gamma:         *lockGamma,
+				MaxLock:       *lockMaxLock,
+				MaxLockExec:   *lockMaxLockExec,
+				Backend:       backend,
+
+				Rhythm:          *lockRhythm,
+				RhythmTolerance: *lockRhythmTolerance,
+				SoftLockPane:    *lockSoftLockPane,
+
+				Yes: *lockYes,
+
+				AllClients: *lockAllClients,
+				ClientArgs: followerFlags(lockFlagSet),
+				Follow:     *lockFollow,
+			})
+		},
+	}
+
+	// Config command
+	configExportFlagSet := flag.NewFlagSet("yule-log config export", flag.ExitOnError)
+	configExportPassword := configExportFlagSet.Bool("password", false, "Include the lock password hash")
+	configExportStats := configExportFlagSet.Bool("stats", false, "Include the idle history")
+
+	configExportCmd := &ffcli.Command{
+		Name:       "export",
+		ShortUsage: "yule-log config export [flags] <bundle.tar.gz|->",
+		ShortHelp:  "Export the configuration to a bundle",
+		FlagSet:    configExportFlagSet,
+		Options:    envOptions,
+		Exec: func(_ context.Context, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("expected one bundle path, got %d arguments", len(args))
+			}
+			return execConfigExport(configExportConfig{
+				Path:     args[0],
+				Password: *configExportPassword,
+				Stats:    *configExportStats,
+			})
+		},
+	}
+
+	configImportFlagSet := flag.NewFlagSet("yule-log config import", flag.ExitOnError)
+	configImportForce := configImportFlagSet.Bool("force", false, "Replace existing files")
+	configImportDryRun := configImportFlagSet.Bool("dry-run", false, "List the bundle content without importing it")
+
+	configImportCmd := &ffcli.Command{
+		Name:       "import",
+		ShortUsage: "yule-log config import [flags] <bundle.tar.gz|->",
+		ShortHelp:  "Import a configuration bundle",
+		FlagSet:    configImportFlagSet,
+		Options:    envOptions,
+		Exec: func(_ context.Context, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("expected one bundle path, got %d arguments", len(args))
+			}
+			return execConfigImport(configImportConfig{
+				Path:   args[0],
+				Force:  *configImportForce,
+				DryRun: *configImportDryRun,
+			})
+		},
+	}
+
+	configCmd := &ffcli.Command{
+		Name:        "config",
+		ShortUsage:  "yule-log config <subcommand>",
+		ShortHelp:   "Export or import the configuration",
+		Subcommands: []*ffcli.Command{configExportCmd, configImportCmd},
+		Exec: func(_ context.Context, _ []string) error {
+			return flag.ErrHelp
+		},
+	}
+
+	infoCmd := &ffcli.Command{
+		Name:       "info",
+		ShortUsage: "yule-log info",
+		ShortHelp:  "Show what yule-log is doing: watcher, lock, config, theme, paths and recent log",
+		Exec:       func(_ context.Context, _ []string) error { return execInfo() },
+	}
+
+	dismissCmd := &ffcli.Command{
+		Name:       "dismiss",
+		ShortUsage: "yule-log dismiss",
+		ShortHelp:  "Close the running screensavers of every client (lock screens stay)",
+		Exec:       func(_ context.Context, _ []string) error { return execDismiss() },
+	}
+
+	reportFlagSet := flag.NewFlagSet("yule-log report", flag.ExitOnError)
+	reportOutput := reportFlagSet.String("o", "", "Path of the archive (default: yule-log-report-<time>.tar.gz)")
+
+	reportCmd := &ffcli.Command{
+		Name:       "report",
+		ShortUsage: "yule-log report [-o report.tar.gz]",
+		ShortHelp:  "Gather info, environment, redacted config, log and last crash for a bug report",
+		FlagSet:    reportFlagSet,
+		Exec:       func(_ context.Context, _ []string) error { return execReport(*reportOutput) },
+	}
+
+	soakFlagSet := flag.NewFlagSet("yule-log soak", flag.ExitOnError)
+	soakHours := soakFlagSet.Float64("hours", 8, "How long to run, in hours")
+	soakSize := soakFlagSet.String("size", defaultSoakSize, "Size of the headless screen")
+	soakTheme := soakFlagSet.String("theme", "", "Theme of the fire")
+	soakContribs := soakFlagSet.Bool("contribs", false, "Show the contribution graph, refreshed like in a real run")
+	soakEvery := soakFlagSet.Duration("every", time.Minute, "How often to sample the heap and goroutines")
+	soakWarmup := soakFlagSet.Duration("warmup", 5*time.Minute, "Run time before the baseline sample, while caches fill")
+	soakHeap := soakFlagSet.Int("max-heap-growth", 32, "Heap growth over the baseline that fails the run, in MiB")
+	soakGoroutines := soakFlagSet.Int("max-goroutine-growth", 8, "Goroutine growth over the baseline that fails the run")
+
+	soakCmd := &ffcli.Command{
+		Name:       "soak",
+		ShortUsage: "yule-log soak [--hours 8] [--size 200x50]",
+		ShortHelp:  "Run the screensaver headless for hours, failing if memory or goroutines leak",
+		FlagSet:    soakFlagSet,
+		Exec: func(_ context.Context, _ []string) error {
+			return execSoak(soakConfig{
+				Duration:        time.Duration(*soakHours * float64(time.Hour)),
+				Size:            *soakSize,
+				Theme:           *soakTheme,
+				Contribs:        *soakContribs,
+				Every:           *soakEvery,
+				Warmup:          *soakWarmup,
+				HeapGrowth:      *soakHeap,
+				GoroutineGrowth: *soakGoroutines,
 			})
 		},
 	}
 
+	hookCmd := &ffcli.Command{
+		Name:       "hook",
+		ShortUsage: "yule-log hook <" + strings.Join(idlectl.Shells, "|") + ">",
+		ShortHelp:  "Print a prompt hook reporting shell activity to the idle watcher",
+		LongHelp:   "Add it to your shell startup file, e.g. eval \"$(yule-log hook zsh)\" in ~/.zshrc\nor yule-log hook fish | source in ~/.config/fish/config.fish.",
+		Exec: func(_ context.Context, args []string) error {
+			if len(args) != 1 {
+				return flag.ErrHelp
+			}
+			exePath, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("finding executable path: %w", err)
+			}
+			snippet, err := idlectl.Hook(args[0], exePath)
+			if err != nil {
+				return err
+			}
+			fmt.Print(snippet)
+			return nil
+		},
+	}
+
 	// Root command
 	return &ffcli.Command{
 		ShortUsage:  "yule-log [flags] <subcommand>",
 		ShortHelp:   "A tmux screensaver with fire animation and git commit ticker",
 		LongHelp:    "Controls:\n  Arrow Up/Down   Adjust flame intensity\n  Any other key   Exit screensaver\n\nLock mode:\n  All keys feed the fire, Enter submits password",
 		FlagSet:     flag.NewFlagSet("yule-log", flag.ExitOnError),
-		Subcommands: []*ffcli.Command{runCmd, idleCmd, lockCmd},
-		Exec:        func(_ context.Context, _ []string) error { return execScreensaver(screensaverConfig{}) },
+		Options:     envOptions,
+		Subcommands: []*ffcli.Command{runCmd, idleCmd, lockCmd, configCmd, infoCmd, dismissCmd, hookCmd, soakCmd, reportCmd},
+		Exec: func(_ context.Context, _ []string) error {
+			return execScreensaver(screensaverConfig{
+				events:           fire.AllEvents,
+				eventMinInterval: defaultEventMinInterval,
+				eventMaxInterval: defaultEventMaxInterval,
+			})
+		},
 	}
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yule-log/internal/lock"
+)
+
+// typeKeys sends text as key events, then Enter, and returns the action of
+// Enter.
+func typeKeys(s *screensaver, text string) action {
+	for _, r := range text {
+		s.handleEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	return s.handleEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+}
+
+// TestRunLockEnter drives run --lock, which gets no loaded passwords from
+// lockScreenConfig, through the Enter key.
+func TestRunLockEnter(t *testing.T) {
+	for _, env := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME", "XDG_RUNTIME_DIR"} {
+		t.Setenv(env, t.TempDir())
+	}
+	_, err := lock.SavePassword([]byte("hunter2"), nil, lock.StoreFile)
+	require.NoError(t, err)
+
+	cfg := screensaverConfig{mode: ModeLock, auth: lock.AuthFile, noTicker: true}
+	require.NoError(t, cfg.applyTestMode(1, "40x12"))
+	s, err := prepareScreensaver(cfg)
+	require.NoError(t, err)
+	defer s.close()
+	s.lastKey = time.Now()
+
+	assert.Equal(t, actionNone, typeKeys(s, "guess"))
+	assert.Positive(t, s.wrongPasswordFrames, "a wrong password flashes")
+	assert.Equal(t, actionExit, typeKeys(s, "hunter2"), "the password ends the lock")
+}
+
+// TestRunLockPanicKeys checks that a panic sequence stays out of the
+// password, while a lone key of it is still typed.
+func TestRunLockPanicKeys(t *testing.T) {
+	for _, env := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME", "XDG_RUNTIME_DIR"} {
+		t.Setenv(env, t.TempDir())
+	}
+	_, err := lock.SavePassword([]byte("hunter2"), nil, lock.StoreFile)
+	require.NoError(t, err)
+
+	cfg := screensaverConfig{mode: ModeLock, auth: lock.AuthFile, noTicker: true}
+	require.NoError(t, cfg.applyTestMode(1, "40x12"))
+	s, err := prepareScreensaver(cfg)
+	require.NoError(t, err)
+	defer s.close()
+	s.lastKey = time.Now()
+	s.panicKeys = &lock.SequenceDetector{Keys: lock.KeySequence{tcell.KeyF1, tcell.KeyF1, tcell.KeyF2}}
+
+	pressKey := func(key tcell.Key) {
+		s.handleEvent(tcell.NewEventKey(key, 0, tcell.ModNone))
+	}
+	pressKey(tcell.KeyF1)
+	assert.Equal(t, actionNone, typeKeys(s, "hunter2"), "a lone F1 is part of the password")
+
+	pressKey(tcell.KeyF1)
+	pressKey(tcell.KeyF1)
+	assert.Empty(t, s.input.Bytes(), "a pending sequence isn't shown")
+	pressKey(tcell.KeyF1)
+	pressKey(tcell.KeyF2)
+	assert.Equal(t, actionNone, typeKeys(s, "hunter2"), "keys before the sequence are typed")
+
+	pressKey(tcell.KeyF1)
+	pressKey(tcell.KeyF1)
+	pressKey(tcell.KeyF2)
+	assert.Equal(t, actionExit, typeKeys(s, "hunter2"), "the sequence leaves no trace")
+}
+
+func TestRunLockNoPassword(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := screensaverConfig{mode: ModeLock, auth: lock.AuthFile, noTicker: true}
+	require.NoError(t, cfg.applyTestMode(1, "40x12"))
+	_, err := prepareScreensaver(cfg)
+	assert.ErrorIs(t, err, lock.ErrNoPassword)
+}
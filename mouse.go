@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+
+	"yule-log/internal/ticker"
+)
+
+// ---- Mouse
+
+// noticeDuration is how long ticker click feedback stays on screen.
+const noticeDuration = 2 * time.Second
+
+// commitEnvVar passes the clicked commit hash to --ticker-click-exec.
+const commitEnvVar = "YULE_LOG_COMMIT"
+
+// handleMouse tracks the pointer over the ticker and copies or opens the
+// commit under a click. In normal mode, clicking anywhere else exits like a
+// key press. The wheel adjusts the heat, see heat.go.
+func (s *screensaver) handleMouse(ev *tcell.EventMouse) action {
+	if s.pathEditor != nil {
+		s.handleMousePathEditor(ev)
+		return actionNone
+	}
+	if s.handleWheel(ev) {
+		return actionNone
+	}
+	x, y := ev.Position()
+	s.hoverItem, s.hovering = s.tickerItemAt(x, y)
+
+	if ev.Buttons()&tcell.Button1 == 0 {
+		return actionNone
+	}
+	if item, ok := s.tickerItemAt(x, y); ok {
+		s.openCommit(item)
+		return actionNone
+	}
+	if s.cfg.mode == ModeNormal {
+		return actionExit
+	}
+	return actionNone
+}
+
+// tickerItemAt returns the ticker item drawn at (x, y), if any.
+func (s *screensaver) tickerItemAt(x, y int) (ticker.Item, bool) {
+	length := s.tickerText.Len()
+	if !s.haveTicker || length == 0 || y < s.height-s.tickerRows() {
+		return ticker.Item{}, false
+	}
+	i, ok := s.tickerText.ItemAt((s.tickerOffset + x) % length)
+	if !ok || s.tickerText.Items[i].Hash == "" {
+		return ticker.Item{}, false
+	}
+	return s.tickerText.Items[i], true
+}
+
+// openCommit runs --ticker-click-exec for the commit, or copies its hash
+// to the tmux paste buffer. The hook's output is discarded: the terminal
+// belongs to the screensaver.
+func (s *screensaver) openCommit(item ticker.Item) {
+	short := shortHash(item.Hash)
+
+	if s.cfg.tickerClickExec != "" {
+		cmd := exec.Command("sh", "-c", s.cfg.tickerClickExec)
+		cmd.Env = append(os.Environ(), commitEnvVar+"="+item.Hash)
+		cmd.Dir = item.Dir
+		if cmd.Dir == "" {
+			cmd.Dir = s.tickerDir()
+		}
+		if err := cmd.Start(); err != nil {
+			s.setNotice("opening " + short + " failed: " + err.Error())
+			return
+		}
+		go func() { _ = cmd.Wait() }()
+		s.setNotice("opening " + short)
+		return
+	}
+
+	if err := exec.Command("tmux", "set-buffer", "--", item.Hash).Run(); err != nil {
+		s.setNotice("copying " + short + " failed: " + err.Error())
+		return
+	}
+	s.setNotice("copied " + short + " to the tmux buffer")
+}
+
+// setNotice shows a short message above the ticker.
+func (s *screensaver) setNotice(msg string) {
+	s.notice = msg
+	s.noticeFrames = framesFor(noticeDuration)
+}
+
+// renderNotice draws the current notice above the ticker, or the details
+// of the hovered commit.
+func (s *screensaver) renderNotice() {
+	switch {
+	case s.noticeFrames > 0:
+		s.noticeFrames--
+		s.renderOverlayLine(s.notice)
+	case s.hovering:
+		s.renderOverlayLine(commitDetails(s.hoverItem))
+	}
+}
+
+// commitDetails describes a commit on one line for the hover overlay.
+func commitDetails(item ticker.Item) string {
+	details := shortHash(item.Hash) + " " + item.Subject + " (" + item.Author
+	if !item.Time.IsZero() {
+		details += ", " + item.Time.Format("2006-01-02 15:04")
+	}
+	return details + ")"
+}
+
+func shortHash(hash string) string {
+	return hash[:min(len(hash), 7)]
+}
+
+// renderOverlayLine draws msg on the row just above the ticker.
+func (s *screensaver) renderOverlayLine(msg string) {
+	row := s.height - s.tickerRows() - 1
+	if row < 0 {
+		return
+	}
+	style := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
+	col := 0
+	for _, r := range " " + msg + " " {
+		width := uniseg.StringWidth(string(r))
+		if col+width > s.width {
+			break
+		}
+		if width > 0 {
+			s.screen.SetContent(col, row, r, nil, style)
+			col += width
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"yule-log/internal/fsutil"
+	"yule-log/internal/render"
+	"yule-log/internal/xdg"
+)
+
+// ---- Output
+
+// present transmits the composed frame to the terminal if the rate limiter
+// allows it, timing the write for automatic rate detection.
+func (s *screensaver) present() {
+	if !s.transmitting {
+		return
+	}
+	start := time.Now()
+	s.screen.Show()
+	if d := time.Since(start); d < render.StallThreshold {
+		s.rate.Observe(d)
+	} else {
+		s.rate.Discard()
+	}
+}
+
+// waitFrame waits for the next frame, skipping the frames missed during a
+// stall and logging it.
+func (s *screensaver) waitFrame() {
+	stall, stalled := s.clock.Wait(s.frameInterval())
+	if !stalled {
+		return
+	}
+	path, err := xdg.LogFile()
+	if err != nil {
+		return
+	}
+	line := fmt.Sprintf("%s frames stalled for %s, skipped %d (last frame took %s)\n",
+		stall.At.Format(time.RFC3339), stall.Duration.Round(time.Millisecond), stall.Skipped, s.clock.Work.Round(time.Millisecond))
+	_ = fsutil.Append(path, []byte(line), 0600)
+}
+
+// blendHeat returns the heat to display for cell i. When blending is
+// enabled, heat is averaged over the frames skipped since the last
+// transmitted frame so motion stays smooth at low output rates.
+func (s *screensaver) blendHeat(i, v int) int {
+	if !s.cfg.blend || s.rate.Every <= 1 {
+		return v
+	}
+	if len(s.blendAcc) != s.width*s.height {
+		s.blendAcc = make([]int, s.width*s.height)
+	}
+
+	s.blendAcc[i] += v
+	if !s.transmitting {
+		return v
+	}
+	avg := s.blendAcc[i] / (s.rate.Skipped() + 1)
+	s.blendAcc[i] = 0
+	return avg
+}
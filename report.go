@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+
+	"yule-log/internal/bundle"
+	"yule-log/internal/config"
+	"yule-log/internal/trigger"
+	"yule-log/internal/xdg"
+)
+
+// ---- Bug Report
+// yule-log report gathers what a bug report needs into one archive, in the
+// bundle format: the info output, the terminal and tmux environment
+// (rendering problems are mostly terminal-specific), the effective
+// configuration with secrets redacted, the end of the log and the last
+// crash. The password and unlock log are never included.
+
+// reportLogLines is how many lines of the log the report keeps.
+const reportLogLines = 500
+
+// reportEnvVars are the variables telling the terminal and locale apart.
+var reportEnvVars = []string{
+	"TERM", "COLORTERM", "TERM_PROGRAM", "TERM_PROGRAM_VERSION",
+	"LANG", "LC_ALL", "LC_CTYPE", "TMUX", "SSH_CONNECTION", "NO_COLOR",
+}
+
+func execReport(path string) error {
+	now := time.Now()
+	if path == "" {
+		path = fmt.Sprintf("yule-log-report-%s.tar.gz", now.Format("20060102-150405"))
+	}
+
+	var info, env bytes.Buffer
+	writeInfo(&info)
+	writeEnvironment(&env)
+	entries := []bundle.Entry{
+		{Name: "info.txt", Data: info.Bytes()},
+		{Name: "environment.txt", Data: env.Bytes()},
+	}
+	if data, err := reportConfig(); err == nil {
+		entries = append(entries, bundle.Entry{Name: "config.toml", Data: data})
+	} else {
+		entries = append(entries, bundle.Entry{Name: "config-error.txt", Data: []byte(err.Error() + "\n")})
+	}
+	if lines := recentLog(reportLogLines); len(lines) > 0 {
+		entries = append(entries, bundle.Entry{Name: "yule-log.log", Data: []byte(strings.Join(lines, "\n") + "\n")})
+	}
+	if crash, err := xdg.CrashFile(); err == nil {
+		if data, err := os.ReadFile(crash); err == nil {
+			entries = append(entries, bundle.Entry{Name: "crash.log", Data: data})
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("reading crash report: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("creating report: %w", err)
+	}
+	names, err := bundle.Write(f, entries, now)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	fmt.Printf("Wrote %s to %s\n", strings.Join(names, ", "), path)
+	fmt.Println("Secrets are redacted, but have a look before attaching it to an issue.")
+	return nil
+}
+
+// writeEnvironment writes the platform, terminal and tmux details to w.
+func writeEnvironment(w io.Writer) {
+	ctx, cancel := context.WithTimeout(context.Background(), infoTimeout)
+	defer cancel()
+
+	fmt.Fprintf(w, "Platform: %s/%s, %s\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+	fmt.Fprintf(w, "Unicode: %v\n", !unicodeUnsupported())
+	fmt.Fprintln(w, "Environment:")
+	for _, name := range reportEnvVars {
+		if v, ok := os.LookupEnv(name); ok {
+			fmt.Fprintf(w, "  %s=%s\n", name, v)
+		}
+	}
+
+	tmux := func(args ...string) string {
+		out, err := exec.CommandContext(ctx, "tmux", args...).Output()
+		if err != nil {
+			return "unavailable"
+		}
+		return strings.TrimSpace(string(out))
+	}
+	fmt.Fprintf(w, "tmux: %s\n", tmux("-V"))
+	if os.Getenv("TMUX") == "" {
+		return
+	}
+	fmt.Fprintf(w, "  default-terminal: %s\n", tmux("show-option", "-gqv", "default-terminal"))
+	fmt.Fprintf(w, "  terminal-features: %s\n", strings.ReplaceAll(tmux("show-option", "-sqv", "terminal-features"), "\n", " "))
+	fmt.Fprintln(w, "Clients:")
+	for _, line := range strings.Split(tmux("list-clients", "-F", trigger.ClientFormat), "\n") {
+		c, ok := trigger.ParseClient(line)
+		if !ok {
+			continue
+		}
+		caps := c.Capabilities()
+		fmt.Fprintf(w, "  %s: TERM=%s features=%s utf8=%v -> colors=%s ascii=%v\n",
+			c.Name, c.Term, strings.Join(c.Features, ","), c.UTF8, caps.Colors, caps.ASCII)
+	}
+}
+
+// reportConfig returns the effective configuration of the current
+// directory and the flag defaults of the global file, redacted.
+func reportConfig() ([]byte, error) {
+	conf, _ := config.Load(".") // Errors are in info.txt
+	data, err := toml.Marshal(conf.Redacted())
+	if err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	buf := bytes.NewBuffer(data)
+
+	path, err := xdg.ConfigFile()
+	if err != nil {
+		return buf.Bytes(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return buf.Bytes(), nil
+	}
+	for _, table := range config.FlagTables {
+		values, err := config.FlagDefaults(raw, table)
+		if err != nil || len(values) == 0 {
+			continue
+		}
+		values = config.RedactFlags(values)
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(buf, "\n[%s]\n", table)
+		for _, name := range names {
+			fmt.Fprintf(buf, "%s = %q\n", name, values[name])
+		}
+	}
+	return buf.Bytes(), nil
+}
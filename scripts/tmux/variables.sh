@@ -5,11 +5,29 @@
 
 # Default values
 readonly default_idle_time="300"           # 5 minutes
-readonly default_mode="fire"               # "fire" or "contribs"
+readonly default_mode="fire"               # "fire", "contribs" or a theme name
+readonly default_animation=""              # Animation name, empty = config or fire
+readonly default_overlay=""                # "sessions", empty = none
 readonly default_show_ticker="on"          # "on" or "off"
+readonly default_ascii="off"               # "on" or "off"
+readonly default_ignite="off"              # "on" or "off"
+readonly default_mouse="off"               # "on" or "off"
+readonly default_background="auto"         # "auto", "dark" or "light"
+readonly default_backend="tcell"           # "tcell" or "vt"
+readonly default_daylight="off"            # "on" or "off"
+readonly default_clock="off"               # "on" or "off"
+readonly default_alerts="off"              # "on" or "off"
+readonly default_ember_after="15m"         # Duration, "0" = never
+readonly default_idle_sequence=""          # e.g. "screensaver,contribs,lock", empty = off
+readonly default_idle_source="tmux"        # "tmux", "system" or "both"
 readonly default_lock_enabled="off"        # "on" or "off"
-readonly default_lock_timeout="0"          # 0 = manual only
+readonly default_lock_timeout="0"          # Seconds, 0 = lock on the first trigger
 readonly default_lock_socket_protect="on"  # "on" or "off"
+readonly default_lock_auth="file"          # "file" or "system" (macOS)
+readonly default_lock_notify="off"         # "off", "desktop" or "osc777"
+readonly default_lock_reveal="off"         # "on" or "off"
+readonly default_lock_max=""               # Duration such as "8h", empty = never
+readonly default_lock_all_clients="off"    # "on" or "off"
 
 # Minimum supported tmux version
 readonly supported_tmux_version="3.2"
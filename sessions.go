@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/inventory"
+)
+
+// ---- Session Overlay
+// A dim list of the tmux sessions and their windows in the top-left
+// corner, with the tmux activity markers (! bell, # activity, ~ silence).
+// Output since the screensaver started counts as activity, so a glance at
+// the idle screen tells where something is waiting. Tab toggles it; the
+// list is polled in the background while it is shown.
+
+// overlaySessions is the --overlay value showing the session list.
+const overlaySessions = "sessions"
+
+const (
+	// sessionsRefresh is how often the windows are listed again.
+	sessionsRefresh = 5 * time.Second
+	// sessionsTimeout bounds one listing.
+	sessionsTimeout = 500 * time.Millisecond
+)
+
+// validateOverlay checks an --overlay flag value. Empty shows none.
+func validateOverlay(name string) error {
+	if name == "" || name == overlaySessions {
+		return nil
+	}
+	return fmt.Errorf("unknown overlay %q (want %s)", name, overlaySessions)
+}
+
+// sessionOverlay is the state of the session list.
+type sessionOverlay struct {
+	shown    bool
+	polling  bool
+	since    time.Time // Screensaver start
+	window   string    // Window the screensaver runs in, its output is not activity
+	sessions []inventory.Session
+	failed   bool // tmux could not be reached
+}
+
+// initSessions shows the session list from the start with --overlay.
+func (s *screensaver) initSessions() {
+	s.sessions = &sessionOverlay{since: time.Now()}
+	if s.cfg.overlay == overlaySessions {
+		s.toggleSessions()
+	}
+}
+
+// toggleSessions shows or hides the session list. Polling starts the first
+// time it is shown.
+func (s *screensaver) toggleSessions() {
+	o := s.sessions
+	o.shown = !o.shown
+	if o.shown {
+		s.pollSessions()
+	}
+}
+
+// pollSessions starts listing the windows in the background, unless it
+// already runs.
+func (s *screensaver) pollSessions() {
+	o := s.sessions
+	if o.polling {
+		return
+	}
+	o.polling = true
+	pane := os.Getenv("TMUX_PANE")
+	s.addBackgroundSource(&backgroundSource{
+		every: sessionsRefresh,
+		fetch: func() func() {
+			ctx, cancel := context.WithTimeout(context.Background(), sessionsTimeout)
+			defer cancel()
+			var window string
+			if pane != "" {
+				out, _ := exec.CommandContext(ctx, "tmux", "display-message", "-p", "-t", pane, "#{window_id}").Output()
+				window = strings.TrimSpace(string(out))
+			}
+			sessions, err := inventory.List(ctx)
+			return func() {
+				o.failed = err != nil
+				if err == nil {
+					o.window, o.sessions = window, sessions
+				}
+			}
+		},
+	})
+}
+
+// renderSessions draws the session list below the top row, left of the
+// soft lock pane, one session per line. Windows with a marker stand out.
+func (s *screensaver) renderSessions() {
+	o := s.sessions
+	if o == nil || !o.shown || s.reveal != nil {
+		return
+	}
+	right := s.width
+	if s.paneView != nil {
+		right, _, _, _ = s.paneViewBox()
+	}
+	rows := s.height - s.tickerRows() - 2 // Between the top row and the notice line
+
+	base := tcell.StyleDefault.Foreground(s.theme.text).Background(tcell.ColorBlack)
+	dim, bright := base.Dim(true), base.Bold(true)
+	draw := func(row, col int, text string, style tcell.Style) int {
+		for _, r := range text {
+			if col >= right {
+				break
+			}
+			s.screen.SetContent(col, 1+row, r, nil, style)
+			col++
+		}
+		return col
+	}
+
+	switch {
+	case rows < 1:
+		return
+	case o.failed && len(o.sessions) == 0:
+		draw(0, 0, "tmux unreachable", dim)
+		return
+	}
+	for i, sess := range o.sessions {
+		if i == rows-1 && len(o.sessions) > rows {
+			draw(i, 0, fmt.Sprintf("+%d more", len(o.sessions)-i), dim)
+			return
+		}
+		col := draw(i, 0, sess.Name+":", dim)
+		for _, w := range sess.Windows {
+			style, text := dim, fmt.Sprintf(" %d:%s", w.Index, w.Name)
+			if o.window == "" || w.ID != o.window {
+				if m := w.Marker(o.since); m != 0 {
+					style, text = bright, text+string(m)
+				}
+			}
+			col = draw(i, col, text, style)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"yule-log/hooks"
+	"yule-log/internal/config"
+	"yule-log/internal/fsutil"
+	"yule-log/internal/lock"
+	"yule-log/internal/xdg"
+)
+
+// ---- Shell Hooks
+// The [hooks] table of the global configuration file runs shell commands
+// on screensaver and lock events, e.g. to pause notifications and mute
+// audio while locked:
+//
+//	[hooks]
+//	lock = "dunstctl set-paused true"
+//	unlock = "dunstctl set-paused false"
+//
+// Commands start in the background, so the fire never waits for them,
+// and are killed once over the timeout if yule-log still runs. Their
+// output is discarded (the screen belongs to the fire); failures go to the
+// log. The event name comes as YULE_LOG_EVENT and its details as
+// YULE_LOG_EVENT_* environment variables, apart from the YULE_LOG_* flag
+// variables: a hook running yule-log doesn't change its flags.
+
+// shellHooks runs the commands of a [hooks] table.
+type shellHooks struct {
+	conf    config.Hooks
+	timeout time.Duration
+}
+
+// loadShellHooks returns the hooks of the global configuration file, nil
+// if it sets none. Repository files can't set hooks.
+func loadShellHooks() *shellHooks {
+	path, err := xdg.ConfigFile()
+	if err != nil {
+		return nil
+	}
+	conf, err := config.LoadFile(path)
+	if err != nil {
+		return nil
+	}
+	if h := conf.Hooks; h.Start == nil && h.Idle == nil && h.Lock == nil && h.Unlock == nil && h.Failed == nil && h.Panic == nil {
+		return nil
+	}
+	return &shellHooks{conf: conf.Hooks, timeout: conf.HookTimeout()}
+}
+
+func (h *shellHooks) OnFrame(hooks.Frame)           {}
+func (h *shellHooks) OnKey(hooks.Key)               {}
+func (h *shellHooks) OnTickerItem(hooks.TickerItem) {}
+
+func (h *shellHooks) OnStart(s hooks.Start) {
+	h.run("start", h.conf.Start, "YULE_LOG_EVENT_MODE="+string(s.Mode))
+}
+
+func (h *shellHooks) OnTrigger(t hooks.Trigger) {
+	h.run("idle", h.conf.Idle,
+		"YULE_LOG_EVENT_IDLE="+strconv.Itoa(int(t.Idle.Seconds())),
+		"YULE_LOG_EVENT_LOCK="+strconv.FormatBool(t.Lock))
+}
+
+func (h *shellHooks) OnLock(l hooks.Lock) {
+	h.run("lock", h.conf.Lock, "YULE_LOG_EVENT_AUTO="+strconv.FormatBool(l.Auto))
+}
+
+func (h *shellHooks) OnUnlock(u hooks.Unlock) {
+	if !u.OK && !u.Expired {
+		h.run("failed", h.conf.Failed, "YULE_LOG_EVENT_FAILURES="+strconv.Itoa(u.Failures))
+		return
+	}
+	h.run("unlock", h.conf.Unlock, "YULE_LOG_EVENT_EXPIRED="+strconv.FormatBool(u.Expired))
+}
+
+func (h *shellHooks) OnPanic(p hooks.Panic) {
+	h.run("panic", h.conf.Panic, "YULE_LOG_EVENT_KEYS="+p.Keys)
+}
+
+// loadPanicKeys returns the detector of the panic keys of the global
+// configuration file, nil if it sets neither a panic hook nor keys.
+func loadPanicKeys() *lock.SequenceDetector {
+	path, err := xdg.ConfigFile()
+	if err != nil {
+		return nil
+	}
+	conf, err := config.LoadFile(path)
+	if err != nil || (conf.Hooks.Panic == nil && conf.Hooks.PanicKeys == nil) {
+		return nil
+	}
+	names := lock.DefaultPanicKeys
+	if conf.Hooks.PanicKeys != nil {
+		names = *conf.Hooks.PanicKeys
+	}
+	keys, err := lock.ParseKeySequence(names)
+	if err != nil {
+		return nil
+	}
+	return &lock.SequenceDetector{Keys: keys}
+}
+
+// run starts command, if set, with the event in its environment.
+func (h *shellHooks) run(event string, command *string, env ...string) {
+	if command == nil || *command == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	cmd := exec.CommandContext(ctx, "sh", "-c", *command)
+	cmd.Env = append(append(os.Environ(), "YULE_LOG_EVENT="+event), env...)
+	if err := cmd.Start(); err != nil {
+		cancel()
+		logHookFailure(event, err)
+		return
+	}
+	go func() {
+		defer cancel()
+		if err := cmd.Wait(); err != nil {
+			if ctx.Err() != nil {
+				err = fmt.Errorf("killed after %s", h.timeout)
+			}
+			logHookFailure(event, err)
+		}
+	}()
+}
+
+// logHookFailure records a failed hook command to the log.
+func logHookFailure(event string, err error) {
+	path, pathErr := xdg.LogFile()
+	if pathErr != nil {
+		return
+	}
+	line := fmt.Sprintf("%s hook %s: %v\n", time.Now().Format(time.RFC3339), event, err)
+	_ = fsutil.Append(path, []byte(line), 0600)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"yule-log/internal/fire"
+	"yule-log/internal/render"
+	"yule-log/internal/soak"
+)
+
+// ---- Soak Test
+// yule-log soak runs the screensaver headless for hours, at its real frame
+// rate and with its control socket, so that the ticker refreshes, the IPC
+// and the caches all run as they do overnight. The heap and goroutines
+// are sampled along the way; growing beyond the limits fails the run.
+
+// defaultSoakSize is the size of the soak screen: a large terminal, so
+// that every frame does a fair amount of work.
+const defaultSoakSize = "200x50"
+
+// soakStrikes is how many samples in a row must exceed a limit.
+const soakStrikes = 3
+
+type soakConfig struct {
+	Duration        time.Duration
+	Size            string
+	Theme           string
+	Contribs        bool
+	Every           time.Duration // Sampling interval
+	Warmup          time.Duration // Run time before the baseline sample
+	HeapGrowth      int           // MiB
+	GoroutineGrowth int
+}
+
+func execSoak(cfg soakConfig) error {
+	switch {
+	case cfg.Duration <= 0:
+		return fmt.Errorf("--hours must be positive")
+	case cfg.Every <= 0:
+		return fmt.Errorf("--every must be positive, got %s", cfg.Every)
+	case cfg.Warmup < 0 || cfg.Warmup >= cfg.Duration:
+		return fmt.Errorf("--warmup must be within the run, got %s", cfg.Warmup)
+	case cfg.HeapGrowth < 0 || cfg.GoroutineGrowth < 0:
+		return fmt.Errorf("growth limits must not be negative")
+	}
+	width, height, err := render.ParseSize(cfg.Size)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- execScreensaver(screensaverConfig{
+			theme:            cfg.Theme,
+			contribs:         cfg.Contribs,
+			events:           fire.AllEvents,
+			eventMinInterval: defaultEventMinInterval,
+			eventMaxInterval: defaultEventMaxInterval,
+			duration:         cfg.Duration,
+			headless:         true,
+			headlessWidth:    width,
+			headlessHeight:   height,
+			soak:             true,
+			stop:             stop,
+		})
+	}()
+
+	monitor := soak.Monitor{
+		Limits: soak.Limits{
+			HeapGrowth:      uint64(cfg.HeapGrowth) << 20,
+			GoroutineGrowth: cfg.GoroutineGrowth,
+			Strikes:         soakStrikes,
+		},
+		Warmup: cfg.Warmup,
+	}
+	fmt.Printf("Soaking at %dx%d for %s, sampling every %s.\n", width, height, cfg.Duration, cfg.Every)
+	check := func() error {
+		sample := soak.Read()
+		fmt.Printf("%s  heap %s  goroutines %d\n", sample.At.Format("15:04:05"), soak.FormatBytes(sample.HeapAlloc), sample.Goroutines)
+		return monitor.Check(sample)
+	}
+	if err := check(); err != nil {
+		return err
+	}
+
+	tick := time.NewTicker(cfg.Every)
+	defer tick.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return fmt.Errorf("soak: screensaver: %w", err)
+			}
+			if _, ok := monitor.Baseline(); !ok {
+				return errors.New("soak: the run ended before the baseline sample")
+			}
+			fmt.Println("Soak passed.")
+			return nil
+		case <-tick.C:
+			if err := check(); err != nil {
+				close(stop)
+				<-done
+				return fmt.Errorf("soak: %w", err)
+			}
+		}
+	}
+}
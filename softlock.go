@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/fire"
+	"yule-log/internal/prompt"
+)
+
+// ---- Soft Lock
+// A soft lock keeps one pane (a dashboard, a music player...) visible in a
+// window of the lock screen, mirrored from tmux and refreshed every second.
+// Ctrl+U/Ctrl+D page through its history, Ctrl+T and Ctrl+E jump to its
+// oldest and latest lines. These are never password keys; no key ever
+// reaches the pane itself, so typing still only feeds the password.
+
+const (
+	// paneViewRefresh is how often the mirrored pane is captured again.
+	paneViewRefresh = time.Second
+	// paneViewTimeout bounds one capture, so a stuck tmux can't freeze the
+	// lock screen.
+	paneViewTimeout = 500 * time.Millisecond
+)
+
+// paneView is the mirrored pane of a soft lock.
+type paneView struct {
+	target  string
+	snap    fire.Snapshot
+	scroll  int // Lines scrolled back into history
+	history int // Lines of history available
+	rows    int // Rows shown, set when drawn
+	frames  int // Frames since the last capture
+}
+
+// resolvePane checks that target names an existing pane and returns its
+// unique id, so the soft lock follows the pane even if windows move.
+func resolvePane(ctx context.Context, target string) (string, error) {
+	out, err := exec.CommandContext(ctx, "tmux", "display-message", "-p", "-t", target, "#{pane_id}").Output()
+	if err != nil {
+		return "", fmt.Errorf("finding pane %q: %w", target, err)
+	}
+	id := strings.TrimSpace(string(out))
+	if id == "" {
+		return "", fmt.Errorf("finding pane %q: no such pane", target)
+	}
+	return id, nil
+}
+
+// capture refreshes the pane content at the current scroll position.
+// Failures keep the last content on screen.
+func (v *paneView) capture() {
+	ctx, cancel := context.WithTimeout(context.Background(), paneViewTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "tmux", "display-message", "-p", "-t", v.target, "#{history_size}").Output()
+	if err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(string(out))); err == nil {
+			v.history = n
+			v.scroll = min(v.scroll, n)
+		}
+	}
+
+	start := strconv.Itoa(-v.scroll)
+	out, err = exec.CommandContext(ctx, "tmux", "capture-pane", "-p", "-t", v.target, "-S", start).Output()
+	if err != nil {
+		return
+	}
+	snap := fire.ParseSnapshot(string(out))
+	// capture-pane prints from the start line to the bottom of the pane:
+	// keep the first lines so scrolling back shows older content.
+	if v.scroll > 0 && v.rows > 0 && len(snap) > v.rows {
+		snap = snap[:v.rows]
+	}
+	v.snap = snap
+	v.frames = 0
+}
+
+// scrollBy moves through the pane history and captures right away.
+func (v *paneView) scrollBy(lines int) {
+	v.scroll = max(min(v.scroll+lines, v.history), 0)
+	v.capture()
+}
+
+// initPaneView starts mirroring the soft lock pane.
+func (s *screensaver) initPaneView() {
+	if s.cfg.mode != ModeLock || s.cfg.softLockPane == "" {
+		return
+	}
+	s.paneView = &paneView{target: s.cfg.softLockPane}
+	s.paneView.capture()
+}
+
+// updatePaneView captures the pane again every paneViewRefresh.
+func (s *screensaver) updatePaneView() {
+	if s.paneView == nil {
+		return
+	}
+	s.paneView.frames++
+	if s.paneView.frames >= framesFor(paneViewRefresh) {
+		s.paneView.capture()
+	}
+}
+
+// handleKeyPaneView scrolls the mirrored pane. It reports whether the key
+// was used.
+func (s *screensaver) handleKeyPaneView(ev *tcell.EventKey) bool {
+	v := s.paneView
+	if v == nil {
+		return false
+	}
+	page := max(v.rows-1, 1)
+	switch ev.Key() {
+	case prompt.KeyScrollUp:
+		v.scrollBy(page)
+	case prompt.KeyScrollDown:
+		v.scrollBy(-page)
+	case prompt.KeyScrollTop:
+		v.scrollBy(v.history)
+	case prompt.KeyScrollBottom:
+		v.scrollBy(-v.scroll)
+	default:
+		return false
+	}
+	return true
+}
+
+// paneViewBox returns the area of the mirrored pane, border included: the
+// top-right quarter of the screen, below the password indicator.
+func (s *screensaver) paneViewBox() (x, y, width, height int) {
+	width = max(s.width/2, min(s.width, 20))
+	height = max((s.height-s.tickerRows())*2/3, min(s.height-s.tickerRows()-1, 5))
+	return s.width - width, 1, width, height
+}
+
+// renderPaneView draws the mirrored pane in a frame over the animation.
+func (s *screensaver) renderPaneView() {
+	v := s.paneView
+	if v == nil || s.reveal != nil {
+		return
+	}
+	x0, y0, width, height := s.paneViewBox()
+	if width < 3 || height < 3 {
+		return
+	}
+	v.rows = height - 2
+
+	border := tcell.StyleDefault.Foreground(s.theme.text).Dim(true)
+	content := tcell.StyleDefault.Foreground(s.theme.text).Background(tcell.ColorBlack)
+	box := func(x, y int, r rune) { s.screen.SetContent(x0+x, y0+y, r, nil, border) }
+	for x := 1; x < width-1; x++ {
+		box(x, 0, '-')
+		box(x, height-1, '-')
+	}
+	for y := 1; y < height-1; y++ {
+		box(0, y, '|')
+		box(width-1, y, '|')
+	}
+	for _, corner := range [][2]int{{0, 0}, {width - 1, 0}, {0, height - 1}, {width - 1, height - 1}} {
+		box(corner[0], corner[1], '+')
+	}
+
+	title := " " + v.target + " "
+	if v.scroll > 0 {
+		title += fmt.Sprintf("[-%d] ", v.scroll)
+	}
+	for i, r := range title {
+		if 2+i < width-2 {
+			box(2+i, 0, r)
+		}
+	}
+
+	// Show the bottom of the capture, like the pane itself, unless
+	// scrolled back.
+	first := 0
+	if v.scroll == 0 {
+		first = max(len(v.snap)-v.rows, 0)
+	}
+	for row := 0; row < v.rows; row++ {
+		for col := 0; col < width-2; col++ {
+			s.screen.SetContent(x0+1+col, y0+1+row, v.snap.At(col, first+row), nil, content)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"yule-log/internal/calendar"
+	"yule-log/internal/config"
+	"yule-log/internal/forge"
+	"yule-log/internal/ticker"
+	"yule-log/internal/xdg"
+)
+
+// ---- Background Ticker Sources
+// Sources that are slow to fetch (a repository scan, a web API) start from
+// their cached items, so they show up right away, and fetch again in the
+// background whenever the items are older than their refresh interval. A
+// fetch never blocks a frame.
+
+// backgroundSource is a ticker source fetched in the background.
+type backgroundSource struct {
+	every   time.Duration
+	fetched time.Time // Time of the items shown
+	// fetch runs on its own goroutine. It returns the function applying
+	// its result on the render loop, or nil if the fetch failed.
+	fetch   func() func()
+	updates chan func() // Result of the running fetch, nil if none
+}
+
+// fetchIfStale starts a fetch when the items are stale and no fetch is
+// running.
+func (b *backgroundSource) fetchIfStale() {
+	if b.updates != nil || time.Since(b.fetched) < b.every {
+		return
+	}
+	updates := make(chan func(), 1)
+	b.updates = updates
+	go func() {
+		defer guard()
+		updates <- b.fetch()
+	}()
+}
+
+// addBackgroundSource registers a source and starts fetching its items if
+// the cached ones are stale.
+func (s *screensaver) addBackgroundSource(b *backgroundSource) {
+	s.background = append(s.background, b)
+	b.fetchIfStale()
+}
+
+// updateBackgroundSources picks up the results of finished fetches and
+// starts the next ones when due.
+func (s *screensaver) updateBackgroundSources() {
+	for _, b := range s.background {
+		select {
+		case apply := <-b.updates:
+			// A failed fetch is retried after b.every too.
+			b.updates = nil
+			b.fetched = time.Now()
+			if apply != nil {
+				apply()
+			}
+		default:
+			b.fetchIfStale()
+		}
+	}
+}
+
+// tickerProviders starts each ticker source, by name: a provider returns
+// the items to show first and registers what keeps them up to date. A new
+// source adds its provider here and its name to ticker.Sources.
+var tickerProviders = map[string]func(*screensaver) []ticker.Item{
+	ticker.SourceCommits:  (*screensaver).initCommits,
+	ticker.SourceTodos:    (*screensaver).initTodos,
+	ticker.SourceForge:    (*screensaver).initForge,
+	ticker.SourceCalendar: (*screensaver).initCalendar,
+	ticker.SourceCommand:  (*screensaver).initCommand,
+	ticker.SourceFeed:     (*screensaver).initFeeds,
+}
+
+// setTickerItems replaces the items of a source.
+func (s *screensaver) setTickerItems(source string, items []ticker.Item) {
+	s.tickerItems[source] = items
+	s.layoutTicker()
+}
+
+// ---- Commits Source
+
+// commitsRefresh is how often the commits are read again.
+const commitsRefresh = 5 * time.Minute
+
+// initCommits reads the commits of the ticker repositories in the
+// background, so a large repository doesn't delay the first frame.
+// Headless runs read them at once: they don't wait between frames.
+func (s *screensaver) initCommits() []ticker.Item {
+	opts := s.tickerOpts
+	if s.cfg.headless && !s.cfg.soak {
+		return s.commitItems(opts)
+	}
+	b := &backgroundSource{every: commitsRefresh}
+	b.fetch = func() func() {
+		items := s.commitItems(opts)
+		return func() { s.setTickerItems(ticker.SourceCommits, items) }
+	}
+	s.addBackgroundSource(b)
+	return nil
+}
+
+// ---- TODO Source
+
+// maxTodoDirs is the number of directories listed, most TODOs first.
+const maxTodoDirs = 8
+
+// initTodos returns the cached TODO counts of the ticker repository and
+// scans it in the background.
+func (s *screensaver) initTodos() []ticker.Item {
+	root := config.RepoRoot(s.tickerDir())
+	if root == "" {
+		return nil
+	}
+	path, err := xdg.TodoCacheFile()
+	if err != nil {
+		return nil
+	}
+
+	ascii := s.cfg.ascii
+	b := &backgroundSource{every: ticker.TodoStale}
+	b.fetch = func() func() {
+		counts, partial, err := ticker.ScanTodos(root)
+		if err != nil {
+			return nil
+		}
+		snap := ticker.TodoSnapshot{Time: time.Now(), Counts: counts}
+		snaps := []ticker.TodoSnapshot{snap}
+		// Without a cache the counts still show, only without a trend.
+		// Partial counts of a huge repository show but aren't a trend point.
+		if !partial && ticker.RecordTodos(path, root, snap) == nil {
+			snaps = ticker.LoadTodoCache(path)[root]
+		}
+		items := ticker.TodoItems(snaps, snap.Time, maxTodoDirs, ascii)
+		return func() { s.setTickerItems(ticker.SourceTodos, items) }
+	}
+
+	snaps := ticker.LoadTodoCache(path)[root]
+	if len(snaps) > 0 {
+		b.fetched = snaps[len(snaps)-1].Time
+	}
+	s.addBackgroundSource(b)
+	return ticker.TodoItems(snaps, time.Now(), maxTodoDirs, ascii)
+}
+
+// ---- Forge Source
+
+// forgeTimeout bounds one fetch of the forge items.
+const forgeTimeout = 30 * time.Second
+
+// initForge returns the cached pull requests and issues of the configured
+// forge and fetches them again in the background.
+func (s *screensaver) initForge() []ticker.Item {
+	var backend forge.Backend
+	if s.conf.Forge.Backend != nil {
+		backend = forge.Backend(*s.conf.Forge.Backend)
+	}
+	var url string
+	if s.conf.Forge.URL != nil {
+		url = *s.conf.Forge.URL
+	}
+	path, err := xdg.ForgeCacheFile()
+	if err != nil {
+		return nil
+	}
+	key := forge.CacheKey(backend, url)
+
+	b := &backgroundSource{every: ticker.ForgeRefresh}
+	b.fetch = func() func() {
+		f, err := forge.New(backend, forge.Options{URL: url, Token: forge.Token(backend)})
+		if err != nil {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), forgeTimeout)
+		defer cancel()
+		found, err := f.Inbox(ctx)
+		if err != nil {
+			return nil
+		}
+		now := time.Now()
+		_ = forge.SaveCache(path, key, forge.Cached{Fetched: now, Items: found})
+		items := ticker.ForgeItems(found, now)
+		return func() { s.setTickerItems(ticker.SourceForge, items) }
+	}
+
+	cached, _ := forge.LoadCache(path, key)
+	b.fetched = cached.Fetched
+	s.addBackgroundSource(b)
+	return ticker.ForgeItems(cached.Items, time.Now())
+}
+
+// ---- Calendar Source
+
+const (
+	// calendarTimeout bounds one fetch of the calendar events.
+	calendarTimeout = 30 * time.Second
+	// calendarFlashAhead is how close an event must be to flash the
+	// screen, with [calendar] flash.
+	calendarFlashAhead = 2 * time.Minute
+	// alertDuration and alertBlink shape the screen flash: the screen is
+	// shown in reverse video every other alertBlink for alertDuration.
+	alertDuration = 1500 * time.Millisecond
+	alertBlink    = 250 * time.Millisecond
+)
+
+// calendarState holds the events of the calendar source, whose items are
+// built again every minute as they count down.
+type calendarState struct {
+	events  []calendar.Event
+	built   time.Time       // Minute the items were built for
+	flash   bool            // Flash the screen before an event
+	flashed map[string]bool // Events already flashed, by start and summary
+}
+
+// initCalendar fetches the events of the configured calendar in the
+// background. Nothing is cached: the items appear once fetched.
+func (s *screensaver) initCalendar() []ticker.Item {
+	conf := s.conf.Calendar
+	if conf.URL == nil && conf.Command == nil {
+		return nil
+	}
+	s.calendar = &calendarState{flash: conf.Flash != nil && *conf.Flash, flashed: map[string]bool{}}
+
+	b := &backgroundSource{every: ticker.CalendarRefresh}
+	b.fetch = func() func() {
+		ctx, cancel := context.WithTimeout(context.Background(), calendarTimeout)
+		defer cancel()
+
+		// Expand recurring events far enough to last until the next fetch.
+		now := time.Now()
+		from, to := now.Add(-time.Hour), now.Add(ticker.CalendarRefresh+ticker.CalendarWindow)
+		var events []calendar.Event
+		fetched := false
+		if conf.URL != nil {
+			if found, err := calendar.FetchICS(ctx, *conf.URL, from, to); err == nil {
+				events, fetched = append(events, found...), true
+			}
+		}
+		if conf.Command != nil {
+			if found, err := calendar.RunCommand(ctx, *conf.Command); err == nil {
+				events, fetched = append(events, found...), true
+			}
+		}
+		if !fetched {
+			return nil
+		}
+		return func() {
+			s.calendar.events = events
+			s.calendar.built = time.Time{}
+		}
+	}
+	s.addBackgroundSource(b)
+	return nil
+}
+
+// updateCalendar builds the calendar items again when the minute changes
+// and flashes the screen when an event is about to start.
+func (s *screensaver) updateCalendar() {
+	c := s.calendar
+	if c == nil {
+		return
+	}
+	now := time.Now()
+	if minute := now.Truncate(time.Minute); !minute.Equal(c.built) {
+		c.built = minute
+		s.setTickerItems(ticker.SourceCalendar, ticker.CalendarItems(c.events, now, s.cfg.ascii))
+	}
+
+	if !c.flash {
+		return
+	}
+	for _, ev := range c.events {
+		key := ev.Start.String() + " " + ev.Summary
+		if ev.AllDay || c.flashed[key] || ev.Start.Before(now) || ev.Start.Sub(now) > calendarFlashAhead {
+			continue
+		}
+		c.flashed[key] = true
+		s.alertFrames = framesFor(alertDuration)
+		s.setNotice(calendar.Label(ev, now))
+	}
+}
+
+// renderAlert flashes the whole screen in reverse video.
+func (s *screensaver) renderAlert() {
+	if s.alertFrames <= 0 {
+		return
+	}
+	s.alertFrames--
+	if (s.alertFrames/max(framesFor(alertBlink), 1))%2 == 1 {
+		return
+	}
+	for y := 0; y < s.height; y++ {
+		for x := 0; x < s.width; {
+			r, comb, style, width := s.screen.GetContent(x, y)
+			s.screen.SetContent(x, y, r, comb, style.Reverse(true))
+			x += max(width, 1)
+		}
+	}
+}
+
+// ---- Command Source
+
+// commandTimeout bounds one run of the ticker command.
+const commandTimeout = 30 * time.Second
+
+// tickerCommand returns the shell command of the command source, from
+// --ticker-cmd or the [ticker] command setting.
+func (s *screensaver) tickerCommand() string {
+	if s.cfg.tickerCmd != "" {
+		return s.cfg.tickerCmd
+	}
+	if s.conf.Ticker.Command != nil {
+		return *s.conf.Ticker.Command
+	}
+	return ""
+}
+
+// initCommand runs the ticker command in the background, again every
+// minute. Its items appear once it has run.
+func (s *screensaver) initCommand() []ticker.Item {
+	command := s.tickerCommand()
+	if command == "" {
+		return nil
+	}
+	b := &backgroundSource{every: ticker.CommandRefresh}
+	b.fetch = func() func() {
+		ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+		defer cancel()
+		items, err := ticker.RunCommand(ctx, command)
+		if err != nil {
+			return nil
+		}
+		return func() { s.setTickerItems(ticker.SourceCommand, items) }
+	}
+	s.addBackgroundSource(b)
+	return nil
+}
+
+// ---- Feed Source
+
+// feedTimeout bounds one fetch of every feed.
+const feedTimeout = 30 * time.Second
+
+// initFeeds fetches the [ticker] feeds in the background. A feed that
+// fails is left out until the next fetch; nothing is cached.
+func (s *screensaver) initFeeds() []ticker.Item {
+	urls := s.conf.Ticker.Feeds
+	if len(urls) == 0 {
+		return nil
+	}
+	b := &backgroundSource{every: ticker.FeedRefresh}
+	b.fetch = func() func() {
+		ctx, cancel := context.WithTimeout(context.Background(), feedTimeout)
+		defer cancel()
+		var feeds []ticker.Feed
+		for _, url := range urls {
+			if feed, err := ticker.FetchFeed(ctx, url); err == nil {
+				feeds = append(feeds, feed)
+			}
+		}
+		if len(feeds) == 0 {
+			return nil
+		}
+		items := ticker.FeedItems(feeds, time.Now())
+		return func() { s.setTickerItems(ticker.SourceFeed, items) }
+	}
+	s.addBackgroundSource(b)
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// ---- Startup Trace
+// The first frame should be on screen within startupBudget of the process
+// start: slow work (git, network, caches) is left to background sources,
+// see sources.go. --trace-startup records how long each phase of the
+// startup took and prints the breakdown on exit, once the terminal is
+// restored.
+
+// startupBudget is the target time from process start to the first frame.
+const startupBudget = 50 * time.Millisecond
+
+// processStart approximates the start of the process: package variables
+// are initialized before main runs.
+var processStart = time.Now()
+
+// startupTrace records the startup phases. A nil trace records nothing.
+type startupTrace struct {
+	last   time.Time
+	phases []startupPhase
+}
+
+type startupPhase struct {
+	name string
+	took time.Duration
+}
+
+func newStartupTrace() *startupTrace {
+	return &startupTrace{last: processStart}
+}
+
+// mark ends the phase called name, started by the previous mark.
+func (t *startupTrace) mark(name string) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.phases = append(t.phases, startupPhase{name: name, took: now.Sub(t.last)})
+	t.last = now
+}
+
+// write prints the phases and their total, flagging a total over the
+// budget.
+func (t *startupTrace) write(w io.Writer) {
+	if t == nil {
+		return
+	}
+	var total time.Duration
+	for _, p := range t.phases {
+		fmt.Fprintf(w, "%-14s %8s\n", p.name, formatPhase(p.took))
+		total += p.took
+	}
+	fmt.Fprintf(w, "%-14s %8s", "total", formatPhase(total))
+	if total > startupBudget {
+		fmt.Fprintf(w, "  (over the %s budget)", startupBudget)
+	}
+	fmt.Fprintln(w)
+}
+
+// formatPhase formats a phase duration in milliseconds.
+func formatPhase(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d.Microseconds())/1000)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"yule-log/internal/fire"
+)
+
+// ---- Transitions
+
+// igniteDuration is how long the burn front takes to climb the pane.
+const igniteDuration = 2 * time.Second
+
+// capturePane returns the text of the pane underneath the popup. Without a
+// target, tmux resolves the client's active pane.
+func capturePane(ctx context.Context) (fire.Snapshot, error) {
+	out, err := exec.CommandContext(ctx, "tmux", "capture-pane", "-p").Output()
+	if err != nil {
+		return nil, err
+	}
+	return fire.ParseSnapshot(string(out)), nil
+}
+
+// initIgnition captures the pane and starts burning it away. Failing to
+// capture just skips the transition. Only the fire burns the pane.
+func (s *screensaver) initIgnition() {
+	if !s.cfg.ignite || s.animationName != animationFire {
+		return
+	}
+	snap, err := capturePane(context.Background())
+	if err != nil || len(snap) == 0 {
+		return
+	}
+	s.ignition = fire.NewIgnition(snap, s.width, s.height, framesFor(igniteDuration), s.rng)
+}
+
+// stepIgnition turns burning characters into heat sources. It reports
+// whether the ignition is still running, in which case the regular heat
+// sources stay off.
+func (s *screensaver) stepIgnition() bool {
+	if s.ignition == nil {
+		return false
+	}
+	s.ignition.Step()
+	if s.ignition.Done() {
+		s.ignition = nil
+		return false
+	}
+
+	for row := 0; row < s.height; row++ {
+		for col := 0; col < s.width; col++ {
+			if _, state := s.ignition.Cell(col, row); state == fire.CellBurning {
+				s.sim.Heat[row*s.width+col] = s.heatPower
+			}
+		}
+	}
+	return true
+}
+
+// renderIgnition draws the characters that haven't burnt away yet over
+// the fire.
+func (s *screensaver) renderIgnition() {
+	if s.ignition == nil {
+		return
+	}
+	burning := s.styleForValue(s.heatPower)
+	for row := 0; row < s.height-s.tickerRows(); row++ {
+		for col := 0; col < s.width; col++ {
+			r, state := s.ignition.Cell(col, row)
+			switch state {
+			case fire.CellIntact:
+				s.screen.SetContent(col, row, r, nil, tcell.StyleDefault)
+			case fire.CellBurning:
+				s.screen.SetContent(col, row, r, nil, burning)
+			}
+		}
+	}
+}
+
+// revealDuration is how long the pane content takes to emerge through the
+// dying flames after unlock.
+const revealDuration = 1500 * time.Millisecond
+
+// startReveal begins the unlock transition, with --reveal. It returns
+// false when there is nothing to reveal and the popup should close right
+// away.
+func (s *screensaver) startReveal() bool {
+	return s.cfg.reveal && s.revealPane()
+}
+
+// revealPane starts revealing the pane content through the dying fire,
+// the closing transition of the unlock and of --duration. It returns
+// false when there is nothing to reveal.
+func (s *screensaver) revealPane() bool {
+	if s.animationName != animationFire {
+		return false
+	}
+	snap, err := capturePane(context.Background())
+	if err != nil || len(snap) == 0 {
+		return false
+	}
+	s.ignition = nil
+	s.reveal = fire.NewReveal(snap, framesFor(revealDuration), maxHeat)
+	return true
+}
+
+// stepReveal advances the unlock transition. It reports whether the reveal
+// is running, in which case no new heat is added so the flames die down.
+func (s *screensaver) stepReveal() bool {
+	if s.reveal == nil {
+		return false
+	}
+	s.reveal.Step()
+	return true
+}
+
+// renderReveal draws the pane content wherever the fire has cooled down.
+// The ticker rows are revealed too since the ticker is hidden meanwhile.
+func (s *screensaver) renderReveal() {
+	if s.reveal == nil {
+		return
+	}
+	for row := 0; row < s.height; row++ {
+		for col := 0; col < s.width; col++ {
+			if r, ok := s.reveal.Cell(col, row, s.sim.Heat[row*s.width+col]); ok {
+				s.screen.SetContent(col, row, r, nil, tcell.StyleDefault)
+			}
+		}
+	}
+}
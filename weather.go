@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+
+	"yule-log/internal/ticker"
+	"yule-log/internal/weather"
+)
+
+// ---- Weather
+// --weather shows a one-line weather summary at the right end of the row
+// above the ticker, for screensavers left up as a dashboard. It comes
+// from wttr.in, or from the [weather] command, and is fetched again every
+// half hour in the background.
+
+// weatherTimeout bounds one fetch of the weather.
+const weatherTimeout = 15 * time.Second
+
+// initWeather starts fetching the weather in the background. Nothing is
+// cached: the line appears once fetched.
+func (s *screensaver) initWeather() {
+	if !s.cfg.weather {
+		return
+	}
+	conf := s.conf.Weather
+	var location string
+	if conf.Location != nil {
+		location = *conf.Location
+	}
+	ascii := s.cfg.ascii
+
+	b := &backgroundSource{every: weather.Refresh}
+	b.fetch = func() func() {
+		ctx, cancel := context.WithTimeout(context.Background(), weatherTimeout)
+		defer cancel()
+		var line string
+		var err error
+		if conf.Command != nil {
+			line, err = weather.RunCommand(ctx, *conf.Command)
+		} else {
+			line, err = weather.Fetch(ctx, weather.DefaultURL, location, ascii)
+		}
+		if err != nil {
+			return nil // Keep the last line
+		}
+		line = ticker.Sanitize(line)
+		return func() { s.weather = line }
+	}
+	s.addBackgroundSource(b)
+}
+
+// renderWeather draws the weather line right-aligned above the ticker.
+func (s *screensaver) renderWeather() {
+	row := s.height - s.tickerRows() - 1
+	if s.weather == "" || row < 0 || s.reveal != nil {
+		return
+	}
+	text := " " + s.weather + " "
+	col := s.width - uniseg.StringWidth(text)
+	if col < 0 {
+		return // Too narrow for the whole line
+	}
+	style := tcell.StyleDefault.Foreground(s.theme.text).Background(tcell.ColorBlack).Dim(true)
+	g := uniseg.NewGraphemes(text)
+	for g.Next() {
+		runes := g.Runes()
+		s.screen.SetContent(col, row, runes[0], runes[1:], style)
+		col += g.Width()
+	}
+}